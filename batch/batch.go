@@ -0,0 +1,293 @@
+// Package batch verifies the provenance of many artifacts concurrently.
+//
+// A batch is a list of items, each an artifact with its provenance and the
+// expectations it must meet. Batches are read from manifests by the CLI
+// and received as requests by the REST service.
+//
+// Example manifest:
+//
+//	version: 1
+//	items:
+//	  - artifactPath: dist/app-linux-amd64
+//	    provenancePath: dist/app.intoto.jsonl
+//	    source: github.com/org/app
+//	    versionedTag: v1
+//	  - name: app-darwin-arm64
+//	    artifactHash: 0a2b4c...
+//	    provenancePath: dist/app.intoto.jsonl
+//	    source: github.com/org/app
+//	    builderID: https://github.com/slsa-framework/slsa-github-generator/.github/workflows/builder_go_slsa3.yml
+package batch
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"sigs.k8s.io/yaml"
+
+	serrors "github.com/slsa-framework/slsa-verifier/v2/errors"
+	"github.com/slsa-framework/slsa-verifier/v2/options"
+	"github.com/slsa-framework/slsa-verifier/v2/report"
+	"github.com/slsa-framework/slsa-verifier/v2/verifiers"
+)
+
+// Version is the only supported version of the manifest format.
+const Version = 1
+
+// DefaultConcurrency is the number of items verified at once by default.
+const DefaultConcurrency = 8
+
+// Manifest is a batch read from a file.
+type Manifest struct {
+	Version int    `json:"version"`
+	Items   []Item `json:"items"`
+}
+
+// Item is an artifact to verify. The artifact is given by its path or its
+// sha256 digest, and the provenance by its path or its content.
+type Item struct {
+	// Name identifies the item in the results. Defaults to the path of
+	// the artifact, or else its digest.
+	Name string `json:"name,omitempty"`
+
+	ArtifactPath   string `json:"artifactPath,omitempty"`
+	ArtifactHash   string `json:"artifactHash,omitempty"`
+	ProvenancePath string `json:"provenancePath,omitempty"`
+	// Provenance is the content of the provenance, encoded in base64.
+	Provenance string `json:"provenance,omitempty"`
+
+	// Source is the expected source repository, e.g. github.com/org/repo.
+	Source         string            `json:"source"`
+	BuilderID      *string           `json:"builderID,omitempty"`
+	Tag            *string           `json:"tag,omitempty"`
+	Branch         *string           `json:"branch,omitempty"`
+	VersionedTag   *string           `json:"versionedTag,omitempty"`
+	WorkflowInputs map[string]string `json:"workflowInputs,omitempty"`
+
+	// IncludeProvenance includes the verified provenance in the report.
+	IncludeProvenance bool `json:"includeProvenance,omitempty"`
+}
+
+// Load reads and validates the manifest at the given path. The relative
+// paths of its items are resolved against the directory of the manifest.
+func Load(manifestPath string) (*Manifest, error) {
+	content, err := os.ReadFile(manifestPath)
+	if err != nil {
+		return nil, err
+	}
+	m, err := FromBytes(content)
+	if err != nil {
+		return nil, err
+	}
+	dir := filepath.Dir(manifestPath)
+	for i := range m.Items {
+		it := &m.Items[i]
+		if it.ArtifactPath != "" && !filepath.IsAbs(it.ArtifactPath) {
+			it.ArtifactPath = filepath.Join(dir, it.ArtifactPath)
+		}
+		if it.ProvenancePath != "" && !filepath.IsAbs(it.ProvenancePath) {
+			it.ProvenancePath = filepath.Join(dir, it.ProvenancePath)
+		}
+	}
+	return m, nil
+}
+
+// FromBytes parses and validates a YAML or JSON manifest.
+func FromBytes(content []byte) (*Manifest, error) {
+	var m Manifest
+	if err := yaml.UnmarshalStrict(content, &m); err != nil {
+		return nil, fmt.Errorf("%w: manifest: %w", serrors.ErrorInvalidFormat, err)
+	}
+	if m.Version != Version {
+		return nil, fmt.Errorf("%w: manifest: unsupported version %d, expected %d",
+			serrors.ErrorInvalidFormat, m.Version, Version)
+	}
+	if len(m.Items) == 0 {
+		return nil, fmt.Errorf("%w: manifest: no items", serrors.ErrorInvalidFormat)
+	}
+	return &m, nil
+}
+
+// Options are the options of the verification of a batch.
+type Options struct {
+	// Concurrency is the number of items verified at once. Defaults to
+	// DefaultConcurrency.
+	Concurrency int
+	// AllowFiles permits items to refer to files by path. Services
+	// receiving batches from clients leave it unset.
+	AllowFiles      bool
+	SigstoreOpts    *options.SigstoreOpts
+	GitHubOpts      *options.GitHubOpts
+	TrustedBuilders []options.TrustedBuilder
+}
+
+// Result is the outcome of the verification of a batch.
+type Result struct {
+	// Result is PASSED only if all the items passed.
+	Result report.Status `json:"result"`
+	Passed int           `json:"passed"`
+	Failed int           `json:"failed"`
+	// Items are the reports of the items, in the order of the batch.
+	Items []*report.Report `json:"items"`
+	// Errors are the errors of the items, nil for the items that passed,
+	// in the order of the batch.
+	Errors []error `json:"-"`
+}
+
+// Verify verifies the items with a bounded pool of workers. The trusted
+// root and the Rekor clients are cached process-wide and initialized once,
+// so the workers share them.
+func Verify(ctx context.Context, items []Item, opts *Options) *Result {
+	if opts == nil {
+		opts = &Options{}
+	}
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = DefaultConcurrency
+	}
+	reports := make([]*report.Report, len(items))
+	errs := make([]error, len(items))
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < min(concurrency, len(items)); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				reports[i], errs[i] = verifyItem(ctx, &items[i], opts)
+			}
+		}()
+	}
+	for i := range items {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	result := &Result{Result: report.StatusPassed, Items: reports, Errors: errs}
+	for _, r := range reports {
+		if r.Result == report.StatusPassed {
+			result.Passed++
+		} else {
+			result.Failed++
+		}
+	}
+	if result.Failed > 0 || len(reports) == 0 {
+		result.Result = report.StatusFailed
+	}
+	return result
+}
+
+func verifyItem(ctx context.Context, it *Item, opts *Options) (*report.Report, error) {
+	rep := report.New(it.name(), "")
+	if err := ctx.Err(); err != nil {
+		// The batch timed out or was canceled before the item was verified.
+		err = fmt.Errorf("%w: not verified: %w", serrors.ErrorUnavailable, err)
+		rep.Finish("", err)
+		return rep, err
+	}
+	artifactHash, provenance, err := it.load(opts.AllowFiles)
+	if err != nil {
+		rep.Finish("", err)
+		return rep, err
+	}
+	rep.Digest = "sha256:" + artifactHash
+
+	provenanceOpts := &options.ProvenanceOpts{
+		ExpectedSourceURI:      it.Source,
+		ExpectedBranch:         it.Branch,
+		ExpectedDigest:         artifactHash,
+		ExpectedVersionedTag:   it.VersionedTag,
+		ExpectedTag:            it.Tag,
+		ExpectedWorkflowInputs: it.WorkflowInputs,
+		SigstoreOpts:           opts.SigstoreOpts,
+		GitHubOpts:             opts.GitHubOpts,
+		TrustedBuilders:        opts.TrustedBuilders,
+	}
+	builderOpts := &options.BuilderOpts{
+		ExpectedID: it.BuilderID,
+	}
+	p, builderID, err := verifiers.VerifyArtifact(report.NewContext(ctx, rep), provenance, artifactHash,
+		provenanceOpts, builderOpts)
+	if err != nil {
+		rep.Finish("", err)
+		return rep, err
+	}
+	if it.IncludeProvenance && json.Valid(p) {
+		rep.Provenance = p
+	}
+	rep.Finish(builderID.String(), nil)
+	return rep, nil
+}
+
+func (it *Item) name() string {
+	switch {
+	case it.Name != "":
+		return it.Name
+	case it.ArtifactPath != "":
+		return it.ArtifactPath
+	default:
+		return it.ArtifactHash
+	}
+}
+
+// load validates the item and returns the digest of the artifact and the
+// content of the provenance.
+func (it *Item) load(allowFiles bool) (string, []byte, error) {
+	if it.Source == "" {
+		return "", nil, fmt.Errorf("%w: empty source", serrors.ErrorInvalidFormat)
+	}
+	if it.Tag != nil && it.VersionedTag != nil {
+		return "", nil, fmt.Errorf("%w: tag and versionedTag are mutually exclusive", serrors.ErrorInvalidFormat)
+	}
+	if !allowFiles && (it.ArtifactPath != "" || it.ProvenancePath != "") {
+		return "", nil, fmt.Errorf("%w: artifactPath and provenancePath are not supported", serrors.ErrorInvalidFormat)
+	}
+	if (it.ArtifactPath == "") == (it.ArtifactHash == "") {
+		return "", nil, fmt.Errorf("%w: exactly one of artifactPath and artifactHash is required", serrors.ErrorInvalidFormat)
+	}
+	if (it.ProvenancePath == "") == (it.Provenance == "") {
+		return "", nil, fmt.Errorf("%w: exactly one of provenancePath and provenance is required", serrors.ErrorInvalidFormat)
+	}
+
+	artifactHash := it.ArtifactHash
+	if it.ArtifactPath != "" {
+		var err error
+		if artifactHash, err = fileHash(it.ArtifactPath); err != nil {
+			return "", nil, err
+		}
+	} else if b, err := hex.DecodeString(artifactHash); err != nil || len(b) != sha256.Size {
+		return "", nil, fmt.Errorf("%w: artifactHash is not a hex-encoded sha256 digest", serrors.ErrorInvalidHash)
+	}
+
+	if it.ProvenancePath != "" {
+		provenance, err := os.ReadFile(it.ProvenancePath)
+		return artifactHash, provenance, err
+	}
+	provenance, err := base64.StdEncoding.DecodeString(it.Provenance)
+	if err != nil {
+		return "", nil, fmt.Errorf("%w: provenance: %w", serrors.ErrorInvalidEncoding, err)
+	}
+	return artifactHash, provenance, nil
+}
+
+func fileHash(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
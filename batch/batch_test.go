@@ -0,0 +1,279 @@
+package batch
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	serrors "github.com/slsa-framework/slsa-verifier/v2/errors"
+	"github.com/slsa-framework/slsa-verifier/v2/options"
+	"github.com/slsa-framework/slsa-verifier/v2/report"
+)
+
+// The provenance of an artifact built by the container-based builder, in
+// a Sigstore bundle verified offline with the trusted root.
+const (
+	bundlePath      = "../cli/slsa-verifier/testdata/gha_container-based/v1.7.0/gha_container-based-binary-linux-amd64-v14.intoto.sigstore"
+	trustedRootPath = "../verifiers/internal/gha/testdata/trusted_root.json"
+	bundleDigest    = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+	bundleSource    = "github.com/slsa-framework/example-package"
+	bundleCommit    = "62cb1f1e485829bafe8bbec8b9900c0cb7624fe7"
+	bundleBuilderID = "https://github.com/slsa-framework/slsa-github-generator/.github/workflows/builder_container-based_slsa3.yml@refs/tags/v1.7.0"
+)
+
+func Test_FromBytes(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		content string
+		err     error
+	}{
+		{
+			name: "valid",
+			content: `
+version: 1
+items:
+  - artifactPath: app
+    provenancePath: app.intoto.jsonl
+    source: github.com/org/repo
+`,
+		},
+		{
+			name:    "valid json",
+			content: `{"version": 1, "items": [{"artifactHash": "abc", "provenance": "e30=", "source": "github.com/org/repo"}]}`,
+		},
+		{
+			name: "unsupported version",
+			content: `
+version: 2
+items:
+  - source: github.com/org/repo
+`,
+			err: serrors.ErrorInvalidFormat,
+		},
+		{
+			name:    "no items",
+			content: `version: 1`,
+			err:     serrors.ErrorInvalidFormat,
+		},
+		{
+			name: "unknown field",
+			content: `
+version: 1
+items:
+  - artifactPath: app
+    source-uri: github.com/org/repo
+`,
+			err: serrors.ErrorInvalidFormat,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := FromBytes([]byte(tt.content))
+			if !errors.Is(err, tt.err) {
+				t.Errorf("unexpected error: got %v, want %v", err, tt.err)
+			}
+		})
+	}
+}
+
+func Test_Load(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	manifestPath := filepath.Join(dir, "manifest.yml")
+	content := `
+version: 1
+items:
+  - artifactPath: dist/app
+    provenancePath: /tmp/app.intoto.jsonl
+    source: github.com/org/repo
+`
+	if err := os.WriteFile(manifestPath, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	m, err := Load(manifestPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Item{{
+		ArtifactPath:   filepath.Join(dir, "dist/app"),
+		ProvenancePath: "/tmp/app.intoto.jsonl",
+		Source:         "github.com/org/repo",
+	}}
+	if diff := cmp.Diff(want, m.Items); diff != "" {
+		t.Errorf("unexpected items (-want +got):\n%s", diff)
+	}
+}
+
+func Test_Verify(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	artifactPath := filepath.Join(dir, "app")
+	if err := os.WriteFile(artifactPath, []byte("app"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	hash := strings.Repeat("a", 64)
+	provenance := base64.StdEncoding.EncodeToString([]byte(`{"payloadType": "application/vnd.in-toto+json"}`))
+	builderID := "https://example.com/builder"
+
+	items := []Item{
+		{
+			Name:         "no source",
+			ArtifactHash: hash,
+			Provenance:   provenance,
+		},
+		{
+			Name:         "both artifact path and hash",
+			ArtifactPath: artifactPath,
+			ArtifactHash: hash,
+			Provenance:   provenance,
+			Source:       "github.com/org/repo",
+		},
+		{
+			Name:         "invalid hash",
+			ArtifactHash: "abc",
+			Provenance:   provenance,
+			Source:       "github.com/org/repo",
+		},
+		{
+			Name:         "invalid provenance encoding",
+			ArtifactHash: hash,
+			Provenance:   "%",
+			Source:       "github.com/org/repo",
+		},
+		{
+			Name:         "unsupported builder",
+			ArtifactHash: hash,
+			Provenance:   provenance,
+			Source:       "github.com/org/repo",
+			BuilderID:    &builderID,
+		},
+		{
+			ArtifactPath: artifactPath,
+			Provenance:   provenance,
+			Source:       "github.com/org/repo",
+			BuilderID:    &builderID,
+		},
+	}
+
+	t.Run("files allowed", func(t *testing.T) {
+		t.Parallel()
+
+		result := Verify(context.Background(), items, &Options{Concurrency: 2, AllowFiles: true})
+		if result.Result != report.StatusFailed || result.Passed != 0 || result.Failed != len(items) {
+			t.Errorf("unexpected result: %v, %d passed, %d failed", result.Result, result.Passed, result.Failed)
+		}
+		want := []struct {
+			artifact string
+			err      error
+		}{
+			{"no source", serrors.ErrorInvalidFormat},
+			{"both artifact path and hash", serrors.ErrorInvalidFormat},
+			{"invalid hash", serrors.ErrorInvalidHash},
+			{"invalid provenance encoding", serrors.ErrorInvalidEncoding},
+			{"unsupported builder", serrors.ErrorVerifierNotSupported},
+			{artifactPath, serrors.ErrorVerifierNotSupported},
+		}
+		for i, w := range want {
+			rep := result.Items[i]
+			if rep.Artifact != w.artifact || rep.Result != report.StatusFailed ||
+				rep.Error == nil || rep.Error.Code != serrors.Code(w.err) {
+				t.Errorf("item %d: unexpected report: %+v, error %+v, want %v", i, rep, rep.Error, w.err)
+			}
+			if !errors.Is(result.Errors[i], w.err) {
+				t.Errorf("item %d: unexpected error: got %v, want %v", i, result.Errors[i], w.err)
+			}
+		}
+		// The digest of the artifact is computed from its path.
+		if got, want := result.Items[5].Digest,
+			"sha256:a172cedcae47474b615c54d510a5d84a8dea3032e958587430b413538be3f333"; got != want {
+			t.Errorf("unexpected digest: got %s, want %s", got, want)
+		}
+	})
+
+	t.Run("files not allowed", func(t *testing.T) {
+		t.Parallel()
+
+		result := Verify(context.Background(), items[5:], &Options{})
+		if rep := result.Items[0]; rep.Error == nil ||
+			rep.Error.Code != serrors.Code(serrors.ErrorInvalidFormat) {
+			t.Errorf("unexpected report: %+v", rep)
+		}
+	})
+
+	t.Run("canceled", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		result := Verify(ctx, items[4:5], nil)
+		if rep := result.Items[0]; rep.Result != report.StatusFailed || rep.Error == nil {
+			t.Errorf("unexpected report: %+v", rep)
+		}
+		if err := result.Errors[0]; !errors.Is(err, context.Canceled) || !errors.Is(err, serrors.ErrorUnavailable) {
+			t.Errorf("unexpected error: got %v, want %v", err, context.Canceled)
+		}
+	})
+
+	t.Run("passed", func(t *testing.T) {
+		t.Parallel()
+
+		content, err := os.ReadFile(bundlePath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		item := Item{
+			ArtifactHash: bundleDigest,
+			Provenance:   base64.StdEncoding.EncodeToString(content),
+			Source:       bundleSource,
+		}
+		// The items are verified concurrently, with the trusted root
+		// shared by the workers.
+		passing := make([]Item, 8)
+		for i := range passing {
+			passing[i] = item
+			passing[i].Name = fmt.Sprintf("app-%d", i)
+		}
+		opts := &Options{
+			Concurrency:  4,
+			SigstoreOpts: &options.SigstoreOpts{TrustedRootPath: trustedRootPath, Offline: true},
+		}
+		result := Verify(context.Background(), append(passing, items[4]), opts)
+		if result.Result != report.StatusFailed || result.Passed != len(passing) || result.Failed != 1 {
+			t.Errorf("unexpected result: %v, %d passed, %d failed", result.Result, result.Passed, result.Failed)
+		}
+		for i, rep := range result.Items[:len(passing)] {
+			if rep.Artifact != passing[i].Name || rep.Result != report.StatusPassed ||
+				rep.BuilderID != bundleBuilderID || rep.SourceCommit != bundleCommit || result.Errors[i] != nil {
+				t.Errorf("item %d: unexpected report: %+v, error %v", i, rep, result.Errors[i])
+			}
+		}
+		if err := result.Errors[len(passing)]; !errors.Is(err, serrors.ErrorVerifierNotSupported) {
+			t.Errorf("unexpected error: got %v, want %v", err, serrors.ErrorVerifierNotSupported)
+		}
+
+		if result := Verify(context.Background(), passing, opts); result.Result != report.StatusPassed {
+			t.Errorf("unexpected result: %v, %d passed, %d failed", result.Result, result.Passed, result.Failed)
+		}
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		t.Parallel()
+
+		if result := Verify(context.Background(), nil, nil); result.Result != report.StatusFailed {
+			t.Errorf("unexpected result: %v", result.Result)
+		}
+	})
+}
@@ -1,34 +1,81 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
 	"fmt"
 	"log"
 	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
 	"time"
 
 	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 
 	"github.com/slsa-framework/slsa-verifier/v2/experimental/rest"
 )
 
 func main() {
+	address := flag.String("address", ":8000", "address to listen on")
+	readTimeout := flag.Duration("read-timeout", 15*time.Second, "maximum duration for reading a request")
+	writeTimeout := flag.Duration("write-timeout", 15*time.Second, "maximum duration for verifying a request and writing its response")
+	idleTimeout := flag.Duration("idle-timeout", 60*time.Second, "maximum duration to keep idle connections open")
+	shutdownTimeout := flag.Duration("shutdown-timeout", 30*time.Second, "maximum duration for in-flight requests to complete on shutdown")
+	rekorURL := flag.String("rekor-url", rest.DefaultRekorURL, "URL of the Rekor instance whose reachability is required for readiness")
+	imageRegistries := flag.String("image-registries", "", "comma-separated hosts of the registries that image verifications may contact, or any if empty")
+	flag.Parse()
+
 	r := mux.NewRouter().StrictSlash(true)
 
 	r.HandleFunc("/", HomeHandler).Methods(http.MethodGet)
+	r.HandleFunc("/healthz", rest.HealthzHandler).Methods(http.MethodGet)
+	r.HandleFunc("/readyz", rest.ReadyzHandler(map[string]rest.ReadinessCheck{
+		"trusted-root": rest.TrustedRootLoaded,
+		"rekor":        rest.RekorReachable(*rekorURL),
+	})).Methods(http.MethodGet)
+	r.Handle("/metrics", promhttp.Handler()).Methods(http.MethodGet)
 	r.HandleFunc("/v1/verify", rest.VerifyHandlerV1).Methods(http.MethodPost)
-	http.Handle("/", r)
+	// A fifth of the write timeout is left to write the result of a batch.
+	v2Opts := rest.V2Options{BatchTimeout: *writeTimeout * 4 / 5}
+	if *imageRegistries != "" {
+		v2Opts.ImageRegistries = strings.Split(*imageRegistries, ",")
+	}
+	rest.RegisterV2(r, v2Opts)
 
-	address := ":8000"
-	fmt.Printf("Starting HTTP server on %v ...\n", address)
+	fmt.Printf("Starting HTTP server on %v ...\n", *address)
 	srv := &http.Server{
-		Handler: r,
-		Addr:    address,
-		// Good practice: enforce timeouts for servers you create!
-		WriteTimeout: 15 * time.Second,
-		ReadTimeout:  15 * time.Second,
+		Handler:      r,
+		Addr:         *address,
+		WriteTimeout: *writeTimeout,
+		ReadTimeout:  *readTimeout,
+		IdleTimeout:  *idleTimeout,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	errc := make(chan error, 1)
+	go func() {
+		errc <- srv.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errc:
+		log.Fatal(err)
+	case <-ctx.Done():
 	}
 
-	if err := srv.ListenAndServe(); err != nil {
+	// Stop accepting requests, and let those in flight complete.
+	fmt.Println("Shutting down HTTP server ...")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), *shutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		log.Fatal(err)
+	}
+	if err := <-errc; !errors.Is(err, http.ErrServerClosed) {
 		log.Fatal(err)
 	}
 }
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/slsa-framework/slsa-verifier/v2/experimental/admission"
+	"github.com/slsa-framework/slsa-verifier/v2/policy"
+)
+
+func main() {
+	configPath := flag.String("config", "", "path to the webhook configuration, with the policies of the namespaces")
+	address := flag.String("address", ":8443", "address to listen on")
+	certFile := flag.String("tls-cert-file", "", "path to the TLS certificate of the webhook, trusted by the API server")
+	keyFile := flag.String("tls-key-file", "", "path to the TLS private key of the webhook")
+	cacheTTL := flag.Duration("cache-ttl", admission.DefaultCacheTTL, "how long verification results are cached, or 0 to disable the cache")
+	trustedBuildersPath := flag.String("trusted-builders", "", "path to a trust configuration file with reusable workflows to trust as builders")
+	flag.Parse()
+
+	if *configPath == "" || *certFile == "" || *keyFile == "" {
+		log.Fatal("--config, --tls-cert-file and --tls-key-file are required")
+	}
+	config, err := admission.LoadConfig(*configPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	opts := &admission.Options{CacheTTL: *cacheTTL}
+	if *trustedBuildersPath != "" {
+		c, err := policy.LoadTrustConfig(*trustedBuildersPath)
+		if err != nil {
+			log.Fatal(err)
+		}
+		opts.TrustedBuilders = c.Options()
+	}
+
+	r := mux.NewRouter().StrictSlash(true)
+	r.Handle("/validate", admission.New(config, opts)).Methods(http.MethodPost)
+
+	fmt.Printf("Starting admission webhook on %v: %v ...\n", *address, config)
+	srv := &http.Server{
+		Handler: r,
+		Addr:    *address,
+		// The API server waits at most 30 seconds for webhooks.
+		WriteTimeout: 30 * time.Second,
+		ReadTimeout:  15 * time.Second,
+	}
+
+	if err := srv.ListenAndServeTLS(*certFile, *keyFile); err != nil {
+		log.Fatal(err)
+	}
+}
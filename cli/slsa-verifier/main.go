@@ -35,8 +35,12 @@ For more information on SLSA, visit https://slsa.dev`,
 	}
 	c.AddCommand(version.Version())
 	c.AddCommand(verifyArtifactCmd())
+	c.AddCommand(verifyChecksumsCmd())
 	c.AddCommand(verifyImageCmd())
 	c.AddCommand(verifyNpmPackageCmd())
+	c.AddCommand(verifyPyPIPackageCmd())
+	c.AddCommand(verifyNpmLockfileCmd())
+	c.AddCommand(verifyBatchCmd())
 	c.AddCommand(verifyVSACmd())
 	// We print our own errors and usage in the check function.
 	c.SilenceErrors = true
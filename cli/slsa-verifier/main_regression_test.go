@@ -8,6 +8,8 @@ import (
 	"errors"
 	"fmt"
 	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"strings"
@@ -22,6 +24,7 @@ import (
 
 	"github.com/slsa-framework/slsa-verifier/v2/cli/slsa-verifier/verify"
 	serrors "github.com/slsa-framework/slsa-verifier/v2/errors"
+	"github.com/slsa-framework/slsa-verifier/v2/options"
 	"github.com/slsa-framework/slsa-verifier/v2/verifiers/utils/container"
 )
 
@@ -705,6 +708,12 @@ func Test_runVerifyGHAArtifactImage(t *testing.T) {
 		image = image[:i]
 		return cosign.VerifyLocalImageAttestations(ctx, image, co)
 	}
+	// The local images of the tests are not image indexes.
+	getImagePlatforms := container.GetImagePlatforms
+	t.Cleanup(func() { container.GetImagePlatforms = getImagePlatforms })
+	container.GetImagePlatforms = func(context.Context, string) ([]container.PlatformImage, error) {
+		return nil, nil
+	}
 
 	builder := "https://github.com/slsa-framework/slsa-github-generator/.github/workflows/generator_container_slsa3.yml"
 	tests := []struct {
@@ -1493,8 +1502,6 @@ func Test_runVerifyGHAContainerBased(t *testing.T) {
 }
 
 func Test_runVerifyNpmPackage(t *testing.T) {
-	// We cannot use t.Setenv due to parallelized tests.
-	os.Setenv("SLSA_VERIFIER_EXPERIMENTAL", "1")
 	t.Parallel()
 
 	tests := []struct {
@@ -1975,6 +1982,68 @@ func Test_runVerifyNpmPackage(t *testing.T) {
 	}
 }
 
+func Test_runVerifyNpmPackageFromRegistry(t *testing.T) {
+	t.Parallel()
+
+	artifactPath := filepath.Clean(filepath.Join(TEST_DIR, "npm", "gha", "supreme-googles-cli-v02-tag.tgz"))
+	attestations, err := os.ReadFile(fmt.Sprintf("%s.json", artifactPath))
+	if err != nil {
+		t.Fatal(err)
+	}
+	registry := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.EscapedPath() != "/-/npm/v1/attestations/@trishankatdatadog%2fsupreme-goggles@1.0.5" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write(attestations)
+	}))
+	t.Cleanup(registry.Close)
+
+	tests := []struct {
+		name            string
+		registry        string
+		attestationsURL string
+		pkgVersion      string
+		err             error
+	}{
+		{
+			name:       "registry",
+			registry:   registry.URL,
+			pkgVersion: "1.0.5",
+		},
+		{
+			name:            "attestations URL",
+			attestationsURL: registry.URL + "/-/npm/v1/attestations/@trishankatdatadog%2fsupreme-goggles@1.0.5",
+			pkgVersion:      "1.0.5",
+		},
+		{
+			name:       "version without attestations",
+			registry:   registry.URL,
+			pkgVersion: "1.0.4",
+			err:        serrors.ErrorFetchAttestations,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			cmd := verify.VerifyNpmPackageCommand{
+				AttestationsURL: tt.attestationsURL,
+				Registry:        tt.registry,
+				BuilderID:       pointerTo("https://github.com/actions/runner/github-hosted"),
+				SourceURI:       "github.com/trishankatdatadog/supreme-goggles",
+				PackageName:     pointerTo("@trishankatdatadog/supreme-goggles"),
+				PackageVersion:  pointerTo(tt.pkgVersion),
+			}
+
+			_, err := cmd.Exec(context.Background(), []string{artifactPath})
+			if diff := cmp.Diff(tt.err, err, cmpopts.EquateErrors()); diff != "" {
+				t.Fatalf("unexpected error (-want +got): \n%s", diff)
+			}
+		})
+	}
+}
+
 // Test_runVerifyVSA tests the CLI inputes of verify-vsa. More extensive tests are in
 // slsa-verifier/verifiers/internal/vsa/verifier_test.go
 func Test_runVerifyVSA(t *testing.T) {
@@ -2045,3 +2114,104 @@ func Test_runVerifyVSA(t *testing.T) {
 func pointerTo[K any](object K) *K {
 	return &object
 }
+
+func Test_runVerifyChecksums(t *testing.T) {
+	t.Parallel()
+
+	const (
+		name   = "gha_container-based-binary-linux-amd64-v14"
+		sha256 = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+		sha512 = "cf83e1357eefb8bdf1542850d66d8007d620e4050b5715dc83f4a921d36ce9ce" +
+			"47d0d13c5d85f2b0ff8318d2877eec2f63b931bd47417a81a538327af927da3e"
+		other = "0000000000000000000000000000000000000000000000000000000000000000"
+	)
+	provenancePath := filepath.Join(TEST_DIR, "gha_container-based", "v1.7.0", name+".intoto.sigstore")
+	sigstoreOpts := &options.SigstoreOpts{
+		TrustedRootPath: "../../verifiers/internal/gha/testdata/trusted_root.json",
+		Offline:         true,
+	}
+
+	tests := []struct {
+		name        string
+		manifest    string
+		source      string
+		file        string
+		verifyFiles bool
+		err         error
+	}{
+		{
+			name:     "valid",
+			manifest: sha256 + "  " + name + "\n",
+			source:   "github.com/slsa-framework/example-package",
+		},
+		{
+			name:        "valid with files",
+			manifest:    sha256 + "  " + name + "\n",
+			source:      "github.com/slsa-framework/example-package",
+			verifyFiles: true,
+		},
+		{
+			name:        "local file mismatch",
+			manifest:    sha256 + "  " + name + "\n",
+			source:      "github.com/slsa-framework/example-package",
+			file:        "modified",
+			verifyFiles: true,
+			err:         serrors.ErrorMismatchHash,
+		},
+		{
+			name:     "entry not a subject first",
+			manifest: other + "  other\n" + sha256 + "  " + name + "\n",
+			source:   "github.com/slsa-framework/example-package",
+			err:      serrors.ErrorMissingSubjectDigest,
+		},
+		{
+			name:     "no entry is a subject",
+			manifest: other + "  other\n",
+			source:   "github.com/slsa-framework/example-package",
+			err:      serrors.ErrorMissingSubjectDigest,
+		},
+		{
+			name:     "sha512 entry",
+			manifest: "SHA512 (" + name + ") = " + sha512 + "\n",
+			source:   "github.com/slsa-framework/example-package",
+			err:      serrors.ErrorMismatchHash,
+		},
+		{
+			name:     "mismatched digest",
+			manifest: other + "  " + name + "\n",
+			source:   "github.com/slsa-framework/example-package",
+			err:      serrors.ErrorMismatchHash,
+		},
+		{
+			name:     "wrong source",
+			manifest: sha256 + "  " + name + "\n",
+			source:   "github.com/slsa-framework/example-packageA",
+			err:      serrors.ErrorMismatchSource,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			dir := t.TempDir()
+			manifestPath := filepath.Join(dir, "checksums.txt")
+			if err := os.WriteFile(manifestPath, []byte(tt.manifest), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(filepath.Join(dir, name), []byte(tt.file), 0o600); err != nil {
+				t.Fatal(err)
+			}
+
+			cmd := verify.VerifyChecksumsCommand{
+				ProvenancePath: provenancePath,
+				SourceURI:      tt.source,
+				SigstoreOpts:   sigstoreOpts,
+				VerifyFiles:    tt.verifyFiles,
+			}
+			_, err := cmd.Exec(context.Background(), manifestPath)
+			if !errCmp(err, tt.err) {
+				t.Errorf("%v", cmp.Diff(err, tt.err, cmpopts.EquateErrors()))
+			}
+		})
+	}
+}
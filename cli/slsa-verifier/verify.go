@@ -20,6 +20,7 @@ import (
 	"os"
 
 	"github.com/slsa-framework/slsa-verifier/v2/cli/slsa-verifier/verify"
+	serrors "github.com/slsa-framework/slsa-verifier/v2/errors"
 	"github.com/spf13/cobra"
 )
 
@@ -28,6 +29,24 @@ const (
 	FAILURE = "FAILED: SLSA verification failed"
 )
 
+// printFailure prints the verification failure, followed by a hint at how
+// to fix it if there is one.
+func printFailure(err error) {
+	fmt.Fprintf(os.Stderr, "%s: %v\n", FAILURE, err)
+	if hint := serrors.Remediation(err); hint != "" {
+		fmt.Fprintf(os.Stderr, "Hint: %s\n", hint)
+	}
+}
+
+// checkVSAOutputFlags exits if --vsa-output is set without --policy: the
+// VSA records the digest of the policy the artifact was verified against.
+func checkVSAOutputFlags(cmd *cobra.Command) {
+	if cmd.Flags().Changed("vsa-output") && !cmd.Flags().Changed("policy") {
+		fmt.Fprintf(os.Stderr, "%s: --vsa-output requires --policy\n", FAILURE)
+		os.Exit(1)
+	}
+}
+
 func verifyArtifactCmd() *cobra.Command {
 	o := &verify.VerifyOptions{}
 
@@ -46,6 +65,14 @@ func verifyArtifactCmd() *cobra.Command {
 				SourceURI:           o.SourceURI,
 				PrintProvenance:     o.PrintProvenance,
 				BuildWorkflowInputs: o.BuildWorkflowInputs.AsMap(),
+				Output:              o.Output,
+				PolicyPath:          o.PolicyPath,
+				SigstoreOpts:        o.SigstoreOpts(),
+				GitHubOpts:          o.GitHubOpts(),
+				GitLabOpts:          o.GitLabOpts(),
+				TrustedBuildersPath: o.TrustedBuildersPath,
+				VSA:                 o.VSAOutput(),
+				TaskRefs:            o.TaskRefs,
 			}
 			if cmd.Flags().Changed("source-branch") {
 				v.SourceBranch = &o.SourceBranch
@@ -56,12 +83,23 @@ func verifyArtifactCmd() *cobra.Command {
 			if cmd.Flags().Changed("source-versioned-tag") {
 				v.SourceVersionTag = &o.SourceVersionTag
 			}
+			if cmd.Flags().Changed("source-commit") {
+				v.SourceCommit = &o.SourceCommit
+			}
 			if cmd.Flags().Changed("builder-id") {
 				v.BuilderID = &o.BuilderID
 			}
+			if cmd.Flags().Changed("public-key-path") {
+				v.PublicKeyPath = &o.PublicKeyPath
+			}
+			if cmd.Flags().Changed("public-key-id") {
+				v.PublicKeyID = &o.PublicKeyID
+			}
+
+			checkVSAOutputFlags(cmd)
 
 			if _, err := v.Exec(cmd.Context(), args); err != nil {
-				fmt.Fprintf(os.Stderr, "%s: %v\n", FAILURE, err)
+				printFailure(err)
 				os.Exit(1)
 			} else {
 				fmt.Fprintf(os.Stderr, "%s\n", SUCCESS)
@@ -75,6 +113,72 @@ func verifyArtifactCmd() *cobra.Command {
 	return cmd
 }
 
+func verifyChecksumsCmd() *cobra.Command {
+	o := &verify.VerifyChecksumsOptions{}
+
+	cmd := &cobra.Command{
+		Use: "verify-checksums [flags] checksums-file",
+		Args: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return errors.New("expects a single path to a checksums manifest")
+			}
+			return nil
+		},
+		Short: "Verifies SLSA provenance on the artifacts listed in a checksums manifest (sha256sum or sha512sum format)",
+		Run: func(cmd *cobra.Command, args []string) {
+			v := verify.VerifyChecksumsCommand{
+				ProvenancePath:      o.ProvenancePath,
+				SourceURI:           o.SourceURI,
+				PrintProvenance:     o.PrintProvenance,
+				BuildWorkflowInputs: o.BuildWorkflowInputs.AsMap(),
+				Output:              o.Output,
+				PolicyPath:          o.PolicyPath,
+				SigstoreOpts:        o.SigstoreOpts(),
+				GitHubOpts:          o.GitHubOpts(),
+				GitLabOpts:          o.GitLabOpts(),
+				TrustedBuildersPath: o.TrustedBuildersPath,
+				VSA:                 o.VSAOutput(),
+				TaskRefs:            o.TaskRefs,
+				VerifyFiles:         o.VerifyFiles,
+			}
+			if cmd.Flags().Changed("source-branch") {
+				v.SourceBranch = &o.SourceBranch
+			}
+			if cmd.Flags().Changed("source-tag") {
+				v.SourceTag = &o.SourceTag
+			}
+			if cmd.Flags().Changed("source-versioned-tag") {
+				v.SourceVersionTag = &o.SourceVersionTag
+			}
+			if cmd.Flags().Changed("source-commit") {
+				v.SourceCommit = &o.SourceCommit
+			}
+			if cmd.Flags().Changed("builder-id") {
+				v.BuilderID = &o.BuilderID
+			}
+			if cmd.Flags().Changed("public-key-path") {
+				v.PublicKeyPath = &o.PublicKeyPath
+			}
+			if cmd.Flags().Changed("public-key-id") {
+				v.PublicKeyID = &o.PublicKeyID
+			}
+
+			checkVSAOutputFlags(cmd)
+
+			if _, err := v.Exec(cmd.Context(), args[0]); err != nil {
+				printFailure(err)
+				os.Exit(1)
+			} else {
+				fmt.Fprintf(os.Stderr, "%s\n", SUCCESS)
+			}
+		},
+	}
+
+	o.AddFlags(cmd)
+	cmd.MarkFlagRequired("provenance-path")
+	return cmd
+}
+
 func verifyImageCmd() *cobra.Command {
 	o := &verify.VerifyOptions{}
 
@@ -92,6 +196,14 @@ func verifyImageCmd() *cobra.Command {
 				SourceURI:           o.SourceURI,
 				PrintProvenance:     o.PrintProvenance,
 				BuildWorkflowInputs: o.BuildWorkflowInputs.AsMap(),
+				Output:              o.Output,
+				PolicyPath:          o.PolicyPath,
+				SigstoreOpts:        o.SigstoreOpts(),
+				GitHubOpts:          o.GitHubOpts(),
+				GitLabOpts:          o.GitLabOpts(),
+				TrustedBuildersPath: o.TrustedBuildersPath,
+				VSA:                 o.VSAOutput(),
+				TaskRefs:            o.TaskRefs,
 			}
 			if cmd.Flags().Changed("provenance-path") {
 				v.ProvenancePath = &o.ProvenancePath
@@ -108,12 +220,23 @@ func verifyImageCmd() *cobra.Command {
 			if cmd.Flags().Changed("source-versioned-tag") {
 				v.SourceVersionTag = &o.SourceVersionTag
 			}
+			if cmd.Flags().Changed("source-commit") {
+				v.SourceCommit = &o.SourceCommit
+			}
 			if cmd.Flags().Changed("builder-id") {
 				v.BuilderID = &o.BuilderID
 			}
+			if cmd.Flags().Changed("public-key-path") {
+				v.PublicKeyPath = &o.PublicKeyPath
+			}
+			if cmd.Flags().Changed("public-key-id") {
+				v.PublicKeyID = &o.PublicKeyID
+			}
+
+			checkVSAOutputFlags(cmd)
 
 			if _, err := v.Exec(cmd.Context(), args); err != nil {
-				fmt.Fprintf(os.Stderr, "%s: %v\n", FAILURE, err)
+				printFailure(err)
 				os.Exit(1)
 			} else {
 				fmt.Fprintf(os.Stderr, "%s\n", SUCCESS)
@@ -136,16 +259,25 @@ func verifyNpmPackageCmd() *cobra.Command {
 			}
 			return nil
 		},
-		Short: "Verifies SLSA provenance for an npm package tarball [experimental]",
+		Short: "Verifies SLSA provenance for an npm package tarball",
 		Run: func(cmd *cobra.Command, args []string) {
 			v := verify.VerifyNpmPackageCommand{
 				SourceURI:           o.SourceURI,
 				PrintProvenance:     o.PrintProvenance,
 				BuildWorkflowInputs: o.BuildWorkflowInputs.AsMap(),
+				Output:              o.Output,
+				PolicyPath:          o.PolicyPath,
+				SigstoreOpts:        o.SigstoreOpts(),
+				GitHubOpts:          o.GitHubOpts(),
+				TrustedBuildersPath: o.TrustedBuildersPath,
 			}
 			if cmd.Flags().Changed("attestations-path") {
 				v.AttestationsPath = o.AttestationsPath
 			}
+			if cmd.Flags().Changed("attestations-url") {
+				v.AttestationsURL = o.AttestationsURL
+			}
+			v.Registry = o.Registry
 			if cmd.Flags().Changed("package-name") {
 				v.PackageName = &o.PackageName
 			}
@@ -173,7 +305,114 @@ func verifyNpmPackageCmd() *cobra.Command {
 			}
 
 			if _, err := v.Exec(cmd.Context(), args); err != nil {
-				fmt.Fprintf(os.Stderr, "%s: %v\n", FAILURE, err)
+				printFailure(err)
+				os.Exit(1)
+			} else {
+				fmt.Fprintf(os.Stderr, "%s\n", SUCCESS)
+			}
+		},
+	}
+
+	o.AddFlags(cmd)
+	return cmd
+}
+
+func verifyPyPIPackageCmd() *cobra.Command {
+	o := &verify.VerifyPyPIOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "verify-pypi-package [flags] distribution [distribution...]",
+		Args:  cobra.MinimumNArgs(1),
+		Short: "Verifies PEP 740 attestations of Python distributions published to PyPI with Trusted Publishing",
+		Run: func(cmd *cobra.Command, args []string) {
+			v := verify.VerifyPyPIPackageCommand{
+				AttestationsPath:    o.AttestationsPath,
+				SourceURI:           o.SourceURI,
+				PrintProvenance:     o.PrintProvenance,
+				Output:              o.Output,
+				PolicyPath:          o.PolicyPath,
+				SigstoreOpts:        o.SigstoreOpts(),
+				GitHubOpts:          o.GitHubOpts(),
+				TrustedBuildersPath: o.TrustedBuildersPath,
+			}
+			if cmd.Flags().Changed("source-branch") {
+				v.SourceBranch = &o.SourceBranch
+			}
+			if cmd.Flags().Changed("source-tag") {
+				v.SourceTag = &o.SourceTag
+			}
+			if cmd.Flags().Changed("source-versioned-tag") {
+				v.SourceVersionTag = &o.SourceVersionTag
+			}
+			if cmd.Flags().Changed("builder-id") {
+				v.BuilderID = &o.BuilderID
+			}
+
+			if _, err := v.Exec(cmd.Context(), args); err != nil {
+				printFailure(err)
+				os.Exit(1)
+			} else {
+				fmt.Fprintf(os.Stderr, "%s\n", SUCCESS)
+			}
+		},
+	}
+
+	o.AddFlags(cmd)
+	return cmd
+}
+
+func verifyNpmLockfileCmd() *cobra.Command {
+	o := &verify.VerifyNpmLockfileOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "verify-npm-lockfile [flags] package-lock.json",
+		Args:  cobra.ExactArgs(1),
+		Short: "Verifies the provenance of every npm package of a package-lock.json",
+		Run: func(cmd *cobra.Command, args []string) {
+			v := verify.VerifyNpmLockfileCommand{
+				LockfilePath:        args[0],
+				BuilderIDs:          o.BuilderIDs,
+				AttestationsDir:     o.AttestationsDir,
+				Registry:            o.Registry,
+				Allowlist:           o.AllowUnverified,
+				Output:              o.Output,
+				SigstoreOpts:        o.SigstoreOpts(),
+				GitHubOpts:          o.GitHubOpts(),
+				TrustedBuildersPath: o.TrustedBuildersPath,
+			}
+
+			if _, err := v.Exec(cmd.Context()); err != nil {
+				printFailure(err)
+				os.Exit(1)
+			} else {
+				fmt.Fprintf(os.Stderr, "%s\n", SUCCESS)
+			}
+		},
+	}
+
+	o.AddFlags(cmd)
+	return cmd
+}
+
+func verifyBatchCmd() *cobra.Command {
+	o := &verify.VerifyBatchOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "verify-batch [flags] manifest",
+		Args:  cobra.ExactArgs(1),
+		Short: "Verifies the provenance of the artifacts listed in a JSON or YAML manifest",
+		Run: func(cmd *cobra.Command, args []string) {
+			v := verify.VerifyBatchCommand{
+				ManifestPath:        args[0],
+				Concurrency:         o.Concurrency,
+				Output:              o.Output,
+				SigstoreOpts:        o.SigstoreOpts(),
+				GitHubOpts:          o.GitHubOpts(),
+				TrustedBuildersPath: o.TrustedBuildersPath,
+			}
+
+			if _, err := v.Exec(cmd.Context()); err != nil {
+				printFailure(err)
 				os.Exit(1)
 			} else {
 				fmt.Fprintf(os.Stderr, "%s\n", SUCCESS)
@@ -202,9 +441,10 @@ func verifyVSACmd() *cobra.Command {
 				PrintAttestation: o.PrintAttestation,
 				PublicKeyPath:    &o.PublicKeyPath,
 				PublicKeyID:      &o.PublicKeyID,
+				Output:           o.Output,
 			}
 			if err := v.Exec(cmd.Context()); err != nil {
-				fmt.Fprintf(os.Stderr, "%s: %v\n", FAILURE, err)
+				printFailure(err)
 				os.Exit(1)
 			} else {
 				fmt.Fprintf(os.Stderr, "%s\n", SUCCESS)
@@ -16,9 +16,14 @@ package verify
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
 	"strings"
 
+	"github.com/slsa-framework/slsa-verifier/v2/batch"
 	serrors "github.com/slsa-framework/slsa-verifier/v2/errors"
+	"github.com/slsa-framework/slsa-verifier/v2/options"
+	"github.com/slsa-framework/slsa-verifier/v2/verifiers/utils"
 	"github.com/spf13/cobra"
 )
 
@@ -37,10 +42,19 @@ type VerifyOptions struct {
 	/* Builder Requirements */
 	BuildWorkflowInputs workflowInputs
 	BuilderID           string
+	TaskRefs            []string
+	SourceCommit        string
 	/* Other */
 	ProvenancePath       string
 	ProvenanceRepository string
 	PrintProvenance      bool
+	Output               OutputFormat
+	PolicyPath           string
+	SigstoreOptions
+	GitHubOptions
+	GitLabOptions
+	VSAOptions
+	PublicKeyOptions
 }
 
 var _ Interface = (*VerifyOptions)(nil)
@@ -53,7 +67,13 @@ func (o *VerifyOptions) AddFlags(cmd *cobra.Command) {
 
 	cmd.Flags().StringVar(&o.BuilderID, "builder-id", "", "[optional] the unique builder ID who created the provenance")
 
+	cmd.Flags().StringArrayVar(&o.TaskRefs, "task-ref", []string{},
+		"[optional] a pattern the name of each pipeline or task referenced by the run must match. Pass multiple patterns by repeating the flag. (Only for Tekton Chains).")
+
 	/* Source options */
+	cmd.Flags().StringVar(&o.SourceCommit, "source-commit", "",
+		"[optional] expected git commit of the source repository. (Only for Tekton Chains).")
+
 	cmd.Flags().StringVar(&o.SourceURI, "source-uri", "",
 		"expected source repository that should have produced the binary, e.g. github.com/some/repo")
 
@@ -74,8 +94,245 @@ func (o *VerifyOptions) AddFlags(cmd *cobra.Command) {
 	cmd.Flags().BoolVar(&o.PrintProvenance, "print-provenance", false,
 		"[optional] print the verified provenance to stdout")
 
-	cmd.MarkFlagRequired("source-uri")
+	cmd.Flags().Var(&o.Output, "output",
+		"[optional] output format of the verification result: text or json")
+
+	cmd.Flags().StringVar(&o.PolicyPath, "policy", "",
+		"[optional] path to a policy file with the verification expectations, instead of the expectation flags")
+
+	o.SigstoreOptions.AddFlags(cmd)
+	o.GitHubOptions.AddFlags(cmd)
+	o.GitLabOptions.AddFlags(cmd)
+	o.VSAOptions.AddFlags(cmd)
+	o.PublicKeyOptions.AddFlags(cmd)
+
+	// The expected source URI may come from the policy.
+	cmd.MarkFlagsOneRequired("source-uri", "policy")
 	cmd.MarkFlagsMutuallyExclusive("source-versioned-tag", "source-tag")
+	for _, f := range []string{"builder-id", "source-branch", "source-tag", "source-versioned-tag", "build-workflow-input", "task-ref", "source-commit"} {
+		cmd.MarkFlagsMutuallyExclusive("policy", f)
+	}
+}
+
+// SigstoreOptions are the options of the Sigstore deployment
+// that signed the provenance. Defaults are read from the environment.
+type SigstoreOptions struct {
+	TrustedRootPath        string
+	Offline                bool
+	TUFMirrorURL           string
+	TUFRootPath            string
+	RekorURL               string
+	RekorPublicKeyPaths    []string
+	CTLogPublicKeyPaths    []string
+	FulcioCertificatePaths []string
+}
+
+var _ Interface = (*SigstoreOptions)(nil)
+
+// AddFlags implements Interface.
+func (o *SigstoreOptions) AddFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&o.TrustedRootPath, "trusted-root", os.Getenv("SLSA_VERIFIER_TRUSTED_ROOT"),
+		"[optional] path to a Sigstore trusted_root.json to verify signatures with, instead of fetching it with TUF")
+
+	cmd.Flags().BoolVar(&o.Offline, "offline", false,
+		"[optional] verify without contacting Sigstore services, using the transparency log entries in Sigstore bundles. Requires --trusted-root")
+
+	cmd.Flags().StringVar(&o.TUFMirrorURL, "sigstore-tuf-mirror", os.Getenv("SLSA_VERIFIER_SIGSTORE_TUF_MIRROR"),
+		"[optional] URL of the TUF repository of a private Sigstore deployment to fetch the trusted root from")
+
+	cmd.Flags().StringVar(&o.TUFRootPath, "sigstore-tuf-root", os.Getenv("SLSA_VERIFIER_SIGSTORE_TUF_ROOT"),
+		"[optional] path to the initial root.json of --sigstore-tuf-mirror")
+
+	cmd.Flags().StringVar(&o.RekorURL, "rekor-url", os.Getenv("SLSA_VERIFIER_REKOR_URL"),
+		"[optional] URL of the Rekor instance to search for transparency log entries")
+
+	cmd.Flags().StringArrayVar(&o.RekorPublicKeyPaths, "rekor-public-key", envList("SLSA_VERIFIER_REKOR_PUBLIC_KEYS"),
+		"[optional] path to a PEM-encoded Rekor public key, replacing those of the trusted root. Pass multiple keys by repeating the flag")
+
+	cmd.Flags().StringArrayVar(&o.CTLogPublicKeyPaths, "ct-log-public-key", envList("SLSA_VERIFIER_CT_LOG_PUBLIC_KEYS"),
+		"[optional] path to a PEM-encoded CT log public key, replacing those of the trusted root. Pass multiple keys by repeating the flag")
+
+	cmd.Flags().StringArrayVar(&o.FulcioCertificatePaths, "fulcio-certificate", envList("SLSA_VERIFIER_FULCIO_CERTIFICATES"),
+		"[optional] path to PEM-encoded Fulcio root and intermediate certificates, replacing those of the trusted root. Pass multiple files by repeating the flag")
+
+	cmd.MarkFlagsMutuallyExclusive("trusted-root", "sigstore-tuf-mirror")
+	cmd.MarkFlagsRequiredTogether("sigstore-tuf-mirror", "sigstore-tuf-root")
+}
+
+// SigstoreOpts returns the Sigstore options for the flags,
+// or nil to use the public-good Sigstore instance.
+func (o *SigstoreOptions) SigstoreOpts() *options.SigstoreOpts {
+	if o.TrustedRootPath == "" && !o.Offline && o.TUFMirrorURL == "" && o.TUFRootPath == "" &&
+		o.RekorURL == "" && len(o.RekorPublicKeyPaths) == 0 && len(o.CTLogPublicKeyPaths) == 0 &&
+		len(o.FulcioCertificatePaths) == 0 {
+		return nil
+	}
+	return &options.SigstoreOpts{
+		TrustedRootPath:        o.TrustedRootPath,
+		Offline:                o.Offline,
+		TUFMirrorURL:           o.TUFMirrorURL,
+		TUFRootPath:            o.TUFRootPath,
+		RekorURL:               o.RekorURL,
+		RekorPublicKeyPaths:    o.RekorPublicKeyPaths,
+		CTLogPublicKeyPaths:    o.CTLogPublicKeyPaths,
+		FulcioCertificatePaths: o.FulcioCertificatePaths,
+	}
+}
+
+// GitHubOptions are the options of the GitHub instance that ran the builds.
+// Defaults are read from the environment.
+type GitHubOptions struct {
+	Host                string
+	OIDCIssuer          string
+	TrustedBuildersPath string
+}
+
+var _ Interface = (*GitHubOptions)(nil)
+
+// AddFlags implements Interface.
+func (o *GitHubOptions) AddFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&o.Host, "github-host", os.Getenv("SLSA_VERIFIER_GITHUB_HOST"),
+		"[optional] hostname of the GitHub Enterprise Server instance that ran the builds, e.g. github.example.com")
+
+	cmd.Flags().StringVar(&o.OIDCIssuer, "github-oidc-issuer", os.Getenv("SLSA_VERIFIER_GITHUB_OIDC_ISSUER"),
+		"[optional] OIDC issuer of the GitHub Actions tokens of --github-host. Defaults to https://<github-host>/_services/token")
+
+	cmd.Flags().StringVar(&o.TrustedBuildersPath, "trusted-builders", "",
+		"[optional] path to a trust configuration file with reusable workflows to trust as builders, in addition to the built-in builders")
+}
+
+// GitHubOpts returns the GitHub options for the flags,
+// or nil to use github.com.
+func (o *GitHubOptions) GitHubOpts() *options.GitHubOpts {
+	if o.Host == "" && o.OIDCIssuer == "" {
+		return nil
+	}
+	return &options.GitHubOpts{
+		Host:       o.Host,
+		OIDCIssuer: o.OIDCIssuer,
+	}
+}
+
+// GitLabOptions are the options of the GitLab instance that ran the
+// builds. Defaults are read from the environment.
+type GitLabOptions struct {
+	GitLabHost              string
+	GitLabRunnerEnvironment string
+}
+
+var _ Interface = (*GitLabOptions)(nil)
+
+// AddFlags implements Interface.
+func (o *GitLabOptions) AddFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&o.GitLabHost, "gitlab-host", os.Getenv("SLSA_VERIFIER_GITLAB_HOST"),
+		"[optional] hostname of the self-managed GitLab instance that ran the builds, e.g. gitlab.example.com")
+
+	cmd.Flags().StringVar(&o.GitLabRunnerEnvironment, "gitlab-runner-environment", "",
+		"[optional] expected environment of the runners of GitLab CI jobs: gitlab-hosted or self-hosted")
+}
+
+// GitLabOpts returns the GitLab options for the flags,
+// or nil to use gitlab.com and accept any runner environment.
+func (o *GitLabOptions) GitLabOpts() *options.GitLabOpts {
+	if o.GitLabHost == "" && o.GitLabRunnerEnvironment == "" {
+		return nil
+	}
+	return &options.GitLabOpts{
+		Host:              o.GitLabHost,
+		RunnerEnvironment: o.GitLabRunnerEnvironment,
+	}
+}
+
+// VSAOptions are the options of the VSA to write after a successful
+// verification.
+type VSAOptions struct {
+	VSAOutputPath     string
+	VSASigningKeyPath string
+	VSASigningKeyID   string
+	VSAVerifierID     string
+	VSAResourceURI    string
+}
+
+var _ Interface = (*VSAOptions)(nil)
+
+// AddFlags implements Interface.
+func (o *VSAOptions) AddFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&o.VSAOutputPath, "vsa-output", "",
+		"[optional] path to write a signed VSA to after a successful verification. Requires --policy")
+
+	cmd.Flags().StringVar(&o.VSASigningKeyPath, "vsa-signing-key", "",
+		"path to a PEM-encoded private key to sign the VSA with")
+
+	cmd.Flags().StringVar(&o.VSASigningKeyID, "vsa-signing-key-id", "",
+		"[optional] the ID of the signing key, defaults to the SHA256 fingerprint of the public key")
+
+	cmd.Flags().StringVar(&o.VSAVerifierID, "vsa-verifier-id", "",
+		"the verifier ID to record in the VSA")
+
+	cmd.Flags().StringVar(&o.VSAResourceURI, "vsa-resource-uri", "",
+		"the URI of the resource the artifacts are verified for, recorded in the VSA")
+
+	cmd.MarkFlagsRequiredTogether("vsa-output", "vsa-signing-key", "vsa-verifier-id", "vsa-resource-uri")
+}
+
+// VSAOutput returns the VSA to write for the flags,
+// or nil if no VSA is requested.
+func (o *VSAOptions) VSAOutput() *VSAOutput {
+	if o.VSAOutputPath == "" {
+		return nil
+	}
+	return &VSAOutput{
+		Path:           o.VSAOutputPath,
+		SigningKeyPath: o.VSASigningKeyPath,
+		SigningKeyID:   o.VSASigningKeyID,
+		VerifierID:     o.VSAVerifierID,
+		ResourceURI:    o.VSAResourceURI,
+	}
+}
+
+// PublicKeyOptions are the options of the public key that signed the
+// provenance, for builders that sign with a key instead of with Sigstore,
+// e.g. Tekton Chains.
+type PublicKeyOptions struct {
+	PublicKeyPath string
+	PublicKeyID   string
+}
+
+var _ Interface = (*PublicKeyOptions)(nil)
+
+// AddFlags implements Interface.
+func (o *PublicKeyOptions) AddFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&o.PublicKeyPath, "public-key-path", "",
+		"[optional] path to the PEM-encoded public key that signed the provenance. (Only for Tekton Chains).")
+
+	cmd.Flags().StringVar(&o.PublicKeyID, "public-key-id", "",
+		"[optional] the ID of the public key, if the signature of the provenance has one")
+}
+
+// envList returns the list of paths in an environment variable,
+// separated by the OS-specific path list separator.
+func envList(key string) []string {
+	value := os.Getenv(key)
+	if value == "" {
+		return nil
+	}
+	return filepath.SplitList(value)
+}
+
+// VerifyChecksumsOptions is the top-level options for the `verifyChecksums` command.
+type VerifyChecksumsOptions struct {
+	VerifyOptions
+	VerifyFiles bool
+}
+
+var _ Interface = (*VerifyChecksumsOptions)(nil)
+
+// AddFlags implements Interface.
+func (o *VerifyChecksumsOptions) AddFlags(cmd *cobra.Command) {
+	o.VerifyOptions.AddFlags(cmd)
+
+	cmd.Flags().BoolVar(&o.VerifyFiles, "verify-files", false,
+		"[optional] also verify the digests of the files of the manifest, relative to the directory of the manifest")
 }
 
 // VerifyNpmOptions is the top-level options for the `verifyNpmPackage` command.
@@ -83,6 +340,8 @@ type VerifyNpmOptions struct {
 	VerifyOptions
 	/* Other */
 	AttestationsPath string
+	AttestationsURL  string
+	Registry         string
 	PackageName      string
 	PackageVersion   string
 }
@@ -109,7 +368,13 @@ func (o *VerifyNpmOptions) AddFlags(cmd *cobra.Command) {
 		"[optional] expected version the binary was compiled from. Uses semantic version to match the tag")
 
 	cmd.Flags().StringVar(&o.AttestationsPath, "attestations-path", "",
-		"path to a file containing the attestations")
+		"[optional] path to a file containing the attestations. If unset, the attestations are fetched from the registry")
+
+	cmd.Flags().StringVar(&o.AttestationsURL, "attestations-url", "",
+		"[optional] URL of the attestations, e.g. the dist.attestations.url of the package metadata")
+
+	cmd.Flags().StringVar(&o.Registry, "registry", utils.DefaultNpmRegistry,
+		"[optional] base URL of the npm registry to fetch the attestations of the package version from")
 
 	cmd.Flags().StringVar(&o.PackageName, "package-name", "",
 		"the package name")
@@ -120,11 +385,130 @@ func (o *VerifyNpmOptions) AddFlags(cmd *cobra.Command) {
 	cmd.Flags().BoolVar(&o.PrintProvenance, "print-provenance", false,
 		"[optional] print the verified provenance to stdout")
 
-	cmd.MarkFlagRequired("source-uri")
-	cmd.MarkFlagRequired("builder-id")
+	cmd.Flags().Var(&o.Output, "output",
+		"[optional] output format of the verification result: text or json")
+
+	cmd.Flags().StringVar(&o.PolicyPath, "policy", "",
+		"[optional] path to a policy file with the verification expectations, instead of the expectation flags")
+
+	o.SigstoreOptions.AddFlags(cmd)
+	o.GitHubOptions.AddFlags(cmd)
+
+	// The expected source URI and builder ID may come from the policy.
+	cmd.MarkFlagsOneRequired("source-uri", "policy")
+	cmd.MarkFlagsOneRequired("builder-id", "policy")
 	cmd.MarkFlagRequired("package-name")
 	cmd.MarkFlagRequired("package-version")
 	cmd.MarkFlagsMutuallyExclusive("source-versioned-tag", "source-tag")
+	cmd.MarkFlagsMutuallyExclusive("attestations-path", "attestations-url", "registry")
+	for _, f := range []string{"builder-id", "build-workflow-input"} {
+		cmd.MarkFlagsMutuallyExclusive("policy", f)
+	}
+}
+
+// VerifyPyPIOptions is the top-level options for the `verifyPyPIPackage` command.
+type VerifyPyPIOptions struct {
+	VerifyOptions
+	/* Other */
+	AttestationsPath string
+}
+
+var _ Interface = (*VerifyPyPIOptions)(nil)
+
+// AddFlags implements Interface.
+func (o *VerifyPyPIOptions) AddFlags(cmd *cobra.Command) {
+	/* Builder options */
+	cmd.Flags().StringVar(&o.BuilderID, "builder-id", "",
+		"the workflow that published the package, e.g. github.com/some/repo/.github/workflows/release.yml")
+
+	/* Source options */
+	cmd.Flags().StringVar(&o.SourceURI, "source-uri", "",
+		"expected source repository that should have published the package, e.g. github.com/some/repo")
+
+	cmd.Flags().StringVar(&o.SourceBranch, "source-branch", "", "[optional] expected branch the package was published from")
+
+	cmd.Flags().StringVar(&o.SourceTag, "source-tag", "", "[optional] expected tag the package was published from")
+
+	cmd.Flags().StringVar(&o.SourceVersionTag, "source-versioned-tag", "",
+		"[optional] expected version the package was published from. Uses semantic version to match the tag")
+
+	cmd.Flags().StringVar(&o.AttestationsPath, "attestations-path", "",
+		"path to a file containing the PEP 740 provenance object, attestation object or Sigstore bundles")
+
+	cmd.Flags().BoolVar(&o.PrintProvenance, "print-provenance", false,
+		"[optional] print the verified attestation statement to stdout")
+
+	cmd.Flags().Var(&o.Output, "output",
+		"[optional] output format of the verification result: text or json")
+
+	cmd.Flags().StringVar(&o.PolicyPath, "policy", "",
+		"[optional] path to a policy file with the verification expectations, instead of the expectation flags")
+
+	o.SigstoreOptions.AddFlags(cmd)
+	o.GitHubOptions.AddFlags(cmd)
+
+	// The expected source URI and builder ID may come from the policy.
+	cmd.MarkFlagsOneRequired("source-uri", "policy")
+	cmd.MarkFlagsOneRequired("builder-id", "policy")
+	cmd.MarkFlagRequired("attestations-path")
+	cmd.MarkFlagsMutuallyExclusive("source-versioned-tag", "source-tag")
+	cmd.MarkFlagsMutuallyExclusive("policy", "builder-id")
+}
+
+// VerifyNpmLockfileOptions is the top-level options for the `verifyNpmLockfile` command.
+type VerifyNpmLockfileOptions struct {
+	SigstoreOptions
+	GitHubOptions
+	BuilderIDs      []string
+	AttestationsDir string
+	Registry        string
+	AllowUnverified []string
+	Output          OutputFormat
+}
+
+var _ Interface = (*VerifyNpmLockfileOptions)(nil)
+
+// AddFlags implements Interface.
+func (o *VerifyNpmLockfileOptions) AddFlags(cmd *cobra.Command) {
+	cmd.Flags().StringSliceVar(&o.BuilderIDs, "builder-id", nil,
+		"[optional] a builder ID allowed for every package. Defaults to the npm CLI on GitHub-hosted runners and the SLSA3 Node.js builder")
+
+	cmd.Flags().StringVar(&o.AttestationsDir, "attestations-dir", "",
+		"[optional] directory caching the attestations of the packages, as <name>@<version>.json. Missing attestations are fetched from the registry")
+
+	cmd.Flags().StringVar(&o.Registry, "registry", utils.DefaultNpmRegistry,
+		"[optional] base URL of the npm registry to fetch the attestations of the packages from")
+
+	cmd.Flags().StringSliceVar(&o.AllowUnverified, "allow-unverified", nil,
+		"[optional] a package known to lack provenance, as a name or name@version pattern, e.g. @scope/* or left-pad@1.3.0")
+
+	cmd.Flags().Var(&o.Output, "output",
+		"[optional] output format of the verification result: text or json")
+
+	o.SigstoreOptions.AddFlags(cmd)
+	o.GitHubOptions.AddFlags(cmd)
+}
+
+// VerifyBatchOptions is the top-level options for the `verifyBatch` command.
+type VerifyBatchOptions struct {
+	SigstoreOptions
+	GitHubOptions
+	Concurrency int
+	Output      OutputFormat
+}
+
+var _ Interface = (*VerifyBatchOptions)(nil)
+
+// AddFlags implements Interface.
+func (o *VerifyBatchOptions) AddFlags(cmd *cobra.Command) {
+	cmd.Flags().IntVar(&o.Concurrency, "concurrency", batch.DefaultConcurrency,
+		"[optional] number of artifacts verified at once")
+
+	cmd.Flags().Var(&o.Output, "output",
+		"[optional] output format of the verification result: text or json")
+
+	o.SigstoreOptions.AddFlags(cmd)
+	o.GitHubOptions.AddFlags(cmd)
 }
 
 // VerifyVSAOptions is the top-level options for the `verifyVSA` command.
@@ -137,6 +521,7 @@ type VerifyVSAOptions struct {
 	PublicKeyPath    string
 	PublicKeyID      string
 	PrintAttestation bool
+	Output           OutputFormat
 }
 
 var _ Interface = (*VerifyVSAOptions)(nil)
@@ -167,6 +552,9 @@ func (o *VerifyVSAOptions) AddFlags(cmd *cobra.Command) {
 	cmd.Flags().StringVar(&o.PublicKeyID, "public-key-id", "",
 		"[optional] the ID of the public key, defaults to the SHA256 digest of the base64-encoded public key")
 
+	cmd.Flags().Var(&o.Output, "output",
+		"[optional] output format of the verification result: text or json")
+
 	cmd.MarkFlagRequired("subject-digests")
 	cmd.MarkFlagRequired("attestation-path")
 	cmd.MarkFlagRequired("verifier-id")
@@ -202,3 +590,34 @@ func (i *workflowInputs) Set(value string) error {
 func (i *workflowInputs) AsMap() map[string]string {
 	return i.kv
 }
+
+// OutputFormat is the format of the verification result.
+type OutputFormat string
+
+const (
+	// OutputText prints a PASSED/FAILED line to stderr.
+	OutputText OutputFormat = "text"
+	// OutputJSON additionally prints a JSON report to stdout.
+	OutputJSON OutputFormat = "json"
+)
+
+func (o *OutputFormat) Type() string {
+	return "format"
+}
+
+func (o *OutputFormat) String() string {
+	if *o == "" {
+		return string(OutputText)
+	}
+	return string(*o)
+}
+
+func (o *OutputFormat) Set(value string) error {
+	switch OutputFormat(value) {
+	case OutputText, OutputJSON:
+		*o = OutputFormat(value)
+		return nil
+	default:
+		return fmt.Errorf("%w: expected one of '%s' or '%s', got '%s'", serrors.ErrorInvalidFormat, OutputText, OutputJSON, value)
+	}
+}
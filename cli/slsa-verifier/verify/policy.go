@@ -0,0 +1,87 @@
+// Copyright 2026 SLSA Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verify
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/slsa-framework/slsa-verifier/v2/options"
+	"github.com/slsa-framework/slsa-verifier/v2/policy"
+	"github.com/slsa-framework/slsa-verifier/v2/report"
+	"github.com/slsa-framework/slsa-verifier/v2/verifiers/utils"
+)
+
+type verifyFn func(ctx context.Context, provenanceOpts *options.ProvenanceOpts,
+	builderOpts *options.BuilderOpts) ([]byte, *utils.TrustedBuilderID, error)
+
+// applyPolicy compiles the first rule of the policy at policyPath that matches
+// the artifact into verification options, and records the rule in the report.
+func applyPolicy(policyPath string, rep *report.Report, sourceURI, artifact, digest string,
+) (*options.ProvenanceOpts, []*options.BuilderOpts, error) {
+	pol, err := policy.Load(policyPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	rule, err := pol.Match(sourceURI, artifact)
+	if err != nil {
+		return nil, nil, err
+	}
+	provenanceOpts, err := rule.ProvenanceOpts(sourceURI, digest)
+	if err != nil {
+		return nil, nil, err
+	}
+	rep.PolicyRule = rule.Name
+	fmt.Fprintf(os.Stderr, "Using policy rule %q for %s\n", rule.Name, artifact)
+	return provenanceOpts, rule.BuilderOpts(), nil
+}
+
+// loadTrustedBuilders returns the trusted builders of the trust configuration
+// at configPath, or nil if configPath is empty.
+func loadTrustedBuilders(configPath string) ([]options.TrustedBuilder, error) {
+	if configPath == "" {
+		return nil, nil
+	}
+	c, err := policy.LoadTrustConfig(configPath)
+	if err != nil {
+		return nil, err
+	}
+	return c.Options(), nil
+}
+
+// verifyWithBuilders runs verify with each of the builder options in turn and
+// returns the result of the first one that succeeds, or else the first error.
+// Only the checks of the returned result are kept in the report.
+func verifyWithBuilders(ctx context.Context, provenanceOpts *options.ProvenanceOpts,
+	builderOpts []*options.BuilderOpts, verify verifyFn,
+) ([]byte, *utils.TrustedBuilderID, error) {
+	rep := report.FromContext(ctx)
+	var firstErr error
+	var failed *report.Report
+	for _, b := range builderOpts {
+		child := rep.Child()
+		verifiedProvenance, builderID, err := verify(report.NewContext(ctx, child), provenanceOpts, b)
+		if err == nil {
+			rep.Merge(child)
+			return verifiedProvenance, builderID, nil
+		}
+		if firstErr == nil {
+			firstErr, failed = err, child
+		}
+	}
+	rep.Merge(failed)
+	return nil, nil, firstErr
+}
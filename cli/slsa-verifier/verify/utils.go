@@ -16,9 +16,12 @@ package verify
 
 import (
 	"encoding/hex"
+	"fmt"
 	"hash"
 	"io"
 	"os"
+
+	"github.com/slsa-framework/slsa-verifier/v2/report"
 )
 
 func computeFileHash(filePath string, h hash.Hash) (string, error) {
@@ -33,3 +36,23 @@ func computeFileHash(filePath string, h hash.Hash) (string, error) {
 	}
 	return hex.EncodeToString(h.Sum(nil)), nil
 }
+
+// writeReports writes the JSON report to stdout if requested by the output format.
+func writeReports(output OutputFormat, reports []*report.Report) {
+	if output != OutputJSON {
+		return
+	}
+	if err := report.Write(os.Stdout, reports); err != nil {
+		fmt.Fprintf(os.Stderr, "writing report: %v\n", err)
+	}
+}
+
+// printProvenance prints the verified provenance to stdout, or adds it
+// to the report when the output format is JSON.
+func printProvenance(output OutputFormat, rep *report.Report, provenance []byte) {
+	if output == OutputJSON {
+		rep.Provenance = provenance
+		return
+	}
+	fmt.Fprintf(os.Stdout, "%s\n", string(provenance))
+}
@@ -19,8 +19,10 @@ import (
 	"crypto/sha256"
 	"fmt"
 	"os"
+	"path/filepath"
 
 	"github.com/slsa-framework/slsa-verifier/v2/options"
+	"github.com/slsa-framework/slsa-verifier/v2/report"
 	"github.com/slsa-framework/slsa-verifier/v2/verifiers"
 	"github.com/slsa-framework/slsa-verifier/v2/verifiers/utils"
 )
@@ -35,17 +37,35 @@ type VerifyArtifactCommand struct {
 	SourceVersionTag    *string
 	BuildWorkflowInputs map[string]string
 	PrintProvenance     bool
+	Output              OutputFormat
+	PolicyPath          string
+	SigstoreOpts        *options.SigstoreOpts
+	GitHubOpts          *options.GitHubOpts
+	GitLabOpts          *options.GitLabOpts
+	TrustedBuildersPath string
+	VSA                 *VSAOutput
+	TaskRefs            []string
+	SourceCommit        *string
+	PublicKeyPath       *string
+	PublicKeyID         *string
 }
 
 func (c *VerifyArtifactCommand) Exec(ctx context.Context, artifacts []string) (*utils.TrustedBuilderID, error) {
 	var builderID *utils.TrustedBuilderID
+	var reports []*report.Report
+	var subjects []options.VSADescriptor
+	defer func() { writeReports(c.Output, reports) }()
 
 	for _, artifact := range artifacts {
+		rep := report.New(artifact, "")
+		reports = append(reports, rep)
 		artifactHash, err := computeFileHash(artifact, sha256.New())
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Verifying artifact %s: FAILED: %v\n\n", artifact, err)
+			rep.Finish("", err)
 			return nil, err
 		}
+		rep.Digest = "sha256:" + artifactHash
 
 		provenanceOpts := &options.ProvenanceOpts{
 			ExpectedSourceURI:      c.SourceURI,
@@ -54,26 +74,61 @@ func (c *VerifyArtifactCommand) Exec(ctx context.Context, artifacts []string) (*
 			ExpectedVersionedTag:   c.SourceVersionTag,
 			ExpectedTag:            c.SourceTag,
 			ExpectedWorkflowInputs: c.BuildWorkflowInputs,
+			ExpectedTaskRefs:       c.TaskRefs,
+			ExpectedSourceCommit:   c.SourceCommit,
 		}
 
-		builderOpts := &options.BuilderOpts{
+		builderOpts := []*options.BuilderOpts{{
 			ExpectedID: c.BuilderID,
+		}}
+
+		if c.PolicyPath != "" {
+			provenanceOpts, builderOpts, err = applyPolicy(c.PolicyPath, rep, c.SourceURI,
+				filepath.Base(artifact), artifactHash)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Verifying artifact %s: FAILED: %v\n\n", artifact, err)
+				rep.Finish("", err)
+				return nil, err
+			}
+		}
+		provenanceOpts.SigstoreOpts = c.SigstoreOpts
+		provenanceOpts.GitHubOpts = c.GitHubOpts
+		provenanceOpts.GitLabOpts = c.GitLabOpts
+		provenanceOpts.TrustedBuilders, err = loadTrustedBuilders(c.TrustedBuildersPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Verifying artifact %s: FAILED: %v\n\n", artifact, err)
+			rep.Finish("", err)
+			return nil, err
+		}
+		if c.PublicKeyPath != nil {
+			provenanceOpts.VerificationOpts, err = loadVerificationOpts(*c.PublicKeyPath, c.PublicKeyID)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Verifying artifact %s: FAILED: %v\n\n", artifact, err)
+				rep.Finish("", err)
+				return nil, err
+			}
 		}
 
 		provenance, err := os.ReadFile(c.ProvenancePath)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Verifying artifact %s: FAILED: %v\n\n", artifact, err)
+			rep.Finish("", err)
 			return nil, err
 		}
 
-		verifiedProvenance, outBuilderID, err := verifiers.VerifyArtifact(ctx, provenance, artifactHash, provenanceOpts, builderOpts)
+		verifiedProvenance, outBuilderID, err := verifyWithBuilders(report.NewContext(ctx, rep), provenanceOpts, builderOpts,
+			func(ctx context.Context, provenanceOpts *options.ProvenanceOpts, builderOpts *options.BuilderOpts,
+			) ([]byte, *utils.TrustedBuilderID, error) {
+				return verifiers.VerifyArtifact(ctx, provenance, artifactHash, provenanceOpts, builderOpts)
+			})
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Verifying artifact %s: FAILED: %v\n\n", artifact, err)
+			rep.Finish("", err)
 			return nil, err
 		}
 
 		if c.PrintProvenance {
-			fmt.Fprintf(os.Stdout, "%s\n", string(verifiedProvenance))
+			printProvenance(c.Output, rep, verifiedProvenance)
 		}
 
 		if builderID == nil {
@@ -81,9 +136,21 @@ func (c *VerifyArtifactCommand) Exec(ctx context.Context, artifacts []string) (*
 		} else if *builderID != *outBuilderID {
 			err := fmt.Errorf("encountered different builderIDs %v %v", builderID, outBuilderID)
 			fmt.Fprintf(os.Stderr, "Verifying artifact %s: FAILED: %v\n\n", artifact, err)
+			rep.Finish("", err)
 			return nil, err
 		}
+		rep.Finish(outBuilderID.String(), nil)
 		fmt.Fprintf(os.Stderr, "Verifying artifact %s: PASSED\n\n", artifact)
+		subjects = append(subjects, options.VSADescriptor{
+			Name:   filepath.Base(artifact),
+			Digest: map[string]string{"sha256": artifactHash},
+		})
+	}
+
+	if c.VSA != nil {
+		if err := c.VSA.write(ctx, subjects, reports, c.PolicyPath, c.ProvenancePath); err != nil {
+			return nil, err
+		}
 	}
 
 	return builderID, nil
@@ -0,0 +1,80 @@
+// Copyright 2026 SLSA Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verify
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/slsa-framework/slsa-verifier/v2/batch"
+	"github.com/slsa-framework/slsa-verifier/v2/options"
+	"github.com/slsa-framework/slsa-verifier/v2/report"
+)
+
+type VerifyBatchCommand struct {
+	ManifestPath string
+	// Concurrency is the number of artifacts verified at once.
+	// Defaults to batch.DefaultConcurrency.
+	Concurrency         int
+	Output              OutputFormat
+	SigstoreOpts        *options.SigstoreOpts
+	GitHubOpts          *options.GitHubOpts
+	TrustedBuildersPath string
+}
+
+func (c *VerifyBatchCommand) Exec(ctx context.Context) (*batch.Result, error) {
+	var reports []*report.Report
+	defer func() { writeReports(c.Output, reports) }()
+
+	m, err := batch.Load(c.ManifestPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Verifying batch %s: FAILED: %v\n\n", c.ManifestPath, err)
+		return nil, err
+	}
+	trustedBuilders, err := loadTrustedBuilders(c.TrustedBuildersPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Verifying batch %s: FAILED: %v\n\n", c.ManifestPath, err)
+		return nil, err
+	}
+
+	result := batch.Verify(ctx, m.Items, &batch.Options{
+		Concurrency:     c.Concurrency,
+		AllowFiles:      true,
+		SigstoreOpts:    c.SigstoreOpts,
+		GitHubOpts:      c.GitHubOpts,
+		TrustedBuilders: trustedBuilders,
+	})
+	reports = result.Items
+
+	var firstErr error
+	for i, rep := range result.Items {
+		err := result.Errors[i]
+		if err == nil {
+			fmt.Fprintf(os.Stderr, "Verifying artifact %s: PASSED\n", rep.Artifact)
+			continue
+		}
+		fmt.Fprintf(os.Stderr, "Verifying artifact %s: FAILED: %v\n", rep.Artifact, err)
+		if firstErr == nil {
+			firstErr = fmt.Errorf("%s: %w", rep.Artifact, err)
+		}
+	}
+	fmt.Fprintf(os.Stderr, "\nVerified %d artifacts of %s: %d passed, %d failed\n\n",
+		len(result.Items), c.ManifestPath, result.Passed, result.Failed)
+	if firstErr != nil {
+		return result, fmt.Errorf("%d artifacts failed verification: %w", result.Failed, firstErr)
+	}
+	return result, nil
+}
@@ -0,0 +1,303 @@
+// Copyright 2026 SLSA Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verify
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	intoto "github.com/in-toto/in-toto-golang/in_toto"
+
+	serrors "github.com/slsa-framework/slsa-verifier/v2/errors"
+	"github.com/slsa-framework/slsa-verifier/v2/options"
+	"github.com/slsa-framework/slsa-verifier/v2/report"
+	"github.com/slsa-framework/slsa-verifier/v2/verifiers"
+	"github.com/slsa-framework/slsa-verifier/v2/verifiers/utils"
+)
+
+// VerifyChecksumsCommand verifies the entries of a checksums manifest
+// against a provenance with one subject per entry.
+// Note: nil branch, tag, version-tag and builder-id means we ignore them during verification.
+type VerifyChecksumsCommand struct {
+	ProvenancePath      string
+	BuilderID           *string
+	SourceURI           string
+	SourceBranch        *string
+	SourceTag           *string
+	SourceVersionTag    *string
+	BuildWorkflowInputs map[string]string
+	PrintProvenance     bool
+	Output              OutputFormat
+	PolicyPath          string
+	SigstoreOpts        *options.SigstoreOpts
+	GitHubOpts          *options.GitHubOpts
+	GitLabOpts          *options.GitLabOpts
+	TrustedBuildersPath string
+	VSA                 *VSAOutput
+	TaskRefs            []string
+	SourceCommit        *string
+	PublicKeyPath       *string
+	PublicKeyID         *string
+	// VerifyFiles also verifies the digests of the files of the manifest,
+	// which are relative to the directory of the manifest.
+	VerifyFiles bool
+}
+
+func (c *VerifyChecksumsCommand) Exec(ctx context.Context, manifestPath string) (*utils.TrustedBuilderID, error) {
+	rep := report.New(manifestPath, "")
+	defer func() { writeReports(c.Output, []*report.Report{rep}) }()
+
+	builderID, checksums, err := c.verify(ctx, rep, manifestPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Verifying checksums %s: FAILED: %v\n\n", manifestPath, err)
+		rep.Finish("", err)
+		return nil, err
+	}
+	rep.Finish(builderID.String(), nil)
+	fmt.Fprintf(os.Stderr, "Verifying checksums %s: PASSED\n\n", manifestPath)
+
+	if c.VSA != nil {
+		subjects := make([]options.VSADescriptor, len(checksums))
+		for i, checksum := range checksums {
+			subjects[i] = options.VSADescriptor{
+				Name:   checksum.Name,
+				Digest: map[string]string{checksum.Algorithm: checksum.Digest},
+			}
+		}
+		if err := c.VSA.write(ctx, subjects, []*report.Report{rep}, c.PolicyPath, c.ProvenancePath); err != nil {
+			return nil, err
+		}
+	}
+	return builderID, nil
+}
+
+func (c *VerifyChecksumsCommand) verify(ctx context.Context, rep *report.Report, manifestPath string,
+) (*utils.TrustedBuilderID, []utils.Checksum, error) {
+	manifest, err := os.ReadFile(manifestPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	manifestHash := sha256.Sum256(manifest)
+	rep.Digest = fmt.Sprintf("sha256:%x", manifestHash)
+	checksums, err := utils.ParseChecksums(bytes.NewReader(manifest))
+	if err != nil {
+		return nil, nil, err
+	}
+	provenance, err := os.ReadFile(c.ProvenancePath)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// The provenance is verified once, with the sha256 digest of one of
+	// its subjects, and the entries are then compared with the subjects of
+	// the verified provenance.
+	digest, err := subjectDigest(checksums, provenanceSubjects(provenance))
+	if err != nil {
+		return nil, nil, rep.Check(report.CheckManifest, err)
+	}
+	provenanceOpts, builderOpts, err := c.options(rep, manifestPath, digest)
+	if err != nil {
+		return nil, nil, err
+	}
+	verifiedProvenance, builderID, err := verifyWithBuilders(report.NewContext(ctx, rep), provenanceOpts, builderOpts,
+		func(ctx context.Context, provenanceOpts *options.ProvenanceOpts, builderOpts *options.BuilderOpts,
+		) ([]byte, *utils.TrustedBuilderID, error) {
+			return verifiers.VerifyArtifact(ctx, provenance, digest, provenanceOpts, builderOpts)
+		})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if c.PrintProvenance {
+		printProvenance(c.Output, rep, verifiedProvenance)
+	}
+
+	statement, err := utils.StatementFromBytes(verifiedProvenance)
+	if err != nil {
+		return nil, nil, err
+	}
+	comparison, err := utils.VerifyChecksums(checksums, statement.Subject)
+	m := &report.Manifest{
+		Entries:    len(checksums),
+		Missing:    comparison.Missing,
+		Mismatched: comparison.Mismatched,
+		Extra:      comparison.Extra,
+	}
+	rep.SetManifest(m)
+	if err := rep.Check(report.CheckManifest, err); err != nil {
+		return nil, nil, err
+	}
+
+	if c.VerifyFiles {
+		m.LocalMismatched, err = verifyLocalChecksums(filepath.Dir(manifestPath), checksums)
+		if err := rep.Check(report.CheckLocalDigest, err); err != nil {
+			return nil, nil, err
+		}
+	}
+	return builderID, checksums, nil
+}
+
+// provenanceSubjects returns the subjects of the in-toto statements of a
+// provenance file that is not verified yet: DSSE envelopes and Sigstore
+// bundles, one per line or nested in another JSON document as with Google
+// Cloud Build. The subjects only select the digest to verify the
+// provenance with.
+func provenanceSubjects(provenance []byte) []intoto.Subject {
+	var subjects []intoto.Subject
+	decoder := json.NewDecoder(bytes.NewReader(provenance))
+	for {
+		var v any
+		if err := decoder.Decode(&v); err != nil {
+			return subjects
+		}
+		subjects = append(subjects, envelopeSubjects(v)...)
+	}
+}
+
+// envelopeSubjects returns the subjects of the payloads of the DSSE
+// envelopes in the decoded JSON value v.
+func envelopeSubjects(v any) []intoto.Subject {
+	var subjects []intoto.Subject
+	switch v := v.(type) {
+	case map[string]any:
+		if payload, ok := v["payload"].(string); ok {
+			subjects = append(subjects, payloadSubjects(payload)...)
+		}
+		for _, k := range slices.Sorted(maps.Keys(v)) {
+			subjects = append(subjects, envelopeSubjects(v[k])...)
+		}
+	case []any:
+		for _, e := range v {
+			subjects = append(subjects, envelopeSubjects(e)...)
+		}
+	}
+	return subjects
+}
+
+// payloadSubjects returns the subjects of the base64-encoded statement of
+// a DSSE envelope.
+func payloadSubjects(payload string) []intoto.Subject {
+	b, err := base64.StdEncoding.DecodeString(payload)
+	if err != nil {
+		if b, err = base64.URLEncoding.DecodeString(payload); err != nil {
+			return nil
+		}
+	}
+	statement, err := utils.StatementFromBytes(b)
+	if err != nil {
+		return nil
+	}
+	return statement.Subject
+}
+
+// subjectDigest returns the sha256 digest of the subject of the first
+// entry that is a subject, or else of the first subject, to verify the
+// provenance with.
+func subjectDigest(checksums []utils.Checksum, subjects []intoto.Subject) (string, error) {
+	for _, checksum := range checksums {
+		for _, subject := range subjects {
+			if subject.Digest["sha256"] != "" && strings.EqualFold(subject.Digest[checksum.Algorithm], checksum.Digest) {
+				return subject.Digest["sha256"], nil
+			}
+		}
+	}
+	for _, subject := range subjects {
+		if subject.Digest["sha256"] != "" {
+			return subject.Digest["sha256"], nil
+		}
+	}
+	return "", fmt.Errorf("%w: no subject of the provenance has a sha256 digest", serrors.ErrorMissingSubjectDigest)
+}
+
+// options returns the verification options of the manifest, for the entry
+// with the digest.
+func (c *VerifyChecksumsCommand) options(rep *report.Report, manifestPath, digest string,
+) (*options.ProvenanceOpts, []*options.BuilderOpts, error) {
+	provenanceOpts := &options.ProvenanceOpts{
+		ExpectedSourceURI:      c.SourceURI,
+		ExpectedBranch:         c.SourceBranch,
+		ExpectedDigest:         digest,
+		ExpectedVersionedTag:   c.SourceVersionTag,
+		ExpectedTag:            c.SourceTag,
+		ExpectedWorkflowInputs: c.BuildWorkflowInputs,
+		ExpectedTaskRefs:       c.TaskRefs,
+		ExpectedSourceCommit:   c.SourceCommit,
+	}
+	builderOpts := []*options.BuilderOpts{{
+		ExpectedID: c.BuilderID,
+	}}
+	var err error
+	if c.PolicyPath != "" {
+		// Rules match the manifest by name.
+		provenanceOpts, builderOpts, err = applyPolicy(c.PolicyPath, rep, c.SourceURI,
+			filepath.Base(manifestPath), digest)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	provenanceOpts.SigstoreOpts = c.SigstoreOpts
+	provenanceOpts.GitHubOpts = c.GitHubOpts
+	provenanceOpts.GitLabOpts = c.GitLabOpts
+	provenanceOpts.TrustedBuilders, err = loadTrustedBuilders(c.TrustedBuildersPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	if c.PublicKeyPath != nil {
+		provenanceOpts.VerificationOpts, err = loadVerificationOpts(*c.PublicKeyPath, c.PublicKeyID)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	return provenanceOpts, builderOpts, nil
+}
+
+// verifyLocalChecksums verifies the digests of the files of the entries,
+// relative to dir. It returns the names of the entries whose file has
+// another digest.
+func verifyLocalChecksums(dir string, checksums []utils.Checksum) ([]string, error) {
+	var mismatched []string
+	for _, checksum := range checksums {
+		var h hash.Hash
+		switch checksum.Algorithm {
+		case "sha512":
+			h = sha512.New()
+		default:
+			h = sha256.New()
+		}
+		digest, err := computeFileHash(filepath.Join(dir, filepath.FromSlash(checksum.Name)), h)
+		if err != nil {
+			return nil, err
+		}
+		if digest != checksum.Digest {
+			mismatched = append(mismatched, checksum.Name)
+		}
+	}
+	if len(mismatched) > 0 {
+		return mismatched, fmt.Errorf("%w: local files with a different digest: %q",
+			serrors.ErrorMismatchHash, mismatched)
+	}
+	return nil, nil
+}
@@ -16,10 +16,10 @@ package verify
 
 import (
 	"context"
-	"fmt"
 	"os"
 
 	"github.com/slsa-framework/slsa-verifier/v2/options"
+	"github.com/slsa-framework/slsa-verifier/v2/report"
 	"github.com/slsa-framework/slsa-verifier/v2/verifiers"
 	"github.com/slsa-framework/slsa-verifier/v2/verifiers/utils"
 	"github.com/slsa-framework/slsa-verifier/v2/verifiers/utils/container"
@@ -39,16 +39,40 @@ type VerifyImageCommand struct {
 	SourceVersionTag     *string
 	BuildWorkflowInputs  map[string]string
 	PrintProvenance      bool
+	Output               OutputFormat
+	PolicyPath           string
+	SigstoreOpts         *options.SigstoreOpts
+	GitHubOpts           *options.GitHubOpts
+	GitLabOpts           *options.GitLabOpts
+	TrustedBuildersPath  string
+	VSA                  *VSAOutput
+	TaskRefs             []string
+	SourceCommit         *string
+	PublicKeyPath        *string
+	PublicKeyID          *string
 }
 
 func (c *VerifyImageCommand) Exec(ctx context.Context, artifacts []string) (*utils.TrustedBuilderID, error) {
 	artifactImage := artifacts[0]
+	rep := report.New(artifactImage, "")
+	defer func() { writeReports(c.Output, []*report.Report{rep}) }()
+
+	// The images of a docker-archive tarball are verified in its OCI image
+	// layout, extracted once for all the builders.
+	image, cleanup, err := container.OpenDockerArchive(artifactImage)
+	if err != nil {
+		rep.Finish("", err)
+		return nil, err
+	}
+	defer cleanup()
 
 	// Verify that the reference is immutable.
-	digest, err := container.GetDigestFromImmutableReference(artifactImage)
+	digest, err := container.GetDigestFromImmutableReference(image)
 	if err != nil {
+		rep.Finish("", err)
 		return nil, err
 	}
+	rep.Digest = "sha256:" + digest
 
 	provenanceOpts := &options.ProvenanceOpts{
 		ExpectedSourceURI:            c.SourceURI,
@@ -58,28 +82,83 @@ func (c *VerifyImageCommand) Exec(ctx context.Context, artifacts []string) (*uti
 		ExpectedTag:                  c.SourceTag,
 		ExpectedProvenanceRepository: c.ProvenanceRepository,
 		ExpectedWorkflowInputs:       c.BuildWorkflowInputs,
+		ExpectedTaskRefs:             c.TaskRefs,
+		ExpectedSourceCommit:         c.SourceCommit,
 	}
 
-	builderOpts := &options.BuilderOpts{
+	builderOpts := []*options.BuilderOpts{{
 		ExpectedID: c.BuilderID,
+	}}
+
+	if c.PolicyPath != "" {
+		// Rules match images by repository, e.g. ghcr.io/org/image, and
+		// local images by path, e.g. oci-layout:/path/to/layout.
+		imageName, err := container.ImageName(artifactImage)
+		if err != nil {
+			rep.Finish("", err)
+			return nil, err
+		}
+		provenanceOpts, builderOpts, err = applyPolicy(c.PolicyPath, rep, c.SourceURI,
+			imageName, digest)
+		if err != nil {
+			rep.Finish("", err)
+			return nil, err
+		}
+		provenanceOpts.ExpectedProvenanceRepository = c.ProvenanceRepository
+	}
+	provenanceOpts.SigstoreOpts = c.SigstoreOpts
+	provenanceOpts.GitHubOpts = c.GitHubOpts
+	provenanceOpts.GitLabOpts = c.GitLabOpts
+	provenanceOpts.TrustedBuilders, err = loadTrustedBuilders(c.TrustedBuildersPath)
+	if err != nil {
+		rep.Finish("", err)
+		return nil, err
+	}
+	if c.PublicKeyPath != nil {
+		provenanceOpts.VerificationOpts, err = loadVerificationOpts(*c.PublicKeyPath, c.PublicKeyID)
+		if err != nil {
+			rep.Finish("", err)
+			return nil, err
+		}
 	}
 
 	var provenance []byte
 	if c.ProvenancePath != nil {
 		provenance, err = os.ReadFile(*c.ProvenancePath)
 		if err != nil {
+			rep.Finish("", err)
 			return nil, err
 		}
 	}
 
-	verifiedProvenance, outBuilderID, err := verifiers.VerifyImage(ctx, artifacts[0], provenance, provenanceOpts, builderOpts)
+	verifiedProvenance, outBuilderID, err := verifyWithBuilders(report.NewContext(ctx, rep), provenanceOpts, builderOpts,
+		func(ctx context.Context, provenanceOpts *options.ProvenanceOpts, builderOpts *options.BuilderOpts,
+		) ([]byte, *utils.TrustedBuilderID, error) {
+			return verifiers.VerifyImage(ctx, image, provenance, provenanceOpts, builderOpts)
+		})
 
 	if err != nil {
+		rep.Finish("", err)
 		return nil, err
 	}
 
 	if c.PrintProvenance {
-		fmt.Fprintf(os.Stdout, "%s\n", string(verifiedProvenance))
+		printProvenance(c.Output, rep, verifiedProvenance)
+	}
+	rep.Finish(outBuilderID.String(), nil)
+
+	if c.VSA != nil {
+		subjects := []options.VSADescriptor{{
+			Name:   artifactImage,
+			Digest: map[string]string{"sha256": digest},
+		}}
+		var provenancePath string
+		if c.ProvenancePath != nil {
+			provenancePath = *c.ProvenancePath
+		}
+		if err := c.VSA.write(ctx, subjects, []*report.Report{rep}, c.PolicyPath, provenancePath); err != nil {
+			return nil, err
+		}
 	}
 
 	return outBuilderID, nil
@@ -0,0 +1,228 @@
+// Copyright 2026 SLSA Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	serrors "github.com/slsa-framework/slsa-verifier/v2/errors"
+	"github.com/slsa-framework/slsa-verifier/v2/options"
+	"github.com/slsa-framework/slsa-verifier/v2/report"
+	"github.com/slsa-framework/slsa-verifier/v2/verifiers"
+	"github.com/slsa-framework/slsa-verifier/v2/verifiers/utils"
+)
+
+// DefaultNpmBuilderIDs are the builders of the packages of a lockfile,
+// unless others are given: the npm CLI on GitHub-hosted runners, and the
+// SLSA3 Node.js builder.
+var DefaultNpmBuilderIDs = []string{
+	"https://github.com/actions/runner/github-hosted",
+	"https://github.com/slsa-framework/slsa-github-generator/.github/workflows/builder_nodejs_slsa3.yml",
+}
+
+type VerifyNpmLockfileCommand struct {
+	LockfilePath string
+	// BuilderIDs are the builders allowed for every package.
+	// Defaults to DefaultNpmBuilderIDs.
+	BuilderIDs []string
+	// AttestationsDir caches the attestations of the packages, in
+	// <dir>/<name>@<version>.json. The attestations missing from it are
+	// fetched from Registry and written to it.
+	AttestationsDir string
+	// Registry is the base URL of the npm registry that serves the
+	// attestations. Defaults to the public registry.
+	Registry string
+	// Allowlist are patterns, in the syntax of path.Match, of the packages
+	// known to lack provenance, e.g. @scope/* or name@1.0.0.
+	Allowlist           []string
+	Output              OutputFormat
+	SigstoreOpts        *options.SigstoreOpts
+	GitHubOpts          *options.GitHubOpts
+	TrustedBuildersPath string
+}
+
+// NpmLockfileSummary lists the packages of a lockfile, as name@version,
+// by the outcome of their verification.
+type NpmLockfileSummary struct {
+	Verified    []string
+	Unverified  []string
+	Allowlisted []string
+	Failed      []string
+}
+
+func (c *VerifyNpmLockfileCommand) Exec(ctx context.Context) (*NpmLockfileSummary, error) {
+	var reports []*report.Report
+	defer func() { writeReports(c.Output, reports) }()
+
+	content, err := os.ReadFile(c.LockfilePath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Verifying npm lockfile %s: FAILED: %v\n\n", c.LockfilePath, err)
+		return nil, err
+	}
+	packages, err := utils.ParseNpmLockfile(content)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Verifying npm lockfile %s: FAILED: %v\n\n", c.LockfilePath, err)
+		return nil, err
+	}
+	if err := utils.ValidatePatterns(c.Allowlist); err != nil {
+		fmt.Fprintf(os.Stderr, "Verifying npm lockfile %s: FAILED: %v\n\n", c.LockfilePath, err)
+		return nil, err
+	}
+	trustedBuilders, err := loadTrustedBuilders(c.TrustedBuildersPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Verifying npm lockfile %s: FAILED: %v\n\n", c.LockfilePath, err)
+		return nil, err
+	}
+	builderIDs := c.BuilderIDs
+	if len(builderIDs) == 0 {
+		builderIDs = DefaultNpmBuilderIDs
+	}
+	builderOpts := make([]*options.BuilderOpts, 0, len(builderIDs))
+	for i := range builderIDs {
+		builderOpts = append(builderOpts, &options.BuilderOpts{ExpectedID: &builderIDs[i]})
+	}
+
+	summary := &NpmLockfileSummary{}
+	var firstErr error
+	for i := range packages {
+		pkg := &packages[i]
+		id := pkg.Name + "@" + pkg.Version
+		rep := report.New(id, "")
+		reports = append(reports, rep)
+
+		err := c.verifyPackage(report.NewContext(ctx, rep), pkg, builderOpts, trustedBuilders)
+		switch {
+		case err == nil:
+			summary.Verified = append(summary.Verified, id)
+		case errors.Is(err, serrors.ErrorNotPresent):
+			allowlisted, _ := utils.MatchesAnyPattern(pkg.Name, c.Allowlist)
+			if !allowlisted {
+				allowlisted, _ = utils.MatchesAnyPattern(id, c.Allowlist)
+			}
+			rep.FinishUnverified(allowlisted, err)
+			if allowlisted {
+				summary.Allowlisted = append(summary.Allowlisted, id)
+			} else {
+				summary.Unverified = append(summary.Unverified, id)
+				fmt.Fprintf(os.Stderr, "Verifying npm package %s: UNVERIFIED: %v\n", id, err)
+			}
+		default:
+			rep.Finish("", err)
+			summary.Failed = append(summary.Failed, id)
+			if firstErr == nil {
+				firstErr = err
+			}
+			fmt.Fprintf(os.Stderr, "Verifying npm package %s: FAILED: %v\n", id, err)
+		}
+	}
+
+	fmt.Fprintf(os.Stderr, "\nVerified %d packages of %s: %d without provenance, %d allowlisted, %d failed\n\n",
+		len(summary.Verified), c.LockfilePath, len(summary.Unverified), len(summary.Allowlisted), len(summary.Failed))
+	if firstErr != nil {
+		return summary, fmt.Errorf("%d packages failed verification: %w", len(summary.Failed), firstErr)
+	}
+	if len(summary.Unverified) > 0 {
+		return summary, fmt.Errorf("%w: %d packages without provenance, allowlist them if they are expected to lack it",
+			serrors.ErrorNotPresent, len(summary.Unverified))
+	}
+	return summary, nil
+}
+
+// verifyPackage verifies the attestations of a package against its
+// integrity. It returns an error wrapping serrors.ErrorNotPresent if the
+// package has no provenance to verify.
+func (c *VerifyNpmLockfileCommand) verifyPackage(ctx context.Context, pkg *utils.NpmLockPackage,
+	builderOpts []*options.BuilderOpts, trustedBuilders []options.TrustedBuilder,
+) error {
+	rep := report.FromContext(ctx)
+	tarballHash, ok := pkg.SHA512()
+	if !ok {
+		return fmt.Errorf("%w: no sha512 integrity in the lockfile", serrors.ErrorNotPresent)
+	}
+	rep.Digest = "sha512:" + tarballHash
+
+	attestations, err := c.attestations(ctx, pkg)
+	if err != nil {
+		return err
+	}
+
+	// The source repositories of the dependencies are not known in advance:
+	// they are recorded in the report.
+	provenanceOpts := &options.ProvenanceOpts{
+		AnySourceURI:           true,
+		ExpectedDigest:         tarballHash,
+		ExpectedPackageName:    &pkg.Name,
+		ExpectedPackageVersion: &pkg.Version,
+		SigstoreOpts:           c.SigstoreOpts,
+		GitHubOpts:             c.GitHubOpts,
+		TrustedBuilders:        trustedBuilders,
+	}
+	_, outBuilderID, err := verifyWithBuilders(ctx, provenanceOpts, builderOpts,
+		func(ctx context.Context, provenanceOpts *options.ProvenanceOpts, builderOpts *options.BuilderOpts,
+		) ([]byte, *utils.TrustedBuilderID, error) {
+			return verifiers.VerifyNpmPackage(ctx, attestations, tarballHash, provenanceOpts, builderOpts)
+		})
+	if err != nil {
+		return err
+	}
+	rep.Finish(outBuilderID.String(), nil)
+	return nil
+}
+
+// attestations returns the attestations of the package from the cache
+// directory, or else fetches them from the registry.
+func (c *VerifyNpmLockfileCommand) attestations(ctx context.Context, pkg *utils.NpmLockPackage) ([]byte, error) {
+	var cachePath string
+	if c.AttestationsDir != "" {
+		name := filepath.FromSlash(pkg.Name) + "@" + pkg.Version + ".json"
+		if !filepath.IsLocal(name) {
+			return nil, fmt.Errorf("%w: %q", serrors.ErrorInvalidPackageName, pkg.Name)
+		}
+		cachePath = filepath.Join(c.AttestationsDir, name)
+		content, err := os.ReadFile(cachePath)
+		if err == nil {
+			return content, nil
+		}
+		if !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+	}
+
+	registry := c.Registry
+	if registry == "" {
+		registry = utils.DefaultNpmRegistry
+	}
+	attestationsURL, err := utils.NpmAttestationsURL(registry, pkg.Name, pkg.Version)
+	if err != nil {
+		return nil, err
+	}
+	content, err := utils.FetchNpmAttestations(ctx, attestationsURL)
+	if err != nil {
+		return nil, err
+	}
+	if cachePath != "" {
+		if err := os.MkdirAll(filepath.Dir(cachePath), 0o750); err != nil {
+			return nil, err
+		}
+		if err := os.WriteFile(cachePath, content, 0o600); err != nil {
+			return nil, err
+		}
+	}
+	return content, nil
+}
@@ -17,17 +17,26 @@ package verify
 import (
 	"context"
 	"crypto/sha512"
-	"errors"
 	"fmt"
 	"os"
 
+	serrors "github.com/slsa-framework/slsa-verifier/v2/errors"
 	"github.com/slsa-framework/slsa-verifier/v2/options"
+	"github.com/slsa-framework/slsa-verifier/v2/report"
 	"github.com/slsa-framework/slsa-verifier/v2/verifiers"
 	"github.com/slsa-framework/slsa-verifier/v2/verifiers/utils"
 )
 
 type VerifyNpmPackageCommand struct {
-	AttestationsPath    string
+	// AttestationsPath is the path of the attestations. If empty, they are
+	// fetched from AttestationsURL, or else from Registry.
+	AttestationsPath string
+	// AttestationsURL is the URL of the attestations, e.g. the
+	// dist.attestations.url of the package metadata.
+	AttestationsURL string
+	// Registry is the base URL of the npm registry that serves the
+	// attestations of the package version. Defaults to the public registry.
+	Registry            string
 	BuilderID           *string
 	SourceURI           string
 	SourceBranch        *string
@@ -37,26 +46,28 @@ type VerifyNpmPackageCommand struct {
 	PackageVersion      *string
 	BuildWorkflowInputs map[string]string
 	PrintProvenance     bool
+	Output              OutputFormat
+	PolicyPath          string
+	SigstoreOpts        *options.SigstoreOpts
+	GitHubOpts          *options.GitHubOpts
+	TrustedBuildersPath string
 }
 
 func (c *VerifyNpmPackageCommand) Exec(ctx context.Context, tarballs []string) (*utils.TrustedBuilderID, error) {
 	var builderID *utils.TrustedBuilderID
-	if !options.ExperimentalEnabled() {
-		err := errors.New("feature support is only provided in SLSA_VERIFIER_EXPERIMENTAL mode")
-		fmt.Fprintf(os.Stderr, "Verifying npm package: FAILED: %v\n\n", err)
-		return nil, err
-	}
+	var reports []*report.Report
+	defer func() { writeReports(c.Output, reports) }()
 	for _, tarball := range tarballs {
+		rep := report.New(tarball, "")
+		reports = append(reports, rep)
 		tarballHash, err := computeFileHash(tarball, sha512.New())
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Verifying npm package %s: FAILED: %v\n\n", tarball, err)
+			rep.Finish("", err)
 			return nil, err
 		}
+		rep.Digest = "sha512:" + tarballHash
 
-		if c.AttestationsPath == "" {
-			fmt.Fprintf(os.Stderr, "--attestations-path is required.\n\n")
-			return nil, err
-		}
 		provenanceOpts := &options.ProvenanceOpts{
 			ExpectedSourceURI:      c.SourceURI,
 			ExpectedBranch:         c.SourceBranch,
@@ -68,29 +79,102 @@ func (c *VerifyNpmPackageCommand) Exec(ctx context.Context, tarballs []string) (
 			ExpectedPackageVersion: c.PackageVersion,
 		}
 
-		builderOpts := &options.BuilderOpts{
+		builderOpts := []*options.BuilderOpts{{
 			ExpectedID: c.BuilderID,
+		}}
+
+		if c.PolicyPath != "" {
+			provenanceOpts, builderOpts, err = c.applyPolicy(rep, tarballHash)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Verifying npm package %s: FAILED: %v\n\n", tarball, err)
+				rep.Finish("", err)
+				return nil, err
+			}
+		}
+		provenanceOpts.SigstoreOpts = c.SigstoreOpts
+		provenanceOpts.GitHubOpts = c.GitHubOpts
+		provenanceOpts.TrustedBuilders, err = loadTrustedBuilders(c.TrustedBuildersPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Verifying npm package %s: FAILED: %v\n\n", tarball, err)
+			rep.Finish("", err)
+			return nil, err
 		}
 
-		attestations, err := os.ReadFile(c.AttestationsPath)
+		attestations, err := c.attestations(ctx)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Verifying npm package %s: FAILED: %v\n\n", tarball, err)
+			rep.Finish("", err)
 			return nil, err
 		}
 
-		verifiedProvenance, outBuilderID, err := verifiers.VerifyNpmPackage(ctx, attestations, tarballHash, provenanceOpts, builderOpts)
+		verifiedProvenance, outBuilderID, err := verifyWithBuilders(report.NewContext(ctx, rep), provenanceOpts, builderOpts,
+			func(ctx context.Context, provenanceOpts *options.ProvenanceOpts, builderOpts *options.BuilderOpts,
+			) ([]byte, *utils.TrustedBuilderID, error) {
+				return verifiers.VerifyNpmPackage(ctx, attestations, tarballHash, provenanceOpts, builderOpts)
+			})
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Verifying npm package %s: FAILED: %v\n\n", tarball, err)
+			rep.Finish("", err)
 			return nil, err
 		}
 
 		if c.PrintProvenance {
-			fmt.Fprintf(os.Stdout, "%s\n", string(verifiedProvenance))
+			printProvenance(c.Output, rep, verifiedProvenance)
 		}
 
 		builderID = outBuilderID
+		rep.Finish(outBuilderID.String(), nil)
 		fmt.Fprintf(os.Stderr, "Verifying npm package %s: PASSED\n\n", tarball)
 	}
 
 	return builderID, nil
 }
+
+// attestations returns the attestations read from AttestationsPath, or else
+// fetched from AttestationsURL or from the registry.
+func (c *VerifyNpmPackageCommand) attestations(ctx context.Context) ([]byte, error) {
+	if c.AttestationsPath != "" {
+		return os.ReadFile(c.AttestationsPath)
+	}
+	attestationsURL := c.AttestationsURL
+	if attestationsURL == "" {
+		registry := c.Registry
+		if registry == "" {
+			registry = utils.DefaultNpmRegistry
+		}
+		var name, version string
+		if c.PackageName != nil {
+			name = *c.PackageName
+		}
+		if c.PackageVersion != nil {
+			version = *c.PackageVersion
+		}
+		var err error
+		attestationsURL, err = utils.NpmAttestationsURL(registry, name, version)
+		if err != nil {
+			return nil, err
+		}
+	}
+	fmt.Fprintf(os.Stderr, "Fetching attestations from %s\n", attestationsURL)
+	return utils.FetchNpmAttestations(ctx, attestationsURL)
+}
+
+// applyPolicy compiles the policy rule that matches the package name.
+func (c *VerifyNpmPackageCommand) applyPolicy(rep *report.Report, tarballHash string,
+) (*options.ProvenanceOpts, []*options.BuilderOpts, error) {
+	var packageName string
+	if c.PackageName != nil {
+		packageName = *c.PackageName
+	}
+	provenanceOpts, builderOpts, err := applyPolicy(c.PolicyPath, rep, c.SourceURI, packageName, tarballHash)
+	if err != nil {
+		return nil, nil, err
+	}
+	if provenanceOpts.ExpectedPackageName != nil && *provenanceOpts.ExpectedPackageName != packageName {
+		return nil, nil, fmt.Errorf("%w: policy expects package %q, got %q", serrors.ErrorMismatchPackageName,
+			*provenanceOpts.ExpectedPackageName, packageName)
+	}
+	provenanceOpts.ExpectedPackageName = c.PackageName
+	provenanceOpts.ExpectedPackageVersion = c.PackageVersion
+	return provenanceOpts, builderOpts, nil
+}
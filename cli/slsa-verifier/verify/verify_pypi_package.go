@@ -0,0 +1,118 @@
+// Copyright 2026 SLSA Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verify
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/slsa-framework/slsa-verifier/v2/options"
+	"github.com/slsa-framework/slsa-verifier/v2/report"
+	"github.com/slsa-framework/slsa-verifier/v2/verifiers"
+	"github.com/slsa-framework/slsa-verifier/v2/verifiers/utils"
+)
+
+type VerifyPyPIPackageCommand struct {
+	AttestationsPath    string
+	BuilderID           *string
+	SourceURI           string
+	SourceBranch        *string
+	SourceTag           *string
+	SourceVersionTag    *string
+	PrintProvenance     bool
+	Output              OutputFormat
+	PolicyPath          string
+	SigstoreOpts        *options.SigstoreOpts
+	GitHubOpts          *options.GitHubOpts
+	TrustedBuildersPath string
+}
+
+func (c *VerifyPyPIPackageCommand) Exec(ctx context.Context, distributions []string) (*utils.TrustedBuilderID, error) {
+	var builderID *utils.TrustedBuilderID
+	var reports []*report.Report
+	defer func() { writeReports(c.Output, reports) }()
+
+	attestations, err := os.ReadFile(c.AttestationsPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Verifying PyPI package: FAILED: %v\n\n", err)
+		return nil, err
+	}
+	for _, distribution := range distributions {
+		rep := report.New(distribution, "")
+		reports = append(reports, rep)
+		distributionHash, err := computeFileHash(distribution, sha256.New())
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Verifying PyPI package %s: FAILED: %v\n\n", distribution, err)
+			rep.Finish("", err)
+			return nil, err
+		}
+		rep.Digest = "sha256:" + distributionHash
+
+		// The attestations are for the distribution file as uploaded
+		// to PyPI, so the subject is its base name.
+		filename := filepath.Base(distribution)
+		provenanceOpts := &options.ProvenanceOpts{
+			ExpectedSourceURI:    c.SourceURI,
+			ExpectedBranch:       c.SourceBranch,
+			ExpectedDigest:       distributionHash,
+			ExpectedVersionedTag: c.SourceVersionTag,
+			ExpectedTag:          c.SourceTag,
+		}
+		builderOpts := []*options.BuilderOpts{{
+			ExpectedID: c.BuilderID,
+		}}
+
+		if c.PolicyPath != "" {
+			provenanceOpts, builderOpts, err = applyPolicy(c.PolicyPath, rep, c.SourceURI, filename, distributionHash)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Verifying PyPI package %s: FAILED: %v\n\n", distribution, err)
+				rep.Finish("", err)
+				return nil, err
+			}
+		}
+		provenanceOpts.SigstoreOpts = c.SigstoreOpts
+		provenanceOpts.GitHubOpts = c.GitHubOpts
+		provenanceOpts.TrustedBuilders, err = loadTrustedBuilders(c.TrustedBuildersPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Verifying PyPI package %s: FAILED: %v\n\n", distribution, err)
+			rep.Finish("", err)
+			return nil, err
+		}
+
+		verifiedProvenance, outBuilderID, err := verifyWithBuilders(report.NewContext(ctx, rep), provenanceOpts, builderOpts,
+			func(ctx context.Context, provenanceOpts *options.ProvenanceOpts, builderOpts *options.BuilderOpts,
+			) ([]byte, *utils.TrustedBuilderID, error) {
+				return verifiers.VerifyPyPIPackage(ctx, attestations, filename, provenanceOpts, builderOpts)
+			})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Verifying PyPI package %s: FAILED: %v\n\n", distribution, err)
+			rep.Finish("", err)
+			return nil, err
+		}
+
+		if c.PrintProvenance {
+			printProvenance(c.Output, rep, verifiedProvenance)
+		}
+
+		builderID = outBuilderID
+		rep.Finish(outBuilderID.String(), nil)
+		fmt.Fprintf(os.Stderr, "Verifying PyPI package %s: PASSED\n\n", distribution)
+	}
+
+	return builderID, nil
+}
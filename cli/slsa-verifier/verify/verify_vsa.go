@@ -16,18 +16,15 @@ package verify
 
 import (
 	"context"
-	"crypto"
-	"crypto/ecdsa"
-	"crypto/ed25519"
-	"crypto/elliptic"
-	"crypto/rsa"
 	"fmt"
 	"os"
 
 	"github.com/sigstore/sigstore/pkg/cryptoutils"
 	serrors "github.com/slsa-framework/slsa-verifier/v2/errors"
 	"github.com/slsa-framework/slsa-verifier/v2/options"
+	"github.com/slsa-framework/slsa-verifier/v2/report"
 	"github.com/slsa-framework/slsa-verifier/v2/verifiers"
+	"github.com/slsa-framework/slsa-verifier/v2/verifiers/utils"
 )
 
 // VerifyVSACommand contains the parameters for the verify-vsa command.
@@ -40,78 +37,66 @@ type VerifyVSACommand struct {
 	PrintAttestation bool
 	PublicKeyPath    *string
 	PublicKeyID      *string
+	Output           OutputFormat
 }
 
 // Exec executes the verifiers.VerifyVSA.
 func (c *VerifyVSACommand) Exec(ctx context.Context) error {
+	rep := report.New(*c.AttestationPath, "")
+	if len(*c.SubjectDigests) == 1 {
+		rep.Digest = (*c.SubjectDigests)[0]
+	}
+	defer func() { writeReports(c.Output, []*report.Report{rep}) }()
+
 	vsaOpts := &options.VSAOpts{
 		ExpectedDigests:        c.SubjectDigests,
 		ExpectedVerifierID:     c.VerifierID,
 		ExpectedResourceURI:    c.ResourceURI,
 		ExpectedVerifiedLevels: c.VerifiedLevels,
 	}
-	pubKeyBytes, err := os.ReadFile(*c.PublicKeyPath)
+	VerificationOpts, err := loadVerificationOpts(*c.PublicKeyPath, c.PublicKeyID)
 	if err != nil {
-		printFailed(err)
+		printFailed(rep, err)
 		return err
 	}
-	pubKey, err := cryptoutils.UnmarshalPEMToPublicKey(pubKeyBytes)
-	if err != nil {
-		err = fmt.Errorf("%w: %w", serrors.ErrorInvalidPublicKey, err)
-		printFailed(err)
-		return err
-	}
-	hashAlgo := determineSignatureHashAlgo(pubKey)
-	VerificationOpts := &options.VerificationOpts{
-		PublicKey:         pubKey,
-		PublicKeyID:       c.PublicKeyID,
-		PublicKeyHashAlgo: hashAlgo,
-	}
 	attestation, err := os.ReadFile(*c.AttestationPath)
 	if err != nil {
-		printFailed(err)
+		printFailed(rep, err)
 		return err
 	}
-	vsaBytes, err := verifiers.VerifyVSA(ctx, attestation, vsaOpts, VerificationOpts)
+	vsaBytes, err := verifiers.VerifyVSA(report.NewContext(ctx, rep), attestation, vsaOpts, VerificationOpts)
 	if err != nil {
-		printFailed(err)
+		printFailed(rep, err)
 		return err
 	}
 	if c.PrintAttestation {
-		fmt.Fprintf(os.Stdout, "%s\n", string(vsaBytes))
+		printProvenance(c.Output, rep, vsaBytes)
 	}
+	rep.Finish("", nil)
 	fmt.Fprintf(os.Stderr, "Verifying VSA: PASSED\n\n")
 	// verfiers.VerifyVSA already checks if the producerID matches
 	return nil
 }
 
-// printFailed prints the error message to stderr.
-func printFailed(err error) {
+// printFailed prints the error message to stderr and records it in the report.
+func printFailed(rep *report.Report, err error) {
+	rep.Finish("", err)
 	fmt.Fprintf(os.Stderr, "Verifying VSA: FAILED: %v\n\n", err)
 }
 
-// determineSignatureHashAlgo determines the hash algorithm used to compute the digest to be signed, based on the public key.
-// some well-known defaults can be determined, otherwise the it returns crypto.SHA256.
-func determineSignatureHashAlgo(pubKey crypto.PublicKey) crypto.Hash {
-	var h crypto.Hash
-	switch pk := pubKey.(type) {
-	case *rsa.PublicKey:
-		h = crypto.SHA256
-	case *ecdsa.PublicKey:
-		switch pk.Curve {
-		case elliptic.P256():
-			h = crypto.SHA256
-		case elliptic.P384():
-			h = crypto.SHA384
-		case elliptic.P521():
-			h = crypto.SHA512
-		default:
-			h = crypto.SHA256
-		}
-	case ed25519.PublicKey:
-		h = crypto.SHA512
-	default:
-		h = crypto.SHA256
+// loadVerificationOpts loads the PEM-encoded public key at the path.
+func loadVerificationOpts(path string, keyID *string) (*options.VerificationOpts, error) {
+	pubKeyBytes, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	pubKey, err := cryptoutils.UnmarshalPEMToPublicKey(pubKeyBytes)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", serrors.ErrorInvalidPublicKey, err)
 	}
-	return h
+	return &options.VerificationOpts{
+		PublicKey:         pubKey,
+		PublicKeyID:       keyID,
+		PublicKeyHashAlgo: utils.SignatureHashAlgo(pubKey),
+	}, nil
 }
@@ -0,0 +1,128 @@
+// Copyright 2026 SLSA Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verify
+
+import (
+	"context"
+	"crypto"
+	"crypto/sha256"
+	"fmt"
+	"os"
+
+	"github.com/secure-systems-lab/go-securesystemslib/dsse"
+	"github.com/sigstore/sigstore/pkg/cryptoutils"
+	"sigs.k8s.io/release-utils/version"
+
+	serrors "github.com/slsa-framework/slsa-verifier/v2/errors"
+	"github.com/slsa-framework/slsa-verifier/v2/options"
+	"github.com/slsa-framework/slsa-verifier/v2/report"
+	"github.com/slsa-framework/slsa-verifier/v2/verifiers"
+	"github.com/slsa-framework/slsa-verifier/v2/verifiers/utils"
+)
+
+// VSAOutput is the VSA to write after a successful verification.
+type VSAOutput struct {
+	Path           string
+	SigningKeyPath string
+	SigningKeyID   string
+	VerifierID     string
+	ResourceURI    string
+}
+
+// write writes a VSA for the subjects, verified against the policy at
+// policyPath with the provenance at provenancePath, if any. The levels
+// reached are derived from the reports of the subjects.
+func (o *VSAOutput) write(ctx context.Context, subjects []options.VSADescriptor, reports []*report.Report,
+	policyPath, provenancePath string,
+) error {
+	signingOpts, err := loadSigningOpts(o.SigningKeyPath, o.SigningKeyID)
+	if err != nil {
+		return err
+	}
+	policyDigest, err := computeFileHash(policyPath, sha256.New())
+	if err != nil {
+		return err
+	}
+	vsaOpts := &options.VSAGenerationOpts{
+		Subjects:        subjects,
+		VerifierID:      o.VerifierID,
+		VerifierVersion: version.GetVersionInfo().GitVersion,
+		ResourceURI:     o.ResourceURI,
+		Policy: options.VSADescriptor{
+			URI:    policyPath,
+			Digest: map[string]string{"sha256": policyDigest},
+		},
+		VerifiedLevels: []string{buildLevel(reports)},
+	}
+	if provenancePath != "" {
+		provenanceDigest, err := computeFileHash(provenancePath, sha256.New())
+		if err != nil {
+			return err
+		}
+		vsaOpts.InputAttestations = []options.VSADescriptor{{
+			URI:    provenancePath,
+			Digest: map[string]string{"sha256": provenanceDigest},
+		}}
+	}
+
+	vsa, err := verifiers.GenerateVSA(ctx, vsaOpts, signingOpts)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(o.Path, append(vsa, '\n'), 0o600); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "Wrote VSA to %s\n", o.Path)
+	return nil
+}
+
+// buildLevel returns the SLSA build level reached by all the verified
+// artifacts of the reports.
+func buildLevel(reports []*report.Report) string {
+	for _, rep := range reports {
+		if verifiers.BuildLevel(rep) != verifiers.BuildLevel3 {
+			return verifiers.BuildLevel2
+		}
+	}
+	return verifiers.BuildLevel3
+}
+
+// loadSigningOpts loads the PEM-encoded private key at keyPath. The key ID
+// defaults to the SHA256 fingerprint of the public key, as for verify-vsa.
+func loadSigningOpts(keyPath, keyID string) (*options.SigningOpts, error) {
+	keyBytes, err := os.ReadFile(keyPath)
+	if err != nil {
+		return nil, err
+	}
+	privateKey, err := cryptoutils.UnmarshalPEMToPrivateKey(keyBytes, cryptoutils.SkipPassword)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", serrors.ErrorInvalidPrivateKey, err)
+	}
+	signer, ok := privateKey.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("%w: unsupported key type %T", serrors.ErrorInvalidPrivateKey, privateKey)
+	}
+	if keyID == "" {
+		keyID, err = dsse.SHA256KeyID(signer.Public())
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", serrors.ErrorInvalidPrivateKey, err)
+		}
+	}
+	return &options.SigningOpts{
+		PrivateKey:         privateKey,
+		PublicKeyID:        keyID,
+		PrivateKeyHashAlgo: utils.SignatureHashAlgo(signer.Public()),
+	}, nil
+}
@@ -0,0 +1,123 @@
+// Copyright 2026 SLSA Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verification
+
+// CodeUnknown is returned by Code for errors that do not wrap
+// any of the errors defined in this package.
+const CodeUnknown = "UNKNOWN"
+
+// codes maps each sentinel error to a stable, machine-readable code.
+// Codes are part of the public output of the verifier and MUST NOT
+// be changed once released.
+// NOTE: this is not a map because errors wrapping this package's errors
+// are not guaranteed to be comparable.
+var codes = []struct {
+	err  error
+	code string
+}{
+	{ErrorInvalidDssePayload, "INVALID_DSSE_PAYLOAD"},
+	{ErrorMismatchBranch, "MISMATCH_BRANCH"},
+	{ErrorMismatchPackageVersion, "MISMATCH_PACKAGE_VERSION"},
+	{ErrorMismatchPackageName, "MISMATCH_PACKAGE_NAME"},
+	{ErrorMismatchBuilderID, "MISMATCH_BUILDER_ID"},
+	{ErrorInvalidBuilderID, "INVALID_BUILDER_ID"},
+	{ErrorInvalidBuildType, "INVALID_BUILD_TYPE"},
+	{ErrorMismatchSource, "MISMATCH_SOURCE"},
+	{ErrorMismatchWorkflowInputs, "MISMATCH_WORKFLOW_INPUTS"},
+	{ErrorMalformedURI, "MALFORMED_URI"},
+	{ErrorMismatchCertificate, "MISMATCH_CERTIFICATE"},
+	{ErrorInvalidCertificate, "INVALID_CERTIFICATE"},
+	{ErrorMismatchTag, "MISMATCH_TAG"},
+	{ErrorInvalidRecipe, "INVALID_RECIPE"},
+	{ErrorMismatchVersionedTag, "MISMATCH_VERSIONED_TAG"},
+	{ErrorInvalidSemver, "INVALID_SEMVER"},
+	{ErrorRekorSearch, "REKOR_SEARCH"},
+	{ErrorMismatchHash, "MISMATCH_HASH"},
+	{ErrorNonVerifiableClaim, "NON_VERIFIABLE_CLAIM"},
+	{ErrorMismatchIntoto, "MISMATCH_INTOTO"},
+	{ErrorInvalidRef, "INVALID_REF"},
+	{ErrorUntrustedReusableWorkflow, "UNTRUSTED_REUSABLE_WORKFLOW"},
+	{ErrorNoValidRekorEntries, "NO_VALID_REKOR_ENTRIES"},
+	{ErrorVerifierNotSupported, "VERIFIER_NOT_SUPPORTED"},
+	{ErrorInvalidOIDCIssuer, "INVALID_OIDC_ISSUER"},
+	{ErrorNotSupported, "NOT_SUPPORTED"},
+	{ErrorInvalidFormat, "INVALID_FORMAT"},
+	{ErrorInvalidPEM, "INVALID_PEM"},
+	{ErrorInvalidSignature, "INVALID_SIGNATURE"},
+	{ErrorNoValidSignature, "NO_VALID_SIGNATURE"},
+	{ErrorMutableImage, "MUTABLE_IMAGE"},
+	{ErrorImageHash, "IMAGE_HASH"},
+	{ErrorInvalidEncoding, "INVALID_ENCODING"},
+	{ErrorInternal, "INTERNAL"},
+	{ErrorInvalidRekorEntry, "INVALID_REKOR_ENTRY"},
+	{ErrorRekorPubKey, "REKOR_PUBLIC_KEY"},
+	{ErrorInvalidPackageName, "INVALID_PACKAGE_NAME"},
+	{ErrorInvalidSubject, "INVALID_SUBJECT"},
+	{ErrorInvalidHash, "INVALID_HASH"},
+	{ErrorNotPresent, "NOT_PRESENT"},
+	{ErrorInvalidPublicKey, "INVALID_PUBLIC_KEY"},
+	{ErrorInvalidPrivateKey, "INVALID_PRIVATE_KEY"},
+	{ErrorInvalidChecksums, "INVALID_CHECKSUMS"},
+	{ErrorInvalidVerificationResult, "INVALID_VERIFICATION_RESULT"},
+	{ErrorMismatchVerifiedLevels, "MISMATCH_VERIFIED_LEVELS"},
+	{ErrorMissingSubjectDigest, "MISSING_SUBJECT_DIGEST"},
+	{ErrorEmptyRequiredField, "EMPTY_REQUIRED_FIELD"},
+	{ErrorMismatchResourceURI, "MISMATCH_RESOURCE_URI"},
+	{ErrorMismatchVerifierID, "MISMATCH_VERIFIER_ID"},
+	{ErrorInvalidSLSALevel, "INVALID_SLSA_LEVEL"},
+	{ErrorInvalidPolicy, "INVALID_POLICY"},
+	{ErrorNoMatchingPolicyRule, "NO_MATCHING_POLICY_RULE"},
+	{ErrorRequiresNetwork, "REQUIRES_NETWORK"},
+	{ErrorMismatchTaskRef, "MISMATCH_TASK_REF"},
+	{ErrorUnverifiedPlatformImage, "UNVERIFIED_PLATFORM_IMAGE"},
+	{ErrorMismatchPlatformImages, "MISMATCH_PLATFORM_IMAGES"},
+	{ErrorFetchAttestations, "FETCH_ATTESTATIONS"},
+	{ErrorUnavailable, "UNAVAILABLE"},
+}
+
+// Code returns the stable code of the outermost error of this package
+// wrapped by err, or CodeUnknown if there is none.
+func Code(err error) string {
+	if err == nil {
+		return ""
+	}
+	if code, ok := findCode(err); ok {
+		return code
+	}
+	return CodeUnknown
+}
+
+// findCode walks the error tree depth-first, in the same order as errors.Is.
+func findCode(err error) (string, bool) {
+	for _, c := range codes {
+		// Exact match: errors.Is would also match the wrapped errors.
+		if err == c.err { //nolint:errorlint
+			return c.code, true
+		}
+	}
+	switch e := err.(type) {
+	case interface{ Unwrap() error }:
+		if next := e.Unwrap(); next != nil {
+			return findCode(next)
+		}
+	case interface{ Unwrap() []error }:
+		for _, next := range e.Unwrap() {
+			if code, ok := findCode(next); ok {
+				return code, true
+			}
+		}
+	}
+	return "", false
+}
@@ -44,6 +44,8 @@ var (
 	ErrorInvalidHash               = errors.New("invalid hash")
 	ErrorNotPresent                = errors.New("not present")
 	ErrorInvalidPublicKey          = errors.New("invalid public key")
+	ErrorInvalidPrivateKey         = errors.New("invalid private key")
+	ErrorInvalidChecksums          = errors.New("invalid checksums manifest")
 	ErrorInvalidVerificationResult = errors.New("verificationResult is not PASSED")
 	ErrorMismatchVerifiedLevels    = errors.New("verified levels do not match")
 	ErrorMissingSubjectDigest      = errors.New("missing subject digest")
@@ -51,4 +53,12 @@ var (
 	ErrorMismatchResourceURI       = errors.New("resource URI does not match")
 	ErrorMismatchVerifierID        = errors.New("verifier ID does not match")
 	ErrorInvalidSLSALevel          = errors.New("invalid SLSA level")
+	ErrorInvalidPolicy             = errors.New("invalid policy")
+	ErrorNoMatchingPolicyRule      = errors.New("no matching policy rule")
+	ErrorRequiresNetwork           = errors.New("verification requires network access")
+	ErrorMismatchTaskRef           = errors.New("task ref does not match provenance")
+	ErrorUnverifiedPlatformImage   = errors.New("platform image has no verified provenance")
+	ErrorMismatchPlatformImages    = errors.New("platform images do not share the same builder and source")
+	ErrorFetchAttestations         = errors.New("cannot fetch attestations")
+	ErrorUnavailable               = errors.New("service unavailable")
 )
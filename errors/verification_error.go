@@ -0,0 +1,108 @@
+package verification
+
+import "errors"
+
+// VerificationError is a verification failure with structured information
+// about its cause. It unwraps to the error it describes, so errors.Is
+// matches the errors of this package as for any other wrapped error.
+type VerificationError struct {
+	// Err is the error, wrapping one of the errors of this package.
+	Err error
+
+	// Check is the name of the check that failed, e.g. "source-uri".
+	Check string
+
+	// Expected and Actual are the expected value and the value found,
+	// when the failure is a mismatch.
+	Expected string
+	Actual   string
+
+	// Remediation is a hint at how to fix the failure.
+	Remediation string
+}
+
+// NewVerificationError returns a VerificationError for err with the expected
+// and actual values, and the remediation hint of the code of err.
+func NewVerificationError(err error, expected, actual string) *VerificationError {
+	return &VerificationError{
+		Err:         err,
+		Expected:    expected,
+		Actual:      actual,
+		Remediation: Remediation(err),
+	}
+}
+
+// Error implements error. It returns the message of Err, which already
+// describes the expected and actual values.
+func (e *VerificationError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns Err.
+func (e *VerificationError) Unwrap() error {
+	return e.Err
+}
+
+// Code returns the stable code of Err. See Code.
+func (e *VerificationError) Code() string {
+	return Code(e.Err)
+}
+
+// WithCheck records that err is the failure of the named check. If err
+// wraps a VerificationError with a check, err is returned. Otherwise, err is
+// wrapped in a new VerificationError for the check, with the expected and
+// actual values of the VerificationError it wraps, if any. err is never
+// modified, as it may be shared.
+func WithCheck(err error, check string) error {
+	if err == nil {
+		return nil
+	}
+	res := &VerificationError{
+		Err:         err,
+		Check:       check,
+		Remediation: Remediation(err),
+	}
+	var verr *VerificationError
+	if errors.As(err, &verr) {
+		if verr.Check != "" {
+			return err
+		}
+		res.Expected = verr.Expected
+		res.Actual = verr.Actual
+		res.Remediation = verr.Remediation
+	}
+	return res
+}
+
+// remediations maps the codes of the errors of this package to a hint
+// at how to fix them.
+var remediations = map[string]string{
+	"MISMATCH_SOURCE":             "verify that the expected source repository is the one the artifact was built from",
+	"MISMATCH_BUILDER_ID":         "verify that the expected builder ID is the builder that generated the provenance",
+	"UNTRUSTED_REUSABLE_WORKFLOW": "use a trusted builder, or pass the builder ID of the workflow that signed the provenance",
+	"INVALID_BUILDER_ID":          "pass a valid builder ID",
+	"MISMATCH_HASH":               "verify that the provenance was generated for this artifact",
+	"MISMATCH_BRANCH":             "verify that the artifact was built from the expected branch",
+	"MISMATCH_TAG":                "verify that the artifact was built from the expected tag",
+	"MISMATCH_VERSIONED_TAG":      "verify that the artifact was built from a tag of the expected version",
+	"MISMATCH_WORKFLOW_INPUTS":    "verify the expected workflow inputs against those of the build",
+	"MISMATCH_PACKAGE_NAME":       "verify that the attestations were generated for this package",
+	"MISMATCH_PACKAGE_VERSION":    "verify that the attestations were generated for this package version",
+	"INVALID_REF":                 "reference the builder at a release tag",
+	"INVALID_OIDC_ISSUER":         "verify the GitHub instance that ran the build",
+	"NO_VALID_REKOR_ENTRIES":      "verify that the provenance was published to the transparency log",
+	"REQUIRES_NETWORK":            "verify with network access, or provide a Sigstore bundle and a trusted root",
+	"INVALID_POLICY":              "fix the policy file",
+	"NO_MATCHING_POLICY_RULE":     "add a policy rule for the artifact",
+	"MISMATCH_TASK_REF":           "verify that the run references the expected pipeline and tasks",
+	"UNVERIFIED_PLATFORM_IMAGE":   "list every platform image as a subject of the provenance of the index, or attach provenance to each platform image",
+	"MISMATCH_PLATFORM_IMAGES":    "build all the platform images of the index with the same builder from the same commit",
+	"FETCH_ATTESTATIONS":          "verify that the package version was published with attestations, or download them and pass their path",
+	"UNAVAILABLE":                 "retry the verification later",
+}
+
+// Remediation returns a hint at how to fix the outermost error of this
+// package wrapped by err, or an empty string if there is none.
+func Remediation(err error) string {
+	return remediations[Code(err)]
+}
@@ -0,0 +1,105 @@
+package verification
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func Test_VerificationError(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		err         error
+		check       string
+		sentinel    error
+		wantCheck   string
+		expected    string
+		actual      string
+		code        string
+		remediation string
+	}{
+		{
+			name:        "mismatch",
+			err:         NewVerificationError(fmt.Errorf("%w: expected 'a', got 'b'", ErrorMismatchSource), "a", "b"),
+			check:       "source-uri",
+			sentinel:    ErrorMismatchSource,
+			wantCheck:   "source-uri",
+			expected:    "a",
+			actual:      "b",
+			code:        "MISMATCH_SOURCE",
+			remediation: remediations["MISMATCH_SOURCE"],
+		},
+		{
+			name:        "wrapped mismatch",
+			err:         fmt.Errorf("verifying: %w", NewVerificationError(fmt.Errorf("%w: 'main'", ErrorMismatchBranch), "main", "dev")),
+			check:       "branch",
+			sentinel:    ErrorMismatchBranch,
+			wantCheck:   "branch",
+			expected:    "main",
+			actual:      "dev",
+			code:        "MISMATCH_BRANCH",
+			remediation: remediations["MISMATCH_BRANCH"],
+		},
+		{
+			name:      "plain error",
+			err:       fmt.Errorf("%w: 'sha1'", ErrorInvalidHash),
+			check:     "digest",
+			sentinel:  ErrorInvalidHash,
+			wantCheck: "digest",
+			code:      "INVALID_HASH",
+		},
+		{
+			name:        "check already set",
+			err:         WithCheck(NewVerificationError(ErrorMismatchTag, "v1", "v2"), "tag"),
+			check:       "versioned-tag",
+			sentinel:    ErrorMismatchTag,
+			wantCheck:   "tag",
+			expected:    "v1",
+			actual:      "v2",
+			code:        "MISMATCH_TAG",
+			remediation: remediations["MISMATCH_TAG"],
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := WithCheck(tt.err, tt.check)
+			if !errors.Is(err, tt.sentinel) {
+				t.Errorf("error %v does not wrap %v", err, tt.sentinel)
+			}
+			if err.Error() != tt.err.Error() {
+				t.Errorf("unexpected message: got %q, want %q", err.Error(), tt.err.Error())
+			}
+			var verr *VerificationError
+			if !errors.As(err, &verr) {
+				t.Fatalf("error %v is not a VerificationError", err)
+			}
+			if verr.Check != tt.wantCheck {
+				t.Errorf("unexpected check: got %q, want %q", verr.Check, tt.wantCheck)
+			}
+			if verr.Expected != tt.expected || verr.Actual != tt.actual {
+				t.Errorf("unexpected values: got %q and %q, want %q and %q",
+					verr.Expected, verr.Actual, tt.expected, tt.actual)
+			}
+			if verr.Code() != tt.code {
+				t.Errorf("unexpected code: got %q, want %q", verr.Code(), tt.code)
+			}
+			if verr.Remediation != tt.remediation {
+				t.Errorf("unexpected remediation: got %q, want %q", verr.Remediation, tt.remediation)
+			}
+		})
+	}
+
+	if err := WithCheck(nil, "source-uri"); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	// The error is not modified.
+	verr := NewVerificationError(ErrorMismatchTag, "v1", "v2")
+	if err := WithCheck(verr, "tag"); verr.Check != "" {
+		t.Errorf("error %v was modified by WithCheck returning %v", verr, err)
+	}
+}
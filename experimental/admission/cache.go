@@ -0,0 +1,85 @@
+package admission
+
+import (
+	"sync"
+	"time"
+)
+
+// maxCacheEntries bounds the memory used by the cache.
+const maxCacheEntries = 10000
+
+// cache holds the verification results of images, keyed by namespace and
+// digest, until they expire.
+type cache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[string]cacheEntry
+	// order lists the keys of the entries from the oldest. The TTL is the
+	// same for all entries, so they expire in that order. Keys put again
+	// are listed again, and their first element is stale.
+	order []cacheKey
+	now   func() time.Time
+}
+
+type cacheEntry struct {
+	err     error
+	expires time.Time
+}
+
+type cacheKey struct {
+	key     string
+	expires time.Time
+}
+
+func newCache(ttl time.Duration) *cache {
+	return &cache{
+		ttl:     ttl,
+		entries: make(map[string]cacheEntry),
+		now:     time.Now,
+	}
+}
+
+// get returns the cached result of the verification, and true if there is one.
+func (c *cache) get(key string) (cacheEntry, bool) {
+	if c.ttl <= 0 {
+		return cacheEntry{}, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[key]
+	if !ok {
+		return cacheEntry{}, false
+	}
+	if !c.now().Before(e.expires) {
+		delete(c.entries, key)
+		return cacheEntry{}, false
+	}
+	return e, true
+}
+
+// put caches the result of the verification. The expired entries are
+// evicted, and then the oldest ones if the cache is full.
+func (c *cache) put(key string, err error) {
+	if c.ttl <= 0 {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.now()
+	for len(c.order) > 0 && (!now.Before(c.order[0].expires) || len(c.entries) >= maxCacheEntries) {
+		c.evictOldest()
+	}
+	e := cacheEntry{err: err, expires: now.Add(c.ttl)}
+	c.entries[key] = e
+	c.order = append(c.order, cacheKey{key: key, expires: e.expires})
+}
+
+// evictOldest removes the first key of the order, and its entry unless the
+// key was put again since.
+func (c *cache) evictOldest() {
+	k := c.order[0]
+	c.order = c.order[1:]
+	if e, ok := c.entries[k.key]; ok && e.expires.Equal(k.expires) {
+		delete(c.entries, k.key)
+	}
+}
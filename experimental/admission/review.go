@@ -0,0 +1,132 @@
+package admission
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// AdmissionReview is the admission.k8s.io/v1 AdmissionReview sent by the
+// API server to validating webhooks. Only the fields used by the webhook
+// are declared.
+type AdmissionReview struct {
+	APIVersion string             `json:"apiVersion"`
+	Kind       string             `json:"kind"`
+	Request    *AdmissionRequest  `json:"request,omitempty"`
+	Response   *AdmissionResponse `json:"response,omitempty"`
+}
+
+// GroupVersionKind identifies the type of the admitted object.
+type GroupVersionKind struct {
+	Group   string `json:"group"`
+	Version string `json:"version"`
+	Kind    string `json:"kind"`
+}
+
+// AdmissionRequest describes the object to admit.
+type AdmissionRequest struct {
+	UID       string           `json:"uid"`
+	Kind      GroupVersionKind `json:"kind"`
+	Name      string           `json:"name,omitempty"`
+	Namespace string           `json:"namespace,omitempty"`
+	Operation string           `json:"operation"`
+	Object    json.RawMessage  `json:"object,omitempty"`
+}
+
+// AdmissionResponse is the decision of the webhook.
+type AdmissionResponse struct {
+	UID     string  `json:"uid"`
+	Allowed bool    `json:"allowed"`
+	Result  *Status `json:"status,omitempty"`
+	// Warnings are shown to the client, e.g. by kubectl.
+	Warnings []string `json:"warnings,omitempty"`
+	// AuditAnnotations are recorded in the audit log of the API server.
+	AuditAnnotations map[string]string `json:"auditAnnotations,omitempty"`
+}
+
+// Status explains a denial.
+type Status struct {
+	Code    int32  `json:"code,omitempty"`
+	Reason  string `json:"reason,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+type container struct {
+	Name  string `json:"name"`
+	Image string `json:"image"`
+}
+
+type podSpec struct {
+	InitContainers      []container `json:"initContainers"`
+	Containers          []container `json:"containers"`
+	EphemeralContainers []container `json:"ephemeralContainers"`
+}
+
+type podTemplate struct {
+	Spec podSpec `json:"spec"`
+}
+
+// Pods are admitted by their spec, and workload controllers by the spec
+// of the template of their pods.
+type pod struct {
+	Spec podSpec `json:"spec"`
+}
+
+type workload struct {
+	Spec struct {
+		Template podTemplate `json:"template"`
+	} `json:"spec"`
+}
+
+type cronJob struct {
+	Spec struct {
+		JobTemplate struct {
+			Spec struct {
+				Template podTemplate `json:"template"`
+			} `json:"spec"`
+		} `json:"jobTemplate"`
+	} `json:"spec"`
+}
+
+// image is a container image of an admitted object.
+type image struct {
+	// Container is the name of the container, e.g. initContainers[0].
+	Container string
+	Image     string
+}
+
+// images returns the container images of a Pod or of the pods of a
+// workload controller. It returns false for other kinds of objects.
+func images(kind string, object []byte) ([]image, bool, error) {
+	var spec podSpec
+	var err error
+	switch kind {
+	case "Pod":
+		var p pod
+		err = json.Unmarshal(object, &p)
+		spec = p.Spec
+	case "Deployment", "ReplicaSet", "StatefulSet", "DaemonSet", "Job", "ReplicationController":
+		var w workload
+		err = json.Unmarshal(object, &w)
+		spec = w.Spec.Template.Spec
+	case "CronJob":
+		var c cronJob
+		err = json.Unmarshal(object, &c)
+		spec = c.Spec.JobTemplate.Spec.Template.Spec
+	default:
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, true, fmt.Errorf("%w: %s: %w", errInvalid, kind, err)
+	}
+
+	var imgs []image
+	add := func(field string, containers []container) {
+		for _, c := range containers {
+			imgs = append(imgs, image{Container: fmt.Sprintf("%s[%s]", field, c.Name), Image: c.Image})
+		}
+	}
+	add("initContainers", spec.InitContainers)
+	add("containers", spec.Containers)
+	add("ephemeralContainers", spec.EphemeralContainers)
+	return imgs, true, nil
+}
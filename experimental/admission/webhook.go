@@ -0,0 +1,418 @@
+// Package admission implements a Kubernetes validating admission webhook
+// that admits Pods and workload controllers only if the SLSA provenance of
+// their container images is verified.
+//
+// The images of each namespace are verified against a policy of the policy
+// package, whose rules match images by repository, e.g. ghcr.io/org/image.
+// Images referenced by tag are resolved to the digest of the tag, which is
+// verified and reported: a tag may be moved once the object is admitted, so
+// the nodes may pull another image. Namespaces may require images to be
+// referenced by digest instead. The results are cached per digest.
+package admission
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/crane"
+	crname "github.com/google/go-containerregistry/pkg/name"
+	"sigs.k8s.io/yaml"
+
+	serrors "github.com/slsa-framework/slsa-verifier/v2/errors"
+	"github.com/slsa-framework/slsa-verifier/v2/options"
+	"github.com/slsa-framework/slsa-verifier/v2/policy"
+	"github.com/slsa-framework/slsa-verifier/v2/verifiers"
+	"github.com/slsa-framework/slsa-verifier/v2/verifiers/utils"
+)
+
+var errInvalid = errors.New("invalid")
+
+// maxReviewSize is the size of the largest AdmissionReview read. The API
+// server limits objects to 3 MiB.
+const maxReviewSize = 4 << 20
+
+// DefaultCacheTTL is how long verification results are cached.
+const DefaultCacheTTL = 10 * time.Minute
+
+// Config is the configuration of the webhook, read from a YAML or JSON file.
+//
+// Example:
+//
+//	failOpen: false
+//	auditOnly: false
+//	requireDigest: [prod]
+//	namespaces:
+//	  prod:
+//	    version: 1
+//	    rules:
+//	      - name: app
+//	        match:
+//	          sourceURI: github.com/org/app
+//	          artifact: ghcr.io/org/app
+//	        builderIDs:
+//	          - https://github.com/slsa-framework/slsa-github-generator/.github/workflows/generator_container_slsa3.yml
+//	        tags: ["v*"]
+//	default:
+//	  version: 1
+//	  rules: [...]
+type Config struct {
+	// Namespaces are the policies of the images of each namespace.
+	Namespaces map[string]*policy.Policy `json:"namespaces,omitempty"`
+
+	// Default is the policy of the namespaces not listed in Namespaces.
+	// If nil, the images of those namespaces are admitted unverified.
+	Default *policy.Policy `json:"default,omitempty"`
+
+	// FailOpen admits objects whose images could not be verified because
+	// of an error, e.g. an unreachable registry. Images that fail
+	// verification are denied regardless.
+	FailOpen bool `json:"failOpen,omitempty"`
+
+	// AuditOnly admits all objects. Images that fail verification are
+	// reported as warnings and audit annotations.
+	AuditOnly bool `json:"auditOnly,omitempty"`
+
+	// RequireDigest lists the namespaces whose images must be referenced
+	// by digest. Images referenced by tag are denied, with the digest the
+	// tag resolves to. The images of other namespaces that are referenced
+	// by tag are admitted if the digest of the tag is verified.
+	RequireDigest []string `json:"requireDigest,omitempty"`
+}
+
+// LoadConfig reads and validates the configuration at the given path.
+func LoadConfig(configPath string) (*Config, error) {
+	content, err := os.ReadFile(configPath)
+	if err != nil {
+		return nil, err
+	}
+	var c Config
+	if err := yaml.UnmarshalStrict(content, &c); err != nil {
+		return nil, fmt.Errorf("%w: %w", serrors.ErrorInvalidPolicy, err)
+	}
+	for ns, p := range c.Namespaces {
+		if p == nil {
+			return nil, fmt.Errorf("%w: namespace %q: no policy", serrors.ErrorInvalidPolicy, ns)
+		}
+		if err := p.Validate(); err != nil {
+			return nil, fmt.Errorf("namespace %q: %w", ns, err)
+		}
+	}
+	if c.Default != nil {
+		if err := c.Default.Validate(); err != nil {
+			return nil, fmt.Errorf("default: %w", err)
+		}
+	}
+	return &c, nil
+}
+
+// policy returns the policy of the images of the namespace, or nil if they
+// are not verified.
+func (c *Config) policy(namespace string) *policy.Policy {
+	if p, ok := c.Namespaces[namespace]; ok {
+		return p
+	}
+	return c.Default
+}
+
+// requiresDigest returns true if the images of the namespace must be
+// referenced by digest.
+func (c *Config) requiresDigest(namespace string) bool {
+	return slices.Contains(c.RequireDigest, namespace)
+}
+
+// Options are the options of the verification of the images.
+type Options struct {
+	// CacheTTL is how long verification results are cached. Zero disables
+	// the cache.
+	CacheTTL        time.Duration
+	SigstoreOpts    *options.SigstoreOpts
+	GitHubOpts      *options.GitHubOpts
+	TrustedBuilders []options.TrustedBuilder
+}
+
+type verifyFn func(ctx context.Context, image string,
+	provenanceOpts *options.ProvenanceOpts, builderOpts *options.BuilderOpts) error
+
+// Webhook is an http.Handler serving AdmissionReview requests.
+type Webhook struct {
+	config *Config
+	opts   Options
+	cache  *cache
+	// resolve returns the digest of an image, e.g. sha256:abc.
+	resolve func(ctx context.Context, image string) (string, error)
+	verify  verifyFn
+}
+
+var _ http.Handler = (*Webhook)(nil)
+
+// New returns a webhook enforcing the policies of config.
+func New(config *Config, opts *Options) *Webhook {
+	w := &Webhook{
+		config:  config,
+		resolve: resolveDigest,
+		verify:  verifyImage,
+	}
+	if opts != nil {
+		w.opts = *opts
+	}
+	w.cache = newCache(w.opts.CacheTTL)
+	return w
+}
+
+func resolveDigest(ctx context.Context, image string) (string, error) {
+	digest, err := crane.Digest(image, crane.WithContext(ctx), crane.WithAuthFromKeychain(authn.DefaultKeychain))
+	if err != nil {
+		return "", fmt.Errorf("%w: crane.Digest(): %w", serrors.ErrorImageHash, err)
+	}
+	return digest, nil
+}
+
+func verifyImage(ctx context.Context, image string,
+	provenanceOpts *options.ProvenanceOpts, builderOpts *options.BuilderOpts,
+) error {
+	_, _, err := verifiers.VerifyImage(ctx, image, nil, provenanceOpts, builderOpts)
+	return err
+}
+
+// ServeHTTP implements http.Handler.
+func (w *Webhook) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(rw, "expected a POST request", http.StatusMethodNotAllowed)
+		return
+	}
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxReviewSize+1))
+	if err != nil {
+		http.Error(rw, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if len(body) > maxReviewSize {
+		http.Error(rw, "request too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+	var review AdmissionReview
+	if err := json.Unmarshal(body, &review); err != nil {
+		http.Error(rw, fmt.Sprintf("invalid AdmissionReview: %v", err), http.StatusBadRequest)
+		return
+	}
+	if review.Request == nil {
+		http.Error(rw, "invalid AdmissionReview: no request", http.StatusBadRequest)
+		return
+	}
+
+	response := AdmissionReview{
+		APIVersion: review.APIVersion,
+		Kind:       "AdmissionReview",
+		Response:   w.Review(r.Context(), review.Request),
+	}
+	rw.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(rw).Encode(response); err != nil {
+		http.Error(rw, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// imageResult is the outcome of the verification of an image.
+type imageResult struct {
+	image
+	// Ref is the image pinned to its digest, if it was resolved.
+	Ref string
+	// Resolved is true if the image is referenced by tag, and Ref is the
+	// digest the tag resolved to.
+	Resolved bool
+	Err      error
+	// Unavailable is true if the image could not be verified because of an
+	// error, rather than failed verification.
+	Unavailable bool
+}
+
+func (r *imageResult) String() string {
+	ref := r.Image
+	if r.Ref != "" && r.Ref != r.Image {
+		ref = fmt.Sprintf("%s (%s)", r.Image, r.Ref)
+	}
+	return fmt.Sprintf("%s: image %s: %v", r.Container, ref, r.Err)
+}
+
+// Review returns the decision on the admission of the object of the request.
+func (w *Webhook) Review(ctx context.Context, req *AdmissionRequest) *AdmissionResponse {
+	resp := &AdmissionResponse{UID: req.UID, Allowed: true}
+
+	imgs, ok, err := images(req.Kind.Kind, req.Object)
+	if !ok {
+		// Not an object with pods.
+		return resp
+	}
+	if err != nil {
+		return deny(resp, http.StatusBadRequest, "BadRequest", err.Error())
+	}
+	pol := w.config.policy(req.Namespace)
+	if pol == nil {
+		return resp
+	}
+
+	var failed, unavailable []*imageResult
+	var resolved []string
+	for _, img := range imgs {
+		r := w.verifyImage(ctx, req.Namespace, pol, img)
+		switch {
+		case r.Err == nil:
+			if r.Resolved {
+				resolved = append(resolved, fmt.Sprintf("%s: image %s resolved to %s", r.Container, r.Image, r.Ref))
+			}
+		case r.Unavailable:
+			unavailable = append(unavailable, r)
+		default:
+			failed = append(failed, r)
+		}
+	}
+	if len(resolved) > 0 {
+		// The nodes pull the tag, which may have been moved since.
+		resp.Warnings = append(resp.Warnings, resolved...)
+		resp.AuditAnnotations = map[string]string{"resolved-images": strings.Join(resolved, "; ")}
+	}
+	if len(failed) == 0 && len(unavailable) == 0 {
+		return resp
+	}
+
+	object := fmt.Sprintf("%s %s/%s", req.Kind.Kind, req.Namespace, req.Name)
+	var msgs []string
+	for _, r := range append(failed, unavailable...) {
+		msgs = append(msgs, r.String())
+	}
+	switch {
+	case w.config.AuditOnly:
+		log.Printf("audit: %s would be denied: %s", object, strings.Join(msgs, "; "))
+		resp.Warnings = append(resp.Warnings, msgs...)
+		if resp.AuditAnnotations == nil {
+			resp.AuditAnnotations = make(map[string]string)
+		}
+		resp.AuditAnnotations["unverified-images"] = strings.Join(msgs, "; ")
+		return resp
+	case len(failed) == 0 && w.config.FailOpen:
+		log.Printf("fail-open: %s admitted unverified: %s", object, strings.Join(msgs, "; "))
+		resp.Warnings = append(resp.Warnings, msgs...)
+		return resp
+	default:
+		log.Printf("denied: %s: %s", object, strings.Join(msgs, "; "))
+		return deny(resp, http.StatusForbidden, "Forbidden",
+			fmt.Sprintf("SLSA verification failed: %s", strings.Join(msgs, "; ")))
+	}
+}
+
+func deny(resp *AdmissionResponse, code int32, reason, msg string) *AdmissionResponse {
+	resp.Allowed = false
+	resp.Result = &Status{Code: code, Reason: reason, Message: msg}
+	return resp
+}
+
+// verifyImage verifies the image against the first rule of the policy that
+// matches its repository.
+func (w *Webhook) verifyImage(ctx context.Context, namespace string, pol *policy.Policy, img image) *imageResult {
+	r := &imageResult{image: img}
+	ref, err := crname.ParseReference(img.Image)
+	if err != nil {
+		r.Err = fmt.Errorf("%w: %w", errInvalid, err)
+		return r
+	}
+
+	// Objects are admitted with the references of their spec, and the tag
+	// of an image may be moved once it is admitted. Tags are verified by
+	// the digest they resolve to, or denied with it if the namespace
+	// requires digests.
+	digest := ref.Identifier()
+	if _, ok := ref.(crname.Digest); !ok {
+		digest, err = w.resolve(ctx, img.Image)
+		if w.config.requiresDigest(namespace) {
+			if err != nil {
+				r.Err = fmt.Errorf("%w: reference the image by digest", serrors.ErrorMutableImage)
+				return r
+			}
+			r.Err = fmt.Errorf("%w: reference the image by digest: %s@%s", serrors.ErrorMutableImage,
+				ref.Context().Name(), digest)
+			return r
+		}
+		if err != nil {
+			r.Err = err
+			r.Unavailable = isUnavailable(err)
+			return r
+		}
+		r.Resolved = true
+	}
+	r.Ref = ref.Context().Name() + "@" + digest
+
+	// Namespaces may have different policies for the same image.
+	key := namespace + "\x00" + r.Ref
+	if e, ok := w.cache.get(key); ok {
+		r.Err = e.err
+		return r
+	}
+
+	r.Err = w.verifyRef(ctx, pol, ref.Context().Name(), r.Ref, digest)
+	if isUnavailable(r.Err) {
+		r.Unavailable = true
+		return r
+	}
+	w.cache.put(key, r.Err)
+	return r
+}
+
+func (w *Webhook) verifyRef(ctx context.Context, pol *policy.Policy, repository, ref, digest string) error {
+	rule, err := pol.Match("", repository)
+	if err != nil {
+		return err
+	}
+	provenanceOpts, err := rule.ProvenanceOpts("", strings.TrimPrefix(digest, "sha256:"))
+	if err != nil {
+		return err
+	}
+	provenanceOpts.SigstoreOpts = w.opts.SigstoreOpts
+	provenanceOpts.GitHubOpts = w.opts.GitHubOpts
+	provenanceOpts.TrustedBuilders = w.opts.TrustedBuilders
+
+	// The image is verified if it is verified with any of the builders of the rule.
+	var firstErr error
+	for _, builderOpts := range rule.BuilderOpts() {
+		err := w.verify(ctx, ref, provenanceOpts, builderOpts)
+		if err == nil {
+			return nil
+		}
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+	return fmt.Errorf("rule %q: %w", rule.Name, firstErr)
+}
+
+// isUnavailable returns true if the error is not a failed verification, and
+// the image may be verified if the request is retried: errors of Rekor, of
+// the TUF repository and of the registry, and timeouts. Their results are
+// not cached.
+func isUnavailable(err error) bool {
+	return utils.IsUnavailable(err) || errors.Is(err, serrors.ErrorImageHash)
+}
+
+// namespaces returns the namespaces with a policy, for logging.
+func (c *Config) namespaces() []string {
+	ns := make([]string, 0, len(c.Namespaces))
+	for n := range c.Namespaces {
+		ns = append(ns, n)
+	}
+	sort.Strings(ns)
+	return ns
+}
+
+// String describes the configuration.
+func (c *Config) String() string {
+	return fmt.Sprintf("namespaces %v, default policy %v, fail-open %v, audit-only %v, require-digest %v",
+		c.namespaces(), c.Default != nil, c.FailOpen, c.AuditOnly, c.RequireDigest)
+}
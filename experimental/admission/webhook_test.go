@@ -0,0 +1,531 @@
+package admission
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-containerregistry/pkg/crane"
+	"github.com/google/go-containerregistry/pkg/registry"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+
+	serrors "github.com/slsa-framework/slsa-verifier/v2/errors"
+	"github.com/slsa-framework/slsa-verifier/v2/options"
+	"github.com/slsa-framework/slsa-verifier/v2/policy"
+)
+
+const (
+	testSourceURI = "github.com/org/app"
+	testBuilderID = "https://github.com/slsa-framework/slsa-github-generator/.github/workflows/generator_container_slsa3.yml"
+)
+
+// newTestRegistry starts a registry with a verified and an unverified
+// image, tagged v1, and returns their references by digest.
+func newTestRegistry(t *testing.T) (verified, unverified string) {
+	t.Helper()
+
+	s := httptest.NewServer(registry.New(registry.Logger(log.New(io.Discard, "", 0))))
+	t.Cleanup(s.Close)
+	host := strings.TrimPrefix(s.URL, "http://")
+	var refs []string
+	for _, repo := range []string{host + "/org/app", host + "/org/other"} {
+		img, err := random.Image(256, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := crane.Push(img, repo+":v1"); err != nil {
+			t.Fatal(err)
+		}
+		digest, err := img.Digest()
+		if err != nil {
+			t.Fatal(err)
+		}
+		refs = append(refs, repo+"@"+digest.String())
+	}
+	return refs[0], refs[1]
+}
+
+// fakeVerifier verifies the images of the source repository of
+// testSourceURI built by testBuilderID, and counts its calls.
+type fakeVerifier struct {
+	mu    sync.Mutex
+	calls int
+	err   error
+}
+
+func (v *fakeVerifier) verify(_ context.Context, image string,
+	provenanceOpts *options.ProvenanceOpts, builderOpts *options.BuilderOpts,
+) error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.calls++
+	switch {
+	case v.err != nil:
+		return v.err
+	case !strings.Contains(image, "@sha256:"):
+		return fmt.Errorf("%w: %s", serrors.ErrorMutableImage, image)
+	case !strings.Contains(image, "/org/app@"):
+		return fmt.Errorf("%w: %s", serrors.ErrorMismatchSource, image)
+	case provenanceOpts.ExpectedSourceURI != testSourceURI:
+		return fmt.Errorf("%w: %s", serrors.ErrorMismatchSource, provenanceOpts.ExpectedSourceURI)
+	case builderOpts.ExpectedID == nil || *builderOpts.ExpectedID != testBuilderID:
+		return serrors.ErrorUntrustedReusableWorkflow
+	}
+	return nil
+}
+
+func newTestConfig(t *testing.T, host string) *Config {
+	t.Helper()
+
+	pol, err := policy.FromBytes([]byte(`
+version: 1
+rules:
+  - name: app
+    match:
+      sourceURI: ` + testSourceURI + `
+      artifact: ` + host + `/org/*
+    builderIDs: [` + testBuilderID + `]
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &Config{Namespaces: map[string]*policy.Policy{"prod": pol}}
+}
+
+func newTestReview(kind, namespace string, object any) []byte {
+	o, err := json.Marshal(object)
+	if err != nil {
+		panic(err)
+	}
+	review, err := json.Marshal(AdmissionReview{
+		APIVersion: "admission.k8s.io/v1",
+		Kind:       "AdmissionReview",
+		Request: &AdmissionRequest{
+			UID:       "705ab4f5-6393-11e8-b7cc-42010a800002",
+			Kind:      GroupVersionKind{Version: "v1", Kind: kind},
+			Name:      "app",
+			Namespace: namespace,
+			Operation: "CREATE",
+			Object:    o,
+		},
+	})
+	if err != nil {
+		panic(err)
+	}
+	return review
+}
+
+func newTestPod(images ...string) map[string]any {
+	var containers []map[string]any
+	for i, img := range images {
+		containers = append(containers, map[string]any{"name": fmt.Sprintf("c%d", i), "image": img})
+	}
+	return map[string]any{"kind": "Pod", "spec": map[string]any{"containers": containers}}
+}
+
+func review(t *testing.T, w *Webhook, body []byte) *AdmissionResponse {
+	t.Helper()
+
+	rec := httptest.NewRecorder()
+	w.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/validate", bytes.NewReader(body)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("unexpected status: %d: %s", rec.Code, rec.Body)
+	}
+	var resp AdmissionReview
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Kind != "AdmissionReview" || resp.Response == nil ||
+		resp.Response.UID != "705ab4f5-6393-11e8-b7cc-42010a800002" {
+		t.Fatalf("unexpected response: %+v", resp)
+	}
+	return resp.Response
+}
+
+func Test_Webhook(t *testing.T) {
+	t.Parallel()
+
+	verified, unverified := newTestRegistry(t)
+	host := strings.Split(verified, "/")[0]
+	workload := map[string]any{"spec": map[string]any{"template": newTestPod(verified, unverified)}}
+	cronJob := map[string]any{"spec": map[string]any{"jobTemplate": map[string]any{"spec": workload["spec"]}}}
+
+	tests := []struct {
+		name        string
+		config      func(*Config)
+		verifierErr error
+		review      []byte
+		allowed     bool
+		message     []string
+		warnings    int
+		warning     string
+	}{
+		{
+			name:    "verified pod",
+			review:  newTestReview("Pod", "prod", newTestPod(verified)),
+			allowed: true,
+		},
+		{
+			name:    "unverified pod",
+			review:  newTestReview("Pod", "prod", newTestPod(verified, unverified)),
+			message: []string{"containers[c1]: image " + unverified, "@sha256:", "does not match provenance"},
+		},
+		{
+			name:    "deployment",
+			review:  newTestReview("Deployment", "prod", workload),
+			message: []string{"containers[c1]"},
+		},
+		{
+			name:    "cron job",
+			review:  newTestReview("CronJob", "prod", cronJob),
+			message: []string{"containers[c1]"},
+		},
+		{
+			name:    "namespace without policy",
+			review:  newTestReview("Pod", "dev", newTestPod(unverified)),
+			allowed: true,
+		},
+		{
+			name: "default policy",
+			config: func(c *Config) {
+				c.Default = c.Namespaces["prod"]
+			},
+			review:  newTestReview("Pod", "dev", newTestPod(unverified)),
+			message: []string{"containers[c0]"},
+		},
+		{
+			name:    "no matching policy rule",
+			review:  newTestReview("Pod", "prod", newTestPod("ghcr.io/org/app@sha256:"+strings.Repeat("a", 64))),
+			message: []string{"no matching policy rule"},
+		},
+		{
+			name:    "other kind",
+			review:  newTestReview("ConfigMap", "prod", map[string]any{"data": map[string]string{}}),
+			allowed: true,
+		},
+		{
+			name:     "tag",
+			review:   newTestReview("Pod", "prod", newTestPod(host+"/org/app:v1")),
+			allowed:  true,
+			warnings: 1,
+			warning:  "containers[c0]: image " + host + "/org/app:v1 resolved to " + verified,
+		},
+		{
+			name:    "unverified tag",
+			review:  newTestReview("Pod", "prod", newTestPod(host+"/org/other:v1")),
+			message: []string{"containers[c0]: image " + host + "/org/other:v1 (" + unverified + ")", "does not match provenance"},
+		},
+		{
+			name:    "missing tag",
+			review:  newTestReview("Pod", "prod", newTestPod(host+"/org/missing:v1")),
+			message: []string{"cannot retrieve sha256 of image"},
+		},
+		{
+			name: "missing tag, fail open",
+			config: func(c *Config) {
+				c.FailOpen = true
+			},
+			review:   newTestReview("Pod", "prod", newTestPod(host+"/org/missing:v1")),
+			allowed:  true,
+			warnings: 1,
+			warning:  "cannot retrieve sha256 of image",
+		},
+		{
+			name: "tag, digest required",
+			config: func(c *Config) {
+				c.RequireDigest = []string{"prod"}
+			},
+			review:  newTestReview("Pod", "prod", newTestPod(host+"/org/app:v1")),
+			message: []string{"the image is mutable", "reference the image by digest: " + verified},
+		},
+		{
+			name: "tag, digest required, fail open",
+			config: func(c *Config) {
+				c.RequireDigest = []string{"prod"}
+				c.FailOpen = true
+			},
+			review:  newTestReview("Pod", "prod", newTestPod(host+"/org/app:v1")),
+			message: []string{"reference the image by digest: " + verified},
+		},
+		{
+			name: "missing tag, digest required",
+			config: func(c *Config) {
+				c.RequireDigest = []string{"prod"}
+			},
+			review:  newTestReview("Pod", "prod", newTestPod(host+"/org/missing:v1")),
+			message: []string{"the image is mutable: reference the image by digest"},
+		},
+		{
+			name: "tag, digest required in other namespace",
+			config: func(c *Config) {
+				c.RequireDigest = []string{"dev"}
+			},
+			review:   newTestReview("Pod", "prod", newTestPod(host+"/org/app:v1")),
+			allowed:  true,
+			warnings: 1,
+		},
+		{
+			name: "unverified pod, fail open",
+			config: func(c *Config) {
+				c.FailOpen = true
+			},
+			review:  newTestReview("Pod", "prod", newTestPod(unverified)),
+			message: []string{"does not match provenance"},
+		},
+		{
+			name: "verification timeout, fail open",
+			config: func(c *Config) {
+				c.FailOpen = true
+			},
+			verifierErr: fmt.Errorf("rekor: %w", context.DeadlineExceeded),
+			review:      newTestReview("Pod", "prod", newTestPod(verified)),
+			allowed:     true,
+			warnings:    1,
+		},
+		{
+			name: "rekor unreachable, fail open",
+			config: func(c *Config) {
+				c.FailOpen = true
+			},
+			verifierErr: fmt.Errorf("%w: %w", serrors.ErrorRekorSearch,
+				&url.Error{Op: "Post", URL: "https://rekor.sigstore.dev", Err: syscall.ECONNREFUSED}),
+			review:   newTestReview("Pod", "prod", newTestPod(verified)),
+			allowed:  true,
+			warnings: 1,
+		},
+		{
+			name: "trusted root unavailable, fail open",
+			config: func(c *Config) {
+				c.FailOpen = true
+			},
+			verifierErr: fmt.Errorf("%w: TUF repository", serrors.ErrorUnavailable),
+			review:      newTestReview("Pod", "prod", newTestPod(verified)),
+			allowed:     true,
+			warnings:    1,
+		},
+		{
+			name: "unverified pod, audit only",
+			config: func(c *Config) {
+				c.AuditOnly = true
+			},
+			review:   newTestReview("Pod", "prod", newTestPod(verified, unverified)),
+			allowed:  true,
+			warnings: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			config := newTestConfig(t, host)
+			if tt.config != nil {
+				tt.config(config)
+			}
+			v := &fakeVerifier{err: tt.verifierErr}
+			w := New(config, &Options{CacheTTL: DefaultCacheTTL})
+			w.verify = v.verify
+
+			resp := review(t, w, tt.review)
+			if resp.Allowed != tt.allowed {
+				t.Fatalf("unexpected decision: allowed %v, %+v", resp.Allowed, resp.Result)
+			}
+			if len(resp.Warnings) != tt.warnings {
+				t.Errorf("unexpected warnings: %v", resp.Warnings)
+			}
+			if tt.warning != "" && (len(resp.Warnings) == 0 || !strings.Contains(resp.Warnings[0], tt.warning)) {
+				t.Errorf("warnings %v do not contain %q", resp.Warnings, tt.warning)
+			}
+			if tt.config != nil && config.AuditOnly && resp.AuditAnnotations["unverified-images"] == "" {
+				t.Errorf("no audit annotation: %v", resp.AuditAnnotations)
+			}
+			for _, m := range tt.message {
+				if resp.Result == nil || !strings.Contains(resp.Result.Message, m) {
+					t.Errorf("message %+v does not contain %q", resp.Result, m)
+				}
+			}
+		})
+	}
+}
+
+func Test_Webhook_cache(t *testing.T) {
+	t.Parallel()
+
+	verified, unverified := newTestRegistry(t)
+	host := strings.Split(verified, "/")[0]
+	v := &fakeVerifier{}
+	w := New(newTestConfig(t, host), &Options{CacheTTL: time.Minute})
+	w.verify = v.verify
+	now := time.Now()
+	w.cache.now = func() time.Time { return now }
+
+	body := newTestReview("Pod", "prod", newTestPod(verified, unverified))
+	for i := 0; i < 3; i++ {
+		if resp := review(t, w, body); resp.Allowed {
+			t.Fatal("unverified image admitted")
+		}
+	}
+	// Both results are cached, including the failure.
+	if v.calls != 2 {
+		t.Errorf("unexpected verifications: got %d, want 2", v.calls)
+	}
+
+	// Tags are cached by the digest they resolve to.
+	if resp := review(t, w, newTestReview("Pod", "prod", newTestPod(host+"/org/app:v1"))); !resp.Allowed {
+		t.Fatalf("verified tag denied: %+v", resp.Result)
+	}
+	if v.calls != 2 {
+		t.Errorf("unexpected verifications of tag: got %d, want 2", v.calls)
+	}
+
+	// The results of other namespaces are cached separately.
+	w.config.Default = w.config.Namespaces["prod"]
+	review(t, w, newTestReview("Pod", "dev", newTestPod(verified)))
+	if v.calls != 3 {
+		t.Errorf("unexpected verifications: got %d, want 3", v.calls)
+	}
+
+	now = now.Add(2 * time.Minute)
+	review(t, w, newTestReview("Pod", "prod", newTestPod(verified)))
+	if v.calls != 4 {
+		t.Errorf("unexpected verifications after expiry: got %d, want 4", v.calls)
+	}
+
+	// Errors of unavailable services are not cached.
+	now = now.Add(2 * time.Minute)
+	v.err = fmt.Errorf("%w: %w", serrors.ErrorRekorSearch, serrors.ErrorUnavailable)
+	for i := 0; i < 2; i++ {
+		if resp := review(t, w, newTestReview("Pod", "prod", newTestPod(verified))); resp.Allowed {
+			t.Fatal("unverified image admitted")
+		}
+	}
+	if v.calls != 6 {
+		t.Errorf("unexpected verifications of unavailable services: got %d, want 6", v.calls)
+	}
+}
+
+func Test_cache_put(t *testing.T) {
+	t.Parallel()
+
+	c := newCache(time.Minute)
+	now := time.Now()
+	c.now = func() time.Time { return now }
+	for i := 0; i < maxCacheEntries; i++ {
+		c.put(fmt.Sprint(i), nil)
+		now = now.Add(time.Millisecond)
+	}
+	// The first entry is put again, so the second one is the oldest.
+	c.put("0", serrors.ErrorMismatchSource)
+	c.put("new", nil)
+	if len(c.entries) != maxCacheEntries {
+		t.Errorf("unexpected entries: got %d, want %d", len(c.entries), maxCacheEntries)
+	}
+	for key, want := range map[string]bool{"0": true, "1": false, "2": true, "new": true} {
+		if _, ok := c.get(key); ok != want {
+			t.Errorf("entry %q: got %v, want %v", key, ok, want)
+		}
+	}
+
+	// The expired entries are evicted: all but the last two.
+	now = now.Add(time.Minute - time.Millisecond)
+	c.put("last", nil)
+	if len(c.entries) != 3 {
+		t.Errorf("unexpected entries after expiry: got %d, want 3", len(c.entries))
+	}
+}
+
+func Test_ServeHTTP_invalid(t *testing.T) {
+	t.Parallel()
+
+	w := New(&Config{}, nil)
+	tests := []struct {
+		name   string
+		method string
+		body   string
+		code   int
+	}{
+		{name: "GET", method: http.MethodGet, code: http.StatusMethodNotAllowed},
+		{name: "not JSON", method: http.MethodPost, body: "review", code: http.StatusBadRequest},
+		{name: "no request", method: http.MethodPost, body: `{"kind": "AdmissionReview"}`, code: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			rec := httptest.NewRecorder()
+			w.ServeHTTP(rec, httptest.NewRequest(tt.method, "/validate", strings.NewReader(tt.body)))
+			if rec.Code != tt.code {
+				t.Errorf("unexpected status: got %d, want %d", rec.Code, tt.code)
+			}
+		})
+	}
+}
+
+func Test_images(t *testing.T) {
+	t.Parallel()
+
+	pod := `{"spec": {
+		"initContainers": [{"name": "init", "image": "busybox"}],
+		"containers": [{"name": "app", "image": "ghcr.io/org/app:v1"}]}}`
+	got, ok, err := images("Pod", []byte(pod))
+	if err != nil || !ok {
+		t.Fatalf("unexpected result: %v, %v", ok, err)
+	}
+	want := []image{
+		{Container: "initContainers[init]", Image: "busybox"},
+		{Container: "containers[app]", Image: "ghcr.io/org/app:v1"},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("unexpected images (-want +got):\n%s", diff)
+	}
+}
+
+func Test_LoadConfig(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		p := dir + "/" + name
+		if err := os.WriteFile(p, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		return p
+	}
+	valid := write("valid.yml", `
+failOpen: true
+namespaces:
+  prod:
+    version: 1
+    rules:
+      - name: app
+        match: {sourceURI: github.com/org/app}
+`)
+	c, err := LoadConfig(valid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !c.FailOpen || c.policy("prod") == nil || c.policy("dev") != nil {
+		t.Errorf("unexpected config: %v", c)
+	}
+
+	invalid := write("invalid.yml", `
+namespaces:
+  prod:
+    version: 2
+`)
+	if _, err := LoadConfig(invalid); err == nil {
+		t.Error("invalid policy accepted")
+	}
+}
@@ -0,0 +1,112 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/slsa-framework/slsa-verifier/v2/verifiers/utils"
+)
+
+// DefaultRekorURL is the Rekor instance whose reachability is checked by
+// the readiness endpoint: the one searched by the verifiers.
+const DefaultRekorURL = "https://rekor.sigstore.dev"
+
+// readinessTimeout bounds the time spent in each readiness check.
+const readinessTimeout = 5 * time.Second
+
+// ReadinessCheck returns an error if the service cannot verify yet.
+type ReadinessCheck func(ctx context.Context) error
+
+// TrustedRootLoaded checks that the trusted root of the public-good
+// Sigstore instance is loaded. It is fetched with TUF the first time,
+// and then cached.
+func TrustedRootLoaded(context.Context) error {
+	_, err := utils.GetTrustedMaterial(nil)
+	return err
+}
+
+// RekorReachable returns a check that the Rekor instance at rekorURL
+// answers requests for its log information.
+func RekorReachable(rekorURL string) ReadinessCheck {
+	return func(ctx context.Context) error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, rekorURL+"/api/v1/log", nil)
+		if err != nil {
+			return err
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return fmt.Errorf("unexpected status %s", resp.Status)
+		}
+		return nil
+	}
+}
+
+type readinessResult struct {
+	Checks []readinessCheckResult `json:"checks"`
+}
+
+type readinessCheckResult struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// HealthzHandler answers 200 while the service is running.
+func HealthzHandler(w http.ResponseWriter, _ *http.Request) {
+	w.WriteHeader(http.StatusOK)
+}
+
+// ReadyzHandler returns a handler that runs the named checks concurrently.
+// It answers 200 if they all pass, and 503 otherwise, with the errors of
+// the checks that failed.
+func ReadyzHandler(checks map[string]ReadinessCheck) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(r.Context(), readinessTimeout)
+		defer cancel()
+
+		type result struct {
+			name string
+			err  error
+		}
+		results := make(chan result, len(checks))
+		for name, check := range checks {
+			go func() {
+				results <- result{name: name, err: check(ctx)}
+			}()
+		}
+		failures := map[string]string{}
+		for range checks {
+			if res := <-results; res.err != nil {
+				failures[res.name] = res.err.Error()
+			}
+		}
+
+		names := make([]string, 0, len(checks))
+		for name := range checks {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		var readiness readinessResult
+		for _, name := range names {
+			c := readinessCheckResult{Name: name, Status: "ok"}
+			if msg, ok := failures[name]; ok {
+				c.Status, c.Error = "failed", msg
+			}
+			readiness.Checks = append(readiness.Checks, c)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if len(failures) > 0 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		json.NewEncoder(w).Encode(readiness)
+	}
+}
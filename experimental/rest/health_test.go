@@ -0,0 +1,110 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func Test_ReadyzHandler(t *testing.T) {
+	t.Parallel()
+
+	rekor := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/log" {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(rekor.Close)
+	ok := func(context.Context) error { return nil }
+	failed := func(context.Context) error { return errors.New("no trusted root") }
+
+	tests := []struct {
+		name   string
+		checks map[string]ReadinessCheck
+		status int
+		result readinessResult
+	}{
+		{
+			name:   "ready",
+			checks: map[string]ReadinessCheck{"trusted-root": ok, "rekor": RekorReachable(rekor.URL)},
+			status: http.StatusOK,
+			result: readinessResult{Checks: []readinessCheckResult{
+				{Name: "rekor", Status: "ok"},
+				{Name: "trusted-root", Status: "ok"},
+			}},
+		},
+		{
+			name:   "no trusted root",
+			checks: map[string]ReadinessCheck{"trusted-root": failed, "rekor": RekorReachable(rekor.URL)},
+			status: http.StatusServiceUnavailable,
+			result: readinessResult{Checks: []readinessCheckResult{
+				{Name: "rekor", Status: "ok"},
+				{Name: "trusted-root", Status: "failed", Error: "no trusted root"},
+			}},
+		},
+		{
+			name:   "rekor unreachable",
+			checks: map[string]ReadinessCheck{"rekor": RekorReachable(rekor.URL + "/unknown")},
+			status: http.StatusServiceUnavailable,
+			result: readinessResult{Checks: []readinessCheckResult{
+				{Name: "rekor", Status: "failed", Error: "unexpected status 404 Not Found"},
+			}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			rec := httptest.NewRecorder()
+			ReadyzHandler(tt.checks)(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+			if rec.Code != tt.status {
+				t.Errorf("unexpected status: got %d, want %d", rec.Code, tt.status)
+			}
+			var result readinessResult
+			if err := json.Unmarshal(rec.Body.Bytes(), &result); err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tt.result, result); diff != "" {
+				t.Errorf("unexpected result (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_metrics(t *testing.T) {
+	t.Parallel()
+
+	r := mux.NewRouter()
+	RegisterV2(r, V2Options{})
+	counter := verificationsTotal.WithLabelValues("vsa", "FAILED", codeInvalidRequest)
+	before := testutil.ToFloat64(counter)
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/v2/verify/vsa", strings.NewReader("{")))
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("unexpected status: %d", rec.Code)
+	}
+	if got := testutil.ToFloat64(counter); got != before+1 {
+		t.Errorf("unexpected count of invalid requests: got %v, want %v", got, before+1)
+	}
+	if n := testutil.CollectAndCount(verificationDuration, "slsa_verifier_verification_duration_seconds"); n == 0 {
+		t.Error("no duration recorded")
+	}
+
+	// The v1 API is instrumented too.
+	counter = verificationsTotal.WithLabelValues(verifierV1, "FAILED", codeInvalidRequest)
+	before = testutil.ToFloat64(counter)
+	rec = httptest.NewRecorder()
+	VerifyHandlerV1(rec, httptest.NewRequest(http.MethodPost, "/v1/verify", strings.NewReader(`{"artifactHash": "abcd"}`)))
+	if got := testutil.ToFloat64(counter); got != before+1 {
+		t.Errorf("unexpected count of invalid v1 requests: got %v, want %v", got, before+1)
+	}
+}
@@ -0,0 +1,77 @@
+package rest
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/slsa-framework/slsa-verifier/v2/report"
+	"github.com/slsa-framework/slsa-verifier/v2/verifiers/utils"
+)
+
+// The metrics of the verifications, registered with the default Prometheus
+// registry. The verifier label is the kind of the verification of the v2
+// API: artifact, image, npm-package, vsa or batch, or v1 for the v1 API.
+var (
+	verificationsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "slsa_verifier",
+		Name:      "verifications_total",
+		Help:      "Number of verifications, by verifier, result and error code.",
+	}, []string{"verifier", "result", "code"})
+
+	verificationDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "slsa_verifier",
+		Name:      "verification_duration_seconds",
+		Help:      "Duration of the verification requests, by verifier.",
+		Buckets:   prometheus.ExponentialBuckets(0.01, 2, 14),
+	}, []string{"verifier"})
+
+	stageDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "slsa_verifier",
+		Name:      "stage_duration_seconds",
+		Help:      "Duration of the stages of the verifications: signature, rekor and provenance.",
+		Buckets:   prometheus.ExponentialBuckets(0.001, 2, 16),
+	}, []string{"verifier", "stage"})
+
+	cacheHitsDesc = prometheus.NewDesc("slsa_verifier_cache_hits_total",
+		"Number of lookups of the caches of trusted roots and clients that hit.", []string{"cache"}, nil)
+	cacheMissesDesc = prometheus.NewDesc("slsa_verifier_cache_misses_total",
+		"Number of lookups of the caches of trusted roots and clients that missed.", []string{"cache"}, nil)
+)
+
+func init() {
+	prometheus.MustRegister(verificationsTotal, verificationDuration, stageDuration, cacheCollector{})
+}
+
+// cacheCollector exports the statistics of the caches of the verifiers.
+type cacheCollector struct{}
+
+func (cacheCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- cacheHitsDesc
+	ch <- cacheMissesDesc
+}
+
+func (cacheCollector) Collect(ch chan<- prometheus.Metric) {
+	for _, s := range utils.AllCacheStats() {
+		ch <- prometheus.MustNewConstMetric(cacheHitsDesc, prometheus.CounterValue, float64(s.Hits), s.Name)
+		ch <- prometheus.MustNewConstMetric(cacheMissesDesc, prometheus.CounterValue, float64(s.Misses), s.Name)
+	}
+}
+
+// observeReport records the result and the stage durations of a
+// verification.
+func observeReport(verifier string, rep *report.Report) {
+	var code string
+	if rep.Error != nil {
+		code = rep.Error.Code
+	}
+	verificationsTotal.WithLabelValues(verifier, string(rep.Result), code).Inc()
+	for stage, d := range rep.Durations() {
+		stageDuration.WithLabelValues(verifier, stage).Observe(d.Seconds())
+	}
+}
+
+// observeDuration records the duration of a verification request.
+func observeDuration(verifier string, start time.Time) {
+	verificationDuration.WithLabelValues(verifier).Observe(time.Since(start).Seconds())
+}
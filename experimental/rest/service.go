@@ -8,8 +8,10 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"time"
 
 	"github.com/slsa-framework/slsa-verifier/v2/options"
+	"github.com/slsa-framework/slsa-verifier/v2/report"
 	"github.com/slsa-framework/slsa-verifier/v2/verifiers"
 )
 
@@ -36,11 +38,12 @@ var (
 )
 
 type v1Result struct {
-	Version         uint       `json:"version"`
-	Error           *string    `json:"error,omitempty"`
-	Validation      validation `json:"validation"`
-	BuilderID       string     `json:"builderID"`
-	IntotoStatement *string    `json:"provenanceContent,omitempty"`
+	Version         uint          `json:"version"`
+	Error           *string       `json:"error,omitempty"`
+	ErrorDetails    *report.Error `json:"errorDetails,omitempty"`
+	Validation      validation    `json:"validation"`
+	BuilderID       string        `json:"builderID"`
+	IntotoStatement *string       `json:"provenanceContent,omitempty"`
 }
 
 func VerifyHandlerV1(w http.ResponseWriter, r *http.Request) {
@@ -49,6 +52,7 @@ func VerifyHandlerV1(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	defer observeDuration(verifierV1, time.Now())
 	results := verifyHandlerV1(r)
 	encoder := json.NewEncoder(w)
 	if err := encoder.Encode(results); err != nil {
@@ -76,6 +80,7 @@ func v1ResultNew() *v1Result {
 
 func (r *v1Result) withError(e error) *v1Result {
 	r.Error = toStringPtr(e)
+	r.ErrorDetails = report.NewError(e)
 	return r
 }
 
@@ -95,25 +100,39 @@ func (r *v1Result) withIntotoStatement(c []byte) *v1Result {
 	return r
 }
 
+// verifierV1 is the verifier label of the metrics of the v1 API.
+const verifierV1 = "v1"
+
 func verifyHandlerV1(r *http.Request) *v1Result {
 	results := v1ResultNew()
+	// The report of the verification is only recorded in the metrics.
+	rep := report.New("", "")
+	defer observeReport(verifierV1, rep)
+	fail := func(err error) *v1Result {
+		rep.Finish("", err)
+		if errors.Is(err, errInvalid) {
+			rep.Error.Code = codeInvalidRequest
+		}
+		return results.withError(err)
+	}
 
 	body, err := io.ReadAll(r.Body)
 	if err != nil {
-		return results.withError(err)
+		return fail(err)
 	}
 	r.Body.Close()
 
 	// Create a query.
 	query, err := queryFromString(body)
 	if err != nil {
-		return results.withError(err)
+		return fail(err)
 	}
 
 	// Validate it.
 	if err := query.validate(); err != nil {
-		return results.withError(err)
+		return fail(err)
 	}
+	rep.Digest = "sha256:" + query.ArtifactHash
 
 	// Run the verification.
 	provenanceOpts := &options.ProvenanceOpts{
@@ -128,12 +147,13 @@ func verifyHandlerV1(r *http.Request) *v1Result {
 		ExpectedID: query.BuilderID,
 	}
 
-	ctx := context.Background()
+	ctx := report.NewContext(context.Background(), rep)
 	p, builderID, err := verifiers.VerifyArtifact(ctx, []byte(query.DsseEnvelope),
 		query.ArtifactHash, provenanceOpts, builderOpts)
 	if err != nil {
-		return results.withError(err)
+		return fail(err)
 	}
+	rep.Finish(builderID.String(), nil)
 
 	if query.PrintProvenance != nil && *query.PrintProvenance {
 		results = results.withIntotoStatement(p)
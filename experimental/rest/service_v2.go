@@ -0,0 +1,544 @@
+package rest
+
+import (
+	"bytes"
+	"context"
+	_ "embed"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"time"
+
+	crname "github.com/google/go-containerregistry/pkg/name"
+	"github.com/gorilla/mux"
+	"github.com/sigstore/sigstore/pkg/cryptoutils"
+
+	"github.com/slsa-framework/slsa-verifier/v2/batch"
+	serrors "github.com/slsa-framework/slsa-verifier/v2/errors"
+	"github.com/slsa-framework/slsa-verifier/v2/options"
+	"github.com/slsa-framework/slsa-verifier/v2/report"
+	"github.com/slsa-framework/slsa-verifier/v2/verifiers"
+	"github.com/slsa-framework/slsa-verifier/v2/verifiers/utils"
+	"github.com/slsa-framework/slsa-verifier/v2/verifiers/utils/container"
+)
+
+// APIVersionV2 is the version of the v2 API, returned in every response.
+const APIVersionV2 = "2"
+
+// codeInvalidRequest is the error code of the requests that cannot be
+// verified because they are malformed or miss required fields.
+const codeInvalidRequest = "INVALID_REQUEST"
+
+// maxV2RequestSize is the size of the largest request body read. Requests
+// carry provenance and attestations, encoded in base64.
+const maxV2RequestSize = 10 << 20
+
+// maxV2BatchRequestSize and maxV2BatchItems bound the batch requests.
+const (
+	maxV2BatchRequestSize = 64 << 20
+	maxV2BatchItems       = 1000
+)
+
+// OpenAPIV2 is the OpenAPI document of the v2 API, served at
+// /v2/openapi.yaml.
+//
+//go:embed openapi.yaml
+var OpenAPIV2 []byte
+
+// V2Options configures the v2 API.
+type V2Options struct {
+	// ImageRegistries are the hosts of the registries that the image
+	// verifications may contact, e.g. ghcr.io. If empty, any registry may
+	// be contacted: clients can then make the service send requests to any
+	// host, which is only acceptable if they are trusted.
+	ImageRegistries []string
+	// BatchTimeout bounds the verification of a batch, so that its result
+	// is written before the WriteTimeout of the server. The items not
+	// verified by then fail. Zero means no bound.
+	BatchTimeout time.Duration
+}
+
+// RegisterV2 registers the handlers of the v2 API on the router.
+func RegisterV2(r *mux.Router, opts V2Options) {
+	r.HandleFunc("/v2/openapi.yaml", openAPIHandlerV2).Methods(http.MethodGet)
+	r.HandleFunc("/v2/verify/artifact", v2Handler("artifact", verifyArtifactV2)).Methods(http.MethodPost)
+	r.HandleFunc("/v2/verify/image", v2Handler("image", imageVerifierV2(opts.ImageRegistries))).Methods(http.MethodPost)
+	r.HandleFunc("/v2/verify/npm-package", v2Handler("npm-package", verifyNpmPackageV2)).Methods(http.MethodPost)
+	r.HandleFunc("/v2/verify/vsa", v2Handler("vsa", verifyVSAV2)).Methods(http.MethodPost)
+	r.HandleFunc("/v2/verify:batch", batchHandlerV2(opts.BatchTimeout)).Methods(http.MethodPost)
+}
+
+func openAPIHandlerV2(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/yaml")
+	w.Write(OpenAPIV2)
+}
+
+// v2Expectations are the expectations on the provenance shared by the
+// artifact, image and npm package requests.
+type v2Expectations struct {
+	// Compulsory fields.
+	Source string `json:"source"`
+	// Optional fields.
+	BuilderID         *string           `json:"builderID,omitempty"`
+	Tag               *string           `json:"tag,omitempty"`
+	Branch            *string           `json:"branch,omitempty"`
+	VersionedTag      *string           `json:"versionedTag,omitempty"`
+	WorkflowInputs    map[string]string `json:"workflowInputs,omitempty"`
+	IncludeProvenance bool              `json:"includeProvenance,omitempty"`
+}
+
+type v2ArtifactQuery struct {
+	v2Expectations
+	ArtifactHash string `json:"artifactHash"`
+	Provenance   string `json:"provenance"`
+}
+
+type v2ImageQuery struct {
+	v2Expectations
+	Image string `json:"image"`
+	// Provenance is optional: it is otherwise fetched from the registry.
+	Provenance           *string `json:"provenance,omitempty"`
+	ProvenanceRepository *string `json:"provenanceRepository,omitempty"`
+}
+
+type v2NpmPackageQuery struct {
+	v2Expectations
+	PackageName    string `json:"packageName"`
+	PackageVersion string `json:"packageVersion"`
+	TarballHash    string `json:"tarballHash"`
+	// Attestations is optional: they are otherwise fetched from the
+	// public npm registry.
+	Attestations *string `json:"attestations,omitempty"`
+}
+
+type v2VSAQuery struct {
+	Attestation       string   `json:"attestation"`
+	SubjectDigests    []string `json:"subjectDigests"`
+	VerifierID        string   `json:"verifierID"`
+	ResourceURI       string   `json:"resourceURI"`
+	VerifiedLevels    []string `json:"verifiedLevels,omitempty"`
+	PublicKey         string   `json:"publicKey"`
+	PublicKeyID       *string  `json:"publicKeyID,omitempty"`
+	IncludeProvenance bool     `json:"includeProvenance,omitempty"`
+}
+
+// v2BatchItem is an artifact of a batch request. Its name identifies its
+// report in the result.
+type v2BatchItem struct {
+	Name string `json:"name,omitempty"`
+	v2ArtifactQuery
+}
+
+type v2BatchQuery struct {
+	Items []v2BatchItem `json:"items"`
+}
+
+// v2Result is the response of the verification endpoints: the report of
+// the verification.
+type v2Result struct {
+	APIVersion string `json:"apiVersion"`
+	*report.Report
+}
+
+// v2BatchResult is the response of the batch endpoint: the reports of the
+// items and the aggregate verdict.
+type v2BatchResult struct {
+	APIVersion string `json:"apiVersion"`
+	*batch.Result
+}
+
+// v2VerifyFn verifies the request body. It returns an error wrapping
+// errInvalid if the request is invalid, and otherwise the report of the
+// verification, passed or failed, and the status of the response.
+type v2VerifyFn func(ctx context.Context, body []byte) (*report.Report, int, error)
+
+// v2Handler serves a verification endpoint. Passed verifications are
+// answered with 200, failed verifications with 422, 502 or 503, see
+// statusV2, and invalid requests with 400 or 413. The verifications are
+// recorded in the metrics of the verifier.
+func v2Handler(verifier string, verify v2VerifyFn) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		defer observeDuration(verifier, time.Now())
+		body, status, err := readV2(r, maxV2RequestSize)
+		if err != nil {
+			observeReport(verifier, writeInvalidV2(w, status, err))
+			return
+		}
+
+		rep, status, err := verify(r.Context(), body)
+		if err != nil {
+			observeReport(verifier, writeInvalidV2(w, http.StatusBadRequest, err))
+			return
+		}
+		observeReport(verifier, rep)
+		writeV2(w, status, rep)
+	}
+}
+
+// readV2 reads the request body, up to maxSize bytes.
+func readV2(r *http.Request, maxSize int) ([]byte, int, error) {
+	body, err := io.ReadAll(io.LimitReader(r.Body, int64(maxSize)+1))
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+	if len(body) > maxSize {
+		return nil, http.StatusRequestEntityTooLarge,
+			fmt.Errorf("%w: request larger than %d bytes", errInvalid, maxSize)
+	}
+	return body, http.StatusOK, nil
+}
+
+// writeInvalidV2 answers an invalid request, and returns the report of
+// the failure.
+func writeInvalidV2(w http.ResponseWriter, status int, err error) *report.Report {
+	rep := report.New("", "")
+	rep.Finish("", err)
+	rep.Error.Code = codeInvalidRequest
+	writeV2(w, status, rep)
+	return rep
+}
+
+func writeV2(w http.ResponseWriter, status int, rep *report.Report) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v2Result{APIVersion: APIVersionV2, Report: rep})
+}
+
+// decodeV2 decodes the request body into query. Unknown fields are
+// rejected, so that misspelled expectations are not silently ignored.
+func decodeV2(body []byte, query any) error {
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(query); err != nil {
+		return fmt.Errorf("%w: %w", errInvalid, err)
+	}
+	return nil
+}
+
+func decodeBase64V2(field, s string) ([]byte, error) {
+	b, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("%w: decoding %s", errInvalid, field)
+	}
+	if len(b) == 0 {
+		return nil, fmt.Errorf("%w: empty %s", errInvalid, field)
+	}
+	return b, nil
+}
+
+func (e *v2Expectations) validate() error {
+	if e.Source == "" {
+		return fmt.Errorf("%w: empty source", errInvalid)
+	}
+	if e.Tag != nil && e.VersionedTag != nil {
+		return fmt.Errorf("%w: tag and versionedTag are mutually exclusive", errInvalid)
+	}
+	return nil
+}
+
+func (e *v2Expectations) options(digest string) (*options.ProvenanceOpts, *options.BuilderOpts) {
+	return &options.ProvenanceOpts{
+		ExpectedSourceURI:      e.Source,
+		ExpectedBranch:         e.Branch,
+		ExpectedDigest:         digest,
+		ExpectedVersionedTag:   e.VersionedTag,
+		ExpectedTag:            e.Tag,
+		ExpectedWorkflowInputs: e.WorkflowInputs,
+	}, &options.BuilderOpts{
+		ExpectedID: e.BuilderID,
+	}
+}
+
+// statusV2 returns the status of the response to a verification that
+// returned err: 200 if it passed, 503 if it did not complete in time, 502
+// if a service it depends on failed, e.g. a registry, Rekor or the Sigstore
+// TUF repository, and 422 if the provenance failed a check.
+func statusV2(err error) int {
+	switch {
+	case err == nil:
+		return http.StatusOK
+	case errors.Is(err, context.DeadlineExceeded), errors.Is(err, context.Canceled):
+		return http.StatusServiceUnavailable
+	case utils.IsUnavailable(err):
+		return http.StatusBadGateway
+	default:
+		return http.StatusUnprocessableEntity
+	}
+}
+
+// finishV2 records the outcome of the verification in the report, and
+// returns the status of the response.
+func finishV2(rep *report.Report, provenance []byte, builderID *utils.TrustedBuilderID,
+	err error, includeProvenance bool,
+) (*report.Report, int, error) {
+	if err != nil {
+		rep.Finish("", err)
+		return rep, statusV2(err), nil
+	}
+	if includeProvenance && json.Valid(provenance) {
+		rep.Provenance = provenance
+	}
+	rep.Finish(builderID.String(), nil)
+	return rep, http.StatusOK, nil
+}
+
+func verifyArtifactV2(ctx context.Context, body []byte) (*report.Report, int, error) {
+	var query v2ArtifactQuery
+	if err := decodeV2(body, &query); err != nil {
+		return nil, 0, err
+	}
+	if err := query.validate(); err != nil {
+		return nil, 0, err
+	}
+	if _, err := hex.DecodeString(query.ArtifactHash); err != nil || len(query.ArtifactHash) != 64 {
+		return nil, 0, fmt.Errorf("%w: artifactHash is not a hex-encoded sha256 digest", errInvalid)
+	}
+	provenance, err := decodeBase64V2("provenance", query.Provenance)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	rep := report.New("", "sha256:"+query.ArtifactHash)
+	provenanceOpts, builderOpts := query.options(query.ArtifactHash)
+	p, builderID, err := verifiers.VerifyArtifact(report.NewContext(ctx, rep), provenance,
+		query.ArtifactHash, provenanceOpts, builderOpts)
+	return finishV2(rep, p, builderID, err, query.IncludeProvenance)
+}
+
+// imageVerifierV2 returns the verifier of the image requests, which may
+// only contact the registries, or any registry if empty.
+func imageVerifierV2(registries []string) v2VerifyFn {
+	return func(ctx context.Context, body []byte) (*report.Report, int, error) {
+		return verifyImageV2(ctx, body, registries)
+	}
+}
+
+func verifyImageV2(ctx context.Context, body []byte, registries []string) (*report.Report, int, error) {
+	var query v2ImageQuery
+	if err := decodeV2(body, &query); err != nil {
+		return nil, 0, err
+	}
+	if err := query.validate(); err != nil {
+		return nil, 0, err
+	}
+	// Local images would be read from the file system of the service.
+	if query.Image == "" || container.IsLocalImage(query.Image) {
+		return nil, 0, fmt.Errorf("%w: image must be a registry image", errInvalid)
+	}
+	if err := query.verifyRegistries(registries); err != nil {
+		return nil, 0, err
+	}
+	var provenance []byte
+	if query.Provenance != nil {
+		var err error
+		if provenance, err = decodeBase64V2("provenance", *query.Provenance); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	digest, err := container.GetDigestFromImmutableReference(query.Image)
+	if err != nil {
+		return nil, 0, fmt.Errorf("%w: %w", errInvalid, err)
+	}
+	rep := report.New(query.Image, "sha256:"+digest)
+	provenanceOpts, builderOpts := query.options(digest)
+	provenanceOpts.ExpectedProvenanceRepository = query.ProvenanceRepository
+	p, builderID, err := verifiers.VerifyImage(report.NewContext(ctx, rep), query.Image, provenance,
+		provenanceOpts, builderOpts)
+	return finishV2(rep, p, builderID, err, query.IncludeProvenance)
+}
+
+// verifyRegistries verifies that the registries of the image and of the
+// provenance repository are among registries, if not empty.
+func (q *v2ImageQuery) verifyRegistries(registries []string) error {
+	if len(registries) == 0 {
+		return nil
+	}
+	ref, err := crname.ParseReference(q.Image)
+	if err != nil {
+		return fmt.Errorf("%w: %w", errInvalid, err)
+	}
+	repos := []crname.Repository{ref.Context()}
+	if q.ProvenanceRepository != nil {
+		repo, err := crname.NewRepository(*q.ProvenanceRepository)
+		if err != nil {
+			return fmt.Errorf("%w: %w", errInvalid, err)
+		}
+		repos = append(repos, repo)
+	}
+	for _, repo := range repos {
+		if !slices.ContainsFunc(registries, func(host string) bool {
+			registry, err := crname.NewRegistry(host)
+			return err == nil && registry.RegistryStr() == repo.RegistryStr()
+		}) {
+			return fmt.Errorf("%w: registry %q is not allowed", errInvalid, repo.RegistryStr())
+		}
+	}
+	return nil
+}
+
+func verifyNpmPackageV2(ctx context.Context, body []byte) (*report.Report, int, error) {
+	var query v2NpmPackageQuery
+	if err := decodeV2(body, &query); err != nil {
+		return nil, 0, err
+	}
+	if err := query.validate(); err != nil {
+		return nil, 0, err
+	}
+	if query.PackageName == "" || query.PackageVersion == "" {
+		return nil, 0, fmt.Errorf("%w: empty packageName or packageVersion", errInvalid)
+	}
+	if _, err := hex.DecodeString(query.TarballHash); err != nil || len(query.TarballHash) != 128 {
+		return nil, 0, fmt.Errorf("%w: tarballHash is not a hex-encoded sha512 digest", errInvalid)
+	}
+
+	rep := report.New(query.PackageName+"@"+query.PackageVersion, "sha512:"+query.TarballHash)
+	var attestations []byte
+	var err error
+	if query.Attestations != nil {
+		if attestations, err = decodeBase64V2("attestations", *query.Attestations); err != nil {
+			return nil, 0, err
+		}
+	} else {
+		attestations, err = fetchNpmAttestations(ctx, query.PackageName, query.PackageVersion)
+		if err != nil {
+			return finishV2(rep, nil, nil, err, false)
+		}
+	}
+
+	provenanceOpts, builderOpts := query.options(query.TarballHash)
+	provenanceOpts.ExpectedPackageName = &query.PackageName
+	provenanceOpts.ExpectedPackageVersion = &query.PackageVersion
+	p, builderID, err := verifiers.VerifyNpmPackage(report.NewContext(ctx, rep), attestations,
+		query.TarballHash, provenanceOpts, builderOpts)
+	return finishV2(rep, p, builderID, err, query.IncludeProvenance)
+}
+
+// fetchNpmAttestations fetches the attestations from the public registry
+// only: the service does not send requests to URLs given by clients.
+func fetchNpmAttestations(ctx context.Context, name, version string) ([]byte, error) {
+	u, err := utils.NpmAttestationsURL(utils.DefaultNpmRegistry, name, version)
+	if err != nil {
+		return nil, err
+	}
+	return utils.FetchNpmAttestations(ctx, u)
+}
+
+// batchHandlerV2 returns a handler verifying the artifacts of a batch
+// concurrently, for up to timeout if it is positive. The batch is answered
+// with 200 if all the artifacts passed, with 422 if any failed, and with
+// 400 or 413 if the request is invalid. An invalid item, or one not
+// verified before the timeout, fails the batch, but not the request.
+func batchHandlerV2(timeout time.Duration) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		verifyBatchV2(w, r, timeout)
+	}
+}
+
+func verifyBatchV2(w http.ResponseWriter, r *http.Request, timeout time.Duration) {
+	const verifier = "batch"
+	defer observeDuration(verifier, time.Now())
+	body, status, err := readV2(r, maxV2BatchRequestSize)
+	if err != nil {
+		observeReport(verifier, writeInvalidV2(w, status, err))
+		return
+	}
+	var query v2BatchQuery
+	if err := decodeV2(body, &query); err != nil {
+		observeReport(verifier, writeInvalidV2(w, http.StatusBadRequest, err))
+		return
+	}
+	if len(query.Items) == 0 || len(query.Items) > maxV2BatchItems {
+		observeReport(verifier, writeInvalidV2(w, http.StatusBadRequest,
+			fmt.Errorf("%w: a batch has between 1 and %d items", errInvalid, maxV2BatchItems)))
+		return
+	}
+
+	items := make([]batch.Item, len(query.Items))
+	for i := range query.Items {
+		q := &query.Items[i]
+		items[i] = batch.Item{
+			Name:              q.Name,
+			ArtifactHash:      q.ArtifactHash,
+			Provenance:        q.Provenance,
+			Source:            q.Source,
+			BuilderID:         q.BuilderID,
+			Tag:               q.Tag,
+			Branch:            q.Branch,
+			VersionedTag:      q.VersionedTag,
+			WorkflowInputs:    q.WorkflowInputs,
+			IncludeProvenance: q.IncludeProvenance,
+		}
+	}
+	ctx := r.Context()
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	// Items may not refer to files: they would be read from the file
+	// system of the service.
+	result := batch.Verify(ctx, items, &batch.Options{})
+	for _, rep := range result.Items {
+		observeReport(verifier, rep)
+	}
+
+	status = http.StatusOK
+	if result.Result != report.StatusPassed {
+		status = http.StatusUnprocessableEntity
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v2BatchResult{APIVersion: APIVersionV2, Result: result})
+}
+
+func verifyVSAV2(ctx context.Context, body []byte) (*report.Report, int, error) {
+	var query v2VSAQuery
+	if err := decodeV2(body, &query); err != nil {
+		return nil, 0, err
+	}
+	if len(query.SubjectDigests) == 0 || query.VerifierID == "" || query.ResourceURI == "" ||
+		query.PublicKey == "" {
+		return nil, 0, fmt.Errorf("%w: subjectDigests, verifierID, resourceURI and publicKey are required", errInvalid)
+	}
+	attestation, err := decodeBase64V2("attestation", query.Attestation)
+	if err != nil {
+		return nil, 0, err
+	}
+	pubKey, err := cryptoutils.UnmarshalPEMToPublicKey([]byte(query.PublicKey))
+	if err != nil {
+		return nil, 0, fmt.Errorf("%w: %w: %w", errInvalid, serrors.ErrorInvalidPublicKey, err)
+	}
+
+	rep := report.New(query.ResourceURI, "")
+	if len(query.SubjectDigests) == 1 {
+		rep.Digest = query.SubjectDigests[0]
+	}
+	vsaOpts := &options.VSAOpts{
+		ExpectedDigests:        &query.SubjectDigests,
+		ExpectedVerifierID:     &query.VerifierID,
+		ExpectedResourceURI:    &query.ResourceURI,
+		ExpectedVerifiedLevels: &query.VerifiedLevels,
+	}
+	verificationOpts := &options.VerificationOpts{
+		PublicKey:         pubKey,
+		PublicKeyID:       query.PublicKeyID,
+		PublicKeyHashAlgo: utils.SignatureHashAlgo(pubKey),
+	}
+	vsa, err := verifiers.VerifyVSA(report.NewContext(ctx, rep), attestation, vsaOpts, verificationOpts)
+	if err != nil {
+		rep.Finish("", err)
+		return rep, statusV2(err), nil
+	}
+	if query.IncludeProvenance && json.Valid(vsa) {
+		rep.Provenance = vsa
+	}
+	// The VSA has no builder: the verifier ID is an expectation of the query.
+	rep.Finish("", nil)
+	return rep, http.StatusOK, nil
+}
@@ -0,0 +1,445 @@
+package rest
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
+	"github.com/gorilla/mux"
+	"sigs.k8s.io/yaml"
+
+	"github.com/slsa-framework/slsa-verifier/v2/batch"
+	serrors "github.com/slsa-framework/slsa-verifier/v2/errors"
+	"github.com/slsa-framework/slsa-verifier/v2/report"
+)
+
+type openAPISchema struct {
+	Ref        string                    `json:"$ref"`
+	AllOf      []openAPISchema           `json:"allOf"`
+	Required   []string                  `json:"required"`
+	Properties map[string]*openAPISchema `json:"properties"`
+}
+
+type openAPIDocument struct {
+	Paths      map[string]map[string]any `json:"paths"`
+	Components struct {
+		Schemas map[string]*openAPISchema `json:"schemas"`
+	} `json:"components"`
+}
+
+// properties returns the names of the properties of the schema, and of
+// the schemas it is composed of.
+func (d *openAPIDocument) properties(s *openAPISchema) []string {
+	var names []string
+	if s.Ref != "" {
+		return d.properties(d.Components.Schemas[strings.TrimPrefix(s.Ref, "#/components/schemas/")])
+	}
+	for i := range s.AllOf {
+		names = append(names, d.properties(&s.AllOf[i])...)
+	}
+	for name := range s.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// jsonFields returns the names of the JSON fields of v, with all its
+// fields set.
+func jsonFields(t *testing.T, v any) []string {
+	t.Helper()
+
+	b, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var m map[string]any
+	if err := json.Unmarshal(b, &m); err != nil {
+		t.Fatal(err)
+	}
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func Test_OpenAPIV2(t *testing.T) {
+	t.Parallel()
+
+	var doc openAPIDocument
+	if err := yaml.Unmarshal(OpenAPIV2, &doc); err != nil {
+		t.Fatal(err)
+	}
+
+	// The document lists the routes of the handlers.
+	r := mux.NewRouter()
+	RegisterV2(r, V2Options{})
+	routes := map[string]map[string]any{}
+	err := r.Walk(func(route *mux.Route, _ *mux.Router, _ []*mux.Route) error {
+		path, err := route.GetPathTemplate()
+		if err != nil {
+			return err
+		}
+		methods, err := route.GetMethods()
+		if err != nil {
+			return err
+		}
+		routes[path] = map[string]any{}
+		for _, m := range methods {
+			routes[path][strings.ToLower(m)] = nil
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for path := range doc.Paths {
+		for method := range doc.Paths[path] {
+			doc.Paths[path][method] = nil
+		}
+	}
+	if diff := cmp.Diff(routes, doc.Paths); diff != "" {
+		t.Errorf("routes do not match the document (-handlers +document):\n%s", diff)
+	}
+
+	// The schemas list the fields of the requests and of the result.
+	str := "s"
+	expectations := v2Expectations{
+		Source: str, BuilderID: &str, Tag: &str, Branch: &str, VersionedTag: &str,
+		WorkflowInputs: map[string]string{"k": "v"}, IncludeProvenance: true,
+	}
+	rep := report.New("a", "d")
+	var index int64
+	rep.BuilderID, rep.BuilderTrust, rep.SourceURI, rep.SourceCommit = str, str, str, str
+	rep.SourceRef, rep.PolicyRule, rep.RekorLogIndex = str, str, &index
+	rep.Manifest, rep.Platforms = &report.Manifest{}, []*report.Platform{{}}
+	rep.Error, rep.Provenance = &report.Error{}, json.RawMessage(`{}`)
+	rep.Result = report.StatusPassed
+
+	tests := []struct {
+		schema string
+		value  any
+	}{
+		{
+			schema: "ArtifactRequest",
+			value:  v2ArtifactQuery{v2Expectations: expectations, ArtifactHash: str, Provenance: str},
+		},
+		{
+			schema: "ImageRequest",
+			value: v2ImageQuery{
+				v2Expectations: expectations, Image: str, Provenance: &str, ProvenanceRepository: &str,
+			},
+		},
+		{
+			schema: "NpmPackageRequest",
+			value: v2NpmPackageQuery{
+				v2Expectations: expectations, PackageName: str, PackageVersion: str, TarballHash: str, Attestations: &str,
+			},
+		},
+		{
+			schema: "VSARequest",
+			value: v2VSAQuery{
+				Attestation: str, SubjectDigests: []string{str}, VerifierID: str, ResourceURI: str,
+				VerifiedLevels: []string{str}, PublicKey: str, PublicKeyID: &str, IncludeProvenance: true,
+			},
+		},
+		{
+			schema: "Result",
+			value:  v2Result{APIVersion: APIVersionV2, Report: rep},
+		},
+		{
+			schema: "BatchRequest",
+			value:  v2BatchQuery{Items: []v2BatchItem{{}}},
+		},
+		{
+			schema: "BatchItem",
+			value: v2BatchItem{
+				Name: str, v2ArtifactQuery: v2ArtifactQuery{v2Expectations: expectations, ArtifactHash: str, Provenance: str},
+			},
+		},
+		{
+			schema: "BatchResult",
+			value: v2BatchResult{
+				APIVersion: APIVersionV2,
+				Result:     &batch.Result{Result: report.StatusPassed, Items: []*report.Report{rep}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.schema, func(t *testing.T) {
+			t.Parallel()
+
+			schema, ok := doc.Components.Schemas[tt.schema]
+			if !ok {
+				t.Fatalf("no schema %s", tt.schema)
+			}
+			if diff := cmp.Diff(jsonFields(t, tt.value), doc.properties(schema)); diff != "" {
+				t.Errorf("fields do not match the schema (-handler +document):\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_v2Handler(t *testing.T) {
+	t.Parallel()
+
+	r := mux.NewRouter()
+	RegisterV2(r, V2Options{ImageRegistries: []string{"ghcr.io"}})
+	hash := strings.Repeat("a", 64)
+	provenance := base64.StdEncoding.EncodeToString([]byte(`{"payloadType": "application/vnd.in-toto+json"}`))
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		status int
+		code   string
+	}{
+		{
+			name:   "not JSON",
+			path:   "/v2/verify/artifact",
+			body:   "provenance",
+			status: http.StatusBadRequest,
+			code:   codeInvalidRequest,
+		},
+		{
+			name:   "unknown field",
+			path:   "/v2/verify/artifact",
+			body:   `{"source": "github.com/org/repo", "sourceTag": "v1"}`,
+			status: http.StatusBadRequest,
+			code:   codeInvalidRequest,
+		},
+		{
+			name:   "no source",
+			path:   "/v2/verify/artifact",
+			body:   `{"artifactHash": "` + hash + `", "provenance": "` + provenance + `"}`,
+			status: http.StatusBadRequest,
+			code:   codeInvalidRequest,
+		},
+		{
+			name: "unsupported builder",
+			path: "/v2/verify/artifact",
+			body: `{"source": "github.com/org/repo", "builderID": "https://example.com/builder",
+				"artifactHash": "` + hash + `", "provenance": "` + provenance + `"}`,
+			status: http.StatusUnprocessableEntity,
+			code:   "VERIFIER_NOT_SUPPORTED",
+		},
+		{
+			name:   "mutable image",
+			path:   "/v2/verify/image",
+			body:   `{"source": "github.com/org/repo", "image": "ghcr.io/org/image:v1"}`,
+			status: http.StatusBadRequest,
+			code:   codeInvalidRequest,
+		},
+		{
+			name:   "local image",
+			path:   "/v2/verify/image",
+			body:   `{"source": "github.com/org/repo", "image": "oci-layout:/etc@sha256:` + hash + `"}`,
+			status: http.StatusBadRequest,
+			code:   codeInvalidRequest,
+		},
+		{
+			name:   "registry not allowed",
+			path:   "/v2/verify/image",
+			body:   `{"source": "github.com/org/repo", "image": "example.com/org/image@sha256:` + hash + `"}`,
+			status: http.StatusBadRequest,
+			code:   codeInvalidRequest,
+		},
+		{
+			name: "provenance registry not allowed",
+			path: "/v2/verify/image",
+			body: `{"source": "github.com/org/repo", "image": "ghcr.io/org/image@sha256:` + hash + `",
+				"provenanceRepository": "example.com/org/provenance"}`,
+			status: http.StatusBadRequest,
+			code:   codeInvalidRequest,
+		},
+		{
+			name:   "npm package without version",
+			path:   "/v2/verify/npm-package",
+			body:   `{"source": "github.com/org/repo", "packageName": "pkg", "tarballHash": "` + hash + hash + `"}`,
+			status: http.StatusBadRequest,
+			code:   codeInvalidRequest,
+		},
+		{
+			name:   "VSA without public key",
+			path:   "/v2/verify/vsa",
+			body:   `{"attestation": "` + provenance + `", "subjectDigests": ["sha256:` + hash + `"], "verifierID": "v", "resourceURI": "r"}`,
+			status: http.StatusBadRequest,
+			code:   codeInvalidRequest,
+		},
+		{
+			name:   "empty batch",
+			path:   "/v2/verify:batch",
+			body:   `{"items": []}`,
+			status: http.StatusBadRequest,
+			code:   codeInvalidRequest,
+		},
+		{
+			name:   "batch with unknown field",
+			path:   "/v2/verify:batch",
+			body:   `{"items": [{"source": "github.com/org/repo", "artifactPath": "/etc/passwd"}]}`,
+			status: http.StatusBadRequest,
+			code:   codeInvalidRequest,
+		},
+		{
+			name:   "GET",
+			method: http.MethodGet,
+			path:   "/v2/verify/artifact",
+			status: http.StatusMethodNotAllowed,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			method := tt.method
+			if method == "" {
+				method = http.MethodPost
+			}
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, httptest.NewRequest(method, tt.path, strings.NewReader(tt.body)))
+			if rec.Code != tt.status {
+				t.Fatalf("unexpected status: got %d, want %d: %s", rec.Code, tt.status, rec.Body)
+			}
+			if tt.code == "" {
+				return
+			}
+			var result struct {
+				APIVersion string        `json:"apiVersion"`
+				Result     report.Status `json:"result"`
+				Error      *report.Error `json:"error"`
+			}
+			if err := json.Unmarshal(rec.Body.Bytes(), &result); err != nil {
+				t.Fatal(err)
+			}
+			if result.APIVersion != APIVersionV2 || result.Result != report.StatusFailed ||
+				result.Error == nil || result.Error.Code != tt.code {
+				t.Errorf("unexpected result: %s", rec.Body)
+			}
+		})
+	}
+}
+
+func Test_batchHandlerV2(t *testing.T) {
+	t.Parallel()
+
+	r := mux.NewRouter()
+	RegisterV2(r, V2Options{})
+	hash := strings.Repeat("a", 64)
+	provenance := base64.StdEncoding.EncodeToString([]byte(`{"payloadType": "application/vnd.in-toto+json"}`))
+	body := `{"items": [
+		{"name": "no source", "artifactHash": "` + hash + `", "provenance": "` + provenance + `"},
+		{"source": "github.com/org/repo", "builderID": "https://example.com/builder",
+			"artifactHash": "` + hash + `", "provenance": "` + provenance + `"}
+	]}`
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/v2/verify:batch", strings.NewReader(body)))
+	if rec.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("unexpected status: got %d, want %d: %s", rec.Code, http.StatusUnprocessableEntity, rec.Body)
+	}
+	var result v2BatchResult
+	if err := json.Unmarshal(rec.Body.Bytes(), &result); err != nil {
+		t.Fatal(err)
+	}
+	if result.APIVersion != APIVersionV2 || result.Result.Result != report.StatusFailed ||
+		result.Passed != 0 || result.Failed != 2 || len(result.Items) != 2 {
+		t.Fatalf("unexpected result: %s", rec.Body)
+	}
+	for i, want := range []struct {
+		artifact string
+		code     string
+	}{
+		{"no source", "INVALID_FORMAT"},
+		{hash, "VERIFIER_NOT_SUPPORTED"},
+	} {
+		rep := result.Items[i]
+		if rep.Artifact != want.artifact || rep.Error == nil || rep.Error.Code != want.code {
+			t.Errorf("item %d: unexpected report: %+v", i, rep)
+		}
+	}
+}
+
+func Test_batchHandlerV2_timeout(t *testing.T) {
+	t.Parallel()
+
+	hash := strings.Repeat("a", 64)
+	provenance := base64.StdEncoding.EncodeToString([]byte(`{"payloadType": "application/vnd.in-toto+json"}`))
+	body := `{"items": [
+		{"source": "github.com/org/repo", "artifactHash": "` + hash + `", "provenance": "` + provenance + `"}
+	]}`
+	// The batch is not verified before the deadline of the request.
+	ctx, cancel := context.WithDeadline(context.Background(), time.Now())
+	defer cancel()
+	req := httptest.NewRequest(http.MethodPost, "/v2/verify:batch", strings.NewReader(body)).WithContext(ctx)
+
+	rec := httptest.NewRecorder()
+	batchHandlerV2(time.Minute)(rec, req)
+	if rec.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("unexpected status: got %d, want %d: %s", rec.Code, http.StatusUnprocessableEntity, rec.Body)
+	}
+	var result v2BatchResult
+	if err := json.Unmarshal(rec.Body.Bytes(), &result); err != nil {
+		t.Fatal(err)
+	}
+	if result.Failed != 1 || len(result.Items) != 1 || result.Items[0].Error == nil ||
+		result.Items[0].Error.Code != "UNAVAILABLE" {
+		t.Errorf("unexpected result: %s", rec.Body)
+	}
+}
+
+func Test_statusV2(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		err    error
+		status int
+	}{
+		{
+			name:   "passed",
+			status: http.StatusOK,
+		},
+		{
+			name:   "failed check",
+			err:    fmt.Errorf("%w: digest", serrors.ErrorMismatchHash),
+			status: http.StatusUnprocessableEntity,
+		},
+		{
+			name:   "registry failure",
+			err:    fmt.Errorf("%w: %w", serrors.ErrorImageHash, &transport.Error{StatusCode: http.StatusBadGateway}),
+			status: http.StatusBadGateway,
+		},
+		{
+			name:   "npm registry unavailable",
+			err:    fmt.Errorf("%w: %w: status 503", serrors.ErrorFetchAttestations, serrors.ErrorUnavailable),
+			status: http.StatusBadGateway,
+		},
+		{
+			name:   "timeout",
+			err:    fmt.Errorf("%w: %w", serrors.ErrorRekorSearch, context.DeadlineExceeded),
+			status: http.StatusServiceUnavailable,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if status := statusV2(tt.err); status != tt.status {
+				t.Errorf("unexpected status: got %d, want %d", status, tt.status)
+			}
+		})
+	}
+}
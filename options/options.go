@@ -1,6 +1,11 @@
 package options
 
-import "crypto"
+import (
+	"crypto"
+
+	rekorGenClient "github.com/sigstore/rekor/pkg/generated/client"
+	sigstoreRoot "github.com/sigstore/sigstore-go/pkg/root"
+)
 
 // ProvenanceOpts are the options for checking provenance information.
 type ProvenanceOpts struct {
@@ -8,9 +13,17 @@ type ProvenanceOpts struct {
 	// the invocation parameters.
 	ExpectedBranch *string
 
+	// ExpectedBranchPatterns are patterns, in the syntax of path.Match,
+	// one of which the branch must match.
+	ExpectedBranchPatterns []string
+
 	// ExpectedTag is the expected tag, github_ref, in the invocation parameters.
 	ExpectedTag *string
 
+	// ExpectedTagPatterns are patterns, in the syntax of path.Match,
+	// one of which the tag must match.
+	ExpectedTagPatterns []string
+
 	// ExpectedVersionedTag is the expected versioned tag.
 	ExpectedVersionedTag *string
 
@@ -20,6 +33,12 @@ type ProvenanceOpts struct {
 	// ExpectedSourceURI is the expected source URI in the provenance.
 	ExpectedSourceURI string
 
+	// AnySourceURI accepts any source repository, which is then taken from
+	// the signing certificate instead of ExpectedSourceURI. It is only
+	// supported for npm packages, to audit dependencies whose source
+	// repositories are not known in advance.
+	AnySourceURI bool
+
 	// ExpectedBuilderID is the expected builder ID that is passed from user and verified
 	ExpectedBuilderID string
 
@@ -32,6 +51,122 @@ type ProvenanceOpts struct {
 
 	// ExpectedProvenanceRepository is the provenance repository that is passed from user.
 	ExpectedProvenanceRepository *string
+
+	// ExpectedTaskRefs are patterns, in the syntax of path.Match, one of
+	// which the name of each pipeline or task referenced by a Tekton run
+	// must match.
+	ExpectedTaskRefs []string
+
+	// ExpectedSourceCommit is the expected git commit of the source, for
+	// builders that do not record its ref, e.g. Tekton Chains.
+	ExpectedSourceCommit *string
+
+	// SigstoreOpts configures how signatures are verified with Sigstore.
+	// If nil, the public-good Sigstore instance is used.
+	SigstoreOpts *SigstoreOpts
+
+	// GitHubOpts configures the GitHub instance that ran the build.
+	// If nil, github.com is used.
+	GitHubOpts *GitHubOpts
+
+	// GitLabOpts configures the GitLab instance that ran the build.
+	// If nil, gitlab.com is used.
+	GitLabOpts *GitLabOpts
+
+	// TrustedBuilders are reusable workflows trusted in addition to
+	// the built-in builders of the GHA verifier.
+	TrustedBuilders []TrustedBuilder
+
+	// VerificationOpts is the public key that signed the provenance,
+	// for builders that sign with a key instead of with Sigstore,
+	// e.g. Tekton Chains.
+	VerificationOpts *VerificationOpts
+}
+
+// TrustedBuilder is a reusable workflow trusted as a builder. It generates
+// the same provenance as one of the built-in builders, e.g. because it is
+// a copy of the built-in builder maintained by an organization.
+type TrustedBuilder struct {
+	// Workflow is the path of the reusable workflow on the GitHub instance,
+	// e.g. org/builders/.github/workflows/generator_generic_slsa3.yml.
+	Workflow string
+
+	// Builder is the ID of the built-in builder whose provenance the workflow
+	// generates. The workflow is trusted for the same artifacts as that builder.
+	Builder string
+
+	// RefPatterns are patterns, in the syntax of path.Match, one of which
+	// the ref of the workflow must match, e.g. refs/tags/v*. If empty,
+	// the ref must be a semantic version tag, as for the built-in builders.
+	RefPatterns []string
+}
+
+// GitHubOpts are the options of a GitHub Enterprise Server (GHES) instance.
+type GitHubOpts struct {
+	// Host is the hostname of the instance, e.g. github.example.com.
+	// If empty, github.com is used.
+	Host string
+
+	// OIDCIssuer is the issuer of the OIDC tokens of GitHub Actions on the
+	// instance. If empty, it is https://<Host>/_services/token.
+	OIDCIssuer string
+}
+
+// GitLabOpts are the options of the GitLab instance that ran the build.
+type GitLabOpts struct {
+	// Host is the hostname of a self-managed instance, e.g.
+	// gitlab.example.com. If empty, gitlab.com is used. The OIDC issuer
+	// of the jobs of the instance is https://<Host>.
+	Host string
+
+	// RunnerEnvironment is the expected environment of the runner of the
+	// job, gitlab-hosted or self-hosted. If empty, both are accepted.
+	RunnerEnvironment string
+}
+
+// SigstoreOpts are the options for verifying signatures with Sigstore.
+type SigstoreOpts struct {
+	// TrustedRootPath is the path to a trusted root in the Sigstore
+	// trusted_root.json format. If empty, the trusted root is fetched
+	// from the Sigstore TUF repository.
+	TrustedRootPath string
+
+	// Offline disables all requests to Sigstore services: the TUF repository
+	// and Rekor. Only Sigstore bundles can be verified, using the transparency
+	// log entry they contain. Requires TrustedRootPath.
+	Offline bool
+
+	// TUFMirrorURL is the URL of the TUF repository of a private Sigstore
+	// deployment, to fetch the trusted root from. Requires TUFRootPath.
+	TUFMirrorURL string
+
+	// TUFRootPath is the path to the initial root.json of TUFMirrorURL.
+	TUFRootPath string
+
+	// RekorURL is the URL of the Rekor instance to search for transparency
+	// log entries. If empty, the public-good Rekor instance is used.
+	RekorURL string
+
+	// RekorPublicKeyPaths are paths to PEM-encoded Rekor public keys.
+	// If set, they replace the Rekor keys of the trusted root.
+	RekorPublicKeyPaths []string
+
+	// CTLogPublicKeyPaths are paths to PEM-encoded CT log public keys.
+	// If set, they replace the CT log keys of the trusted root.
+	CTLogPublicKeyPaths []string
+
+	// FulcioCertificatePaths are paths to PEM-encoded Fulcio certificate
+	// chains. Self-signed certificates are roots, the others intermediates.
+	// If set, they replace the Fulcio certificate authorities of the trusted root.
+	FulcioCertificatePaths []string
+
+	// TrustedMaterial, if set, is the trusted root to verify signatures
+	// with, instead of the one of TrustedRootPath or of the TUF repository.
+	TrustedMaterial sigstoreRoot.TrustedMaterial `json:"-"`
+
+	// RekorClient, if set, is the client to search Rekor with, instead of
+	// a client of RekorURL.
+	RekorClient *rekorGenClient.Rekor `json:"-"`
 }
 
 // BuildOpts are the options for checking the builder.
@@ -65,3 +200,50 @@ type VerificationOpts struct {
 	// PublicKeyHashAlgo is the hash algorithm used to compute digest that was signed.
 	PublicKeyHashAlgo crypto.Hash
 }
+
+// VSAGenerationOpts are the options for generating a VSA after a successful
+// verification.
+type VSAGenerationOpts struct {
+	// Subjects are the verified artifacts.
+	Subjects []VSADescriptor
+
+	// VerifierID is the ID of the verifier that issues the VSA.
+	VerifierID string
+
+	// VerifierVersion is the version of slsa-verifier.
+	VerifierVersion string
+
+	// ResourceURI is the URI of the resource the artifacts were verified for.
+	ResourceURI string
+
+	// Policy is the policy the artifacts were verified against.
+	Policy VSADescriptor
+
+	// InputAttestations are the verified attestations.
+	InputAttestations []VSADescriptor
+
+	// VerifiedLevels are the levels reached, e.g. SLSA_BUILD_LEVEL_3.
+	VerifiedLevels []string
+}
+
+// VSADescriptor is a resource referenced by a VSA. Name is set for
+// subjects, URI for the other resources.
+type VSADescriptor struct {
+	Name string
+	URI  string
+
+	// Digest maps digest types, e.g. sha256, to digest values.
+	Digest map[string]string
+}
+
+// SigningOpts are the options for signing an Envelope.
+type SigningOpts struct {
+	// PrivateKey is the private key used to sign the Envelope.
+	PrivateKey crypto.PrivateKey
+
+	// PublicKeyID is the ID of the public key.
+	PublicKeyID string
+
+	// PrivateKeyHashAlgo is the hash algorithm used to compute the digest to sign.
+	PrivateKeyHashAlgo crypto.Hash
+}
@@ -0,0 +1,227 @@
+// Package policy implements declarative verification policies.
+//
+// A policy is a list of rules. Each rule matches artifacts by source
+// repository and/or artifact name, and lists the expectations that the
+// provenance of the matched artifacts must meet. The first matching rule
+// is compiled into options.ProvenanceOpts and options.BuilderOpts.
+//
+// Example:
+//
+//	version: 1
+//	rules:
+//	  - name: release-binaries
+//	    match:
+//	      sourceURI: github.com/org/repo
+//	      artifact: "*-linux-*"
+//	    builderIDs:
+//	      - https://github.com/slsa-framework/slsa-github-generator/.github/workflows/builder_go_slsa3.yml
+//	    tags: ["v*"]
+//	    versionedTag: ">=v1.2.0 <v2"
+//	  - name: default
+//	    builderIDs:
+//	      - https://github.com/slsa-framework/slsa-github-generator/.github/workflows/generator_generic_slsa3.yml
+//	    branches: [main, "release/*"]
+package policy
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+
+	serrors "github.com/slsa-framework/slsa-verifier/v2/errors"
+	"github.com/slsa-framework/slsa-verifier/v2/options"
+	"github.com/slsa-framework/slsa-verifier/v2/verifiers/utils"
+)
+
+// Version is the only supported version of the policy format.
+const Version = 1
+
+// Policy is a list of rules, evaluated in order.
+type Policy struct {
+	Version int    `json:"version"`
+	Rules   []Rule `json:"rules"`
+}
+
+// Match selects the artifacts a rule applies to. Both fields are patterns
+// in the syntax of path.Match. An empty field matches everything.
+type Match struct {
+	// SourceURI is matched against the expected source repository,
+	// e.g. github.com/org/* or github.com/org/repo.
+	SourceURI string `json:"sourceURI,omitempty"`
+
+	// Artifact is matched against the base name of artifact files,
+	// the repository of images, or the name of npm packages.
+	Artifact string `json:"artifact,omitempty"`
+}
+
+// Rule lists the expectations for the artifacts it matches.
+type Rule struct {
+	// Name identifies the rule in verification output.
+	Name  string `json:"name"`
+	Match Match  `json:"match,omitempty"`
+
+	// BuilderIDs are the allowed builder IDs. Verification succeeds
+	// if the provenance was generated by any of them. If empty, any
+	// trusted builder is accepted.
+	BuilderIDs []string `json:"builderIDs,omitempty"`
+
+	// Branches are patterns, one of which the source branch must match.
+	Branches []string `json:"branches,omitempty"`
+
+	// Tags are patterns, one of which the source tag must match.
+	Tags []string `json:"tags,omitempty"`
+
+	// VersionedTag is the expected semantic version of the source tag,
+	// as for --source-versioned-tag: v1 accepts any v1.x.y release,
+	// v1.2 any v1.2.x release. It may also be a version range, e.g.
+	// ">=v1.2.0 <v2", see utils.ValidateVersionedTag.
+	VersionedTag string `json:"versionedTag,omitempty"`
+
+	// WorkflowInputs are the required workflow inputs.
+	WorkflowInputs map[string]string `json:"workflowInputs,omitempty"`
+
+	// PackageName is the expected npm package name.
+	PackageName string `json:"packageName,omitempty"`
+}
+
+// Load reads and validates the policy at the given path.
+func Load(policyPath string) (*Policy, error) {
+	content, err := os.ReadFile(policyPath)
+	if err != nil {
+		return nil, err
+	}
+	return FromBytes(content)
+}
+
+// FromBytes parses and validates a YAML or JSON policy.
+func FromBytes(content []byte) (*Policy, error) {
+	var p Policy
+	if err := yaml.UnmarshalStrict(content, &p); err != nil {
+		return nil, fmt.Errorf("%w: %w", serrors.ErrorInvalidPolicy, err)
+	}
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// Validate checks the version of the policy and the patterns of its rules.
+// Policies returned by Load and FromBytes are already validated.
+func (p *Policy) Validate() error {
+	if p.Version != Version {
+		return fmt.Errorf("%w: unsupported version %d, expected %d", serrors.ErrorInvalidPolicy, p.Version, Version)
+	}
+	if len(p.Rules) == 0 {
+		return fmt.Errorf("%w: no rules", serrors.ErrorInvalidPolicy)
+	}
+	names := make(map[string]bool, len(p.Rules))
+	for i := range p.Rules {
+		r := &p.Rules[i]
+		if r.Name == "" {
+			return fmt.Errorf("%w: rule %d: empty name", serrors.ErrorInvalidPolicy, i)
+		}
+		if names[r.Name] {
+			return fmt.Errorf("%w: rule %q: duplicate name", serrors.ErrorInvalidPolicy, r.Name)
+		}
+		names[r.Name] = true
+
+		patterns := append([]string{r.Match.SourceURI, r.Match.Artifact}, r.Branches...)
+		patterns = append(patterns, r.Tags...)
+		if err := utils.ValidatePatterns(patterns); err != nil {
+			return fmt.Errorf("%w: rule %q: %w", serrors.ErrorInvalidPolicy, r.Name, err)
+		}
+		if len(r.Tags) > 0 && r.VersionedTag != "" {
+			return fmt.Errorf("%w: rule %q: tags and versionedTag are mutually exclusive", serrors.ErrorInvalidPolicy, r.Name)
+		}
+		if r.VersionedTag != "" {
+			if err := utils.ValidateVersionedTag(r.VersionedTag); err != nil {
+				return fmt.Errorf("%w: rule %q: %w", serrors.ErrorInvalidPolicy, r.Name, err)
+			}
+		}
+		for _, id := range r.BuilderIDs {
+			if id == "" {
+				return fmt.Errorf("%w: rule %q: empty builder ID", serrors.ErrorInvalidPolicy, r.Name)
+			}
+		}
+	}
+	return nil
+}
+
+// Match returns the first rule that matches the source URI and artifact name.
+// An empty sourceURI matches all rules: the expected source URI is then
+// taken from the rule. See Rule.ProvenanceOpts.
+func (p *Policy) Match(sourceURI, artifact string) (*Rule, error) {
+	for i := range p.Rules {
+		r := &p.Rules[i]
+		if sourceURI != "" && r.Match.SourceURI != "" &&
+			!match(normalizeSourceURI(r.Match.SourceURI), normalizeSourceURI(sourceURI)) {
+			continue
+		}
+		if r.Match.Artifact != "" && !match(r.Match.Artifact, artifact) {
+			continue
+		}
+		return r, nil
+	}
+	return nil, fmt.Errorf("%w: source %q, artifact %q", serrors.ErrorNoMatchingPolicyRule, sourceURI, artifact)
+}
+
+func match(pattern, value string) bool {
+	// Patterns were validated when the policy was loaded.
+	ok, _ := path.Match(pattern, value)
+	return ok
+}
+
+// normalizeSourceURI strips the scheme, so that github.com/org/repo
+// and https://github.com/org/repo are equivalent.
+func normalizeSourceURI(uri string) string {
+	uri = strings.TrimPrefix(uri, "git+")
+	if i := strings.Index(uri, "://"); i >= 0 {
+		uri = uri[i+len("://"):]
+	}
+	return uri
+}
+
+// ProvenanceOpts compiles the rule into provenance options. sourceURI
+// is the expected source repository given by the user. If it is empty,
+// the rule must match a single repository, which is used instead.
+func (r *Rule) ProvenanceOpts(sourceURI, digest string) (*options.ProvenanceOpts, error) {
+	if sourceURI == "" {
+		if r.Match.SourceURI == "" || strings.ContainsAny(r.Match.SourceURI, `*?[\`) {
+			return nil, fmt.Errorf("%w: rule %q: no expected source URI: provide one or match a single repository",
+				serrors.ErrorInvalidPolicy, r.Name)
+		}
+		sourceURI = r.Match.SourceURI
+	}
+	opts := &options.ProvenanceOpts{
+		ExpectedSourceURI:      sourceURI,
+		ExpectedDigest:         digest,
+		ExpectedBranchPatterns: r.Branches,
+		ExpectedTagPatterns:    r.Tags,
+		ExpectedWorkflowInputs: r.WorkflowInputs,
+	}
+	if r.VersionedTag != "" {
+		versionedTag := r.VersionedTag
+		opts.ExpectedVersionedTag = &versionedTag
+	}
+	if r.PackageName != "" {
+		packageName := r.PackageName
+		opts.ExpectedPackageName = &packageName
+	}
+	return opts, nil
+}
+
+// BuilderOpts compiles the rule into builder options, one for each
+// allowed builder ID. Verification succeeds if it succeeds with any of them.
+func (r *Rule) BuilderOpts() []*options.BuilderOpts {
+	if len(r.BuilderIDs) == 0 {
+		return []*options.BuilderOpts{{}}
+	}
+	opts := make([]*options.BuilderOpts, 0, len(r.BuilderIDs))
+	for i := range r.BuilderIDs {
+		opts = append(opts, &options.BuilderOpts{ExpectedID: &r.BuilderIDs[i]})
+	}
+	return opts
+}
@@ -0,0 +1,301 @@
+package policy
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+
+	serrors "github.com/slsa-framework/slsa-verifier/v2/errors"
+	"github.com/slsa-framework/slsa-verifier/v2/options"
+)
+
+func Test_FromBytes(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		content string
+		err     error
+	}{
+		{
+			name: "valid",
+			content: `
+version: 1
+rules:
+  - name: default
+    branches: [main]
+`,
+		},
+		{
+			name:    "valid json",
+			content: `{"version": 1, "rules": [{"name": "default", "tags": ["v*"]}]}`,
+		},
+		{
+			name: "unsupported version",
+			content: `
+version: 2
+rules:
+  - name: default
+`,
+			err: serrors.ErrorInvalidPolicy,
+		},
+		{
+			name:    "no rules",
+			content: `version: 1`,
+			err:     serrors.ErrorInvalidPolicy,
+		},
+		{
+			name: "unknown field",
+			content: `
+version: 1
+rules:
+  - name: default
+    branch: main
+`,
+			err: serrors.ErrorInvalidPolicy,
+		},
+		{
+			name: "empty name",
+			content: `
+version: 1
+rules:
+  - branches: [main]
+`,
+			err: serrors.ErrorInvalidPolicy,
+		},
+		{
+			name: "duplicate name",
+			content: `
+version: 1
+rules:
+  - name: default
+  - name: default
+`,
+			err: serrors.ErrorInvalidPolicy,
+		},
+		{
+			name: "invalid pattern",
+			content: `
+version: 1
+rules:
+  - name: default
+    branches: ["[main"]
+`,
+			err: serrors.ErrorInvalidPolicy,
+		},
+		{
+			name: "tags and versioned tag",
+			content: `
+version: 1
+rules:
+  - name: default
+    tags: ["v*"]
+    versionedTag: v1
+`,
+			err: serrors.ErrorInvalidPolicy,
+		},
+		{
+			name: "versioned tag range",
+			content: `
+version: 1
+rules:
+  - name: default
+    versionedTag: ">=v1.2.0 <v2"
+`,
+		},
+		{
+			name: "invalid versioned tag",
+			content: `
+version: 1
+rules:
+  - name: default
+    versionedTag: 1.2
+`,
+			err: serrors.ErrorInvalidPolicy,
+		},
+		{
+			name: "invalid versioned tag range",
+			content: `
+version: 1
+rules:
+  - name: default
+    versionedTag: ">=v1.2.0 ~v2"
+`,
+			err: serrors.ErrorInvalidPolicy,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := FromBytes([]byte(tt.content))
+			if !cmp.Equal(err, tt.err, cmpopts.EquateErrors()) {
+				t.Errorf("unexpected error: %v", cmp.Diff(err, tt.err, cmpopts.EquateErrors()))
+			}
+		})
+	}
+}
+
+func Test_Match(t *testing.T) {
+	t.Parallel()
+
+	p, err := Load(filepath.Join("testdata", "policy.yaml"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		sourceURI string
+		artifact  string
+		expected  string
+		err       error
+	}{
+		{
+			name:      "first rule",
+			sourceURI: "github.com/slsa-framework/slsa-verifier",
+			artifact:  "slsa-verifier-linux-amd64",
+			expected:  "release-binaries",
+		},
+		{
+			name:      "scheme is ignored",
+			sourceURI: "https://github.com/slsa-framework/slsa-verifier",
+			artifact:  "slsa-verifier-linux-amd64",
+			expected:  "release-binaries",
+		},
+		{
+			name:      "first match wins",
+			sourceURI: "github.com/slsa-framework/slsa-verifier",
+			artifact:  "ghcr.io/slsa-framework/slsa-verifier",
+			expected:  "org-images",
+		},
+		{
+			name:     "no source uri",
+			artifact: "slsa-verifier-linux-amd64",
+			expected: "release-binaries",
+		},
+		{
+			name:      "artifact only",
+			sourceURI: "github.com/other/repo",
+			artifact:  "@slsa-framework/pkg",
+			expected:  "npm",
+		},
+		{
+			name:      "no match",
+			sourceURI: "github.com/other/repo",
+			artifact:  "slsa-verifier-linux-amd64",
+			err:       serrors.ErrorNoMatchingPolicyRule,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			r, err := p.Match(tt.sourceURI, tt.artifact)
+			if !cmp.Equal(err, tt.err, cmpopts.EquateErrors()) {
+				t.Fatalf("unexpected error: %v", cmp.Diff(err, tt.err, cmpopts.EquateErrors()))
+			}
+			if err != nil {
+				return
+			}
+			if r.Name != tt.expected {
+				t.Errorf("expected rule %q, got %q", tt.expected, r.Name)
+			}
+		})
+	}
+}
+
+func Test_Compile(t *testing.T) {
+	t.Parallel()
+
+	p, err := Load(filepath.Join("testdata", "policy.yaml"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	versionedTag := "v1"
+	packageName := "@slsa-framework/pkg"
+	goBuilder := "https://github.com/slsa-framework/slsa-github-generator/.github/workflows/builder_go_slsa3.yml"
+	genericBuilder := "https://github.com/slsa-framework/slsa-github-generator/.github/workflows/generator_generic_slsa3.yml"
+	nodejsBuilder := "https://github.com/slsa-framework/slsa-github-generator/.github/workflows/builder_nodejs_slsa3.yml"
+
+	tests := []struct {
+		name           string
+		rule           int
+		sourceURI      string
+		provenanceOpts *options.ProvenanceOpts
+		builderOpts    []*options.BuilderOpts
+		err            error
+	}{
+		{
+			name: "source uri from rule",
+			rule: 0,
+			provenanceOpts: &options.ProvenanceOpts{
+				ExpectedSourceURI:      "github.com/slsa-framework/slsa-verifier",
+				ExpectedDigest:         "abcd",
+				ExpectedTagPatterns:    []string{"v*"},
+				ExpectedWorkflowInputs: map[string]string{"release": "true"},
+			},
+			builderOpts: []*options.BuilderOpts{
+				{ExpectedID: &goBuilder},
+				{ExpectedID: &genericBuilder},
+			},
+		},
+		{
+			name:      "source uri from user",
+			rule:      1,
+			sourceURI: "github.com/slsa-framework/example",
+			provenanceOpts: &options.ProvenanceOpts{
+				ExpectedSourceURI:      "github.com/slsa-framework/example",
+				ExpectedDigest:         "abcd",
+				ExpectedBranchPatterns: []string{"main", "release/*"},
+			},
+			builderOpts: []*options.BuilderOpts{{}},
+		},
+		{
+			name: "source uri pattern",
+			rule: 1,
+			err:  serrors.ErrorInvalidPolicy,
+		},
+		{
+			name:      "versioned tag and package name",
+			rule:      2,
+			sourceURI: "github.com/slsa-framework/example",
+			provenanceOpts: &options.ProvenanceOpts{
+				ExpectedSourceURI:    "github.com/slsa-framework/example",
+				ExpectedDigest:       "abcd",
+				ExpectedVersionedTag: &versionedTag,
+				ExpectedPackageName:  &packageName,
+			},
+			builderOpts: []*options.BuilderOpts{
+				{ExpectedID: &nodejsBuilder},
+			},
+		},
+		{
+			name: "no source uri",
+			rule: 2,
+			err:  serrors.ErrorInvalidPolicy,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			r := &p.Rules[tt.rule]
+			provenanceOpts, err := r.ProvenanceOpts(tt.sourceURI, "abcd")
+			if !cmp.Equal(err, tt.err, cmpopts.EquateErrors()) {
+				t.Fatalf("unexpected error: %v", cmp.Diff(err, tt.err, cmpopts.EquateErrors()))
+			}
+			if err != nil {
+				return
+			}
+			if diff := cmp.Diff(tt.provenanceOpts, provenanceOpts); diff != "" {
+				t.Errorf("unexpected provenance options (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tt.builderOpts, r.BuilderOpts()); diff != "" {
+				t.Errorf("unexpected builder options (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package policy
+
+import (
+	"fmt"
+	"os"
+
+	"sigs.k8s.io/yaml"
+
+	serrors "github.com/slsa-framework/slsa-verifier/v2/errors"
+	"github.com/slsa-framework/slsa-verifier/v2/options"
+	"github.com/slsa-framework/slsa-verifier/v2/verifiers/utils"
+)
+
+// TrustConfig lists the reusable workflows trusted as builders,
+// in addition to the built-in builders of the verifier.
+//
+// Example:
+//
+//	version: 1
+//	trustedBuilders:
+//	  - workflow: org/builders/.github/workflows/generator_generic_slsa3.yml
+//	    builder: https://github.com/slsa-framework/slsa-github-generator/.github/workflows/generator_generic_slsa3.yml
+//	  - workflow: org/builders/.github/workflows/delegator_generic_slsa3.yml
+//	    builder: https://github.com/slsa-framework/slsa-github-generator/.github/workflows/delegator_generic_slsa3.yml
+//	    refs: ["refs/tags/v*"]
+type TrustConfig struct {
+	Version         int              `json:"version"`
+	TrustedBuilders []TrustedBuilder `json:"trustedBuilders"`
+}
+
+// TrustedBuilder is a reusable workflow trusted as a builder.
+// See options.TrustedBuilder.
+type TrustedBuilder struct {
+	// Workflow is the path of the reusable workflow on the GitHub instance.
+	Workflow string `json:"workflow"`
+
+	// Builder is the ID of the built-in builder whose provenance
+	// the workflow generates.
+	Builder string `json:"builder"`
+
+	// Refs are patterns, one of which the ref of the workflow must match.
+	// If empty, the ref must be a semantic version tag.
+	Refs []string `json:"refs,omitempty"`
+}
+
+// LoadTrustConfig reads and validates the trust configuration at the given path.
+func LoadTrustConfig(configPath string) (*TrustConfig, error) {
+	content, err := os.ReadFile(configPath)
+	if err != nil {
+		return nil, err
+	}
+	return TrustConfigFromBytes(content)
+}
+
+// TrustConfigFromBytes parses and validates a YAML or JSON trust configuration.
+func TrustConfigFromBytes(content []byte) (*TrustConfig, error) {
+	var c TrustConfig
+	if err := yaml.UnmarshalStrict(content, &c); err != nil {
+		return nil, fmt.Errorf("%w: %w", serrors.ErrorInvalidPolicy, err)
+	}
+	if err := c.validate(); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+func (c *TrustConfig) validate() error {
+	if c.Version != Version {
+		return fmt.Errorf("%w: unsupported trust configuration version %d, expected %d",
+			serrors.ErrorInvalidPolicy, c.Version, Version)
+	}
+	for i := range c.TrustedBuilders {
+		b := &c.TrustedBuilders[i]
+		if b.Workflow == "" || b.Builder == "" {
+			return fmt.Errorf("%w: trusted builder %d: empty workflow or builder", serrors.ErrorInvalidPolicy, i)
+		}
+		if err := utils.ValidatePatterns(b.Refs); err != nil {
+			return fmt.Errorf("%w: trusted builder %q: %w", serrors.ErrorInvalidPolicy, b.Workflow, err)
+		}
+	}
+	return nil
+}
+
+// Options returns the trusted builders as verification options.
+func (c *TrustConfig) Options() []options.TrustedBuilder {
+	builders := make([]options.TrustedBuilder, 0, len(c.TrustedBuilders))
+	for _, b := range c.TrustedBuilders {
+		builders = append(builders, options.TrustedBuilder{
+			Workflow:    b.Workflow,
+			Builder:     b.Builder,
+			RefPatterns: b.Refs,
+		})
+	}
+	return builders
+}
@@ -0,0 +1,109 @@
+package policy
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+
+	serrors "github.com/slsa-framework/slsa-verifier/v2/errors"
+	"github.com/slsa-framework/slsa-verifier/v2/options"
+)
+
+func Test_TrustConfigFromBytes(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		content string
+		err     error
+	}{
+		{
+			name: "valid",
+			content: `
+version: 1
+trustedBuilders:
+  - workflow: org/builders/.github/workflows/build.yml
+    builder: https://github.com/slsa-framework/slsa-github-generator/.github/workflows/builder_go_slsa3.yml
+    refs: ["refs/tags/v*"]
+`,
+		},
+		{
+			name:    "no builders",
+			content: `version: 1`,
+		},
+		{
+			name: "unsupported version",
+			content: `
+version: 2
+trustedBuilders: []
+`,
+			err: serrors.ErrorInvalidPolicy,
+		},
+		{
+			name: "unknown field",
+			content: `
+version: 1
+trustedBuilders:
+  - workflow: org/builders/.github/workflows/build.yml
+    builder: https://github.com/slsa-framework/slsa-github-generator/.github/workflows/builder_go_slsa3.yml
+    ref: refs/tags/v1.0.0
+`,
+			err: serrors.ErrorInvalidPolicy,
+		},
+		{
+			name: "empty builder",
+			content: `
+version: 1
+trustedBuilders:
+  - workflow: org/builders/.github/workflows/build.yml
+`,
+			err: serrors.ErrorInvalidPolicy,
+		},
+		{
+			name: "invalid pattern",
+			content: `
+version: 1
+trustedBuilders:
+  - workflow: org/builders/.github/workflows/build.yml
+    builder: https://github.com/slsa-framework/slsa-github-generator/.github/workflows/builder_go_slsa3.yml
+    refs: ["refs/tags/[v"]
+`,
+			err: serrors.ErrorInvalidPolicy,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := TrustConfigFromBytes([]byte(tt.content))
+			if !cmp.Equal(err, tt.err, cmpopts.EquateErrors()) {
+				t.Errorf("unexpected error: %v", cmp.Diff(err, tt.err, cmpopts.EquateErrors()))
+			}
+		})
+	}
+}
+
+func Test_TrustConfigOptions(t *testing.T) {
+	t.Parallel()
+
+	c, err := LoadTrustConfig(filepath.Join("testdata", "trust.yaml"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []options.TrustedBuilder{
+		{
+			Workflow: "org/builders/.github/workflows/generator_generic_slsa3.yml",
+			Builder:  "https://github.com/slsa-framework/slsa-github-generator/.github/workflows/generator_generic_slsa3.yml",
+		},
+		{
+			Workflow:    "org/builders/.github/workflows/delegator_generic_slsa3.yml",
+			Builder:     "https://github.com/slsa-framework/slsa-github-generator/.github/workflows/delegator_generic_slsa3.yml",
+			RefPatterns: []string{"refs/tags/v*", "refs/heads/release/*"},
+		},
+	}
+	if diff := cmp.Diff(expected, c.Options()); diff != "" {
+		t.Errorf("unexpected trusted builders (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,542 @@
+// Copyright 2026 SLSA Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package report records the outcome of a verification in a
+// machine-readable form.
+package report
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"slices"
+	"sync"
+	"time"
+
+	serrors "github.com/slsa-framework/slsa-verifier/v2/errors"
+)
+
+// SchemaVersion is the version of the JSON document written by Write.
+const SchemaVersion = "1"
+
+// Status is the outcome of a check or of a whole verification.
+type Status string
+
+const (
+	StatusPassed Status = "PASSED"
+	StatusFailed Status = "FAILED"
+	// StatusUnverified is for artifacts without provenance to verify.
+	StatusUnverified Status = "UNVERIFIED"
+	// StatusAllowlisted is for artifacts without provenance that are
+	// allowed to lack it.
+	StatusAllowlisted Status = "ALLOWLISTED"
+)
+
+// Names of the checks performed by the verifiers.
+const (
+	CheckSignature          = "signature"
+	CheckBuilderID          = "builder-id"
+	CheckSourceURI          = "source-uri"
+	CheckSubjectDigest      = "subject-digest"
+	CheckBranch             = "branch"
+	CheckTag                = "tag"
+	CheckVersionedTag       = "versioned-tag"
+	CheckWorkflowInputs     = "workflow-inputs"
+	CheckPackageName        = "package-name"
+	CheckPackageVersion     = "package-version"
+	CheckAttestationHeaders = "attestation-headers"
+	CheckMetadata           = "metadata"
+	CheckSummary            = "summary"
+	CheckTextProvenance     = "text-provenance"
+	CheckVerifierID         = "verifier-id"
+	CheckResourceURI        = "resource-uri"
+	CheckVerificationResult = "verification-result"
+	CheckVerifiedLevels     = "verified-levels"
+	CheckManifest           = "manifest"
+	CheckLocalDigest        = "local-digest"
+	CheckTaskRefs           = "task-refs"
+	CheckPlatforms          = "platforms"
+)
+
+// Stages of the verification, timed by the verifiers. See Report.Time.
+// The stages do not overlap: the signature stage excludes the Rekor lookups
+// it requires, which are timed as the rekor stage.
+const (
+	StageSignature  = "signature"
+	StageRekor      = "rekor"
+	StageProvenance = "provenance"
+)
+
+// Origins of the trust in the builder. See Report.BuilderTrust.
+const (
+	// BuilderTrustBuiltIn is for the builders trusted by the verifier.
+	BuilderTrustBuiltIn = "built-in"
+	// BuilderTrustCustom is for the builders trusted by the user.
+	BuilderTrustCustom = "custom"
+	// BuilderTrustWorkflow is for the workflows that sign the provenance of
+	// their own builds, e.g. with GitHub artifact attestations.
+	BuilderTrustWorkflow = "workflow"
+)
+
+// Check is the outcome of a single check.
+type Check struct {
+	Name   string `json:"name"`
+	Status Status `json:"status"`
+}
+
+// Error describes why a verification failed.
+type Error struct {
+	// Code is a stable, machine-readable code. See serrors.Code.
+	Code    string `json:"code"`
+	Message string `json:"message"`
+
+	// Check, Expected, Actual and Remediation are set from the
+	// serrors.VerificationError wrapped by the error, if any.
+	Check       string `json:"check,omitempty"`
+	Expected    string `json:"expected,omitempty"`
+	Actual      string `json:"actual,omitempty"`
+	Remediation string `json:"remediation,omitempty"`
+}
+
+// NewError returns the description of err.
+func NewError(err error) *Error {
+	if err == nil {
+		return nil
+	}
+	e := &Error{
+		Code:        serrors.Code(err),
+		Message:     err.Error(),
+		Remediation: serrors.Remediation(err),
+	}
+	var verr *serrors.VerificationError
+	if errors.As(err, &verr) {
+		e.Check = verr.Check
+		e.Expected = verr.Expected
+		e.Actual = verr.Actual
+		if verr.Remediation != "" {
+			e.Remediation = verr.Remediation
+		}
+	}
+	return e
+}
+
+// Report is the result of the verification of a single artifact.
+// All methods are safe to call on a nil *Report, in which case they
+// do nothing. This lets verifiers record checks unconditionally.
+type Report struct {
+	mu sync.Mutex
+
+	Artifact      string          `json:"artifact"`
+	Digest        string          `json:"digest,omitempty"`
+	Result        Status          `json:"result"`
+	BuilderID     string          `json:"builderID,omitempty"`
+	BuilderTrust  string          `json:"builderTrust,omitempty"`
+	SourceURI     string          `json:"sourceURI,omitempty"`
+	SourceCommit  string          `json:"sourceCommit,omitempty"`
+	SourceRef     string          `json:"sourceRef,omitempty"`
+	PolicyRule    string          `json:"policyRule,omitempty"`
+	RekorLogIndex *int64          `json:"rekorLogIndex,omitempty"`
+	Checks        []Check         `json:"checks"`
+	Manifest      *Manifest       `json:"manifest,omitempty"`
+	Platforms     []*Platform     `json:"platforms,omitempty"`
+	Error         *Error          `json:"error,omitempty"`
+	Provenance    json.RawMessage `json:"provenance,omitempty"`
+
+	// durations is the time spent in each stage. It is not part of the
+	// JSON document, but is exported as metrics by services.
+	durations map[string]time.Duration
+	// timers are the stages being timed, innermost last.
+	timers []*timer
+	// signer and rekorEntry describe the verified signature. They are not
+	// part of the JSON document, but are returned by package verifier.
+	signer     *Signer
+	rekorEntry *RekorEntry
+}
+
+// Signer is the identity of the certificate that signed the provenance.
+type Signer struct {
+	// Workflow is the URI of the workflow that signed, with its ref.
+	Workflow string
+	// Issuer is the OIDC issuer of the identity token of the workflow.
+	Issuer string
+	// SAN is the subject alternative name of the certificate.
+	SAN string
+}
+
+// RekorEntry identifies the transparency log entry of a signature.
+type RekorEntry struct {
+	LogIndex int64
+	// UUID is the hex-encoded leaf hash of the entry.
+	UUID string
+	// IntegratedTime is the Unix time the entry was added to the log.
+	IntegratedTime int64
+	// LogID is the hex-encoded ID of the log.
+	LogID string
+}
+
+// Manifest is the comparison of the entries of a checksums manifest with
+// the subjects of the provenance, by name.
+type Manifest struct {
+	// Entries is the number of entries of the manifest.
+	Entries int `json:"entries"`
+	// Missing are the entries that are not subjects.
+	Missing []string `json:"missing,omitempty"`
+	// Mismatched are the entries whose digest differs from the subject's.
+	Mismatched []string `json:"mismatched,omitempty"`
+	// Extra are the subjects that are not entries.
+	Extra []string `json:"extra,omitempty"`
+	// LocalMismatched are the entries whose local file has another digest.
+	LocalMismatched []string `json:"localMismatched,omitempty"`
+}
+
+// Platform is the result of the verification of a platform image of an
+// image index.
+type Platform struct {
+	// Platform is the platform of the image, e.g. linux/amd64.
+	Platform string `json:"platform"`
+	Digest   string `json:"digest"`
+	Result   Status `json:"result"`
+	// Index is true if the image is a subject of the provenance of the index.
+	Index        bool   `json:"index,omitempty"`
+	BuilderID    string `json:"builderID,omitempty"`
+	SourceCommit string `json:"sourceCommit,omitempty"`
+	Error        *Error `json:"error,omitempty"`
+}
+
+// New creates a report for the given artifact and digest.
+func New(artifact, digest string) *Report {
+	return &Report{
+		Artifact: artifact,
+		Digest:   digest,
+		Checks:   []Check{},
+	}
+}
+
+// Check records the outcome of the named check and returns err as the
+// failure of the check, see serrors.WithCheck. A check that failed once
+// stays failed.
+func (r *Report) Check(name string, err error) error {
+	err = serrors.WithCheck(err, name)
+	if r == nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	status := StatusPassed
+	if err != nil {
+		status = StatusFailed
+	}
+	r.record(name, status)
+	return err
+}
+
+func (r *Report) record(name string, status Status) {
+	for i := range r.Checks {
+		if r.Checks[i].Name == name {
+			if status == StatusFailed {
+				r.Checks[i].Status = status
+			}
+			return
+		}
+	}
+	r.Checks = append(r.Checks, Check{Name: name, Status: status})
+}
+
+// SetSource records the source repository, commit and ref
+// the artifact was built from.
+func (r *Report) SetSource(uri, commit, ref string) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.SourceURI = uri
+	r.SourceCommit = commit
+	r.SourceRef = ref
+}
+
+// SetRekorLogIndex records the index of the transparency log entry.
+func (r *Report) SetRekorLogIndex(index int64) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.RekorLogIndex = &index
+}
+
+// SetSigner records the identity of the signing certificate.
+func (r *Report) SetSigner(s Signer) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.signer = &s
+}
+
+// Signer returns the identity recorded with SetSigner, or nil.
+func (r *Report) Signer() *Signer {
+	if r == nil {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.signer
+}
+
+// SetRekorEntry records the transparency log entry, and its index as with
+// SetRekorLogIndex.
+func (r *Report) SetRekorEntry(e RekorEntry) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	index := e.LogIndex
+	r.RekorLogIndex = &index
+	r.rekorEntry = &e
+}
+
+// RekorEntry returns the entry recorded with SetRekorEntry, or nil.
+func (r *Report) RekorEntry() *RekorEntry {
+	if r == nil {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.rekorEntry
+}
+
+// SetBuilderTrust records whether the builder is trusted by the verifier
+// or by the user: BuilderTrustBuiltIn, BuilderTrustCustom or
+// BuilderTrustWorkflow.
+func (r *Report) SetBuilderTrust(trust string) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.BuilderTrust = trust
+}
+
+// SetManifest records the comparison of a checksums manifest with the
+// subjects of the provenance.
+func (r *Report) SetManifest(m *Manifest) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.Manifest = m
+}
+
+// AddPlatform records the result of the verification of a platform image.
+func (r *Report) AddPlatform(p *Platform) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.Platforms = append(r.Platforms, p)
+}
+
+// timer is a stage being timed.
+type timer struct {
+	start time.Time
+	// nested is the time spent in the stages timed while this one is.
+	nested time.Duration
+}
+
+// Time starts timing the named stage and returns the function that stops
+// it. The durations of a stage timed several times add up. The time spent
+// in a stage timed while another one is, e.g. the Rekor lookups of the
+// signature verification, only counts for the inner stage.
+func (r *Report) Time(stage string) func() {
+	if r == nil {
+		return func() {}
+	}
+	t := &timer{start: time.Now()}
+	r.mu.Lock()
+	r.timers = append(r.timers, t)
+	r.mu.Unlock()
+	return func() {
+		d := time.Since(t.start)
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		i := slices.Index(r.timers, t)
+		if i < 0 {
+			// Already stopped.
+			return
+		}
+		r.timers = slices.Delete(r.timers, i, i+1)
+		r.addDuration(stage, d-t.nested)
+		r.excludeDuration(d)
+	}
+}
+
+func (r *Report) addDuration(stage string, d time.Duration) {
+	if r.durations == nil {
+		r.durations = make(map[string]time.Duration)
+	}
+	r.durations[stage] += d
+}
+
+// excludeDuration excludes d, spent in a nested stage, from the duration
+// of the innermost stage being timed, if any.
+func (r *Report) excludeDuration(d time.Duration) {
+	if len(r.timers) > 0 {
+		r.timers[len(r.timers)-1].nested += d
+	}
+}
+
+// Durations returns the time spent in each stage timed with Time.
+func (r *Report) Durations() map[string]time.Duration {
+	if r == nil {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	durations := make(map[string]time.Duration, len(r.durations))
+	for stage, d := range r.durations {
+		durations[stage] = d
+	}
+	return durations
+}
+
+// Child returns an empty report for the same artifact. It is used
+// when a verifier tries several attestations, so that only the checks of
+// the attestation that is eventually selected end up in r. See Merge.
+func (r *Report) Child() *Report {
+	if r == nil {
+		return nil
+	}
+	return New(r.Artifact, r.Digest)
+}
+
+// Merge copies the checks, the source information and the signature
+// recorded in child into r, and adds the durations of its stages to those of r.
+func (r *Report) Merge(child *Report) {
+	if r == nil || child == nil {
+		return
+	}
+	child.mu.Lock()
+	defer child.mu.Unlock()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, c := range child.Checks {
+		r.record(c.Name, c.Status)
+	}
+	if child.SourceURI != "" || child.SourceCommit != "" || child.SourceRef != "" {
+		r.SourceURI = child.SourceURI
+		r.SourceCommit = child.SourceCommit
+		r.SourceRef = child.SourceRef
+	}
+	if child.RekorLogIndex != nil {
+		index := *child.RekorLogIndex
+		r.RekorLogIndex = &index
+	}
+	if child.signer != nil {
+		r.signer = child.signer
+	}
+	if child.rekorEntry != nil {
+		r.rekorEntry = child.rekorEntry
+	}
+	if child.BuilderTrust != "" {
+		r.BuilderTrust = child.BuilderTrust
+	}
+	for stage, d := range child.durations {
+		r.addDuration(stage, d)
+		r.excludeDuration(d)
+	}
+}
+
+// Finish sets the final result of the verification. builderID is
+// the verified builder ID, and err the error returned by the verifier.
+func (r *Report) Finish(builderID string, err error) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err != nil {
+		r.Result = StatusFailed
+		r.Error = NewError(err)
+		return
+	}
+	r.Result = StatusPassed
+	r.BuilderID = builderID
+}
+
+// FinishUnverified records that the artifact has no provenance to verify,
+// for the reason err. The result is StatusAllowlisted if the artifact is
+// allowed to lack provenance.
+func (r *Report) FinishUnverified(allowlisted bool, err error) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.Result = StatusUnverified
+	if allowlisted {
+		r.Result = StatusAllowlisted
+	}
+	r.Error = NewError(err)
+}
+
+// Document is the top-level JSON document written by Write.
+type Document struct {
+	Version   string    `json:"version"`
+	Result    Status    `json:"result"`
+	Artifacts []*Report `json:"artifacts"`
+}
+
+// Write writes the reports to w as a single JSON document.
+// The overall result is PASSED only if there is at least one report
+// and all the reports passed or are allowlisted.
+func Write(w io.Writer, reports []*Report) error {
+	doc := Document{
+		Version:   SchemaVersion,
+		Result:    StatusPassed,
+		Artifacts: reports,
+	}
+	if len(reports) == 0 {
+		doc.Result = StatusFailed
+		doc.Artifacts = []*Report{}
+	}
+	for _, r := range reports {
+		if r.Result != StatusPassed && r.Result != StatusAllowlisted {
+			doc.Result = StatusFailed
+		}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(doc)
+}
+
+type contextKey struct{}
+
+// NewContext returns a copy of ctx that carries r.
+func NewContext(ctx context.Context, r *Report) context.Context {
+	return context.WithValue(ctx, contextKey{}, r)
+}
+
+// FromContext returns the report carried by ctx, or nil if there is none.
+func FromContext(ctx context.Context) *Report {
+	r, _ := ctx.Value(contextKey{}).(*Report)
+	return r
+}
@@ -0,0 +1,263 @@
+package report
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+
+	serrors "github.com/slsa-framework/slsa-verifier/v2/errors"
+)
+
+func Test_Check(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		results  []error
+		expected Status
+	}{
+		{
+			name:     "passed",
+			results:  []error{nil},
+			expected: StatusPassed,
+		},
+		{
+			name:     "failed",
+			results:  []error{serrors.ErrorMismatchBranch},
+			expected: StatusFailed,
+		},
+		{
+			name:     "failed then passed",
+			results:  []error{serrors.ErrorMismatchBranch, nil},
+			expected: StatusFailed,
+		},
+		{
+			name:     "passed then failed",
+			results:  []error{nil, serrors.ErrorMismatchBranch},
+			expected: StatusFailed,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			r := New("artifact", "sha256:abcd")
+			for _, result := range tt.results {
+				if err := r.Check(CheckBranch, result); !errors.Is(err, result) {
+					t.Errorf("unexpected error: %v", err)
+				}
+			}
+			expected := []Check{{Name: CheckBranch, Status: tt.expected}}
+			if diff := cmp.Diff(expected, r.Checks); diff != "" {
+				t.Errorf("unexpected checks (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_NilReport(t *testing.T) {
+	t.Parallel()
+
+	// Verifiers record checks without knowing whether a report was requested.
+	r := FromContext(context.Background())
+	if err := r.Check(CheckSignature, serrors.ErrorNoValidSignature); !errors.Is(err, serrors.ErrorNoValidSignature) {
+		t.Errorf("unexpected error: %v", err)
+	}
+	r.SetSource("https://github.com/org/repo", "abcd", "refs/heads/main")
+	r.SetRekorLogIndex(1)
+	r.SetSigner(Signer{Issuer: "https://token.actions.githubusercontent.com"})
+	r.SetRekorEntry(RekorEntry{LogIndex: 1})
+	r.SetBuilderTrust(BuilderTrustBuiltIn)
+	r.Merge(r.Child())
+	r.Time(StageSignature)()
+	r.Finish("builder", nil)
+	if d := r.Durations(); d != nil {
+		t.Errorf("unexpected durations: %v", d)
+	}
+	if r.Signer() != nil || r.RekorEntry() != nil {
+		t.Errorf("unexpected signature: %v %v", r.Signer(), r.RekorEntry())
+	}
+}
+
+func Test_Time(t *testing.T) {
+	t.Parallel()
+
+	r := New("artifact", "sha256:abcd")
+	stop := r.Time(StageSignature)
+	time.Sleep(time.Millisecond)
+	stop()
+	child := r.Child()
+	child.Time(StageSignature)()
+	child.Time(StageProvenance)()
+	r.Merge(child)
+
+	d := r.Durations()
+	if len(d) != 2 || d[StageSignature] < time.Millisecond {
+		t.Errorf("unexpected durations: %v", d)
+	}
+	// The durations are not part of the JSON document.
+	var buf bytes.Buffer
+	if err := Write(&buf, []*Report{r}); err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(buf.Bytes(), []byte(StageProvenance)) {
+		t.Errorf("unexpected durations in the document: %s", buf.String())
+	}
+}
+
+func Test_Time_nested(t *testing.T) {
+	t.Parallel()
+
+	r := New("artifact", "sha256:abcd")
+	stopSignature := r.Time(StageSignature)
+	stopRekor := r.Time(StageRekor)
+	time.Sleep(20 * time.Millisecond)
+	stopRekor()
+	stopSignature()
+	stopProvenance := r.Time(StageProvenance)
+	child := r.Child()
+	stopRekor = child.Time(StageRekor)
+	time.Sleep(20 * time.Millisecond)
+	stopRekor()
+	r.Merge(child)
+	stopProvenance()
+	stopProvenance()
+
+	// The time spent in the rekor stages is excluded from the outer stages.
+	d := r.Durations()
+	if d[StageRekor] < 40*time.Millisecond ||
+		d[StageSignature] >= 10*time.Millisecond || d[StageProvenance] >= 10*time.Millisecond {
+		t.Errorf("unexpected durations: %v", d)
+	}
+}
+
+func Test_Write(t *testing.T) {
+	t.Parallel()
+
+	passed := New("passed", "sha256:abcd")
+	ctx := NewContext(context.Background(), passed)
+	_ = FromContext(ctx).Check(CheckSignature, nil)
+	FromContext(ctx).SetSource("https://github.com/org/repo", "abcd", "refs/heads/main")
+	FromContext(ctx).SetRekorLogIndex(42)
+	child := passed.Child()
+	child.SetBuilderTrust(BuilderTrustCustom)
+	child.SetSigner(Signer{Workflow: "https://github.com/org/builder@refs/tags/v1.0.0"})
+	passed.Merge(child)
+	// The signature recorded in the child is merged.
+	if s := passed.Signer(); s == nil || s.Workflow != "https://github.com/org/builder@refs/tags/v1.0.0" {
+		t.Errorf("unexpected signer: %v", s)
+	}
+	passed.Finish("https://github.com/org/builder@refs/tags/v1.0.0", nil)
+
+	failed := New("failed", "sha256:ef01")
+	child = failed.Child()
+	err := child.Check(CheckBranch, serrors.NewVerificationError(
+		fmt.Errorf("%w: expected main, got dev", serrors.ErrorMismatchBranch), "main", "dev"))
+	failed.Merge(child)
+	failed.Finish("", err)
+
+	var buf bytes.Buffer
+	if err := Write(&buf, []*Report{passed, failed}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var doc Document
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	index := int64(42)
+	expected := Document{
+		Version: SchemaVersion,
+		Result:  StatusFailed,
+		Artifacts: []*Report{
+			{
+				Artifact:      "passed",
+				Digest:        "sha256:abcd",
+				Result:        StatusPassed,
+				BuilderID:     "https://github.com/org/builder@refs/tags/v1.0.0",
+				BuilderTrust:  BuilderTrustCustom,
+				SourceURI:     "https://github.com/org/repo",
+				SourceCommit:  "abcd",
+				SourceRef:     "refs/heads/main",
+				RekorLogIndex: &index,
+				Checks:        []Check{{Name: CheckSignature, Status: StatusPassed}},
+			},
+			{
+				Artifact: "failed",
+				Digest:   "sha256:ef01",
+				Result:   StatusFailed,
+				Checks:   []Check{{Name: CheckBranch, Status: StatusFailed}},
+				Error: &Error{
+					Code:        "MISMATCH_BRANCH",
+					Message:     err.Error(),
+					Check:       CheckBranch,
+					Expected:    "main",
+					Actual:      "dev",
+					Remediation: serrors.Remediation(serrors.ErrorMismatchBranch),
+				},
+			},
+		},
+	}
+	if diff := cmp.Diff(expected, doc, cmpopts.IgnoreUnexported(Report{})); diff != "" {
+		t.Errorf("unexpected document (-want +got):\n%s", diff)
+	}
+}
+
+func Test_WriteUnverified(t *testing.T) {
+	t.Parallel()
+
+	notPresent := fmt.Errorf("%w: no provenance", serrors.ErrorNotPresent)
+	tests := []struct {
+		name        string
+		allowlisted bool
+		result      Status
+		docResult   Status
+	}{
+		{
+			name:      "unverified",
+			result:    StatusUnverified,
+			docResult: StatusFailed,
+		},
+		{
+			name:        "allowlisted",
+			allowlisted: true,
+			result:      StatusAllowlisted,
+			docResult:   StatusPassed,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			passed := New("passed", "")
+			passed.Finish("builder", nil)
+			unverified := New("unverified", "")
+			unverified.FinishUnverified(tt.allowlisted, notPresent)
+			if unverified.Result != tt.result {
+				t.Errorf("unexpected result: got %q, want %q", unverified.Result, tt.result)
+			}
+			if unverified.Error == nil || unverified.Error.Code != "NOT_PRESENT" {
+				t.Errorf("unexpected error: %v", unverified.Error)
+			}
+
+			var buf bytes.Buffer
+			if err := Write(&buf, []*Report{passed, unverified}); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			var doc Document
+			if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if doc.Result != tt.docResult {
+				t.Errorf("unexpected document result: got %q, want %q", doc.Result, tt.docResult)
+			}
+		})
+	}
+}
@@ -0,0 +1,154 @@
+package verifier
+
+import (
+	"maps"
+	"strings"
+
+	"github.com/slsa-framework/slsa-verifier/v2/options"
+)
+
+// Expectations are the properties the provenance must have. Empty fields
+// are not checked, except SourceURI, which is required unless the
+// Verifier has a policy.
+type Expectations struct {
+	// SourceURI is the source repository, e.g. github.com/org/repo.
+	SourceURI string
+
+	// BuilderID is the ID of the builder, with an optional version,
+	// e.g. https://github.com/org/repo/.github/workflows/builder.yml@v1.
+	BuilderID string
+
+	// Branch is the branch the artifact was built from.
+	Branch string
+
+	// Tag is the tag the artifact was built from.
+	Tag string
+
+	// VersionedTag is the semantic version of the tag the artifact was
+	// built from: v1 accepts any v1.x.y release, v1.2 any v1.2.x release.
+	VersionedTag string
+
+	// WorkflowInputs are the inputs the build workflow was run with.
+	WorkflowInputs map[string]string
+
+	// PackageName and PackageVersion are the name and version of an npm
+	// package. PackageName is required to match the rules of a policy.
+	PackageName    string
+	PackageVersion string
+}
+
+// hasPolicyExpectations returns true if the expectations set fields that
+// are taken from the rules of a policy instead.
+func (e *Expectations) hasPolicyExpectations() bool {
+	return e.BuilderID != "" || e.Branch != "" || e.Tag != "" || e.VersionedTag != "" ||
+		len(e.WorkflowInputs) > 0
+}
+
+// options compiles the expectations into options of the verifiers.
+func (e *Expectations) options(digest string) (*options.ProvenanceOpts, []*options.BuilderOpts) {
+	provenanceOpts := &options.ProvenanceOpts{
+		ExpectedSourceURI:      e.SourceURI,
+		ExpectedDigest:         digest,
+		ExpectedWorkflowInputs: e.WorkflowInputs,
+	}
+	if e.Branch != "" {
+		provenanceOpts.ExpectedBranch = &e.Branch
+	}
+	if e.Tag != "" {
+		provenanceOpts.ExpectedTag = &e.Tag
+	}
+	if e.VersionedTag != "" {
+		provenanceOpts.ExpectedVersionedTag = &e.VersionedTag
+	}
+	builderOpts := &options.BuilderOpts{}
+	if e.BuilderID != "" {
+		builderOpts.ExpectedID = &e.BuilderID
+	}
+	return provenanceOpts, []*options.BuilderOpts{builderOpts}
+}
+
+// setPackage sets the expected npm package in the options.
+func (e *Expectations) setPackage(provenanceOpts *options.ProvenanceOpts) {
+	provenanceOpts.ExpectedPackageName = nil
+	if e.PackageName != "" {
+		provenanceOpts.ExpectedPackageName = &e.PackageName
+	}
+	if e.PackageVersion != "" {
+		provenanceOpts.ExpectedPackageVersion = &e.PackageVersion
+	}
+}
+
+// clone returns a deep copy of the expectations.
+func (e Expectations) clone() Expectations {
+	e.WorkflowInputs = maps.Clone(e.WorkflowInputs)
+	return e
+}
+
+// ArtifactRequest is a request to verify the provenance of an artifact.
+// It is immutable: it holds copies of the values it was created with.
+type ArtifactRequest struct {
+	name       string
+	digest     string
+	provenance []byte
+	expect     Expectations
+}
+
+// NewArtifactRequest returns a request to verify the provenance of the
+// artifact called name, e.g. the base name of its file, whose sha256
+// digest is digest, hex-encoded with an optional sha256: prefix. Rules of
+// a policy match artifacts by name.
+func NewArtifactRequest(name, digest string, provenance []byte, expect Expectations) ArtifactRequest {
+	return ArtifactRequest{
+		name:       name,
+		digest:     strings.TrimPrefix(digest, "sha256:"),
+		provenance: append([]byte(nil), provenance...),
+		expect:     expect.clone(),
+	}
+}
+
+// ImageRequest is a request to verify the provenance of a container image.
+// It is immutable: it holds copies of the values it was created with.
+type ImageRequest struct {
+	image      string
+	provenance []byte
+	expect     Expectations
+}
+
+// NewImageRequest returns a request to verify the provenance of the image,
+// referenced by digest, e.g. ghcr.io/org/image@sha256:0a2b4c.... If
+// provenance is nil, the attestations of the image are fetched from its
+// registry. Rules of a policy match images by repository.
+func NewImageRequest(image string, provenance []byte, expect Expectations) ImageRequest {
+	var p []byte
+	if provenance != nil {
+		p = append([]byte{}, provenance...)
+	}
+	return ImageRequest{
+		image:      image,
+		provenance: p,
+		expect:     expect.clone(),
+	}
+}
+
+// NpmPackageRequest is a request to verify the attestations of an npm
+// package tarball. It is immutable: it holds copies of the values it was
+// created with.
+type NpmPackageRequest struct {
+	name         string
+	digest       string
+	attestations []byte
+	expect       Expectations
+}
+
+// NewNpmPackageRequest returns a request to verify the attestations of the
+// npm package tarball called name, whose sha512 digest is digest,
+// hex-encoded with an optional sha512: prefix. Rules of a policy match
+// packages by the expected package name.
+func NewNpmPackageRequest(name, digest string, attestations []byte, expect Expectations) NpmPackageRequest {
+	return NpmPackageRequest{
+		name:         name,
+		digest:       strings.TrimPrefix(digest, "sha512:"),
+		attestations: append([]byte(nil), attestations...),
+		expect:       expect.clone(),
+	}
+}
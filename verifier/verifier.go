@@ -0,0 +1,373 @@
+// Package verifier is the Go library API of slsa-verifier.
+//
+// A Verifier is configured once with functional options, and is then safe
+// to use concurrently. Each verification takes an immutable request and
+// returns a Result with the verified provenance, the identity of the
+// signing certificate, the transparency log entry and the checks that
+// were performed:
+//
+//	v, err := verifier.New(verifier.WithLogger(slog.Default()))
+//	if err != nil {
+//		return err
+//	}
+//	req := verifier.NewArtifactRequest("app-linux-amd64", digest, provenance, verifier.Expectations{
+//		SourceURI:    "github.com/org/app",
+//		VersionedTag: "v1",
+//	})
+//	res, err := v.VerifyArtifact(ctx, req)
+//
+// The functions of package verifiers are the lower-level API the Verifier
+// is built on, rather than thin wrappers of it: the verifiers of the
+// builders are internal to package verifiers, and are registered with it,
+// so a Verifier dispatches to them through its functions. They keep their
+// signatures for compatibility, and only differ from the Verifier in that
+// they do not apply a policy nor return a Result.
+package verifier
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	intoto "github.com/in-toto/in-toto-golang/in_toto"
+	rekorGenClient "github.com/sigstore/rekor/pkg/generated/client"
+	sigstoreRoot "github.com/sigstore/sigstore-go/pkg/root"
+
+	serrors "github.com/slsa-framework/slsa-verifier/v2/errors"
+	"github.com/slsa-framework/slsa-verifier/v2/options"
+	"github.com/slsa-framework/slsa-verifier/v2/policy"
+	"github.com/slsa-framework/slsa-verifier/v2/report"
+	"github.com/slsa-framework/slsa-verifier/v2/verifiers"
+	"github.com/slsa-framework/slsa-verifier/v2/verifiers/utils"
+	"github.com/slsa-framework/slsa-verifier/v2/verifiers/utils/container"
+)
+
+// Verifier verifies the provenance of artifacts, container images and npm
+// packages. Create one with New.
+type Verifier struct {
+	sigstoreOpts    options.SigstoreOpts
+	githubOpts      *options.GitHubOpts
+	trustedBuilders []options.TrustedBuilder
+	policy          *policy.Policy
+	logger          *slog.Logger
+}
+
+// Option configures a Verifier.
+type Option func(*Verifier)
+
+// WithTrustedRoot sets the trusted root to verify signatures with, instead
+// of the trusted root of the public-good Sigstore instance.
+func WithTrustedRoot(trustedMaterial sigstoreRoot.TrustedMaterial) Option {
+	return func(v *Verifier) {
+		v.sigstoreOpts.TrustedMaterial = trustedMaterial
+	}
+}
+
+// WithRekorClient sets the client to search the transparency log with,
+// instead of a client of the public-good Rekor instance.
+func WithRekorClient(client *rekorGenClient.Rekor) Option {
+	return func(v *Verifier) {
+		v.sigstoreOpts.RekorClient = client
+	}
+}
+
+// WithSigstore configures the Sigstore deployment, as the flags of the CLI
+// do. The trusted root and Rekor client of WithTrustedRoot and
+// WithRekorClient take precedence over those configured by opts.
+func WithSigstore(opts options.SigstoreOpts) Option {
+	return func(v *Verifier) {
+		trustedMaterial, client := v.sigstoreOpts.TrustedMaterial, v.sigstoreOpts.RekorClient
+		v.sigstoreOpts = opts
+		v.sigstoreOpts.RekorPublicKeyPaths = append([]string(nil), opts.RekorPublicKeyPaths...)
+		v.sigstoreOpts.CTLogPublicKeyPaths = append([]string(nil), opts.CTLogPublicKeyPaths...)
+		v.sigstoreOpts.FulcioCertificatePaths = append([]string(nil), opts.FulcioCertificatePaths...)
+		if trustedMaterial != nil {
+			v.sigstoreOpts.TrustedMaterial = trustedMaterial
+		}
+		if client != nil {
+			v.sigstoreOpts.RekorClient = client
+		}
+	}
+}
+
+// WithGitHub sets the GitHub Enterprise Server instance that ran the builds.
+func WithGitHub(opts options.GitHubOpts) Option {
+	return func(v *Verifier) {
+		v.githubOpts = &opts
+	}
+}
+
+// WithTrustedBuilders trusts reusable workflows as builders, in addition
+// to the built-in builders.
+func WithTrustedBuilders(builders ...options.TrustedBuilder) Option {
+	return func(v *Verifier) {
+		v.trustedBuilders = append(v.trustedBuilders, builders...)
+	}
+}
+
+// WithPolicy verifies with the expectations of the first rule of the
+// policy that matches each request, as the --policy flag of the CLI does.
+// The requests may then only set the expected source URI.
+func WithPolicy(p *policy.Policy) Option {
+	return func(v *Verifier) {
+		v.policy = p
+	}
+}
+
+// WithLogger sets the logger of the progress messages of the verifiers,
+// which are otherwise written to stderr.
+func WithLogger(logger *slog.Logger) Option {
+	return func(v *Verifier) {
+		v.logger = logger
+	}
+}
+
+// New returns a Verifier configured with opts.
+func New(opts ...Option) (*Verifier, error) {
+	v := &Verifier{}
+	for _, opt := range opts {
+		opt(v)
+	}
+	if v.policy != nil {
+		if err := v.policy.Validate(); err != nil {
+			return nil, err
+		}
+	}
+	return v, nil
+}
+
+// Result is the outcome of a verification.
+type Result struct {
+	// Report is the report of the verification, as written by the CLI
+	// with --output.
+	Report *report.Report
+
+	// BuilderID is the verified ID of the builder, with its version if any.
+	BuilderID string
+
+	// Provenance is the verified provenance. It is an in-toto statement,
+	// except for container images with several attestations, whose
+	// verified statements are not returned.
+	Provenance []byte
+
+	// Statement is the parsed Provenance, or nil if it is not an in-toto
+	// statement.
+	Statement *intoto.Statement
+
+	// Identity is the identity of the signer of the provenance.
+	Identity Identity
+
+	// TlogEntry is the transparency log entry of the signature, or nil if
+	// the provenance is not signed with Sigstore.
+	TlogEntry *TlogEntry
+
+	// Checks are the checks performed, in order.
+	Checks []report.Check
+}
+
+// Identity is the identity of the signer of the provenance: the source
+// repository, commit and ref of the build, taken from the signing
+// certificate or the provenance, and the identity of the certificate.
+type Identity struct {
+	SourceURI    string
+	SourceCommit string
+	SourceRef    string
+
+	// SignerWorkflow is the URI of the workflow that signed, with its ref.
+	SignerWorkflow string
+	// Issuer is the OIDC issuer of the identity token of the workflow.
+	Issuer string
+	// SAN is the subject alternative name of the certificate.
+	SAN string
+}
+
+// TlogEntry identifies the transparency log entry of a signature.
+type TlogEntry struct {
+	LogIndex int64
+	// UUID is the hex-encoded leaf hash of the entry.
+	UUID string
+	// IntegratedTime is the time the entry was added to the log.
+	IntegratedTime time.Time
+	// LogID is the hex-encoded ID of the log.
+	LogID string
+}
+
+// VerifyArtifact verifies the provenance of an artifact. The Result is
+// returned even if the verification fails, to describe the failure.
+func (v *Verifier) VerifyArtifact(ctx context.Context, req ArtifactRequest) (*Result, error) {
+	t := target{name: req.name, policyName: req.name, algorithm: "sha256", digest: req.digest}
+	return v.verify(ctx, t, &req.expect, func(ctx context.Context,
+		provenanceOpts *options.ProvenanceOpts, builderOpts *options.BuilderOpts,
+	) ([]byte, *utils.TrustedBuilderID, error) {
+		return verifiers.VerifyArtifact(ctx, req.provenance, req.digest, provenanceOpts, builderOpts)
+	})
+}
+
+// VerifyImage verifies the provenance of a container image. The
+// provenance is fetched from the registry if the request has none.
+// The Result is returned even if the verification fails.
+func (v *Verifier) VerifyImage(ctx context.Context, req ImageRequest) (*Result, error) {
+	t := target{name: req.image, algorithm: "sha256"}
+	// The images of a docker-archive tarball are verified in its OCI image
+	// layout, extracted once.
+	image, cleanup, err := container.OpenDockerArchive(req.image)
+	if err != nil {
+		return failed(report.New(req.image, ""), err)
+	}
+	defer cleanup()
+	t.digest, err = container.GetDigestFromImmutableReference(image)
+	if err == nil {
+		// Rules match images by repository, e.g. ghcr.io/org/image.
+		t.policyName, err = container.ImageName(req.image)
+	}
+	if err != nil {
+		return failed(report.New(req.image, ""), err)
+	}
+	return v.verify(ctx, t, &req.expect, func(ctx context.Context,
+		provenanceOpts *options.ProvenanceOpts, builderOpts *options.BuilderOpts,
+	) ([]byte, *utils.TrustedBuilderID, error) {
+		return verifiers.VerifyImage(ctx, image, req.provenance, provenanceOpts, builderOpts)
+	})
+}
+
+// VerifyNpmPackage verifies the attestations of an npm package tarball.
+// The Result is returned even if the verification fails.
+func (v *Verifier) VerifyNpmPackage(ctx context.Context, req NpmPackageRequest) (*Result, error) {
+	t := target{name: req.name, policyName: req.expect.PackageName, algorithm: "sha512", digest: req.digest}
+	return v.verify(ctx, t, &req.expect, func(ctx context.Context,
+		provenanceOpts *options.ProvenanceOpts, builderOpts *options.BuilderOpts,
+	) ([]byte, *utils.TrustedBuilderID, error) {
+		return verifiers.VerifyNpmPackage(ctx, req.attestations, req.digest, provenanceOpts, builderOpts)
+	})
+}
+
+// target is the artifact a request verifies.
+type target struct {
+	// name identifies the artifact in the report.
+	name string
+	// policyName is the name matched by the rules of the policy.
+	policyName string
+	// algorithm is the algorithm of the hex-encoded digest.
+	algorithm string
+	digest    string
+}
+
+type verifyFn func(ctx context.Context, provenanceOpts *options.ProvenanceOpts,
+	builderOpts *options.BuilderOpts) ([]byte, *utils.TrustedBuilderID, error)
+
+// verify runs verify with each of the allowed builders in turn, and returns
+// the result of the first one that succeeds, or else of the first one.
+func (v *Verifier) verify(ctx context.Context, t target, expect *Expectations, verify verifyFn,
+) (*Result, error) {
+	if v.logger != nil {
+		ctx = utils.ContextWithLogger(ctx, v.logger)
+	}
+	rep := report.New(t.name, t.algorithm+":"+t.digest)
+	provenanceOpts, builderOpts, err := v.options(ctx, rep, t, expect)
+	if err != nil {
+		return failed(rep, err)
+	}
+
+	var firstErr error
+	var failedRep *report.Report
+	for _, b := range builderOpts {
+		child := rep.Child()
+		provenance, builderID, err := verify(report.NewContext(ctx, child), provenanceOpts, b)
+		if err == nil {
+			rep.Merge(child)
+			rep.Finish(builderID.String(), nil)
+			res := newResult(rep)
+			res.BuilderID = builderID.String()
+			res.Provenance = provenance
+			// The provenance of an image with several attestations is
+			// not a statement.
+			res.Statement, _ = utils.StatementFromBytes(provenance)
+			return res, nil
+		}
+		if firstErr == nil {
+			firstErr, failedRep = err, child
+		}
+	}
+	rep.Merge(failedRep)
+	return failed(rep, firstErr)
+}
+
+// options compiles the configuration of the verifier and the expectations
+// of a request into options of the verifiers. They are created for each
+// request, so that requests share no state.
+func (v *Verifier) options(ctx context.Context, rep *report.Report, t target, expect *Expectations,
+) (*options.ProvenanceOpts, []*options.BuilderOpts, error) {
+	var provenanceOpts *options.ProvenanceOpts
+	var builderOpts []*options.BuilderOpts
+	if v.policy != nil {
+		if expect.hasPolicyExpectations() {
+			return nil, nil, fmt.Errorf("%w: a request verified with a policy sets expectations of the policy",
+				serrors.ErrorInvalidFormat)
+		}
+		rule, err := v.policy.Match(expect.SourceURI, t.policyName)
+		if err != nil {
+			return nil, nil, err
+		}
+		provenanceOpts, err = rule.ProvenanceOpts(expect.SourceURI, t.digest)
+		if err != nil {
+			return nil, nil, err
+		}
+		if provenanceOpts.ExpectedPackageName != nil && *provenanceOpts.ExpectedPackageName != expect.PackageName {
+			return nil, nil, fmt.Errorf("%w: policy expects package %q, got %q", serrors.ErrorMismatchPackageName,
+				*provenanceOpts.ExpectedPackageName, expect.PackageName)
+		}
+		builderOpts = rule.BuilderOpts()
+		rep.PolicyRule = rule.Name
+		utils.Logf(ctx, "Using policy rule %q for %s\n", rule.Name, t.name)
+	} else {
+		if expect.SourceURI == "" {
+			return nil, nil, fmt.Errorf("%w: empty source URI", serrors.ErrorInvalidFormat)
+		}
+		provenanceOpts, builderOpts = expect.options(t.digest)
+	}
+	expect.setPackage(provenanceOpts)
+
+	sigstoreOpts := v.sigstoreOpts
+	provenanceOpts.SigstoreOpts = &sigstoreOpts
+	provenanceOpts.GitHubOpts = v.githubOpts
+	provenanceOpts.TrustedBuilders = v.trustedBuilders
+	return provenanceOpts, builderOpts, nil
+}
+
+// failed finishes the report of a failed verification, and returns its
+// result and err.
+func failed(rep *report.Report, err error) (*Result, error) {
+	rep.Finish("", err)
+	return newResult(rep), err
+}
+
+// newResult returns a result with the fields that the verifiers record in
+// the finished report rep.
+func newResult(rep *report.Report) *Result {
+	res := &Result{
+		Report: rep,
+		Checks: rep.Checks,
+		Identity: Identity{
+			SourceURI:    rep.SourceURI,
+			SourceCommit: rep.SourceCommit,
+			SourceRef:    rep.SourceRef,
+		},
+	}
+	if s := rep.Signer(); s != nil {
+		res.Identity.SignerWorkflow = s.Workflow
+		res.Identity.Issuer = s.Issuer
+		res.Identity.SAN = s.SAN
+	}
+	if e := rep.RekorEntry(); e != nil {
+		res.TlogEntry = &TlogEntry{
+			LogIndex:       e.LogIndex,
+			UUID:           e.UUID,
+			IntegratedTime: time.Unix(e.IntegratedTime, 0),
+			LogID:          e.LogID,
+		}
+	} else if rep.RekorLogIndex != nil {
+		res.TlogEntry = &TlogEntry{LogIndex: *rep.RekorLogIndex}
+	}
+	return res
+}
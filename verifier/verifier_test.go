@@ -0,0 +1,380 @@
+package verifier
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	sigstoreRoot "github.com/sigstore/sigstore-go/pkg/root"
+
+	serrors "github.com/slsa-framework/slsa-verifier/v2/errors"
+	"github.com/slsa-framework/slsa-verifier/v2/options"
+	"github.com/slsa-framework/slsa-verifier/v2/policy"
+	"github.com/slsa-framework/slsa-verifier/v2/report"
+)
+
+const (
+	bundlePath      = "../verifiers/internal/gha/testdata/bundle/valid.intoto.sigstore"
+	trustedRootPath = "../verifiers/internal/gha/testdata/trusted_root.json"
+	bundleDigest    = "975a0582b8c9607f3f20a6b8cfef01b25823e68c5c3658e6e1ccaaced2a3255d"
+	bundleSource    = "github.com/slsa-framework/slsa-github-generator"
+	bundleCommit    = "521743c2cf7e3b7b2cc9fdcc2a1606d8e41211e0"
+	dockerBuilderID = "https://github.com/slsa-framework/slsa-github-generator/.github/workflows/builder_docker-based_slsa3.yml"
+	bundleUUID      = "9de5be082d52aed89b7fa3955aeebed5c163494f1336046039e1b3b574fb6fff"
+	bundleLogID     = "c0d23d6ad406973f9559f3ba2d1ca01f84147d8ffc5b8445c224f98b9591801d"
+	githubIssuer    = "https://token.actions.githubusercontent.com"
+
+	// The provenance of an artifact built by the container-based builder,
+	// which verifies offline with the trusted root.
+	passingBundlePath   = "../cli/slsa-verifier/testdata/gha_container-based/v1.7.0/gha_container-based-binary-linux-amd64-v14.intoto.sigstore"
+	passingDigest       = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+	passingSource       = "github.com/slsa-framework/example-package"
+	passingCommit       = "62cb1f1e485829bafe8bbec8b9900c0cb7624fe7"
+	containerBuilderID  = "https://github.com/slsa-framework/slsa-github-generator/.github/workflows/builder_container-based_slsa3.yml"
+	passingBuilderIDRef = containerBuilderID + "@refs/tags/v1.7.0"
+)
+
+func Test_New(t *testing.T) {
+	t.Parallel()
+
+	if _, err := New(WithPolicy(&policy.Policy{Version: policy.Version})); !errors.Is(err, serrors.ErrorInvalidPolicy) {
+		t.Errorf("unexpected error: got %v, want %v", err, serrors.ErrorInvalidPolicy)
+	}
+	if _, err := New(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func Test_VerifyArtifact(t *testing.T) {
+	t.Parallel()
+
+	trustedRoot, err := sigstoreRoot.NewTrustedRootFromPath(trustedRootPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	provenance, err := os.ReadFile(bundlePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pol, err := policy.FromBytes([]byte(`
+version: 1
+rules:
+  - name: generator
+    match:
+      sourceURI: github.com/slsa-framework/*
+    builderIDs: [` + dockerBuilderID + `]
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The bundle is verified offline with the trusted root, but its
+	// builder is not trusted at refs/heads/main.
+	signedChecks := []report.Check{
+		{Name: "signature", Status: report.StatusPassed},
+		{Name: "builder-id", Status: report.StatusFailed},
+	}
+
+	tests := []struct {
+		name       string
+		opts       []Option
+		expect     Expectations
+		err        error
+		checks     []report.Check
+		policyRule string
+		log        string
+	}{
+		{
+			name:   "trusted root",
+			opts:   []Option{WithTrustedRoot(trustedRoot), WithSigstore(options.SigstoreOpts{Offline: true})},
+			expect: Expectations{SourceURI: bundleSource, BuilderID: dockerBuilderID},
+			err:    serrors.ErrorInvalidRef,
+			checks: signedChecks,
+		},
+		{
+			name:   "no trusted root",
+			opts:   []Option{WithSigstore(options.SigstoreOpts{Offline: true})},
+			expect: Expectations{SourceURI: bundleSource, BuilderID: dockerBuilderID},
+			err:    serrors.ErrorRequiresNetwork,
+			checks: []report.Check{},
+		},
+		{
+			name:   "no source URI",
+			expect: Expectations{BuilderID: dockerBuilderID},
+			err:    serrors.ErrorInvalidFormat,
+			checks: []report.Check{},
+		},
+		{
+			name: "policy",
+			opts: []Option{
+				WithTrustedRoot(trustedRoot), WithSigstore(options.SigstoreOpts{Offline: true}),
+				WithPolicy(pol),
+			},
+			expect:     Expectations{SourceURI: bundleSource},
+			err:        serrors.ErrorInvalidRef,
+			checks:     signedChecks,
+			policyRule: "generator",
+			log:        "Using policy rule",
+		},
+		{
+			name:   "policy with expectations",
+			opts:   []Option{WithPolicy(pol)},
+			expect: Expectations{SourceURI: bundleSource, Branch: "main"},
+			err:    serrors.ErrorInvalidFormat,
+			checks: []report.Check{},
+		},
+		{
+			name:   "no matching policy rule",
+			opts:   []Option{WithPolicy(pol)},
+			expect: Expectations{SourceURI: "github.com/org/repo"},
+			err:    serrors.ErrorNoMatchingPolicyRule,
+			checks: []report.Check{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var log bytes.Buffer
+			v, err := New(append(tt.opts, WithLogger(slog.New(slog.NewTextHandler(&log, nil))))...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			req := NewArtifactRequest("config.toml", "sha256:"+bundleDigest, provenance, tt.expect)
+			res, err := v.VerifyArtifact(context.Background(), req)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("unexpected error: got %v, want %v", err, tt.err)
+			}
+			if res.Report.Result != report.StatusFailed || res.Report.Digest != "sha256:"+bundleDigest {
+				t.Errorf("unexpected report: %s %s", res.Report.Result, res.Report.Digest)
+			}
+			if diff := cmp.Diff(tt.checks, res.Checks); diff != "" {
+				t.Errorf("unexpected checks (-want +got):\n%s", diff)
+			}
+			if res.Report.PolicyRule != tt.policyRule {
+				t.Errorf("unexpected policy rule: got %q, want %q", res.Report.PolicyRule, tt.policyRule)
+			}
+			if !strings.Contains(log.String(), tt.log) {
+				t.Errorf("unexpected log: %q does not contain %q", log.String(), tt.log)
+			}
+			if len(tt.checks) == 0 {
+				return
+			}
+			// The identity and the transparency log entry are recorded
+			// before the builder is verified.
+			wantIdentity := Identity{
+				SourceURI:      "https://" + bundleSource,
+				SourceCommit:   bundleCommit,
+				SignerWorkflow: dockerBuilderID + "@refs/heads/main",
+				Issuer:         githubIssuer,
+				SAN:            dockerBuilderID + "@refs/heads/main",
+			}
+			if diff := cmp.Diff(wantIdentity, res.Identity); diff != "" {
+				t.Errorf("unexpected identity (-want +got):\n%s", diff)
+			}
+			wantEntry := &TlogEntry{
+				LogIndex:       12421178,
+				UUID:           bundleUUID,
+				IntegratedTime: time.Date(2023, time.February, 1, 17, 24, 3, 0, time.UTC),
+				LogID:          bundleLogID,
+			}
+			if diff := cmp.Diff(wantEntry, res.TlogEntry); diff != "" {
+				t.Errorf("unexpected transparency log entry (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_VerifyArtifact_passed(t *testing.T) {
+	t.Parallel()
+
+	trustedRoot, err := sigstoreRoot.NewTrustedRootFromPath(trustedRootPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	provenance, err := os.ReadFile(passingBundlePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v, err := New(WithTrustedRoot(trustedRoot), WithSigstore(options.SigstoreOpts{Offline: true}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := NewArtifactRequest("app", passingDigest, provenance, Expectations{
+		SourceURI:    passingSource,
+		BuilderID:    containerBuilderID,
+		VersionedTag: "v14",
+	})
+	res, err := v.VerifyArtifact(context.Background(), req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if res.Report.Result != report.StatusPassed || res.Report.BuilderID != passingBuilderIDRef {
+		t.Errorf("unexpected report: %s %s", res.Report.Result, res.Report.BuilderID)
+	}
+	if res.BuilderID != passingBuilderIDRef {
+		t.Errorf("unexpected builder ID: got %s, want %s", res.BuilderID, passingBuilderIDRef)
+	}
+	wantChecks := []report.Check{
+		{Name: report.CheckSignature, Status: report.StatusPassed},
+		{Name: report.CheckBuilderID, Status: report.StatusPassed},
+		{Name: report.CheckSourceURI, Status: report.StatusPassed},
+		{Name: report.CheckSubjectDigest, Status: report.StatusPassed},
+		{Name: report.CheckVersionedTag, Status: report.StatusPassed},
+	}
+	if diff := cmp.Diff(wantChecks, res.Checks); diff != "" {
+		t.Errorf("unexpected checks (-want +got):\n%s", diff)
+	}
+	wantIdentity := Identity{
+		SourceURI:      "https://" + passingSource,
+		SourceCommit:   passingCommit,
+		SourceRef:      "refs/tags/v14",
+		SignerWorkflow: passingBuilderIDRef,
+		Issuer:         githubIssuer,
+		SAN:            passingBuilderIDRef,
+	}
+	if diff := cmp.Diff(wantIdentity, res.Identity); diff != "" {
+		t.Errorf("unexpected identity (-want +got):\n%s", diff)
+	}
+	wantEntry := &TlogEntry{
+		LogIndex:       23032500,
+		UUID:           "25bd949b8541a74b39aa8d906ffb0247cd9ca9719cdb55777f7ad8c6f9c372f3",
+		IntegratedTime: time.Date(2023, time.June, 7, 14, 44, 42, 0, time.UTC),
+		LogID:          bundleLogID,
+	}
+	if diff := cmp.Diff(wantEntry, res.TlogEntry); diff != "" {
+		t.Errorf("unexpected transparency log entry (-want +got):\n%s", diff)
+	}
+	if res.Statement == nil || len(res.Statement.Subject) != 1 ||
+		res.Statement.Subject[0].Digest["sha256"] != passingDigest {
+		t.Errorf("unexpected statement: %+v", res.Statement)
+	}
+	if len(res.Provenance) == 0 {
+		t.Error("no provenance")
+	}
+}
+
+func Test_VerifyArtifact_immutable(t *testing.T) {
+	t.Parallel()
+
+	trustedRoot, err := sigstoreRoot.NewTrustedRootFromPath(trustedRootPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	provenance, err := os.ReadFile(passingBundlePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sigstoreOpts := options.SigstoreOpts{Offline: true}
+	githubOpts := options.GitHubOpts{}
+	builders := []options.TrustedBuilder{{
+		Workflow:    "org/builders/.github/workflows/builder.yml",
+		Builder:     containerBuilderID,
+		RefPatterns: []string{"refs/tags/v*"},
+	}}
+	expect := Expectations{SourceURI: passingSource, BuilderID: containerBuilderID, VersionedTag: "v14"}
+	v, err := New(WithTrustedRoot(trustedRoot), WithSigstore(sigstoreOpts), WithGitHub(githubOpts),
+		WithTrustedBuilders(builders...))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := NewArtifactRequest("app", passingDigest, provenance, expect)
+
+	// The requests share the verifier and the request.
+	results := make([]*Result, 4)
+	errs := make([]error, len(results))
+	var wg sync.WaitGroup
+	for i := range results {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i], errs[i] = v.VerifyArtifact(context.Background(), req)
+		}()
+	}
+	wg.Wait()
+	for i, res := range results {
+		if errs[i] != nil || res.BuilderID != passingBuilderIDRef {
+			t.Errorf("verification %d: unexpected result: %v %s", i, errs[i], res.BuilderID)
+		}
+	}
+
+	// The verifications change neither the options of the caller, nor
+	// the verifier, nor the request.
+	if diff := cmp.Diff(options.SigstoreOpts{Offline: true}, sigstoreOpts); diff != "" {
+		t.Errorf("unexpected Sigstore options (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(options.GitHubOpts{}, githubOpts); diff != "" {
+		t.Errorf("unexpected GitHub options (-want +got):\n%s", diff)
+	}
+	wantBuilders := []options.TrustedBuilder{{
+		Workflow:    "org/builders/.github/workflows/builder.yml",
+		Builder:     containerBuilderID,
+		RefPatterns: []string{"refs/tags/v*"},
+	}}
+	if diff := cmp.Diff(wantBuilders, builders); diff != "" {
+		t.Errorf("unexpected trusted builders (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(wantBuilders, v.trustedBuilders); diff != "" {
+		t.Errorf("unexpected trusted builders of the verifier (-want +got):\n%s", diff)
+	}
+	if v.sigstoreOpts.RekorClient != nil || !v.sigstoreOpts.Offline || *v.githubOpts != githubOpts {
+		t.Errorf("unexpected options of the verifier: %+v %+v", v.sigstoreOpts, v.githubOpts)
+	}
+	want := ArtifactRequest{
+		name:       "app",
+		digest:     passingDigest,
+		provenance: provenance,
+		expect:     Expectations{SourceURI: passingSource, BuilderID: containerBuilderID, VersionedTag: "v14"},
+	}
+	if diff := cmp.Diff(want, req, cmp.AllowUnexported(ArtifactRequest{})); diff != "" {
+		t.Errorf("unexpected request (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(want.expect, expect); diff != "" {
+		t.Errorf("unexpected expectations (-want +got):\n%s", diff)
+	}
+}
+
+func Test_VerifyImage(t *testing.T) {
+	t.Parallel()
+
+	v, err := New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := v.VerifyImage(context.Background(), NewImageRequest("ghcr.io/org/image:latest", nil,
+		Expectations{SourceURI: bundleSource}))
+	if !errors.Is(err, serrors.ErrorMutableImage) {
+		t.Errorf("unexpected error: got %v, want %v", err, serrors.ErrorMutableImage)
+	}
+	if res.Report.Result != report.StatusFailed || res.Report.Error.Code != serrors.Code(serrors.ErrorMutableImage) {
+		t.Errorf("unexpected report: %+v", res.Report)
+	}
+}
+
+func Test_NewArtifactRequest(t *testing.T) {
+	t.Parallel()
+
+	provenance := []byte("provenance")
+	expect := Expectations{SourceURI: bundleSource, WorkflowInputs: map[string]string{"release": "true"}}
+	req := NewArtifactRequest("app", "sha256:"+bundleDigest, provenance, expect)
+
+	// Changes to the values the request was created with do not change it.
+	provenance[0] = 'P'
+	expect.WorkflowInputs["release"] = "false"
+	want := ArtifactRequest{
+		name:       "app",
+		digest:     bundleDigest,
+		provenance: []byte("provenance"),
+		expect:     Expectations{SourceURI: bundleSource, WorkflowInputs: map[string]string{"release": "true"}},
+	}
+	if diff := cmp.Diff(want, req, cmp.AllowUnexported(ArtifactRequest{})); diff != "" {
+		t.Errorf("unexpected request (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,210 @@
+package verifiers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	serrors "github.com/slsa-framework/slsa-verifier/v2/errors"
+	"github.com/slsa-framework/slsa-verifier/v2/options"
+	"github.com/slsa-framework/slsa-verifier/v2/register"
+	"github.com/slsa-framework/slsa-verifier/v2/report"
+	"github.com/slsa-framework/slsa-verifier/v2/verifiers/utils"
+	"github.com/slsa-framework/slsa-verifier/v2/verifiers/utils/container"
+)
+
+// platformResult is the outcome of the verification of a platform image.
+type platformResult struct {
+	report     *report.Platform
+	provenance []byte
+	builderID  *utils.TrustedBuilderID
+	err        error
+}
+
+// verifyImageIndex verifies an image index and each of its platform images.
+// A platform image is verified by the provenance of the index if it is one
+// of its subjects, and otherwise by its own provenance. All the images must
+// be built by the same builder from the same commit. The index may lack
+// provenance, but signed provenance of the index must pass its checks. The
+// result of each platform image is recorded in the report.
+func verifyImageIndex(ctx context.Context, verifier register.SLSAVerifier,
+	provenance []byte, artifactImage string,
+	platforms []container.PlatformImage,
+	provenanceOpts *options.ProvenanceOpts,
+	builderOpts *options.BuilderOpts,
+) ([]byte, *utils.TrustedBuilderID, error) {
+	rep := report.FromContext(ctx)
+	indexDigest := "sha256:" + provenanceOpts.ExpectedDigest
+
+	indexRep := report.New(artifactImage, indexDigest)
+	indexProvenance, indexBuilderID, indexErr := verifier.VerifyImage(report.NewContext(ctx, indexRep),
+		provenance, artifactImage, provenanceOpts, builderOpts)
+
+	// The platform images verified with the index.
+	covered := make(map[string]*report.Platform)
+	if indexErr == nil {
+		for _, digest := range subjectDigests(indexProvenance) {
+			covered[digest] = &report.Platform{
+				Result:       report.StatusPassed,
+				Index:        true,
+				BuilderID:    indexBuilderID.String(),
+				SourceCommit: indexRep.SourceCommit,
+			}
+		}
+		// The provenance of the platform images of a BuildKit index
+		// is verified with the index.
+		for _, p := range indexRep.Platforms {
+			if p.Result == report.StatusPassed {
+				covered[p.Digest] = p
+			}
+		}
+	}
+
+	results := make([]*platformResult, 0, len(platforms))
+	for _, image := range platforms {
+		var result *platformResult
+		if p, ok := covered[image.Digest]; ok {
+			result = &platformResult{
+				report:     p,
+				provenance: indexProvenance,
+				builderID:  indexBuilderID,
+			}
+		} else {
+			platformImage, err := container.WithDigest(artifactImage, image.Digest)
+			if err != nil {
+				return nil, nil, err
+			}
+			result = verifyPlatformImage(ctx, verifier, provenance, platformImage,
+				image.Digest, provenanceOpts, builderOpts)
+		}
+		result.report.Platform = image.Platform
+		result.report.Digest = image.Digest
+		rep.AddPlatform(result.report)
+		results = append(results, result)
+	}
+
+	verifiedProvenance, builderID := indexProvenance, indexBuilderID
+	var failed *platformResult
+	for _, result := range results {
+		if result.err != nil {
+			if failed == nil {
+				failed = result
+			}
+			continue
+		}
+		if verifiedProvenance == nil {
+			verifiedProvenance, builderID = result.provenance, result.builderID
+		}
+	}
+
+	// The failure of the index is the most relevant without provenance for
+	// any of its images, or if its provenance is signed but failed a check.
+	if indexErr != nil && (verifiedProvenance == nil || signatureVerified(indexRep)) {
+		rep.Merge(indexRep)
+		return nil, nil, indexErr
+	}
+	if indexErr == nil {
+		rep.Merge(indexRep)
+	}
+	if failed != nil {
+		return nil, nil, rep.Check(report.CheckPlatforms,
+			fmt.Errorf("%w: %s image %s: %w", serrors.ErrorUnverifiedPlatformImage,
+				failed.report.Platform, failed.report.Digest, failed.err))
+	}
+	builds := make([]*report.Platform, 0, len(results)+1)
+	if indexErr == nil {
+		builds = append(builds, &report.Platform{
+			Digest:       indexDigest,
+			BuilderID:    indexBuilderID.String(),
+			SourceCommit: indexRep.SourceCommit,
+		})
+	}
+	for _, result := range results {
+		builds = append(builds, result.report)
+	}
+	if err := rep.Check(report.CheckPlatforms, verifySameBuild(builds)); err != nil {
+		return nil, nil, err
+	}
+	return verifiedProvenance, builderID, nil
+}
+
+// signatureVerified returns true if the report records that the signature
+// of the provenance was verified.
+func signatureVerified(rep *report.Report) bool {
+	return slices.Contains(rep.Checks, report.Check{Name: report.CheckSignature, Status: report.StatusPassed})
+}
+
+// verifyPlatformImage verifies a platform image with its own provenance.
+func verifyPlatformImage(ctx context.Context, verifier register.SLSAVerifier,
+	provenance []byte, image, digest string,
+	provenanceOpts *options.ProvenanceOpts,
+	builderOpts *options.BuilderOpts,
+) *platformResult {
+	rep := report.FromContext(ctx)
+	opts := *provenanceOpts
+	opts.ExpectedDigest = strings.TrimPrefix(digest, "sha256:")
+	imageRep := report.New(image, digest)
+	verifiedProvenance, builderID, err := verifier.VerifyImage(report.NewContext(ctx, imageRep),
+		provenance, image, &opts, builderOpts)
+	rep.Merge(imageRep)
+	if err != nil {
+		return &platformResult{
+			report: &report.Platform{
+				Result: report.StatusFailed,
+				Error:  report.NewError(err),
+			},
+			err: err,
+		}
+	}
+	return &platformResult{
+		report: &report.Platform{
+			Result:       report.StatusPassed,
+			BuilderID:    builderID.String(),
+			SourceCommit: imageRep.SourceCommit,
+		},
+		provenance: verifiedProvenance,
+		builderID:  builderID,
+	}
+}
+
+// verifySameBuild verifies that the images are built by the same builder
+// from the same commit. The commit is not compared if it is not known.
+func verifySameBuild(images []*report.Platform) error {
+	if len(images) == 0 {
+		return nil
+	}
+	first := images[0]
+	for _, image := range images[1:] {
+		if image.BuilderID != first.BuilderID {
+			return fmt.Errorf("%w: builder %q of image %s, %q of image %s",
+				serrors.ErrorMismatchPlatformImages, first.BuilderID, first.Digest, image.BuilderID, image.Digest)
+		}
+		if first.SourceCommit != "" && image.SourceCommit != "" && image.SourceCommit != first.SourceCommit {
+			return fmt.Errorf("%w: commit %q of image %s, %q of image %s",
+				serrors.ErrorMismatchPlatformImages, first.SourceCommit, first.Digest, image.SourceCommit, image.Digest)
+		}
+	}
+	return nil
+}
+
+// subjectDigests returns the sha256 digests of the subjects of the in-toto
+// statement, e.g. sha256:abc.
+func subjectDigests(statement []byte) []string {
+	var s struct {
+		Subject []struct {
+			Digest map[string]string `json:"digest"`
+		} `json:"subject"`
+	}
+	if err := json.Unmarshal(statement, &s); err != nil {
+		return nil
+	}
+	var digests []string
+	for _, subject := range s.Subject {
+		if digest, ok := subject.Digest["sha256"]; ok {
+			digests = append(digests, "sha256:"+digest)
+		}
+	}
+	return digests
+}
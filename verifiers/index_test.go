@@ -0,0 +1,214 @@
+package verifiers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+
+	serrors "github.com/slsa-framework/slsa-verifier/v2/errors"
+	"github.com/slsa-framework/slsa-verifier/v2/options"
+	"github.com/slsa-framework/slsa-verifier/v2/register"
+	"github.com/slsa-framework/slsa-verifier/v2/report"
+	"github.com/slsa-framework/slsa-verifier/v2/verifiers/utils"
+	"github.com/slsa-framework/slsa-verifier/v2/verifiers/utils/container"
+)
+
+const (
+	testRepository  = "ghcr.io/org/app"
+	testIndexDigest = "sha256:1111111111111111111111111111111111111111111111111111111111111111"
+	testAmd64Digest = "sha256:2222222222222222222222222222222222222222222222222222222222222222"
+	testArm64Digest = "sha256:3333333333333333333333333333333333333333333333333333333333333333"
+	testBuilderID   = "https://github.com/slsa-framework/slsa-github-generator/.github/workflows/generator_container_slsa3.yml@refs/tags/v2.0.0"
+	testCommit      = "8d5a3b1c2e4f6a7b9c0d1e2f3a4b5c6d7e8f9a0b"
+)
+
+// testProvenance is the provenance of an image for fakeVerifier.
+type testProvenance struct {
+	subjects  []string
+	builderID string
+	commit    string
+	// err is the failure of a check of the signed provenance, if any.
+	err error
+}
+
+// fakeVerifier verifies the provenance of images by digest.
+type fakeVerifier struct {
+	register.SLSAVerifier
+	provenance map[string]testProvenance
+}
+
+func (v *fakeVerifier) VerifyImage(ctx context.Context,
+	_ []byte, artifactImage string,
+	provenanceOpts *options.ProvenanceOpts,
+	_ *options.BuilderOpts,
+) ([]byte, *utils.TrustedBuilderID, error) {
+	digest := artifactImage[strings.Index(artifactImage, "@")+1:]
+	if provenanceOpts.ExpectedDigest != strings.TrimPrefix(digest, "sha256:") {
+		return nil, nil, fmt.Errorf("%w: %s", serrors.ErrorMismatchHash, digest)
+	}
+	rep := report.FromContext(ctx)
+	prov, ok := v.provenance[digest]
+	var err error
+	if !ok {
+		err = fmt.Errorf("%w: %s", serrors.ErrorNoValidSignature, digest)
+	}
+	if err := rep.Check(report.CheckSignature, err); err != nil {
+		return nil, nil, err
+	}
+	if err := rep.Check(report.CheckSourceURI, prov.err); err != nil {
+		return nil, nil, err
+	}
+	rep.SetSource("https://github.com/org/app", prov.commit, "")
+
+	var statement struct {
+		Subject []map[string]map[string]string `json:"subject"`
+	}
+	for _, subject := range prov.subjects {
+		statement.Subject = append(statement.Subject,
+			map[string]map[string]string{"digest": {"sha256": strings.TrimPrefix(subject, "sha256:")}})
+	}
+	b, err := json.Marshal(statement)
+	if err != nil {
+		return nil, nil, err
+	}
+	builderID, err := utils.TrustedBuilderIDNew(prov.builderID, false)
+	if err != nil {
+		return nil, nil, err
+	}
+	return b, builderID, nil
+}
+
+func Test_verifyImageIndex(t *testing.T) {
+	t.Parallel()
+
+	indexProvenance := testProvenance{
+		subjects:  []string{testIndexDigest, testAmd64Digest, testArm64Digest},
+		builderID: testBuilderID,
+		commit:    testCommit,
+	}
+	imageProvenance := func(digest string) testProvenance {
+		return testProvenance{subjects: []string{digest}, builderID: testBuilderID, commit: testCommit}
+	}
+
+	tests := []struct {
+		name       string
+		provenance map[string]testProvenance
+		results    []report.Status
+		err        error
+	}{
+		{
+			name:       "index provenance of all images",
+			provenance: map[string]testProvenance{testIndexDigest: indexProvenance},
+			results:    []report.Status{report.StatusPassed, report.StatusPassed},
+		},
+		{
+			name: "provenance of each image",
+			provenance: map[string]testProvenance{
+				testIndexDigest: imageProvenance(testIndexDigest),
+				testAmd64Digest: imageProvenance(testAmd64Digest),
+				testArm64Digest: imageProvenance(testArm64Digest),
+			},
+			results: []report.Status{report.StatusPassed, report.StatusPassed},
+		},
+		{
+			name: "provenance of the platform images only",
+			provenance: map[string]testProvenance{
+				testAmd64Digest: imageProvenance(testAmd64Digest),
+				testArm64Digest: imageProvenance(testArm64Digest),
+			},
+			results: []report.Status{report.StatusPassed, report.StatusPassed},
+		},
+		{
+			name: "platform image without provenance",
+			provenance: map[string]testProvenance{
+				testIndexDigest: imageProvenance(testIndexDigest),
+				testAmd64Digest: imageProvenance(testAmd64Digest),
+			},
+			results: []report.Status{report.StatusPassed, report.StatusFailed},
+			err:     serrors.ErrorUnverifiedPlatformImage,
+		},
+		{
+			name: "index provenance failing a check",
+			provenance: map[string]testProvenance{
+				testIndexDigest: {
+					subjects:  []string{testIndexDigest},
+					builderID: testBuilderID,
+					commit:    testCommit,
+					err:       serrors.ErrorMismatchSource,
+				},
+				testAmd64Digest: imageProvenance(testAmd64Digest),
+				testArm64Digest: imageProvenance(testArm64Digest),
+			},
+			results: []report.Status{report.StatusPassed, report.StatusPassed},
+			err:     serrors.ErrorMismatchSource,
+		},
+		{
+			name:       "no provenance",
+			provenance: map[string]testProvenance{},
+			results:    []report.Status{report.StatusFailed, report.StatusFailed},
+			err:        serrors.ErrorNoValidSignature,
+		},
+		{
+			name: "other commit",
+			provenance: map[string]testProvenance{
+				testIndexDigest: imageProvenance(testIndexDigest),
+				testAmd64Digest: imageProvenance(testAmd64Digest),
+				testArm64Digest: {
+					subjects:  []string{testArm64Digest},
+					builderID: testBuilderID,
+					commit:    "fedcba",
+				},
+			},
+			results: []report.Status{report.StatusPassed, report.StatusPassed},
+			err:     serrors.ErrorMismatchPlatformImages,
+		},
+		{
+			name: "other builder",
+			provenance: map[string]testProvenance{
+				testIndexDigest: imageProvenance(testIndexDigest),
+				testAmd64Digest: {
+					subjects:  []string{testAmd64Digest},
+					builderID: "https://github.com/org/app/.github/workflows/release.yml@refs/tags/v1.0.0",
+					commit:    testCommit,
+				},
+				testArm64Digest: imageProvenance(testArm64Digest),
+			},
+			results: []report.Status{report.StatusPassed, report.StatusPassed},
+			err:     serrors.ErrorMismatchPlatformImages,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			rep := report.New(testRepository+"@"+testIndexDigest, testIndexDigest)
+			platforms := []container.PlatformImage{
+				{Digest: testAmd64Digest, Platform: "linux/amd64"},
+				{Digest: testArm64Digest, Platform: "linux/arm64"},
+			}
+			provenanceOpts := &options.ProvenanceOpts{ExpectedDigest: strings.TrimPrefix(testIndexDigest, "sha256:")}
+			_, builderID, err := verifyImageIndex(report.NewContext(context.Background(), rep),
+				&fakeVerifier{provenance: tt.provenance}, nil, testRepository+"@"+testIndexDigest,
+				platforms, provenanceOpts, &options.BuilderOpts{})
+			if diff := cmp.Diff(tt.err, err, cmpopts.EquateErrors()); diff != "" {
+				t.Fatalf("unexpected error (-want +got):\n%s", diff)
+			}
+			if err == nil && builderID.String() != testBuilderID {
+				t.Errorf("unexpected builder ID: want %q, got %q", testBuilderID, builderID.String())
+			}
+
+			var results []report.Status
+			for _, p := range rep.Platforms {
+				results = append(results, p.Result)
+			}
+			if diff := cmp.Diff(tt.results, results); diff != "" {
+				t.Errorf("unexpected platform results (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
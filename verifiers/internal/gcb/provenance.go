@@ -1,10 +1,10 @@
 package gcb
 
 import (
+	"context"
 	"crypto/sha256"
 	"encoding/json"
 	"fmt"
-	"os"
 	"reflect"
 	"strings"
 
@@ -81,10 +81,29 @@ func (p *Provenance) GetVerifiedIntotoStatement() ([]byte, error) {
 	return d, nil
 }
 
+// VerifyArtifactProvenance verifies that the provenance can be verified for
+// an artifact other than a container image: GCB only generates v1.0
+// provenance for those.
+func (p *Provenance) VerifyArtifactProvenance() error {
+	if err := p.isVerified(); err != nil {
+		return err
+	}
+
+	predicateType, err := p.verifiedStatement.PredicateType()
+	if err != nil {
+		return err
+	}
+	if predicateType != v10.PredicateSLSAProvenance {
+		return fmt.Errorf("%w: artifacts with %q provenance", serrors.ErrorNotSupported, predicateType)
+	}
+	return nil
+}
+
 // VerifyMetadata verifies additional metadata contained in the provenance, which is not part
 // of the DSSE payload or headers. It is part of the payload returned by
 // `gcloud artifacts docker images describe image:tag --format json --show-provenance`.
-func (p *Provenance) VerifyMetadata(provenanceOpts *options.ProvenanceOpts) error {
+// isArtifact is true for artifacts other than container images.
+func (p *Provenance) VerifyMetadata(provenanceOpts *options.ProvenanceOpts, isArtifact bool) error {
 	if err := p.isVerified(); err != nil {
 		return err
 	}
@@ -98,41 +117,52 @@ func (p *Provenance) VerifyMetadata(provenanceOpts *options.ProvenanceOpts) erro
 		return fmt.Errorf("%w: expected kind to be 'BUILD', got %s", serrors.ErrorInvalidFormat, prov.Kind)
 	}
 
+	// The `ResourceURI` of other artifacts is the URL of the package,
+	// without a digest: the digest is verified in the DSSE intoto payload.
+	if isArtifact {
+		return nil
+	}
+
 	// Note: this could be verified in `VerifySourceURI`, but it is kept here
 	// because it is not part of the DSSE intoto payload.
 	// The `ResourceURI` is container@sha256:hash, without the tag.
 	// We only verify the URI's sha256 for simplicity.
 	if !strings.HasSuffix(prov.ResourceURI, "@sha256:"+provenanceOpts.ExpectedDigest) {
-		return fmt.Errorf("%w: expected resourceUri '%s', got '%s'",
-			serrors.ErrorMismatchHash, provenanceOpts.ExpectedDigest, prov.ResourceURI)
+		return serrors.NewVerificationError(fmt.Errorf("%w: expected resourceUri '%s', got '%s'",
+			serrors.ErrorMismatchHash, provenanceOpts.ExpectedDigest, prov.ResourceURI),
+			provenanceOpts.ExpectedDigest, prov.ResourceURI)
 	}
 	return nil
 }
 
 // VerifySummary verifies the content of the `image_summary` structure
 // returned by `gcloud artifacts docker images describe image:tag --format json --show-provenance`.
-func (p *Provenance) VerifySummary(provenanceOpts *options.ProvenanceOpts) error {
+// isArtifact is true for artifacts other than container images.
+func (p *Provenance) VerifySummary(provenanceOpts *options.ProvenanceOpts, isArtifact bool) error {
 	if err := p.isVerified(); err != nil {
 		return err
 	}
 
-	if provenanceOpts == nil {
+	// Only the provenance of container images has a summary.
+	if provenanceOpts == nil || isArtifact {
 		return nil
 	}
 
 	// Validate the digest.
 	if p.gcloudProv.ImageSummary.Digest != "sha256:"+provenanceOpts.ExpectedDigest {
-		return fmt.Errorf("%w: expected summary digest '%s', got '%s'",
+		return serrors.NewVerificationError(fmt.Errorf("%w: expected summary digest '%s', got '%s'",
 			serrors.ErrorMismatchHash, provenanceOpts.ExpectedDigest,
-			p.gcloudProv.ImageSummary.Digest)
+			p.gcloudProv.ImageSummary.Digest),
+			provenanceOpts.ExpectedDigest, p.gcloudProv.ImageSummary.Digest)
 	}
 
 	// Validate the qualified digest.
 	if !strings.HasSuffix(p.gcloudProv.ImageSummary.FullyQualifiedDigest,
 		"sha256:"+provenanceOpts.ExpectedDigest) {
-		return fmt.Errorf("%w: expected fully qualifiedd digest '%s', got '%s'",
+		return serrors.NewVerificationError(fmt.Errorf("%w: expected fully qualifiedd digest '%s', got '%s'",
 			serrors.ErrorMismatchHash, provenanceOpts.ExpectedDigest,
-			p.gcloudProv.ImageSummary.FullyQualifiedDigest)
+			p.gcloudProv.ImageSummary.FullyQualifiedDigest),
+			provenanceOpts.ExpectedDigest, p.gcloudProv.ImageSummary.FullyQualifiedDigest)
 	}
 	return nil
 }
@@ -339,8 +369,8 @@ func (p *Provenance) VerifyBuilder(builderOpts *options.BuilderOpts) (*utils.Tru
 		}
 
 		if ts != expectedType {
-			return nil, fmt.Errorf("%w: expected '%s', got '%s'", serrors.ErrorMismatchBuilderID,
-				expectedType, ts)
+			return nil, serrors.NewVerificationError(fmt.Errorf("%w: expected '%s', got '%s'", serrors.ErrorMismatchBuilderID,
+				expectedType, ts), expectedType, ts)
 		}
 	default:
 		return nil, fmt.Errorf("%w: unknown type %v", serrors.ErrorInvalidFormat, v)
@@ -382,7 +412,8 @@ func (p *Provenance) VerifySubjectDigest(expectedHash string) error {
 		}
 	}
 
-	return fmt.Errorf("expected hash '%s' not found: %w", expectedHash, serrors.ErrorMismatchHash)
+	return serrors.NewVerificationError(
+		fmt.Errorf("expected hash '%s' not found: %w", expectedHash, serrors.ErrorMismatchHash), expectedHash, "")
 }
 
 func verifySourceURIV01(builderID utils.TrustedBuilderID, provenanceURI, expectedSourceURI string) error {
@@ -396,8 +427,8 @@ func verifySourceURIV01(builderID utils.TrustedBuilderID, provenanceURI, expecte
 		// `"gs://damith-sds_cloudbuild/source/1665165360.279777-955d1904741e4bbeb3461080299e929a.tgz#1665165361152729"`.
 		if !strings.HasPrefix(provenanceURI, expectedSourceURI+"/commit/") &&
 			!strings.HasPrefix(provenanceURI, expectedSourceURI+"#") {
-			return fmt.Errorf("%w: expected '%s', got '%s'",
-				serrors.ErrorMismatchSource, expectedSourceURI, provenanceURI)
+			return serrors.NewVerificationError(fmt.Errorf("%w: expected '%s', got '%s'",
+				serrors.ErrorMismatchSource, expectedSourceURI, provenanceURI), expectedSourceURI, provenanceURI)
 		}
 	case "v0.3":
 		// In v0.3, it uses the standard intoto and has the commit sha in its own `digest.sha1` field.
@@ -405,8 +436,8 @@ func verifySourceURIV01(builderID utils.TrustedBuilderID, provenanceURI, expecte
 		// `"gs://damith-sds_cloudbuild/source/1665165360.279777-955d1904741e4bbeb3461080299e929a.tgz#1665165361152729"`.
 		if provenanceURI != expectedSourceURI &&
 			!strings.HasPrefix(provenanceURI, expectedSourceURI+"#") {
-			return fmt.Errorf("%w: expected '%s', got '%s'",
-				serrors.ErrorMismatchSource, expectedSourceURI, provenanceURI)
+			return serrors.NewVerificationError(fmt.Errorf("%w: expected '%s', got '%s'",
+				serrors.ErrorMismatchSource, expectedSourceURI, provenanceURI), expectedSourceURI, provenanceURI)
 		}
 	default:
 		err = fmt.Errorf("%w: version '%s'",
@@ -423,14 +454,14 @@ func verifySourceURIV10(builderID utils.TrustedBuilderID, provenanceURI, expecte
 			serrors.ErrorInvalidFormat, provenanceURI)
 	}
 	if parts[0] != expectedSourceURI {
-		return fmt.Errorf("%w: expected '%s', got '%s'",
-			serrors.ErrorMismatchSource, expectedSourceURI, parts[0])
+		return serrors.NewVerificationError(fmt.Errorf("%w: expected '%s', got '%s'",
+			serrors.ErrorMismatchSource, expectedSourceURI, parts[0]), expectedSourceURI, parts[0])
 	}
 	return nil
 }
 
 // Verify source URI in provenance statement.
-func (p *Provenance) VerifySourceURI(expectedSourceURI string, builderID utils.TrustedBuilderID) error {
+func (p *Provenance) VerifySourceURI(ctx context.Context, expectedSourceURI string, builderID utils.TrustedBuilderID) error {
 	if err := p.isVerified(); err != nil {
 		return err
 	}
@@ -452,7 +483,7 @@ func (p *Provenance) VerifySourceURI(expectedSourceURI string, builderID utils.T
 
 	// The build was not configured with a GitHub trigger. Warn.
 	if strings.HasPrefix(uri, "gs://") {
-		fmt.Fprintf(os.Stderr, `This build was not configured with a GitHub trigger `+
+		utils.Logf(ctx, `This build was not configured with a GitHub trigger `+
 			`and will not match on an expected, version controlled source URI. `+
 			`See Cloud Build's documentation on building repositories from GitHub: `+
 			`https://cloud.google.com/build/docs/automating-builds/github/build-repos-from-github`)
@@ -489,6 +520,27 @@ func (p *Provenance) VerifyBranch(branch string) error {
 	return nil
 }
 
+// VerifyBranchPatterns verifies that the branch matches one of the patterns.
+func (p *Provenance) VerifyBranchPatterns(patterns []string) error {
+	if err := p.isVerified(); err != nil {
+		return err
+	}
+
+	provBranch, err := p.verifiedStatement.SourceBranch()
+	if err != nil {
+		return err
+	}
+	ok, err := utils.MatchesAnyPattern(provBranch, patterns)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("%w: expected branch matching one of %q, got %q",
+			serrors.ErrorNotSupported, patterns, provBranch)
+	}
+	return nil
+}
+
 func (p *Provenance) VerifyTag(expectedTag string) error {
 	provenanceTag, err := p.getTag()
 	if err != nil {
@@ -496,8 +548,26 @@ func (p *Provenance) VerifyTag(expectedTag string) error {
 	}
 
 	if provenanceTag != expectedTag {
-		return fmt.Errorf("%w: expected '%s', got '%s'",
-			serrors.ErrorMismatchTag, expectedTag, provenanceTag)
+		return serrors.NewVerificationError(fmt.Errorf("%w: expected '%s', got '%s'",
+			serrors.ErrorMismatchTag, expectedTag, provenanceTag), expectedTag, provenanceTag)
+	}
+	return nil
+}
+
+// VerifyTagPatterns verifies that the tag matches one of the patterns.
+func (p *Provenance) VerifyTagPatterns(patterns []string) error {
+	provenanceTag, err := p.getTag()
+	if err != nil {
+		return fmt.Errorf("%w: %v", serrors.ErrorMismatchTag, err.Error())
+	}
+
+	ok, err := utils.MatchesAnyPattern(provenanceTag, patterns)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("%w: expected tag matching one of %q, got '%s'",
+			serrors.ErrorMismatchTag, patterns, provenanceTag)
 	}
 	return nil
 }
@@ -520,7 +590,7 @@ func (p *Provenance) getTag() (string, error) {
 
 // verifySignatures iterates over all the signatures in the DSSE and verifies them.
 // It succeeds if one of them can be verified.
-func (p *Provenance) verifySignatures(prov *provenance) error {
+func (p *Provenance) verifySignatures(ctx context.Context, prov *provenance) error {
 	// Verify the envelope type. It should be an intoto type.
 	if prov.Envelope.PayloadType != intoto.PayloadType {
 		return fmt.Errorf("%w: expected payload type '%s', got %s",
@@ -607,7 +677,7 @@ func (p *Provenance) verifySignatures(prov *provenance) error {
 
 		p.verifiedStatement = stmt
 		p.verifiedProvenance = prov
-		fmt.Fprintf(os.Stderr, "Verification succeeded with key %q\n", keyName)
+		utils.Logf(ctx, "Verification succeeded with key %q\n", keyName)
 		return nil
 	}
 
@@ -615,7 +685,7 @@ func (p *Provenance) verifySignatures(prov *provenance) error {
 }
 
 // VerifySignature verifiers the signature for a provenance.
-func (p *Provenance) VerifySignature() error {
+func (p *Provenance) VerifySignature(ctx context.Context) error {
 	if len(p.gcloudProv.ProvenanceSummary.Provenance) == 0 {
 		return fmt.Errorf("%w: no provenance found", serrors.ErrorInvalidDssePayload)
 	}
@@ -623,7 +693,7 @@ func (p *Provenance) VerifySignature() error {
 	// Iterate over all provenances available.
 	var errs []error
 	for i := range p.gcloudProv.ProvenanceSummary.Provenance {
-		err := p.verifySignatures(&p.gcloudProv.ProvenanceSummary.Provenance[i])
+		err := p.verifySignatures(ctx, &p.gcloudProv.ProvenanceSummary.Provenance[i])
 		if err != nil {
 			errs = append(errs, err)
 			continue
@@ -1,6 +1,7 @@
 package gcb
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
@@ -577,7 +578,7 @@ func Test_VerifySourceURI(t *testing.T) {
 			if err != nil {
 				panic(fmt.Errorf("BuilderIDNew: %w", err))
 			}
-			err = prov.VerifySourceURI(tt.source, *builderID)
+			err = prov.VerifySourceURI(context.Background(), tt.source, *builderID)
 			if !cmp.Equal(err, tt.expected, cmpopts.EquateErrors()) {
 				t.Error(cmp.Diff(err, tt.expected, cmpopts.EquateErrors()))
 			}
@@ -668,6 +669,10 @@ func Test_VerifySignature(t *testing.T) {
 			name: "signature global v1.0 pae valid",
 			path: "./testdata/v1.0-gcloud-container-github-single.json",
 		},
+		{
+			name: "signature global v1.0 pae valid artifact",
+			path: "./testdata/v1.0-gcloud-artifact-github.json",
+		},
 		{
 			name:     "signature global v1.0 pae invalid",
 			path:     "./testdata/v1.0-gcloud-container-github-single-invalid-sig.json",
@@ -702,7 +707,7 @@ func Test_VerifySignature(t *testing.T) {
 				panic(fmt.Errorf("ProvenanceFromBytes: %w", err))
 			}
 
-			err = prov.VerifySignature()
+			err = prov.VerifySignature(context.Background())
 			if !cmp.Equal(err, tt.expected, cmpopts.EquateErrors()) {
 				t.Error(cmp.Diff(err, tt.expected, cmpopts.EquateErrors()))
 			}
@@ -819,11 +824,12 @@ func Test_VerifySubjectDigest(t *testing.T) {
 func Test_VerifySummary(t *testing.T) {
 	t.Parallel()
 	tests := []struct {
-		name     string
-		path     string
-		hash     string
-		version  string
-		expected error
+		name       string
+		path       string
+		hash       string
+		version    string
+		isArtifact bool
+		expected   error
 	}{
 		// v0.1 provenance.
 		{
@@ -862,6 +868,20 @@ func Test_VerifySummary(t *testing.T) {
 			version:  versionV10,
 			expected: serrors.ErrorMismatchHash,
 		},
+		{
+			name:       "v1.0 artifact without summary",
+			path:       "./testdata/v1.0-gcloud-artifact-github.json",
+			hash:       "7e9b6e7ba2842c91cf49f3e214d04a7a496f8214356f41d81a6e6dcad11f11e3",
+			version:    versionV10,
+			isArtifact: true,
+		},
+		{
+			name:     "v1.0 image without summary",
+			path:     "./testdata/v1.0-gcloud-artifact-github.json",
+			hash:     "7e9b6e7ba2842c91cf49f3e214d04a7a496f8214356f41d81a6e6dcad11f11e3",
+			version:  versionV10,
+			expected: serrors.ErrorMismatchHash,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -887,7 +907,7 @@ func Test_VerifySummary(t *testing.T) {
 			provenanceOpts := options.ProvenanceOpts{
 				ExpectedDigest: tt.hash,
 			}
-			err = prov.VerifySummary(&provenanceOpts)
+			err = prov.VerifySummary(&provenanceOpts, tt.isArtifact)
 			if !cmp.Equal(err, tt.expected, cmpopts.EquateErrors()) {
 				t.Error(cmp.Diff(err, tt.expected, cmpopts.EquateErrors()))
 			}
@@ -898,11 +918,12 @@ func Test_VerifySummary(t *testing.T) {
 func Test_VerifyMetadata(t *testing.T) {
 	t.Parallel()
 	tests := []struct {
-		name     string
-		path     string
-		hash     string
-		version  string
-		expected error
+		name       string
+		path       string
+		hash       string
+		version    string
+		isArtifact bool
+		expected   error
 	}{
 		// v0.1 provenance.
 		{
@@ -943,6 +964,20 @@ func Test_VerifyMetadata(t *testing.T) {
 			version:  versionV10,
 			expected: serrors.ErrorInvalidFormat,
 		},
+		{
+			name:       "v1.0 artifact package URI",
+			path:       "./testdata/v1.0-gcloud-artifact-github.json",
+			hash:       "7e9b6e7ba2842c91cf49f3e214d04a7a496f8214356f41d81a6e6dcad11f11e3",
+			version:    versionV10,
+			isArtifact: true,
+		},
+		{
+			name:     "v1.0 image package URI",
+			path:     "./testdata/v1.0-gcloud-artifact-github.json",
+			hash:     "7e9b6e7ba2842c91cf49f3e214d04a7a496f8214356f41d81a6e6dcad11f11e3",
+			version:  versionV10,
+			expected: serrors.ErrorMismatchHash,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -968,7 +1003,53 @@ func Test_VerifyMetadata(t *testing.T) {
 			provenanceOpts := options.ProvenanceOpts{
 				ExpectedDigest: tt.hash,
 			}
-			err = prov.VerifyMetadata(&provenanceOpts)
+			err = prov.VerifyMetadata(&provenanceOpts, tt.isArtifact)
+			if !cmp.Equal(err, tt.expected, cmpopts.EquateErrors()) {
+				t.Error(cmp.Diff(err, tt.expected, cmpopts.EquateErrors()))
+			}
+		})
+	}
+}
+
+func Test_VerifyArtifactProvenance(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		path     string
+		version  string
+		expected error
+	}{
+		{
+			name:     "v0.1 provenance",
+			path:     "./testdata/gcloud-container-github.json",
+			version:  versionV01,
+			expected: serrors.ErrorNotSupported,
+		},
+		{
+			name:    "v1.0 provenance",
+			path:    "./testdata/v1.0-gcloud-artifact-github.json",
+			version: versionV10,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			content, err := os.ReadFile(tt.path)
+			if err != nil {
+				panic(fmt.Errorf("os.ReadFile: %w", err))
+			}
+
+			prov, err := ProvenanceFromBytes(content)
+			if err != nil {
+				panic(fmt.Errorf("ProvenanceFromBytes: %w", err))
+			}
+
+			if err := setStatement(prov, tt.version); err != nil {
+				panic(fmt.Errorf("setStatement: %w", err))
+			}
+
+			err = prov.VerifyArtifactProvenance()
 			if !cmp.Equal(err, tt.expected, cmpopts.EquateErrors()) {
 				t.Error(cmp.Diff(err, tt.expected, cmpopts.EquateErrors()))
 			}
@@ -6,6 +6,7 @@ import (
 	serrors "github.com/slsa-framework/slsa-verifier/v2/errors"
 	"github.com/slsa-framework/slsa-verifier/v2/options"
 	register "github.com/slsa-framework/slsa-verifier/v2/register"
+	"github.com/slsa-framework/slsa-verifier/v2/report"
 	_ "github.com/slsa-framework/slsa-verifier/v2/verifiers/internal/gcb/keys"
 	"github.com/slsa-framework/slsa-verifier/v2/verifiers/utils"
 )
@@ -36,7 +37,7 @@ func (v *GCBVerifier) VerifyArtifact(ctx context.Context,
 	provenanceOpts *options.ProvenanceOpts,
 	builderOpts *options.BuilderOpts,
 ) ([]byte, *utils.TrustedBuilderID, error) {
-	return nil, nil, serrors.ErrorNotSupported
+	return verifyProvenance(ctx, provenance, provenanceOpts, builderOpts, true)
 }
 
 // VerifyNpmPackage verifies an npm package tarball.
@@ -54,68 +55,105 @@ func (v *GCBVerifier) VerifyImage(ctx context.Context,
 	provenanceOpts *options.ProvenanceOpts,
 	builderOpts *options.BuilderOpts,
 ) ([]byte, *utils.TrustedBuilderID, error) {
+	return verifyProvenance(ctx, provenance, provenanceOpts, builderOpts, false)
+}
+
+// verifyProvenance verifies the provenance of a container image, or of
+// another artifact if isArtifact is true.
+func verifyProvenance(ctx context.Context, provenance []byte,
+	provenanceOpts *options.ProvenanceOpts,
+	builderOpts *options.BuilderOpts,
+	isArtifact bool,
+) ([]byte, *utils.TrustedBuilderID, error) {
+	rep := report.FromContext(ctx)
 	prov, err := ProvenanceFromBytes(provenance)
 	if err != nil {
 		return nil, nil, err
 	}
 
 	// Verify signature on the intoto attestation.
-	if err := prov.VerifySignature(); err != nil {
+	stop := rep.Time(report.StageSignature)
+	err = prov.VerifySignature(ctx)
+	stop()
+	if err := rep.Check(report.CheckSignature, err); err != nil {
 		return nil, nil, err
 	}
+	defer rep.Time(report.StageProvenance)()
+
+	// Verify the version of the provenance of artifacts.
+	if isArtifact {
+		if err := rep.Check(report.CheckMetadata, prov.VerifyArtifactProvenance()); err != nil {
+			return nil, nil, err
+		}
+	}
 
 	// Verify the builder.
 	builderID, err := prov.VerifyBuilder(builderOpts)
-	if err != nil {
+	if err := rep.Check(report.CheckBuilderID, err); err != nil {
 		return nil, nil, err
 	}
 
 	// Verify subject digest.
-	if err := prov.VerifySubjectDigest(provenanceOpts.ExpectedDigest); err != nil {
+	if err := rep.Check(report.CheckSubjectDigest, prov.VerifySubjectDigest(provenanceOpts.ExpectedDigest)); err != nil {
 		return nil, nil, err
 	}
 
 	// Verify source.
-	if err := prov.VerifySourceURI(provenanceOpts.ExpectedSourceURI, *builderID); err != nil {
+	if err := rep.Check(report.CheckSourceURI, prov.VerifySourceURI(ctx, provenanceOpts.ExpectedSourceURI, *builderID)); err != nil {
 		return nil, nil, err
 	}
 
 	// Verify metadata.
 	// This is metadata that GCB appends to the DSSE content.
-	if err := prov.VerifyMetadata(provenanceOpts); err != nil {
+	if err := rep.Check(report.CheckMetadata, prov.VerifyMetadata(provenanceOpts, isArtifact)); err != nil {
 		return nil, nil, err
 	}
 
 	// Verify the summary.
-	// This is an additional structure that GCB prepends to the provenance.
-	if err := prov.VerifySummary(provenanceOpts); err != nil {
+	// This is an additional structure that GCB prepends to the provenance
+	// of container images.
+	if err := rep.Check(report.CheckSummary, prov.VerifySummary(provenanceOpts, isArtifact)); err != nil {
 		return nil, nil, err
 	}
 
 	// Verify the text provenance.
 	// This is an additional structure that GCB prepends to the provenance,
 	// intended for humans. It reflect the DSSE payload.
-	if err := prov.VerifyTextProvenance(); err != nil {
+	if err := rep.Check(report.CheckTextProvenance, prov.VerifyTextProvenance()); err != nil {
 		return nil, nil, err
 	}
 
 	// Verify branch.
 	if provenanceOpts.ExpectedBranch != nil {
-		if err := prov.VerifyBranch(*provenanceOpts.ExpectedBranch); err != nil {
+		if err := rep.Check(report.CheckBranch, prov.VerifyBranch(*provenanceOpts.ExpectedBranch)); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	// Verify the branch patterns.
+	if len(provenanceOpts.ExpectedBranchPatterns) > 0 {
+		if err := rep.Check(report.CheckBranch, prov.VerifyBranchPatterns(provenanceOpts.ExpectedBranchPatterns)); err != nil {
 			return nil, nil, err
 		}
 	}
 
 	// Verify the tag.
 	if provenanceOpts.ExpectedTag != nil {
-		if err := prov.VerifyTag(*provenanceOpts.ExpectedTag); err != nil {
+		if err := rep.Check(report.CheckTag, prov.VerifyTag(*provenanceOpts.ExpectedTag)); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	// Verify the tag patterns.
+	if len(provenanceOpts.ExpectedTagPatterns) > 0 {
+		if err := rep.Check(report.CheckTag, prov.VerifyTagPatterns(provenanceOpts.ExpectedTagPatterns)); err != nil {
 			return nil, nil, err
 		}
 	}
 
 	// Verify the versioned tag.
 	if provenanceOpts.ExpectedVersionedTag != nil {
-		if err := prov.VerifyVersionedTag(*provenanceOpts.ExpectedVersionedTag); err != nil {
+		if err := rep.Check(report.CheckVersionedTag, prov.VerifyVersionedTag(*provenanceOpts.ExpectedVersionedTag)); err != nil {
 			return nil, nil, err
 		}
 	}
@@ -0,0 +1,91 @@
+package gcb
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+
+	serrors "github.com/slsa-framework/slsa-verifier/v2/errors"
+	"github.com/slsa-framework/slsa-verifier/v2/options"
+)
+
+func Test_VerifyArtifact(t *testing.T) {
+	t.Parallel()
+
+	builderID := "https://cloudbuild.googleapis.com/GoogleHostedWorker"
+	tests := []struct {
+		name   string
+		path   string
+		hash   string
+		source string
+		branch string
+		err    error
+	}{
+		{
+			name:   "v1.0 artifact provenance",
+			path:   "./testdata/v1.0-gcloud-artifact-github.json",
+			hash:   "7e9b6e7ba2842c91cf49f3e214d04a7a496f8214356f41d81a6e6dcad11f11e3",
+			source: "github.com/khalkie/gcb-prod-prov",
+		},
+		{
+			name:   "v1.0 container provenance",
+			path:   "./testdata/v1.0-gcloud-container-github-single.json",
+			hash:   "7e9b6e7ba2842c91cf49f3e214d04a7a496f8214356f41d81a6e6dcad11f11e3",
+			source: "github.com/khalkie/gcb-prod-prov",
+		},
+		{
+			name:   "v0.1 provenance",
+			path:   "./testdata/gcloud-container-github.json",
+			hash:   "1a033b002f89ed2b8ea733162497fb70f1a4049a7f8602d6a33682b4ad9921fd",
+			source: "github.com/laurentsimon/gcb-tests",
+			err:    serrors.ErrorNotSupported,
+		},
+		{
+			name:   "mismatch digest",
+			path:   "./testdata/v1.0-gcloud-artifact-github.json",
+			hash:   "7e9b6e7ba2842c91cf49f3e214d04a7a496f8214356f41d81a6e6dcad11f11e4",
+			source: "github.com/khalkie/gcb-prod-prov",
+			err:    serrors.ErrorMismatchHash,
+		},
+		{
+			name:   "mismatch source",
+			path:   "./testdata/v1.0-gcloud-artifact-github.json",
+			hash:   "7e9b6e7ba2842c91cf49f3e214d04a7a496f8214356f41d81a6e6dcad11f11e3",
+			source: "github.com/khalkie/other",
+			err:    serrors.ErrorMismatchSource,
+		},
+		{
+			name:   "mismatch branch",
+			path:   "./testdata/v1.0-gcloud-artifact-github.json",
+			hash:   "7e9b6e7ba2842c91cf49f3e214d04a7a496f8214356f41d81a6e6dcad11f11e3",
+			source: "github.com/khalkie/gcb-prod-prov",
+			branch: "release",
+			err:    serrors.ErrorNotSupported,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			content, err := os.ReadFile(tt.path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			provenanceOpts := &options.ProvenanceOpts{
+				ExpectedDigest:    tt.hash,
+				ExpectedSourceURI: tt.source,
+			}
+			if tt.branch != "" {
+				provenanceOpts.ExpectedBranch = &tt.branch
+			}
+			_, _, err = GCBVerifierNew().VerifyArtifact(context.Background(), content, tt.hash,
+				provenanceOpts, &options.BuilderOpts{ExpectedID: &builderID})
+			if diff := cmp.Diff(tt.err, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("unexpected error (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
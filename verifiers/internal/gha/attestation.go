@@ -0,0 +1,267 @@
+package gha
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+
+	"github.com/secure-systems-lab/go-securesystemslib/dsse"
+
+	serrors "github.com/slsa-framework/slsa-verifier/v2/errors"
+	"github.com/slsa-framework/slsa-verifier/v2/options"
+	"github.com/slsa-framework/slsa-verifier/v2/report"
+	"github.com/slsa-framework/slsa-verifier/v2/verifiers/internal/gha/slsaprovenance"
+	"github.com/slsa-framework/slsa-verifier/v2/verifiers/internal/gha/slsaprovenance/common"
+	slsav1 "github.com/slsa-framework/slsa-verifier/v2/verifiers/internal/gha/slsaprovenance/v1.0"
+	"github.com/slsa-framework/slsa-verifier/v2/verifiers/utils"
+)
+
+// GitHub artifact attestations are generated and signed by the workflow
+// that builds the artifact, using actions/attest-build-provenance.
+// Unlike the provenance of the builders of slsa-github-generator, the
+// signer is not a trusted builder: callers provide the workflow they trust,
+// and the fields of the provenance are verified against the certificate.
+// See https://docs.github.com/en/actions/security-guides/using-artifact-attestations-to-establish-provenance-for-builds.
+
+// isArtifactAttestation returns true if the envelope contains the provenance
+// of a GitHub artifact attestation.
+func isArtifactAttestation(env *dsse.Envelope) bool {
+	pyld, err := base64.StdEncoding.DecodeString(env.Payload)
+	if err != nil {
+		return false
+	}
+	var statement struct {
+		PredicateType string `json:"predicateType"`
+		Predicate     struct {
+			BuildDefinition struct {
+				BuildType string `json:"buildType"`
+			} `json:"buildDefinition"`
+		} `json:"predicate"`
+	}
+	if err := json.Unmarshal(pyld, &statement); err != nil {
+		return false
+	}
+	return statement.PredicateType == common.ProvenanceV1Type &&
+		statement.Predicate.BuildDefinition.BuildType == common.GitHubActionsBuildTypeV1
+}
+
+// verifyAttestationEnvAndCert verifies a GitHub artifact attestation whose
+// signature has been verified.
+func verifyAttestationEnvAndCert(ctx context.Context, env *dsse.Envelope,
+	cert *x509.Certificate,
+	provenanceOpts *options.ProvenanceOpts,
+	builderOpts *options.BuilderOpts,
+) ([]byte, *utils.TrustedBuilderID, error) {
+	rep := report.FromContext(ctx)
+	defer rep.Time(report.StageProvenance)()
+	host, err := newGitHubHost(provenanceOpts.GitHubOpts)
+	if err != nil {
+		return nil, nil, err
+	}
+	workflowInfo, err := GetWorkflowInfoFromCertificate(cert)
+	if err != nil {
+		return nil, nil, rep.Check(report.CheckBuilderID, err)
+	}
+	recordSource(rep, host, workflowInfo)
+
+	// Verify the workflow that signed the attestation.
+	signerID, err := verifyAttestationSigner(workflowInfo, host, builderOpts)
+	if err := rep.Check(report.CheckBuilderID, err); err != nil {
+		return nil, nil, err
+	}
+	rep.SetBuilderTrust(report.BuilderTrustWorkflow)
+
+	// Verify the source repository from the certificate.
+	if err := VerifyCertficateSourceRepository(workflowInfo, host, provenanceOpts.ExpectedSourceURI); err != nil {
+		return nil, nil, rep.Check(report.CheckSourceURI, err)
+	}
+
+	prov, err := slsaprovenance.ProvenanceFromEnvelope(signerID.Name(), env)
+	if err != nil {
+		return nil, nil, err
+	}
+	ghaProv, ok := prov.(*slsav1.GitHubActionsProvenance)
+	if !ok {
+		return nil, nil, fmt.Errorf("%w: unexpected provenance for buildType %q",
+			serrors.ErrorInvalidBuildType, common.GitHubActionsBuildTypeV1)
+	}
+
+	// The provenance is generated by the workflow, so every field
+	// must match the certificate.
+	if err := rep.Check(report.CheckMetadata,
+		verifyAttestationMatchesCertificate(ghaProv, host, workflowInfo)); err != nil {
+		return nil, nil, err
+	}
+
+	provenanceOpts = withExpectedBuilderID(provenanceOpts, signerID.String())
+	if err := VerifyProvenanceCommonOptions(ctx, prov, provenanceOpts); err != nil {
+		return nil, nil, err
+	}
+
+	utils.Logf(ctx, "Verified build using workflow %q at commit %s\n",
+		signerID.String(),
+		workflowInfo.SourceSha1)
+
+	r, err := base64.StdEncoding.DecodeString(env.Payload)
+	if err != nil {
+		return nil, nil, err
+	}
+	return r, signerID, nil
+}
+
+// verifyAttestationSigner verifies the workflow that signed an attestation
+// against the builder ID provided by the caller, which is required: any
+// workflow of the source repository may sign an attestation.
+func verifyAttestationSigner(id *WorkflowIdentity, host *githubHost,
+	builderOpts *options.BuilderOpts,
+) (*utils.TrustedBuilderID, error) {
+	if err := verifyIssuerAndHost(id, host); err != nil {
+		return nil, err
+	}
+	if builderOpts == nil || builderOpts.ExpectedID == nil || *builderOpts.ExpectedID == "" {
+		return nil, fmt.Errorf("%w: no expected builder ID: the workflow that signed the attestation must be provided",
+			serrors.ErrorInvalidBuilderID)
+	}
+
+	workflowID := id.SubjectWorkflowName()
+	workflowRef := id.SubjectWorkflowRef()
+	if workflowID == "" || workflowRef == "" {
+		return nil, fmt.Errorf("%w: workflow uri: %q", serrors.ErrorMalformedURI, id.SubjectWorkflow.String())
+	}
+	signerID, err := utils.TrustedBuilderIDNew(workflowID+"@"+workflowRef, true)
+	if err != nil {
+		return nil, err
+	}
+	if err := signerID.MatchesLoose(*builderOpts.ExpectedID, true); err != nil {
+		return nil, fmt.Errorf("%w: %v", serrors.ErrorUntrustedReusableWorkflow, err)
+	}
+	return signerID, nil
+}
+
+// verifyAttestationMatchesCertificate verifies the fields of the provenance of
+// an artifact attestation against the certificate. Fields that cannot be
+// verified are rejected.
+func verifyAttestationMatchesCertificate(prov *slsav1.GitHubActionsProvenance, host *githubHost,
+	workflow *WorkflowIdentity,
+) error {
+	predicate := prov.Predicate()
+
+	// Verify the builder, which is the workflow that signed the attestation.
+	builderID := predicate.RunDetails.Builder.ID
+	if builderID != workflow.SubjectWorkflow.String() {
+		return fmt.Errorf("%w: builder ID: '%s' != '%s'", serrors.ErrorMismatchCertificate,
+			builderID, workflow.SubjectWorkflow.String())
+	}
+
+	// Verify the workflow parameters.
+	if err := verifyAttestationExternalParameters(prov, host, workflow); err != nil {
+		return err
+	}
+
+	// Verify the GitHub parameters.
+	if err := verifyAttestationInternalParameters(prov, workflow); err != nil {
+		return err
+	}
+
+	// Verify the source commit. The source URI is verified against
+	// the trigger by the common verification.
+	if err := verifyResolvedDependencies(prov); err != nil {
+		return err
+	}
+	if err := equalCertificateValue(&workflow.SourceSha1,
+		predicate.BuildDefinition.ResolvedDependencies[0].Digest["gitCommit"], "source commit"); err != nil {
+		return err
+	}
+
+	// Verify the run.
+	if workflow.RunID == nil {
+		return fmt.Errorf("%w: empty certificate value to verify 'invocationId'",
+			serrors.ErrorMismatchCertificate)
+	}
+	invocationID := fmt.Sprintf("%s%s/actions/runs/%s", host.url(), workflow.SourceRepository, *workflow.RunID)
+	if err := equalCertificateValue(&invocationID,
+		predicate.RunDetails.BuildMetadata.InvocationID, "invocationId"); err != nil {
+		return err
+	}
+	if predicate.RunDetails.BuildMetadata.StartedOn != nil ||
+		predicate.RunDetails.BuildMetadata.FinishedOn != nil {
+		return fmt.Errorf("%w: build start or finish time", serrors.ErrorNonVerifiableClaim)
+	}
+	if len(predicate.RunDetails.Byproducts) > 0 {
+		return fmt.Errorf("%w: byproducts", serrors.ErrorNonVerifiableClaim)
+	}
+	return nil
+}
+
+func verifyAttestationExternalParameters(prov *slsav1.GitHubActionsProvenance, host *githubHost,
+	workflow *WorkflowIdentity,
+) error {
+	externalParams, ok := prov.Predicate().BuildDefinition.ExternalParameters.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("%w: %s", serrors.ErrorInvalidDssePayload, "external parameters type")
+	}
+	for k := range externalParams {
+		if k != "workflow" {
+			return fmt.Errorf("%w: unknown '%s' parameter", serrors.ErrorMismatchCertificate, k)
+		}
+	}
+	workflowParams, ok := externalParams["workflow"].(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("%w: %s", serrors.ErrorInvalidFormat, "workflow parameters")
+	}
+	repository := host.url() + workflow.SourceRepository
+	supportedNames := map[string]*string{
+		"repository": &repository,
+		"ref":        workflow.SourceRef,
+		"path":       workflow.BuildConfigPath,
+	}
+	return verifyParametersMatchCertificate(workflowParams, supportedNames)
+}
+
+func verifyAttestationInternalParameters(prov *slsav1.GitHubActionsProvenance, workflow *WorkflowIdentity) error {
+	sysParams, err := prov.GetSystemParameters()
+	if err != nil {
+		return err
+	}
+	for k := range sysParams {
+		if k != "github" {
+			return fmt.Errorf("%w: unknown '%s' parameter", serrors.ErrorMismatchCertificate, k)
+		}
+	}
+	githubParams, ok := sysParams["github"].(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("%w: %s", serrors.ErrorInvalidFormat, "github parameters")
+	}
+	var runnerEnvironment *string
+	if workflow.SubjectHosted != nil {
+		env := string(hostedSelf)
+		if *workflow.SubjectHosted == HostedGitHub {
+			env = string(hostedGitHub)
+		}
+		runnerEnvironment = &env
+	}
+	supportedNames := map[string]*string{
+		"event_name":          &workflow.BuildTrigger,
+		"repository_id":       workflow.SourceID,
+		"repository_owner_id": workflow.SourceOwnerID,
+		"runner_environment":  runnerEnvironment,
+	}
+	return verifyParametersMatchCertificate(githubParams, supportedNames)
+}
+
+// verifyParametersMatchCertificate verifies that the parameters contain only
+// the supported names, and that their values match the certificate.
+func verifyParametersMatchCertificate(params map[string]any, supportedNames map[string]*string) error {
+	for k := range params {
+		certValue, ok := supportedNames[k]
+		if !ok {
+			return fmt.Errorf("%w: unknown '%s' parameter", serrors.ErrorMismatchCertificate, k)
+		}
+		if err := verifySystemParameter(params, k, certValue); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,399 @@
+package gha
+
+import (
+	"context"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/json"
+	"net/url"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/secure-systems-lab/go-securesystemslib/dsse"
+	fulcio "github.com/sigstore/fulcio/pkg/certificate"
+
+	serrors "github.com/slsa-framework/slsa-verifier/v2/errors"
+	"github.com/slsa-framework/slsa-verifier/v2/options"
+	"github.com/slsa-framework/slsa-verifier/v2/verifiers/internal/gha/slsaprovenance/common"
+	slsav1 "github.com/slsa-framework/slsa-verifier/v2/verifiers/internal/gha/slsaprovenance/v1.0"
+)
+
+const testAttestationWorkflow = "https://github.com/org/repo/.github/workflows/release.yml"
+
+// newTestAttestationStatement returns the statement of an artifact attestation
+// generated by actions/attest-build-provenance.
+func newTestAttestationStatement() map[string]interface{} {
+	return map[string]interface{}{
+		"_type":         "https://in-toto.io/Statement/v1",
+		"predicateType": common.ProvenanceV1Type,
+		"subject": []interface{}{
+			map[string]interface{}{
+				"name":   "artifact",
+				"digest": map[string]interface{}{"sha256": "0a2b4c"},
+			},
+		},
+		"predicate": map[string]interface{}{
+			"buildDefinition": map[string]interface{}{
+				"buildType": common.GitHubActionsBuildTypeV1,
+				"externalParameters": map[string]interface{}{
+					"workflow": map[string]interface{}{
+						"ref":        "refs/tags/v1.0.0",
+						"repository": "https://github.com/org/repo",
+						"path":       ".github/workflows/release.yml",
+					},
+				},
+				"internalParameters": map[string]interface{}{
+					"github": map[string]interface{}{
+						"event_name":          "push",
+						"repository_id":       "123",
+						"repository_owner_id": "456",
+						"runner_environment":  "github-hosted",
+					},
+				},
+				"resolvedDependencies": []interface{}{
+					map[string]interface{}{
+						"uri":    "git+https://github.com/org/repo@refs/tags/v1.0.0",
+						"digest": map[string]interface{}{"gitCommit": "abcdef"},
+					},
+				},
+			},
+			"runDetails": map[string]interface{}{
+				"builder": map[string]interface{}{
+					"id": testAttestationWorkflow + "@refs/tags/v1.0.0",
+				},
+				"metadata": map[string]interface{}{
+					"invocationId": "https://github.com/org/repo/actions/runs/789/attempts/1",
+				},
+			},
+		},
+	}
+}
+
+func newTestAttestationWorkflowIdentity(t *testing.T) *WorkflowIdentity {
+	t.Helper()
+	subject, err := url.Parse(testAttestationWorkflow + "@refs/tags/v1.0.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ref := "refs/tags/v1.0.0"
+	sourceID := "123"
+	ownerID := "456"
+	configPath := ".github/workflows/release.yml"
+	runID := "789/attempts/1"
+	hosted := HostedGitHub
+	return &WorkflowIdentity{
+		SourceRepository: "org/repo",
+		SourceSha1:       "abcdef",
+		SourceRef:        &ref,
+		SourceID:         &sourceID,
+		SourceOwnerID:    &ownerID,
+		SubjectWorkflow:  subject,
+		SubjectHosted:    &hosted,
+		BuildTrigger:     "push",
+		BuildConfigPath:  &configPath,
+		RunID:            &runID,
+		Issuer:           certOidcIssuer,
+	}
+}
+
+// newTestAttestationCertificate returns the certificate of the workflow of
+// newTestAttestationWorkflowIdentity.
+func newTestAttestationCertificate(t *testing.T) *x509.Certificate {
+	t.Helper()
+	claims := []struct {
+		id    asn1.ObjectIdentifier
+		value string
+	}{
+		{fulcio.OIDIssuerV2, certOidcIssuer},
+		{fulcio.OIDBuildTrigger, "push"},
+		{fulcio.OIDSourceRepositoryURI, "https://github.com/org/repo"},
+		{fulcio.OIDSourceRepositoryDigest, "abcdef"},
+		{fulcio.OIDSourceRepositoryRef, "refs/tags/v1.0.0"},
+		{fulcio.OIDSourceRepositoryIdentifier, "123"},
+		{fulcio.OIDSourceRepositoryOwnerIdentifier, "456"},
+		{fulcio.OIDRunnerEnvironment, "github-hosted"},
+		{fulcio.OIDBuildConfigURI, testAttestationWorkflow + "@refs/tags/v1.0.0"},
+		{fulcio.OIDBuildConfigDigest, "abcdef"},
+		{fulcio.OIDRunInvocationURI, "https://github.com/org/repo/actions/runs/789/attempts/1"},
+	}
+	cert := &x509.Certificate{
+		URIs: []*url.URL{Must(url.Parse(testAttestationWorkflow + "@refs/tags/v1.0.0"))},
+	}
+	for _, claim := range claims {
+		value, err := asn1.MarshalWithParams(claim.value, "utf8")
+		if err != nil {
+			t.Fatal(err)
+		}
+		cert.Extensions = append(cert.Extensions, pkix.Extension{Id: claim.id, Value: value})
+	}
+	return cert
+}
+
+func newTestEnvelope(t *testing.T, statement map[string]interface{}) *dsse.Envelope {
+	t.Helper()
+	pyld, err := json.Marshal(statement)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &dsse.Envelope{
+		PayloadType: "application/vnd.in-toto+json",
+		Payload:     base64.StdEncoding.EncodeToString(pyld),
+	}
+}
+
+func predicateOf(statement map[string]interface{}) map[string]interface{} {
+	return statement["predicate"].(map[string]interface{})
+}
+
+func buildDefinitionOf(statement map[string]interface{}) map[string]interface{} {
+	return predicateOf(statement)["buildDefinition"].(map[string]interface{})
+}
+
+func Test_isArtifactAttestation(t *testing.T) {
+	t.Parallel()
+
+	statement := newTestAttestationStatement()
+	if !isArtifactAttestation(newTestEnvelope(t, statement)) {
+		t.Errorf("artifact attestation not detected")
+	}
+
+	buildDefinitionOf(statement)["buildType"] = common.BYOBBuildTypeV0
+	if isArtifactAttestation(newTestEnvelope(t, statement)) {
+		t.Errorf("unexpected artifact attestation for buildType %q", common.BYOBBuildTypeV0)
+	}
+
+	if isArtifactAttestation(&dsse.Envelope{Payload: "not base64"}) {
+		t.Errorf("unexpected artifact attestation for invalid payload")
+	}
+}
+
+func Test_verifyAttestationSigner(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		issuer   string
+		expected *string
+		err      error
+	}{
+		{
+			name:     "matching workflow",
+			expected: asStringPointer(testAttestationWorkflow),
+		},
+		{
+			name:     "matching workflow and ref",
+			expected: asStringPointer(testAttestationWorkflow + "@refs/tags/v1.0.0"),
+		},
+		{
+			name:     "other ref",
+			expected: asStringPointer(testAttestationWorkflow + "@refs/heads/main"),
+			err:      serrors.ErrorUntrustedReusableWorkflow,
+		},
+		{
+			name:     "other workflow",
+			expected: asStringPointer("https://github.com/org/repo/.github/workflows/other.yml"),
+			err:      serrors.ErrorUntrustedReusableWorkflow,
+		},
+		{
+			name: "no expected workflow",
+			err:  serrors.ErrorInvalidBuilderID,
+		},
+		{
+			name:     "other issuer",
+			issuer:   "https://other.example.com",
+			expected: asStringPointer(testAttestationWorkflow),
+			err:      serrors.ErrorInvalidOIDCIssuer,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			id := newTestAttestationWorkflowIdentity(t)
+			if tt.issuer != "" {
+				id.Issuer = tt.issuer
+			}
+			signerID, err := verifyAttestationSigner(id, defaultGitHubHost, &options.BuilderOpts{ExpectedID: tt.expected})
+			if diff := cmp.Diff(tt.err, err, cmpopts.EquateErrors()); diff != "" {
+				t.Fatalf("unexpected error (-want +got):\n%s", diff)
+			}
+			if err != nil {
+				return
+			}
+			if want := testAttestationWorkflow + "@refs/tags/v1.0.0"; signerID.String() != want {
+				t.Errorf("unexpected signer: got %q, want %q", signerID.String(), want)
+			}
+		})
+	}
+}
+
+func Test_verifyAttestationMatchesCertificate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		mutate func(statement map[string]interface{}, id *WorkflowIdentity)
+		err    error
+	}{
+		{
+			name: "matching",
+		},
+		{
+			name: "other builder",
+			mutate: func(statement map[string]interface{}, _ *WorkflowIdentity) {
+				predicateOf(statement)["runDetails"].(map[string]interface{})["builder"] = map[string]interface{}{
+					"id": "https://github.com/org/repo/.github/workflows/other.yml@refs/tags/v1.0.0",
+				}
+			},
+			err: serrors.ErrorMismatchCertificate,
+		},
+		{
+			name: "other workflow repository",
+			mutate: func(statement map[string]interface{}, _ *WorkflowIdentity) {
+				buildDefinitionOf(statement)["externalParameters"].(map[string]interface{})["workflow"].(map[string]interface{})["repository"] = "https://github.com/org/other"
+			},
+			err: serrors.ErrorMismatchCertificate,
+		},
+		{
+			name: "other workflow path",
+			mutate: func(statement map[string]interface{}, _ *WorkflowIdentity) {
+				buildDefinitionOf(statement)["externalParameters"].(map[string]interface{})["workflow"].(map[string]interface{})["path"] = ".github/workflows/other.yml"
+			},
+			err: serrors.ErrorMismatchCertificate,
+		},
+		{
+			name: "unknown external parameter",
+			mutate: func(statement map[string]interface{}, _ *WorkflowIdentity) {
+				buildDefinitionOf(statement)["externalParameters"].(map[string]interface{})["inputs"] = map[string]interface{}{}
+			},
+			err: serrors.ErrorMismatchCertificate,
+		},
+		{
+			name: "other repository ID",
+			mutate: func(statement map[string]interface{}, _ *WorkflowIdentity) {
+				buildDefinitionOf(statement)["internalParameters"].(map[string]interface{})["github"].(map[string]interface{})["repository_id"] = "999"
+			},
+			err: serrors.ErrorMismatchCertificate,
+		},
+		{
+			name: "self-hosted runner",
+			mutate: func(_ map[string]interface{}, id *WorkflowIdentity) {
+				hosted := HostedSelf
+				id.SubjectHosted = &hosted
+			},
+			err: serrors.ErrorMismatchCertificate,
+		},
+		{
+			name: "unknown internal parameter",
+			mutate: func(statement map[string]interface{}, _ *WorkflowIdentity) {
+				buildDefinitionOf(statement)["internalParameters"].(map[string]interface{})["github"].(map[string]interface{})["sha"] = "abcdef"
+			},
+			err: serrors.ErrorMismatchCertificate,
+		},
+		{
+			name: "other commit",
+			mutate: func(_ map[string]interface{}, id *WorkflowIdentity) {
+				id.SourceSha1 = "fedcba"
+			},
+			err: serrors.ErrorMismatchCertificate,
+		},
+		{
+			name: "other run",
+			mutate: func(_ map[string]interface{}, id *WorkflowIdentity) {
+				runID := "789/attempts/2"
+				id.RunID = &runID
+			},
+			err: serrors.ErrorMismatchCertificate,
+		},
+		{
+			name: "start time",
+			mutate: func(statement map[string]interface{}, _ *WorkflowIdentity) {
+				predicateOf(statement)["runDetails"].(map[string]interface{})["metadata"].(map[string]interface{})["startedOn"] = "2024-01-01T00:00:00Z"
+			},
+			err: serrors.ErrorNonVerifiableClaim,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			statement := newTestAttestationStatement()
+			id := newTestAttestationWorkflowIdentity(t)
+			if tt.mutate != nil {
+				tt.mutate(statement, id)
+			}
+			pyld, err := json.Marshal(statement)
+			if err != nil {
+				t.Fatal(err)
+			}
+			prov, err := slsav1.New(testAttestationWorkflow, pyld)
+			if err != nil {
+				t.Fatal(err)
+			}
+			ghaProv, ok := prov.(*slsav1.GitHubActionsProvenance)
+			if !ok {
+				t.Fatalf("unexpected provenance type %T", prov)
+			}
+
+			err = verifyAttestationMatchesCertificate(ghaProv, defaultGitHubHost, id)
+			if diff := cmp.Diff(tt.err, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("unexpected error (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_verifyImageEnvAndCert(t *testing.T) {
+	t.Parallel()
+	const testImageDigest = "2b3c4d5e6f708192a3b4c5d6e7f8091a2b3c4d5e6f708192a3b4c5d6e7f80912"
+
+	tests := []struct {
+		name      string
+		buildType string
+		err       error
+	}{
+		{
+			name:      "artifact attestation",
+			buildType: common.GitHubActionsBuildTypeV1,
+		},
+		{
+			// The workflow is not a builder of the buildType.
+			name:      "provenance of a builder",
+			buildType: common.ContainerGeneratorBuildTypeV1,
+			err:       serrors.ErrorInvalidDssePayload,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			statement := newTestAttestationStatement()
+			buildDefinitionOf(statement)["buildType"] = tt.buildType
+			statement["subject"] = []interface{}{
+				map[string]interface{}{
+					"name":   "ghcr.io/org/image",
+					"digest": map[string]interface{}{"sha256": testImageDigest},
+				},
+			}
+			provenanceOpts := &options.ProvenanceOpts{
+				ExpectedSourceURI: "github.com/org/repo",
+				ExpectedDigest:    testImageDigest,
+			}
+			builderOpts := &options.BuilderOpts{ExpectedID: asStringPointer(testAttestationWorkflow + "@refs/tags/v1.0.0")}
+			_, builderID, err := verifyImageEnvAndCert(context.Background(), newTestEnvelope(t, statement),
+				newTestAttestationCertificate(t), provenanceOpts, builderOpts)
+			if diff := cmp.Diff(tt.err, err, cmpopts.EquateErrors()); diff != "" {
+				t.Fatalf("unexpected error (-want +got):\n%s", diff)
+			}
+			if err != nil {
+				return
+			}
+			if want := testAttestationWorkflow + "@refs/tags/v1.0.0"; builderID.String() != want {
+				t.Errorf("unexpected builder: got %q, want %q", builderID.String(), want)
+			}
+		})
+	}
+}
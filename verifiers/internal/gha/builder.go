@@ -46,15 +46,15 @@ var JReleaserRepository = httpsGithubCom + jReleaserActionRepository
 
 // VerifyCertficateSourceRepository verifies the source repository.
 func VerifyCertficateSourceRepository(id *WorkflowIdentity,
-	sourceRepo string,
+	host *githubHost, sourceRepo string,
 ) error {
 	// The caller repository in the x509 extension is not fully qualified. It only contains
 	// {org}/{repository}.
 	expectedSource := strings.TrimPrefix(sourceRepo, "git+https://")
-	expectedSource = strings.TrimPrefix(expectedSource, githubCom)
+	expectedSource = strings.TrimPrefix(expectedSource, host.name+"/")
 	if id.SourceRepository != expectedSource {
-		return fmt.Errorf("%w: expected source '%s', got '%s'", serrors.ErrorMismatchSource,
-			expectedSource, id.SourceRepository)
+		return serrors.NewVerificationError(fmt.Errorf("%w: expected source '%s', got '%s'", serrors.ErrorMismatchSource,
+			expectedSource, id.SourceRepository), expectedSource, id.SourceRepository)
 	}
 	return nil
 }
@@ -62,15 +62,20 @@ func VerifyCertficateSourceRepository(id *WorkflowIdentity,
 // VerifyBuilderIdentity verifies the signing certificate information.
 // Builder IDs are verified against an expected builder ID provided in the
 // builerOpts, or against the set of defaultBuilders provided. The identiy
-// in the certificate corresponds to a GitHub workflow's path.
+// in the certificate corresponds to a GitHub workflow's path on the host.
+// defaultBuilders are IDs on github.com, which are mapped to the host.
+// customBuilders are trusted if they generate the provenance of one of
+// the defaultBuilders.
 func VerifyBuilderIdentity(id *WorkflowIdentity,
+	host *githubHost,
 	builderOpts *options.BuilderOpts,
 	defaultBuilders map[string]bool,
+	customBuilders []customBuilder,
 ) (*utils.TrustedBuilderID, bool, error) {
 	// Issuer verification.
 	// NOTE: this is necessary before we do any further verification.
-	if id.Issuer != certOidcIssuer {
-		return nil, false, fmt.Errorf("%w: %q", serrors.ErrorInvalidOIDCIssuer, id.Issuer)
+	if err := verifyIssuerAndHost(id, host); err != nil {
+		return nil, false, err
 	}
 
 	// cert URI is https://github.com/org/repo/path/to/workflow@ref
@@ -83,12 +88,21 @@ func VerifyBuilderIdentity(id *WorkflowIdentity,
 	}
 
 	// Verify trusted workflow.
-	builderID, byob, err := verifyTrustedBuilderID(workflowID, workflowTag,
-		builderOpts.ExpectedID, defaultBuilders)
+	customBuilders = trustedFor(customBuilders, defaultBuilders)
+	builderID, byob, err := verifyTrustedBuilderID(host, workflowID, workflowTag,
+		builderOpts.ExpectedID, host.trustedBuilders(defaultBuilders), customBuilders)
 	if err != nil {
 		return nil, byob, err
 	}
 
+	// Verify the ref of custom builders against their patterns.
+	if b := findCustomBuilder(customBuilders, workflowID); b != nil {
+		if err := b.verifyRef(workflowTag); err != nil {
+			return nil, byob, err
+		}
+		return builderID, byob, nil
+	}
+
 	// Verify the ref is a full semantic version tag.
 	if err := verifyTrustedBuilderRef(id, workflowTag); err != nil {
 		return nil, byob, err
@@ -97,16 +111,32 @@ func VerifyBuilderIdentity(id *WorkflowIdentity,
 	return builderID, byob, nil
 }
 
+// verifyIssuerAndHost verifies that the certificate was issued to a workflow
+// of the GitHub host.
+func verifyIssuerAndHost(id *WorkflowIdentity, host *githubHost) error {
+	if id.Issuer != host.oidcIssuer {
+		return fmt.Errorf("%w: %q", serrors.ErrorInvalidOIDCIssuer, id.Issuer)
+	}
+	if id.SubjectWorkflow.Host != host.name {
+		return fmt.Errorf("%w: workflow host %q, expected %q", serrors.ErrorMismatchCertificate,
+			id.SubjectWorkflow.Host, host.name)
+	}
+	return nil
+}
+
 // Verifies the builder ID at path against an expected builderID.
-// If an expected builderID is not provided, uses the defaultBuilders.
-func verifyTrustedBuilderID(certBuilderID, certTag string, expectedBuilderID *string, defaultTrustedBuilders map[string]bool) (*utils.TrustedBuilderID, bool, error) {
+// If an expected builderID is not provided, uses the defaultBuilders
+// and the customBuilders.
+func verifyTrustedBuilderID(host *githubHost, certBuilderID, certTag string, expectedBuilderID *string, defaultTrustedBuilders map[string]bool,
+	customBuilders []customBuilder,
+) (*utils.TrustedBuilderID, bool, error) {
 	var trustedBuilderID *utils.TrustedBuilderID
 	var err error
 	// WARNING: we don't validate the tag here, because we need to allow
 	// refs/heads/main for e2e tests. See verifyTrustedBuilderRef().
 	// No builder ID provided by user: use the default trusted workflows.
 	if expectedBuilderID == nil || *expectedBuilderID == "" {
-		if _, ok := defaultTrustedBuilders[certBuilderID]; !ok {
+		if _, ok := defaultTrustedBuilders[certBuilderID]; !ok && findCustomBuilder(customBuilders, certBuilderID) == nil {
 			return nil, false, fmt.Errorf("%w: %s with builderID provided: %t", serrors.ErrorUntrustedReusableWorkflow, certBuilderID, expectedBuilderID != nil)
 		}
 
@@ -124,11 +154,11 @@ func verifyTrustedBuilderID(certBuilderID, certTag string, expectedBuilderID *st
 		//
 		// This return of the delegator builderID enables non-compulsory
 		// builderID feature for BYOB builders by setting byob flag to true.
-		return trustedBuilderID, isTrustedDelegatorBuilder(trustedBuilderID, defaultTrustedBuilders), nil
+		return trustedBuilderID, isTrustedDelegatorBuilder(host, trustedBuilderID, defaultTrustedBuilders, customBuilders), nil
 	}
 
 	// Verify the builderID.
-	// We only accept IDs on github.com.
+	// We only accept IDs on the GitHub host.
 	trustedBuilderID, err = utils.TrustedBuilderIDNew(certBuilderID+"@"+certTag, true)
 	if err != nil {
 		return nil, false, err
@@ -139,7 +169,7 @@ func verifyTrustedBuilderID(certBuilderID, certTag string, expectedBuilderID *st
 	// - the caller trusts the BYOB builder
 	// If both are true, we don't match the user-provided builder ID
 	// against the certificate. Instead that will be done by the caller.
-	if isTrustedDelegatorBuilder(trustedBuilderID, defaultTrustedBuilders) {
+	if isTrustedDelegatorBuilder(host, trustedBuilderID, defaultTrustedBuilders, customBuilders) {
 		return trustedBuilderID, true, nil
 	}
 
@@ -153,8 +183,10 @@ func verifyTrustedBuilderID(certBuilderID, certTag string, expectedBuilderID *st
 	return trustedBuilderID, false, nil
 }
 
-func isTrustedDelegatorBuilder(certBuilder *utils.TrustedBuilderID, trustedBuilders map[string]bool) bool {
-	for byobBuilder := range defaultBYOBReusableWorkflows {
+func isTrustedDelegatorBuilder(host *githubHost, certBuilder *utils.TrustedBuilderID, trustedBuilders map[string]bool,
+	customBuilders []customBuilder,
+) bool {
+	for byobBuilder := range host.trustedBuilders(defaultBYOBReusableWorkflows) {
 		// Check that the certificate builder is a BYOB workflow.
 		if err := certBuilder.MatchesLoose(byobBuilder, true); err == nil {
 			// We found a delegator workflow that matches the certificate identity.
@@ -165,6 +197,15 @@ func isTrustedDelegatorBuilder(certBuilder *utils.TrustedBuilderID, trustedBuild
 			return true
 		}
 	}
+	// Custom delegator workflows are trusted by the user.
+	for i := range customBuilders {
+		if !customBuilders[i].delegator() {
+			continue
+		}
+		if err := certBuilder.MatchesLoose(customBuilders[i].id, true); err == nil {
+			return true
+		}
+	}
 	return false
 }
 
@@ -335,6 +376,9 @@ func GetWorkflowInfoFromCertificate(cert *x509.Certificate) (*WorkflowIdentity,
 	if len(cert.URIs) == 0 {
 		return nil, fmt.Errorf("%w: missing URI information from certificate", serrors.ErrorInvalidFormat)
 	}
+	// The URL of the GitHub instance, e.g. https://github.com/.
+	// It is verified against the expected host by VerifyBuilderIdentity.
+	serverURL := "https://" + cert.URIs[0].Host + "/"
 
 	// 1.3.6.1.4.1.57264.1.2: DEPRECATED.
 	// https://github.com/sigstore/fulcio/blob/main/docs/oid-info.md#1361415726412--github-workflow-BuildTrigger-deprecated
@@ -385,11 +429,11 @@ func GetWorkflowInfoFromCertificate(cert *x509.Certificate) (*WorkflowIdentity,
 		return nil, err
 	}
 	if deprecatedSourceRepository != "" && sourceURI != "" &&
-		httpsGithubCom+deprecatedSourceRepository != sourceURI {
+		serverURL+deprecatedSourceRepository != sourceURI {
 		return nil, fmt.Errorf("%w: '%v' != '%v'",
-			serrors.ErrorInvalidFormat, httpsGithubCom+deprecatedSourceRepository, sourceURI)
+			serrors.ErrorInvalidFormat, serverURL+deprecatedSourceRepository, sourceURI)
 	}
-	sourceRepository := strings.TrimPrefix(sourceURI, httpsGithubCom)
+	sourceRepository := strings.TrimPrefix(sourceURI, serverURL)
 	// Handle old certifcates.
 	if sourceRepository == "" {
 		sourceRepository = deprecatedSourceRepository
@@ -443,7 +487,7 @@ func GetWorkflowInfoFromCertificate(cert *x509.Certificate) (*WorkflowIdentity,
 			return nil, fmt.Errorf("%w: %v",
 				serrors.ErrorInvalidFormat, buildConfigURI)
 		}
-		prefix := fmt.Sprintf("https://github.com/%v/", sourceRepository)
+		prefix := fmt.Sprintf("%s%v/", serverURL, sourceRepository)
 		if !strings.HasPrefix(parts[0], prefix) {
 			return nil, fmt.Errorf("%w: prefix: %v",
 				serrors.ErrorInvalidFormat, parts[0])
@@ -457,7 +501,7 @@ func GetWorkflowInfoFromCertificate(cert *x509.Certificate) (*WorkflowIdentity,
 	if err != nil {
 		return nil, err
 	}
-	runID := strings.TrimPrefix(runURI, fmt.Sprintf("https://github.com/%s/actions/runs/", sourceRepository))
+	runID := strings.TrimPrefix(runURI, fmt.Sprintf("%s%s/actions/runs/", serverURL, sourceRepository))
 
 	// Subject path.
 	if !strings.HasPrefix(cert.URIs[0].Path, "/") {
@@ -36,12 +36,15 @@ func Must[T any](val T, err error) T {
 
 func Test_VerifyBuilderIdentity(t *testing.T) {
 	t.Parallel()
+	ghes := &githubHost{name: "ghes.example.com", oidcIssuer: "https://ghes.example.com/_services/token"}
 	tests := []struct {
 		name      string
 		workflow  *WorkflowIdentity
+		host      *githubHost
 		buildOpts *options.BuilderOpts
 		builderID string
 		defaults  map[string]bool
+		custom    []customBuilder
 		err       error
 		byob      bool
 	}{
@@ -308,6 +311,153 @@ func Test_VerifyBuilderIdentity(t *testing.T) {
 			defaults: defaultContainerTrustedReusableWorkflows,
 			err:      serrors.ErrorUntrustedReusableWorkflow,
 		},
+		{
+			name: "valid ghes trusted builder",
+			workflow: &WorkflowIdentity{
+				SourceRepository: "asraa/slsa-on-github-test",
+				SourceSha1:       "0dfcd24824432c4ce587f79c918eef8fc2c44d7b",
+				SubjectWorkflow:  Must(url.Parse(ghes.builderID(common.GoBuilderID) + refs123)),
+				BuildTrigger:     "workflow_dispatch",
+				Issuer:           ghes.oidcIssuer,
+			},
+			host:      ghes,
+			defaults:  defaultArtifactTrustedReusableWorkflows,
+			builderID: ghes.url() + trustedBuilderRepository + builderGoSlsa3,
+		},
+		{
+			name: "valid ghes generic delegator builder",
+			workflow: &WorkflowIdentity{
+				SourceRepository: "asraa/slsa-on-github-test",
+				SourceSha1:       "0dfcd24824432c4ce587f79c918eef8fc2c44d7b",
+				SubjectWorkflow:  Must(url.Parse(ghes.builderID(common.GenericDelegatorBuilderID) + refs123)),
+				BuildTrigger:     "workflow_dispatch",
+				Issuer:           ghes.oidcIssuer,
+			},
+			host:      ghes,
+			defaults:  defaultBYOBReusableWorkflows,
+			builderID: ghes.builderID(common.GenericDelegatorBuilderID),
+			byob:      true,
+		},
+		{
+			name: "ghes untrusted cert issuer",
+			workflow: &WorkflowIdentity{
+				SourceRepository: "asraa/slsa-on-github-test",
+				SourceSha1:       "0dfcd24824432c4ce587f79c918eef8fc2c44d7b",
+				SubjectWorkflow:  Must(url.Parse(ghes.builderID(common.GoBuilderID) + refs123)),
+				BuildTrigger:     "workflow_dispatch",
+				Issuer:           certOidcIssuer,
+			},
+			host:     ghes,
+			defaults: defaultArtifactTrustedReusableWorkflows,
+			err:      serrors.ErrorInvalidOIDCIssuer,
+		},
+		{
+			name: "github.com builder on ghes",
+			workflow: &WorkflowIdentity{
+				SourceRepository: "asraa/slsa-on-github-test",
+				SourceSha1:       "0dfcd24824432c4ce587f79c918eef8fc2c44d7b",
+				SubjectWorkflow:  Must(url.Parse(common.GoBuilderID + refs123)),
+				BuildTrigger:     "workflow_dispatch",
+				Issuer:           ghes.oidcIssuer,
+			},
+			host:     ghes,
+			defaults: defaultArtifactTrustedReusableWorkflows,
+			err:      serrors.ErrorMismatchCertificate,
+		},
+		{
+			name: "valid custom builder",
+			workflow: &WorkflowIdentity{
+				SourceRepository: "asraa/slsa-on-github-test",
+				SourceSha1:       "0dfcd24824432c4ce587f79c918eef8fc2c44d7b",
+				SubjectWorkflow:  Must(url.Parse(httpsGithubCom + "org/builders" + builderGoSlsa3 + refs123)),
+				BuildTrigger:     "workflow_dispatch",
+				Issuer:           certOidcIssuer,
+			},
+			defaults: defaultArtifactTrustedReusableWorkflows,
+			custom: []customBuilder{{
+				id:        httpsGithubCom + "org/builders" + builderGoSlsa3,
+				builtinID: common.GoBuilderID,
+			}},
+			builderID: httpsGithubCom + "org/builders" + builderGoSlsa3,
+		},
+		{
+			name: "custom builder for other artifacts",
+			workflow: &WorkflowIdentity{
+				SourceRepository: "asraa/slsa-on-github-test",
+				SourceSha1:       "0dfcd24824432c4ce587f79c918eef8fc2c44d7b",
+				SubjectWorkflow:  Must(url.Parse(httpsGithubCom + "org/builders" + builderGoSlsa3 + refs123)),
+				BuildTrigger:     "workflow_dispatch",
+				Issuer:           certOidcIssuer,
+			},
+			defaults: defaultContainerTrustedReusableWorkflows,
+			custom: []customBuilder{{
+				id:        httpsGithubCom + "org/builders" + builderGoSlsa3,
+				builtinID: common.GoBuilderID,
+			}},
+			err: serrors.ErrorUntrustedReusableWorkflow,
+		},
+		{
+			name: "custom builder at a branch",
+			workflow: &WorkflowIdentity{
+				SourceRepository: "asraa/slsa-on-github-test",
+				SourceSha1:       "0dfcd24824432c4ce587f79c918eef8fc2c44d7b",
+				SubjectWorkflow:  Must(url.Parse(httpsGithubCom + "org/builders" + builderGoSlsa3 + "@refs/heads/main")),
+				BuildTrigger:     "workflow_dispatch",
+				Issuer:           certOidcIssuer,
+			},
+			defaults: defaultArtifactTrustedReusableWorkflows,
+			custom: []customBuilder{{
+				id:        httpsGithubCom + "org/builders" + builderGoSlsa3,
+				builtinID: common.GoBuilderID,
+			}},
+			err: serrors.ErrorInvalidRef,
+		},
+		{
+			name: "custom builder at a branch matching its ref patterns",
+			workflow: &WorkflowIdentity{
+				SourceRepository: "asraa/slsa-on-github-test",
+				SourceSha1:       "0dfcd24824432c4ce587f79c918eef8fc2c44d7b",
+				SubjectWorkflow:  Must(url.Parse(httpsGithubCom + "org/builders" + builderGoSlsa3 + "@refs/heads/main")),
+				BuildTrigger:     "workflow_dispatch",
+				Issuer:           certOidcIssuer,
+			},
+			defaults: defaultArtifactTrustedReusableWorkflows,
+			custom: []customBuilder{{
+				id:          httpsGithubCom + "org/builders" + builderGoSlsa3,
+				builtinID:   common.GoBuilderID,
+				refPatterns: []string{"refs/heads/main", "refs/tags/v*"},
+			}},
+			builderID: httpsGithubCom + "org/builders" + builderGoSlsa3,
+		},
+		{
+			name: "custom delegator builder",
+			workflow: &WorkflowIdentity{
+				SourceRepository: "asraa/slsa-on-github-test",
+				SourceSha1:       "0dfcd24824432c4ce587f79c918eef8fc2c44d7b",
+				SubjectWorkflow:  Must(url.Parse(httpsGithubCom + "org/builders" + delegatorGenericSlsa3 + refs123)),
+				BuildTrigger:     "workflow_dispatch",
+				Issuer:           certOidcIssuer,
+			},
+			defaults: defaultBYOBReusableWorkflows,
+			custom: []customBuilder{{
+				id:        httpsGithubCom + "org/builders" + delegatorGenericSlsa3,
+				builtinID: common.GenericDelegatorBuilderID,
+			}},
+			builderID: httpsGithubCom + "org/builders" + delegatorGenericSlsa3,
+			byob:      true,
+		},
+		{
+			name: "ghes builder on github.com",
+			workflow: &WorkflowIdentity{
+				SourceRepository: "asraa/slsa-on-github-test",
+				SourceSha1:       "0dfcd24824432c4ce587f79c918eef8fc2c44d7b",
+				SubjectWorkflow:  Must(url.Parse(ghes.builderID(common.GoBuilderID) + refs123)),
+				BuildTrigger:     "workflow_dispatch",
+				Issuer:           certOidcIssuer,
+			},
+			defaults: defaultArtifactTrustedReusableWorkflows,
+			err:      serrors.ErrorMismatchCertificate,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -319,7 +469,11 @@ func Test_VerifyBuilderIdentity(t *testing.T) {
 			if tt.builderID != "" {
 				opts.ExpectedID = &tt.builderID
 			}
-			id, byob, err := VerifyBuilderIdentity(tt.workflow, opts, tt.defaults)
+			host := tt.host
+			if host == nil {
+				host = defaultGitHubHost
+			}
+			id, byob, err := VerifyBuilderIdentity(tt.workflow, host, opts, tt.defaults, tt.custom)
 			if byob != tt.byob {
 				t.Errorf("unexpected byob value:\n%s", cmp.Diff(tt.byob, byob))
 			}
@@ -374,7 +528,7 @@ func Test_isTrustedDelegatorBuilder(t *testing.T) {
 				t.Fatal(err.Error())
 			}
 
-			res := isTrustedDelegatorBuilder(trustedBuilderID, tt.trustedBuilderIDs)
+			res := isTrustedDelegatorBuilder(defaultGitHubHost, trustedBuilderID, tt.trustedBuilderIDs, nil)
 			if res != tt.result {
 				t.Error(cmp.Diff(res, tt.result))
 			}
@@ -384,9 +538,11 @@ func Test_isTrustedDelegatorBuilder(t *testing.T) {
 
 func Test_VerifyCertficateSourceRepository(t *testing.T) {
 	t.Parallel()
+	ghes := &githubHost{name: "ghes.example.com", oidcIssuer: "https://ghes.example.com/_services/token"}
 	tests := []struct {
 		name     string
 		workflow *WorkflowIdentity
+		host     *githubHost
 		source   string
 		err      error
 	}{
@@ -436,11 +592,40 @@ func Test_VerifyCertficateSourceRepository(t *testing.T) {
 			source: "asraa/slsa-on-github-test",
 			err:    serrors.ErrorMismatchSource,
 		},
+		{
+			name: "ghes repo match",
+			workflow: &WorkflowIdentity{
+				SourceRepository: "asraa/slsa-on-github-test",
+				SourceSha1:       "0dfcd24824432c4ce587f79c918eef8fc2c44d7b",
+				SubjectWorkflow:  Must(url.Parse(ghes.builderID(common.GoBuilderID) + refs123)),
+				BuildTrigger:     "workflow_dispatch",
+				Issuer:           ghes.oidcIssuer,
+			},
+			host:   ghes,
+			source: "ghes.example.com/asraa/slsa-on-github-test",
+		},
+		{
+			name: "github.com source on ghes",
+			workflow: &WorkflowIdentity{
+				SourceRepository: "asraa/slsa-on-github-test",
+				SourceSha1:       "0dfcd24824432c4ce587f79c918eef8fc2c44d7b",
+				SubjectWorkflow:  Must(url.Parse(ghes.builderID(common.GoBuilderID) + refs123)),
+				BuildTrigger:     "workflow_dispatch",
+				Issuer:           ghes.oidcIssuer,
+			},
+			host:   ghes,
+			source: "github.com/asraa/slsa-on-github-test",
+			err:    serrors.ErrorMismatchSource,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			t.Parallel()
-			err := VerifyCertficateSourceRepository(tt.workflow, tt.source)
+			host := tt.host
+			if host == nil {
+				host = defaultGitHubHost
+			}
+			err := VerifyCertficateSourceRepository(tt.workflow, host, tt.source)
 			if !errCmp(err, tt.err) {
 				t.Error(cmp.Diff(err, tt.err, cmpopts.EquateErrors()))
 			}
@@ -609,7 +794,7 @@ func Test_verifyTrustedBuilderID(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			t.Parallel()
-			id, byob, err := verifyTrustedBuilderID(httpsGithubCom+tt.path, tt.tag, tt.id, tt.defaults)
+			id, byob, err := verifyTrustedBuilderID(defaultGitHubHost, httpsGithubCom+tt.path, tt.tag, tt.id, tt.defaults, nil)
 			if byob != tt.byob {
 				t.Error(cmp.Diff(byob, tt.byob))
 			}
@@ -889,6 +1074,22 @@ func Test_GetWorkflowInfoFromCertificate(t *testing.T) {
 		t.Error(err.Error())
 	}
 
+	// Certificates of GitHub Enterprise Server (GHES) instances.
+	ghesHost := "ghes.example.com"
+	ghesURL := "https://" + ghesHost + "/"
+	encodedGHESRepoURI, err := asn1.MarshalWithParams(ghesURL+repo, "utf8")
+	if err != nil {
+		t.Error(err.Error())
+	}
+	encodedGHESBuildConfigURI, err := asn1.MarshalWithParams(ghesURL+repo+"/"+buildConfigPath+"@"+ref, "utf8")
+	if err != nil {
+		t.Error(err.Error())
+	}
+	encodedGHESInvocationURI, err := asn1.MarshalWithParams(ghesURL+repo+"/actions/runs/"+invocationID, "utf8")
+	if err != nil {
+		t.Error(err.Error())
+	}
+
 	tests := []struct {
 		name     string
 		cert     x509.Certificate
@@ -1208,6 +1409,136 @@ func Test_GetWorkflowInfoFromCertificate(t *testing.T) {
 				RunID:            &invocationID,
 			},
 		},
+		{
+			name: "ghes cert",
+			cert: x509.Certificate{
+				URIs: []*url.URL{
+					{
+						Scheme: "https",
+						Host:   ghesHost,
+						Path:   "/" + repo + "/" + buildConfigPath,
+					},
+				},
+				Extensions: []pkix.Extension{
+					{
+						Id:    fulcio.OIDBuildTrigger,
+						Value: encodedTrigger,
+					},
+					{
+						Id:    fulcio.OIDSourceRepositoryURI,
+						Value: encodedGHESRepoURI,
+					},
+					{
+						Id:    fulcio.OIDIssuerV2,
+						Value: encodedIssuer,
+					},
+					{
+						Id:    fulcio.OIDSourceRepositoryDigest,
+						Value: encodedDigest,
+					},
+					{
+						Id:    fulcio.OIDRunnerEnvironment,
+						Value: encodedHosted,
+					},
+					{
+						Id:    fulcio.OIDSourceRepositoryRef,
+						Value: encodedRef,
+					},
+					{
+						Id:    fulcio.OIDSourceRepositoryIdentifier,
+						Value: encodedSourceID,
+					},
+					{
+						Id:    fulcio.OIDSourceRepositoryOwnerIdentifier,
+						Value: encodedSourceOwnerID,
+					},
+					{
+						Id:    fulcio.OIDBuildConfigDigest,
+						Value: encodedBuildConfigSha1,
+					},
+					{
+						Id:    fulcio.OIDBuildConfigURI,
+						Value: encodedGHESBuildConfigURI,
+					},
+					{
+						Id:    fulcio.OIDRunInvocationURI,
+						Value: encodedGHESInvocationURI,
+					},
+				},
+			},
+			workflow: WorkflowIdentity{
+				Issuer:           issuer,
+				SubjectWorkflow:  Must(url.Parse(ghesURL + repo + "/" + buildConfigPath)),
+				SourceRepository: repo,
+				SourceSha1:       digest,
+				BuildTrigger:     trigger,
+				SubjectHosted:    &hosted,
+				SourceRef:        &ref,
+				SourceID:         &sourceID,
+				SourceOwnerID:    &sourceOwnerID,
+				BuildConfigPath:  &buildConfigPath,
+				RunID:            &invocationID,
+			},
+		},
+		{
+			name: "ghes cert github.com build config",
+			cert: x509.Certificate{
+				URIs: []*url.URL{
+					{
+						Scheme: "https",
+						Host:   ghesHost,
+						Path:   "/" + repo + "/" + buildConfigPath,
+					},
+				},
+				Extensions: []pkix.Extension{
+					{
+						Id:    fulcio.OIDBuildTrigger,
+						Value: encodedTrigger,
+					},
+					{
+						Id:    fulcio.OIDSourceRepositoryURI,
+						Value: encodedGHESRepoURI,
+					},
+					{
+						Id:    fulcio.OIDIssuerV2,
+						Value: encodedIssuer,
+					},
+					{
+						Id:    fulcio.OIDSourceRepositoryDigest,
+						Value: encodedDigest,
+					},
+					{
+						Id:    fulcio.OIDRunnerEnvironment,
+						Value: encodedHosted,
+					},
+					{
+						Id:    fulcio.OIDSourceRepositoryRef,
+						Value: encodedRef,
+					},
+					{
+						Id:    fulcio.OIDSourceRepositoryIdentifier,
+						Value: encodedSourceID,
+					},
+					{
+						Id:    fulcio.OIDSourceRepositoryOwnerIdentifier,
+						Value: encodedSourceOwnerID,
+					},
+					{
+						Id:    fulcio.OIDBuildConfigDigest,
+						Value: encodedBuildConfigSha1,
+					},
+					{
+						Id:    fulcio.OIDBuildConfigURI,
+						Value: encodedBuildConfigURI,
+					},
+					{
+						Id:    fulcio.OIDRunInvocationURI,
+						Value: encodedGHESInvocationURI,
+					},
+				},
+			},
+			err: serrors.ErrorInvalidFormat,
+		},
 		{
 			name: "new cert no deprecated claims empty URIs",
 			cert: x509.Certificate{
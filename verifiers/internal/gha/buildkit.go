@@ -0,0 +1,201 @@
+package gha
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/secure-systems-lab/go-securesystemslib/dsse"
+
+	serrors "github.com/slsa-framework/slsa-verifier/v2/errors"
+	"github.com/slsa-framework/slsa-verifier/v2/options"
+	"github.com/slsa-framework/slsa-verifier/v2/report"
+	"github.com/slsa-framework/slsa-verifier/v2/verifiers/internal/gha/slsaprovenance"
+	"github.com/slsa-framework/slsa-verifier/v2/verifiers/internal/gha/slsaprovenance/buildkit"
+	"github.com/slsa-framework/slsa-verifier/v2/verifiers/internal/gha/slsaprovenance/common"
+	"github.com/slsa-framework/slsa-verifier/v2/verifiers/utils"
+	"github.com/slsa-framework/slsa-verifier/v2/verifiers/utils/container"
+)
+
+// Images built by Docker BuildKit, e.g. with docker/build-push-action, carry
+// their provenance in attestation manifests of the image index. BuildKit
+// does not sign the provenance: it is trusted if the workflow that built the
+// image signed the attestation manifest with Sigstore. Like for GitHub
+// artifact attestations, callers provide the workflow they trust, and the
+// source of the provenance is verified against the certificate.
+
+// buildKitPredicateTypes are the predicate types of the provenance generated
+// by BuildKit.
+var buildKitPredicateTypes = []string{common.ProvenanceV02Type, common.ProvenanceV1Type}
+
+// isBuildKitProvenance returns true if the envelope contains provenance
+// generated by BuildKit.
+func isBuildKitProvenance(env *dsse.Envelope) bool {
+	pyld, err := base64.StdEncoding.DecodeString(env.Payload)
+	if err != nil {
+		return false
+	}
+	var statement struct {
+		Predicate struct {
+			BuildType       string `json:"buildType"`
+			BuildDefinition struct {
+				BuildType string `json:"buildType"`
+			} `json:"buildDefinition"`
+		} `json:"predicate"`
+	}
+	if err := json.Unmarshal(pyld, &statement); err != nil {
+		return false
+	}
+	return statement.Predicate.BuildType == common.BuildKitBuildTypeV02 ||
+		statement.Predicate.BuildDefinition.BuildType == common.BuildKitBuildTypeV1
+}
+
+// verifyBuildKitAttestations verifies the BuildKit provenance of the platform
+// images of an image index. The provenance of each image must verify. The
+// result of each platform image is recorded in the report.
+func verifyBuildKitAttestations(ctx context.Context, atts []*container.BuildKitAttestation,
+	provenanceOpts *options.ProvenanceOpts,
+	builderOpts *options.BuilderOpts,
+) ([]byte, *utils.TrustedBuilderID, error) {
+	rep := report.FromContext(ctx)
+	var verifiedProvenance []byte
+	var builderID *utils.TrustedBuilderID
+	for _, att := range atts {
+		if len(att.Bundles) == 0 {
+			return nil, nil, rep.Check(report.CheckSignature,
+				fmt.Errorf("%w: BuildKit provenance of %s image %s is not signed",
+					serrors.ErrorNoValidSignature, att.Platform, att.ImageDigest))
+		}
+		// The statements are about the platform images, whose digests are
+		// listed in the verified image index.
+		opts := *provenanceOpts
+		opts.ExpectedDigest = strings.TrimPrefix(att.ImageDigest, "sha256:")
+		platformRep := report.New(att.Platform, att.ImageDigest)
+		prov, id, err := utils.VerifyEach(report.NewContext(ctx, platformRep), att.Bundles,
+			func(ctx context.Context, bundle []byte) ([]byte, *utils.TrustedBuilderID, error) {
+				return verifyImageBundle(ctx, bundle, &opts, builderOpts)
+			})
+		rep.Merge(platformRep)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s image %s: %w", att.Platform, att.ImageDigest, err)
+		}
+		rep.AddPlatform(&report.Platform{
+			Platform:     att.Platform,
+			Digest:       att.ImageDigest,
+			Result:       report.StatusPassed,
+			BuilderID:    id.String(),
+			SourceCommit: platformRep.SourceCommit,
+		})
+		if verifiedProvenance == nil {
+			verifiedProvenance, builderID = prov, id
+		}
+	}
+	return verifiedProvenance, builderID, nil
+}
+
+// verifyBuildKitEnvAndCert verifies BuildKit provenance whose signature has
+// been verified.
+func verifyBuildKitEnvAndCert(ctx context.Context, env *dsse.Envelope,
+	cert *x509.Certificate,
+	provenanceOpts *options.ProvenanceOpts,
+	builderOpts *options.BuilderOpts,
+) ([]byte, *utils.TrustedBuilderID, error) {
+	rep := report.FromContext(ctx)
+	defer rep.Time(report.StageProvenance)()
+	host, err := newGitHubHost(provenanceOpts.GitHubOpts)
+	if err != nil {
+		return nil, nil, err
+	}
+	workflowInfo, err := GetWorkflowInfoFromCertificate(cert)
+	if err != nil {
+		return nil, nil, rep.Check(report.CheckBuilderID, err)
+	}
+	recordSource(rep, host, workflowInfo)
+
+	// Verify the workflow that signed the provenance.
+	signerID, err := verifyAttestationSigner(workflowInfo, host, builderOpts)
+	if err := rep.Check(report.CheckBuilderID, err); err != nil {
+		return nil, nil, err
+	}
+	rep.SetBuilderTrust(report.BuilderTrustWorkflow)
+
+	// Verify the source repository from the certificate.
+	if err := VerifyCertficateSourceRepository(workflowInfo, host, provenanceOpts.ExpectedSourceURI); err != nil {
+		return nil, nil, rep.Check(report.CheckSourceURI, err)
+	}
+
+	prov, err := slsaprovenance.ProvenanceFromEnvelope(signerID.Name(), env)
+	if err != nil {
+		return nil, nil, err
+	}
+	buildKitProv, ok := prov.(*buildkit.Provenance)
+	if !ok {
+		return nil, nil, fmt.Errorf("%w: unexpected provenance for BuildKit buildType", serrors.ErrorInvalidBuildType)
+	}
+
+	// The provenance is generated by BuildKit in the workflow, so its
+	// source must match the certificate.
+	if err := rep.Check(report.CheckMetadata,
+		verifyBuildKitMatchesCertificate(buildKitProv, host, workflowInfo)); err != nil {
+		return nil, nil, err
+	}
+
+	provenanceOpts = withExpectedBuilderID(provenanceOpts, signerID.String())
+	if err := VerifyProvenanceCommonOptions(ctx, prov, provenanceOpts); err != nil {
+		return nil, nil, err
+	}
+
+	utils.Logf(ctx, "Verified build using workflow %q at commit %s\n",
+		signerID.String(),
+		workflowInfo.SourceSha1)
+
+	r, err := base64.StdEncoding.DecodeString(env.Payload)
+	if err != nil {
+		return nil, nil, err
+	}
+	return r, signerID, nil
+}
+
+// verifyBuildKitMatchesCertificate verifies the source and the builder of
+// BuildKit provenance against the certificate.
+func verifyBuildKitMatchesCertificate(prov *buildkit.Provenance, host *githubHost,
+	workflow *WorkflowIdentity,
+) error {
+	repository, ref, commit, err := prov.Source()
+	if err != nil {
+		return err
+	}
+	certRepository := host.url() + workflow.SourceRepository
+	if err := equalCertificateValue(&certRepository, repository, "source repository"); err != nil {
+		return err
+	}
+	if err := equalCertificateValue(&workflow.SourceSha1, commit, "source commit"); err != nil {
+		return err
+	}
+	// The ref is only recorded for Git contexts.
+	if ref != "" {
+		if err := equalCertificateValue(workflow.SourceRef, ref, "source ref"); err != nil {
+			return err
+		}
+	}
+
+	// docker/build-push-action records the run as the builder.
+	builderID, err := prov.BuilderID()
+	if err != nil {
+		return err
+	}
+	if builderID != "" {
+		if workflow.RunID == nil {
+			return fmt.Errorf("%w: empty certificate value to verify 'builder.id'",
+				serrors.ErrorMismatchCertificate)
+		}
+		runURI := fmt.Sprintf("%s%s/actions/runs/%s", host.url(), workflow.SourceRepository, *workflow.RunID)
+		if err := equalCertificateValue(&runURI, builderID, "builder.id"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,163 @@
+package gha
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+
+	serrors "github.com/slsa-framework/slsa-verifier/v2/errors"
+	"github.com/slsa-framework/slsa-verifier/v2/options"
+	"github.com/slsa-framework/slsa-verifier/v2/verifiers/internal/gha/slsaprovenance/buildkit"
+	"github.com/slsa-framework/slsa-verifier/v2/verifiers/internal/gha/slsaprovenance/common"
+	"github.com/slsa-framework/slsa-verifier/v2/verifiers/utils/container"
+)
+
+// newTestBuildKitStatement returns the statement of BuildKit provenance
+// generated by docker/build-push-action from a Git context.
+func newTestBuildKitStatement() map[string]interface{} {
+	return map[string]interface{}{
+		"_type":         "https://in-toto.io/Statement/v0.1",
+		"predicateType": common.ProvenanceV02Type,
+		"subject": []interface{}{
+			map[string]interface{}{
+				"name":   "pkg:docker/ghcr.io/org/repo@v1.0.0?platform=linux%2Famd64",
+				"digest": map[string]interface{}{"sha256": "0a2b4c"},
+			},
+		},
+		"predicate": map[string]interface{}{
+			"builder":   map[string]interface{}{"id": "https://github.com/org/repo/actions/runs/789/attempts/1"},
+			"buildType": common.BuildKitBuildTypeV02,
+			"invocation": map[string]interface{}{
+				"configSource": map[string]interface{}{
+					"uri":        "https://github.com/org/repo.git#refs/tags/v1.0.0",
+					"digest":     map[string]interface{}{"sha1": "abcdef"},
+					"entryPoint": "Dockerfile",
+				},
+			},
+			"metadata": map[string]interface{}{
+				"https://mobyproject.org/buildkit@v1#metadata": map[string]interface{}{},
+			},
+		},
+	}
+}
+
+func Test_isBuildKitProvenance(t *testing.T) {
+	t.Parallel()
+
+	statement := newTestBuildKitStatement()
+	if !isBuildKitProvenance(newTestEnvelope(t, statement)) {
+		t.Errorf("BuildKit provenance not detected")
+	}
+
+	if isBuildKitProvenance(newTestEnvelope(t, newTestAttestationStatement())) {
+		t.Errorf("unexpected BuildKit provenance for buildType %q", common.GitHubActionsBuildTypeV1)
+	}
+}
+
+func Test_verifyBuildKitMatchesCertificate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		mutate func(statement map[string]interface{}, id *WorkflowIdentity)
+		err    error
+	}{
+		{
+			name: "matching",
+		},
+		{
+			name: "local context",
+			mutate: func(statement map[string]interface{}, _ *WorkflowIdentity) {
+				predicateOf(statement)["invocation"] = map[string]interface{}{}
+				predicateOf(statement)["metadata"] = map[string]interface{}{
+					"https://mobyproject.org/buildkit@v1#metadata": map[string]interface{}{
+						"vcs": map[string]interface{}{
+							"source":   "https://github.com/org/repo.git",
+							"revision": "abcdef",
+						},
+					},
+				}
+			},
+		},
+		{
+			name: "no builder",
+			mutate: func(statement map[string]interface{}, _ *WorkflowIdentity) {
+				predicateOf(statement)["builder"] = map[string]interface{}{}
+			},
+		},
+		{
+			name: "other repository",
+			mutate: func(_ map[string]interface{}, id *WorkflowIdentity) {
+				id.SourceRepository = "org/other"
+			},
+			err: serrors.ErrorMismatchCertificate,
+		},
+		{
+			name: "other commit",
+			mutate: func(_ map[string]interface{}, id *WorkflowIdentity) {
+				id.SourceSha1 = "fedcba"
+			},
+			err: serrors.ErrorMismatchCertificate,
+		},
+		{
+			name: "other ref",
+			mutate: func(_ map[string]interface{}, id *WorkflowIdentity) {
+				ref := "refs/heads/main"
+				id.SourceRef = &ref
+			},
+			err: serrors.ErrorMismatchCertificate,
+		},
+		{
+			name: "other run",
+			mutate: func(_ map[string]interface{}, id *WorkflowIdentity) {
+				runID := "789/attempts/2"
+				id.RunID = &runID
+			},
+			err: serrors.ErrorMismatchCertificate,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			statement := newTestBuildKitStatement()
+			id := newTestAttestationWorkflowIdentity(t)
+			if tt.mutate != nil {
+				tt.mutate(statement, id)
+			}
+			pyld, err := json.Marshal(statement)
+			if err != nil {
+				t.Fatal(err)
+			}
+			prov, err := buildkit.New(testAttestationWorkflow, pyld)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = verifyBuildKitMatchesCertificate(prov.(*buildkit.Provenance), defaultGitHubHost, id)
+			if diff := cmp.Diff(tt.err, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("unexpected error (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_verifyBuildKitAttestations(t *testing.T) {
+	t.Parallel()
+
+	// BuildKit does not sign its provenance.
+	atts := []*container.BuildKitAttestation{
+		{
+			ImageDigest: "sha256:0a2b4c",
+			Platform:    "linux/amd64",
+		},
+	}
+	_, _, err := verifyBuildKitAttestations(context.Background(), atts,
+		&options.ProvenanceOpts{}, &options.BuilderOpts{})
+	if diff := cmp.Diff(serrors.ErrorNoValidSignature, err, cmpopts.EquateErrors()); diff != "" {
+		t.Errorf("unexpected error (-want +got):\n%s", diff)
+	}
+}
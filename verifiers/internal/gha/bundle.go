@@ -1,6 +1,7 @@
 package gha
 
 import (
+	"bytes"
 	"context"
 	"crypto/x509"
 	"encoding/base64"
@@ -38,10 +39,31 @@ func IsSigstoreBundle(bytes []byte) bool {
 	return true
 }
 
+// splitSigstoreBundles returns the lines of content if each non-empty line
+// is a Sigstore bundle, as in the files downloaded with `gh attestation download`.
+// Otherwise, it returns content.
+func splitSigstoreBundles(content []byte) [][]byte {
+	var bundles [][]byte
+	for _, line := range bytes.Split(content, []byte("\n")) {
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 {
+			continue
+		}
+		if !IsSigstoreBundle(line) {
+			return [][]byte{content}
+		}
+		bundles = append(bundles, line)
+	}
+	if len(bundles) == 0 {
+		return [][]byte{content}
+	}
+	return bundles
+}
+
 // verifyRekorEntryFromBundle extracts and verifies the Rekor entry from the Sigstore
 // bundle verification material, validating the SignedEntryTimestamp.
 func verifyRekorEntryFromBundle(ctx context.Context, tlogEntry *v1.TransparencyLogEntry,
-	trustedRoot *sigstoreRoot.LiveTrustedRoot) (
+	trustedRoot sigstoreRoot.TrustedMaterial) (
 	*models.LogEntryAnon, error,
 ) {
 	canonicalBody := tlogEntry.GetCanonicalizedBody()
@@ -223,14 +245,14 @@ func matchRekorEntryWithEnvelopeDSSEv001(tlogEntry *v1.TransparencyLogEntry, env
 // returns the verified DSSE envelope containing the provenance
 // and the signing certificate given the provenance.
 func VerifyProvenanceBundle(ctx context.Context, bundleBytes []byte,
-	trustedRoot *sigstoreRoot.LiveTrustedRoot) (
+	trustedRoot sigstoreRoot.TrustedMaterial, host *githubHost) (
 	*SignedAttestation, error,
 ) {
 	proposedSignedAtt, err := verifyBundleAndEntryFromBytes(ctx, bundleBytes, trustedRoot, true)
 	if err != nil {
 		return nil, err
 	}
-	if err := verifySignedAttestation(proposedSignedAtt, trustedRoot); err != nil {
+	if err := verifySignedAttestation(proposedSignedAtt, trustedRoot, host); err != nil {
 		return nil, err
 	}
 
@@ -240,7 +262,7 @@ func VerifyProvenanceBundle(ctx context.Context, bundleBytes []byte,
 // verifyBundleAndEntry validates the rekor entry inn the bundle
 // and that the entry (cert, signatures) matches the data in the bundle.
 func verifyBundleAndEntry(ctx context.Context, bundle *bundle_v1.Bundle,
-	trustedRoot *sigstoreRoot.LiveTrustedRoot, requireCert bool,
+	trustedRoot sigstoreRoot.TrustedMaterial, requireCert bool,
 ) (*SignedAttestation, error) {
 	// We only expect one TLOG entry. If this changes in the future, we must iterate
 	// for a matching one.
@@ -290,7 +312,7 @@ func verifyBundleAndEntry(ctx context.Context, bundle *bundle_v1.Bundle,
 // verifyBundleAndEntryFromBytes validates the rekor entry inn the bundle
 // and that the entry (cert, signatures) matches the data in the bundle.
 func verifyBundleAndEntryFromBytes(ctx context.Context, bundleBytes []byte,
-	trustedRoot *sigstoreRoot.LiveTrustedRoot, requireCert bool,
+	trustedRoot sigstoreRoot.TrustedMaterial, requireCert bool,
 ) (*SignedAttestation, error) {
 	// Extract the SigningCert, Envelope, and RekorEntry from the bundle.
 	var bundle bundle_v1.Bundle
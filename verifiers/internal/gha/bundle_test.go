@@ -13,6 +13,7 @@ import (
 	rekorpbv1 "github.com/sigstore/protobuf-specs/gen/pb-go/rekor/v1"
 	serrors "github.com/slsa-framework/slsa-verifier/v2/errors"
 
+	"github.com/slsa-framework/slsa-verifier/v2/options"
 	"github.com/slsa-framework/slsa-verifier/v2/verifiers/utils"
 )
 
@@ -76,7 +77,112 @@ func Test_verifyBundle(t *testing.T) {
 				panic(fmt.Errorf("os.ReadFile: %w", err))
 			}
 
-			_, err = VerifyProvenanceBundle(ctx, content, trustedRoot)
+			_, err = VerifyProvenanceBundle(ctx, content, trustedRoot, defaultGitHubHost)
+
+			if !errCmp(err, tt.expected) {
+				t.Error(cmp.Diff(err, tt.expected))
+			}
+		})
+	}
+}
+
+func Test_verifyBundleOffline(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	// Does not fetch the trusted root with TUF.
+	trustedRoot, err := utils.GetTrustedMaterial(&options.SigstoreOpts{
+		TrustedRootPath: "./testdata/trusted_root.json",
+		Offline:         true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name     string
+		path     string
+		expected error
+	}{
+		{
+			name: "valid bundle: v0.1",
+			path: "./testdata/bundle/valid.intoto.sigstore",
+		},
+		{
+			name: "valid bundle: v0.3",
+			path: "./testdata/bundle/valid-v0.3.intoto.sigstore",
+		},
+		{
+			name:     "invalid Rekor SET",
+			path:     "./testdata/bundle/invalid-set.intoto.sigstore",
+			expected: serrors.ErrorInvalidRekorEntry,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			content, err := os.ReadFile(tt.path)
+			if err != nil {
+				panic(fmt.Errorf("os.ReadFile: %w", err))
+			}
+
+			_, err = VerifyProvenanceBundle(ctx, content, trustedRoot, defaultGitHubHost)
+
+			if !errCmp(err, tt.expected) {
+				t.Error(cmp.Diff(err, tt.expected))
+			}
+		})
+	}
+}
+
+func Test_VerifyArtifactOffline(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	tests := []struct {
+		name         string
+		path         string
+		sigstoreOpts *options.SigstoreOpts
+		expected     error
+	}{
+		{
+			name: "not a bundle",
+			path: "./testdata/bazel-trusted-dsseEnvelope.build.slsa",
+			sigstoreOpts: &options.SigstoreOpts{
+				TrustedRootPath: "./testdata/trusted_root.json",
+				Offline:         true,
+			},
+			expected: serrors.ErrorRequiresNetwork,
+		},
+		{
+			name: "no trusted root",
+			path: "./testdata/bundle/valid.intoto.sigstore",
+			sigstoreOpts: &options.SigstoreOpts{
+				Offline: true,
+			},
+			expected: serrors.ErrorRequiresNetwork,
+		},
+		{
+			name: "invalid trusted root",
+			path: "./testdata/bundle/valid.intoto.sigstore",
+			sigstoreOpts: &options.SigstoreOpts{
+				TrustedRootPath: "./testdata/bundle/valid.intoto.sigstore",
+				Offline:         true,
+			},
+			expected: serrors.ErrorInvalidFormat,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			content, err := os.ReadFile(tt.path)
+			if err != nil {
+				panic(fmt.Errorf("os.ReadFile: %w", err))
+			}
+
+			_, _, err = (&GHAVerifier{}).VerifyArtifact(ctx, content, "",
+				&options.ProvenanceOpts{SigstoreOpts: tt.sigstoreOpts}, &options.BuilderOpts{})
 
 			if !errCmp(err, tt.expected) {
 				t.Error(cmp.Diff(err, tt.expected))
@@ -338,3 +444,51 @@ func Test_matchRekorEntryWithEnvelope(t *testing.T) {
 		})
 	}
 }
+
+func Test_splitSigstoreBundles(t *testing.T) {
+	t.Parallel()
+
+	bundle, err := os.ReadFile("./testdata/bundle/valid.intoto.sigstore")
+	if err != nil {
+		t.Fatal(err)
+	}
+	prettyBundle, err := os.ReadFile("./testdata/bundle/valid-v0.3.intoto.sigstore")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name     string
+		content  []byte
+		expected [][]byte
+	}{
+		{
+			name:     "bundle",
+			content:  bundle,
+			expected: [][]byte{bundle},
+		},
+		{
+			name:     "indented bundle",
+			content:  prettyBundle,
+			expected: [][]byte{prettyBundle},
+		},
+		{
+			name:     "bundle per line",
+			content:  []byte(string(bundle) + "\n\n" + string(bundle) + "\n"),
+			expected: [][]byte{bundle, bundle},
+		},
+		{
+			name:     "not a bundle",
+			content:  []byte(string(bundle) + "\nnot a bundle\n"),
+			expected: [][]byte{[]byte(string(bundle) + "\nnot a bundle\n")},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if diff := cmp.Diff(tt.expected, splitSigstoreBundles(tt.content)); diff != "" {
+				t.Errorf("unexpected bundles (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
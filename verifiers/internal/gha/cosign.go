@@ -2,65 +2,134 @@ package gha
 
 import (
 	"context"
+	"crypto/x509"
 	"fmt"
-	"sync"
 
 	"github.com/sigstore/cosign/v2/pkg/cosign"
+	sigstoreRoot "github.com/sigstore/sigstore-go/pkg/root"
+	"github.com/sigstore/sigstore/pkg/cryptoutils"
 	"github.com/sigstore/sigstore/pkg/fulcioroots"
+	"github.com/sigstore/sigstore/pkg/tuf"
+
 	serrors "github.com/slsa-framework/slsa-verifier/v2/errors"
+	"github.com/slsa-framework/slsa-verifier/v2/options"
+	"github.com/slsa-framework/slsa-verifier/v2/verifiers/utils"
 )
 
-var (
-	// defaultCosignCheckOpts are the default options for cosign checks.
-	defaultCosignCheckOpts *cosign.CheckOpts
+// cache the cosign check options, by Sigstore options.
+var cosignCheckOpts utils.KeyedCache[*cosign.CheckOpts]
 
-	// defaultCosignCheckOptsOnce is used for initializing the defaultCosignCheckOpts.
-	defaultCosignCheckOptsOnce = new(sync.Once)
-)
+func init() {
+	utils.RegisterCache("cosign-check-opts", &cosignCheckOpts)
+}
 
-// getDefaultCosignCheckOpts returns the default cosign check options.
-// This is cached in memory.
+// GetCosignCheckOpts returns the cosign check options for the Sigstore options.
+// A nil sigstoreOpts selects the public-good Sigstore instance.
+// This is cached in memory, and a copy is returned.
 // CheckOpts.RegistryClientOpts must be added by the receiver.
-func getDefaultCosignCheckOpts(ctx context.Context) (*cosign.CheckOpts, error) {
-	var getErr error
-	// Initialize the defaultCosignCheckOpts.
-	// defaultCosignCheckOptsOnce is reinitialized upon error.
-	defaultCosignCheckOptsOnce.Do(func() {
-		rootCerts, err := fulcioroots.Get()
-		if err != nil {
-			getErr = fmt.Errorf("%w: %s", serrors.ErrorInternal, err)
-			defaultCosignCheckOptsOnce = new(sync.Once)
-			return
+func GetCosignCheckOpts(ctx context.Context, sigstoreOpts *options.SigstoreOpts) (*cosign.CheckOpts, error) {
+	cached, err := cosignCheckOpts.Get(utils.SigstoreOptsKey(sigstoreOpts), func() (*cosign.CheckOpts, error) {
+		if sigstoreOpts == nil {
+			return newDefaultCosignCheckOpts(ctx)
 		}
-		intermediateCerts, err := fulcioroots.GetIntermediates()
+		return newCosignCheckOpts(sigstoreOpts)
+	})
+	if err != nil {
+		return nil, err
+	}
+	opts := *cached
+	return &opts, nil
+}
+
+func newCosignCheckOpts(sigstoreOpts *options.SigstoreOpts) (*cosign.CheckOpts, error) {
+	trustedMaterial, err := utils.GetTrustedMaterial(sigstoreOpts)
+	if err != nil {
+		return nil, err
+	}
+	opts, err := cosignCheckOptsFromTrustedMaterial(trustedMaterial)
+	if err != nil {
+		return nil, err
+	}
+	// Offline verification uses the transparency log entry bundled
+	// with the signature, instead of searching Rekor.
+	opts.Offline = sigstoreOpts.Offline
+	if !sigstoreOpts.Offline && (sigstoreOpts.RekorURL != "" || sigstoreOpts.RekorClient != nil) {
+		opts.RekorClient, err = getRekorClient(sigstoreOpts)
 		if err != nil {
-			getErr = fmt.Errorf("%w: %s", serrors.ErrorInternal, err)
-			defaultCosignCheckOptsOnce = new(sync.Once)
-			return
+			return nil, err
 		}
-		rekorPubKeys, err := cosign.GetRekorPubs(ctx)
-		if err != nil {
-			getErr = fmt.Errorf("%w: %s", serrors.ErrorRekorPubKey, err)
-			defaultCosignCheckOptsOnce = new(sync.Once)
-			return
+	}
+	return opts, nil
+}
+
+// cosignCheckOptsFromTrustedMaterial converts the trusted material
+// into the roots and keys of cosign check options.
+func cosignCheckOptsFromTrustedMaterial(trustedMaterial sigstoreRoot.TrustedMaterial) (*cosign.CheckOpts, error) {
+	rootCerts := x509.NewCertPool()
+	intermediateCerts := x509.NewCertPool()
+	for _, ca := range trustedMaterial.FulcioCertificateAuthorities() {
+		if ca.Root != nil {
+			rootCerts.AddCert(ca.Root)
 		}
-		ctPubKeys, err := cosign.GetCTLogPubs(ctx)
-		if err != nil {
-			// this is unexpected, hold on to this error.
-			getErr = fmt.Errorf("%w: %s", serrors.ErrorInternal, err)
-			defaultCosignCheckOptsOnce = new(sync.Once)
-			return
+		for _, cert := range ca.Intermediates {
+			intermediateCerts.AddCert(cert)
 		}
+	}
+	rekorPubKeys, err := transparencyLogPubKeys(trustedMaterial.RekorLogs())
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", serrors.ErrorRekorPubKey, err)
+	}
+	ctPubKeys, err := transparencyLogPubKeys(trustedMaterial.CTLogs())
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", serrors.ErrorInvalidPublicKey, err)
+	}
+	return &cosign.CheckOpts{
+		RootCerts:         rootCerts,
+		IntermediateCerts: intermediateCerts,
+		RekorPubKeys:      rekorPubKeys,
+		CTLogPubKeys:      ctPubKeys,
+	}, nil
+}
 
-		defaultCosignCheckOpts = &cosign.CheckOpts{
-			RootCerts:         rootCerts,
-			IntermediateCerts: intermediateCerts,
-			RekorPubKeys:      rekorPubKeys,
-			CTLogPubKeys:      ctPubKeys,
+func transparencyLogPubKeys(logs map[string]*sigstoreRoot.TransparencyLog) (*cosign.TrustedTransparencyLogPubKeys, error) {
+	pubKeys := cosign.NewTrustedTransparencyLogPubKeys()
+	for _, log := range logs {
+		pemBytes, err := cryptoutils.MarshalPublicKeyToPEM(log.PublicKey)
+		if err != nil {
+			return nil, err
+		}
+		if err := pubKeys.AddTransparencyLogPubKey(pemBytes, tuf.Active); err != nil {
+			return nil, err
 		}
-	})
-	if getErr != nil {
-		return nil, getErr
 	}
-	return defaultCosignCheckOpts, nil
+	return &pubKeys, nil
+}
+
+// newDefaultCosignCheckOpts returns the cosign check options
+// of the public-good Sigstore instance.
+func newDefaultCosignCheckOpts(ctx context.Context) (*cosign.CheckOpts, error) {
+	rootCerts, err := fulcioroots.Get()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", serrors.ErrorInternal, err)
+	}
+	intermediateCerts, err := fulcioroots.GetIntermediates()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", serrors.ErrorInternal, err)
+	}
+	rekorPubKeys, err := cosign.GetRekorPubs(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", serrors.ErrorRekorPubKey, err)
+	}
+	ctPubKeys, err := cosign.GetCTLogPubs(ctx)
+	if err != nil {
+		// this is unexpected, hold on to this error.
+		return nil, fmt.Errorf("%w: %s", serrors.ErrorInternal, err)
+	}
+
+	return &cosign.CheckOpts{
+		RootCerts:         rootCerts,
+		IntermediateCerts: intermediateCerts,
+		RekorPubKeys:      rekorPubKeys,
+		CTLogPubKeys:      ctPubKeys,
+	}, nil
 }
@@ -0,0 +1,55 @@
+package gha
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	ociremote "github.com/sigstore/cosign/v2/pkg/oci/remote"
+
+	"github.com/slsa-framework/slsa-verifier/v2/options"
+)
+
+func Test_GetCosignCheckOpts(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	offline := &options.SigstoreOpts{
+		TrustedRootPath: "./testdata/trusted_root.json",
+		Offline:         true,
+	}
+	online := &options.SigstoreOpts{
+		TrustedRootPath: "./testdata/trusted_root.json",
+	}
+
+	opts, err := GetCosignCheckOpts(ctx, offline)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !opts.Offline {
+		t.Errorf("expected offline check options")
+	}
+	if opts.RootCerts == nil || opts.RekorPubKeys == nil || len(opts.RekorPubKeys.Keys) != 1 ||
+		opts.CTLogPubKeys == nil || len(opts.CTLogPubKeys.Keys) != 2 {
+		t.Errorf("expected the roots and keys of the trusted root, got %+v", opts)
+	}
+	opts.RegistryClientOpts = []ociremote.Option{ociremote.WithTargetRepository(name.Repository{})}
+
+	// Receivers get a copy of the cached options.
+	opts, err = GetCosignCheckOpts(ctx, offline)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if opts.RegistryClientOpts != nil {
+		t.Errorf("expected the cached options not to be modified")
+	}
+
+	// Options are cached by Sigstore deployment.
+	opts, err = GetCosignCheckOpts(ctx, online)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if opts.Offline {
+		t.Errorf("expected online check options")
+	}
+}
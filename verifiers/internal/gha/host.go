@@ -0,0 +1,89 @@
+package gha
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	serrors "github.com/slsa-framework/slsa-verifier/v2/errors"
+	"github.com/slsa-framework/slsa-verifier/v2/options"
+)
+
+// githubHost is the GitHub instance that ran the builds:
+// github.com, or a GitHub Enterprise Server (GHES) instance.
+type githubHost struct {
+	// name is the hostname, e.g. github.com.
+	name string
+	// oidcIssuer is the issuer of the OIDC tokens that GitHub Actions
+	// exchange for Fulcio certificates.
+	oidcIssuer string
+}
+
+var defaultGitHubHost = &githubHost{
+	name:       strings.TrimSuffix(githubCom, "/"),
+	oidcIssuer: certOidcIssuer,
+}
+
+// newGitHubHost returns the GitHub instance configured by opts.
+func newGitHubHost(opts *options.GitHubOpts) (*githubHost, error) {
+	if opts == nil || opts.Host == "" || opts.Host+"/" == githubCom {
+		if opts != nil && opts.OIDCIssuer != "" && opts.OIDCIssuer != certOidcIssuer {
+			return nil, fmt.Errorf("%w: %q for github.com", serrors.ErrorInvalidOIDCIssuer, opts.OIDCIssuer)
+		}
+		return defaultGitHubHost, nil
+	}
+	// The host must be a bare hostname, optionally with a port.
+	u, err := url.Parse("https://" + opts.Host)
+	if err != nil || u.Host != opts.Host || u.User != nil {
+		return nil, fmt.Errorf("%w: GitHub host %q", serrors.ErrorMalformedURI, opts.Host)
+	}
+	issuer := opts.OIDCIssuer
+	if issuer == "" {
+		issuer = "https://" + opts.Host + "/_services/token"
+	}
+	return &githubHost{name: opts.Host, oidcIssuer: issuer}, nil
+}
+
+// url returns the URL of the instance with a trailing slash,
+// e.g. https://github.com/.
+func (h *githubHost) url() string {
+	return "https://" + h.name + "/"
+}
+
+// builderID returns the ID on this instance of a builder defined on github.com.
+// Reusable workflows are expected to be mirrored from github.com
+// at the same path, e.g. with actions-sync.
+func (h *githubHost) builderID(id string) string {
+	return h.url() + strings.TrimPrefix(id, httpsGithubCom)
+}
+
+// githubComBuilderID returns the ID on github.com of a builder on this instance.
+// Provenance formats are identified by the builder IDs on github.com.
+func (h *githubHost) githubComBuilderID(id string) string {
+	if !strings.HasPrefix(id, h.url()) {
+		return id
+	}
+	return httpsGithubCom + strings.TrimPrefix(id, h.url())
+}
+
+// trustedBuilders returns the IDs on this instance of builders defined on github.com.
+func (h *githubHost) trustedBuilders(builders map[string]bool) map[string]bool {
+	if h == defaultGitHubHost {
+		return builders
+	}
+	hostBuilders := make(map[string]bool, len(builders))
+	for id, trusted := range builders {
+		hostBuilders[h.builderID(id)] = trusted
+	}
+	return hostBuilders
+}
+
+// certSubjectRegexp matches the subject of the certificates of the workflows
+// that run on this instance. Builders are verified after this.
+func (h *githubHost) certSubjectRegexp() string {
+	if h == defaultGitHubHost {
+		return certSubjectRegexp
+	}
+	return "^" + regexp.QuoteMeta(h.url())
+}
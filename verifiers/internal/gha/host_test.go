@@ -0,0 +1,192 @@
+package gha
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/sigstore/sigstore/pkg/cryptoutils"
+
+	serrors "github.com/slsa-framework/slsa-verifier/v2/errors"
+	"github.com/slsa-framework/slsa-verifier/v2/options"
+	"github.com/slsa-framework/slsa-verifier/v2/verifiers/internal/gha/slsaprovenance/common"
+)
+
+func Test_newGitHubHost(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		opts     *options.GitHubOpts
+		expected *githubHost
+		err      error
+	}{
+		{
+			name:     "nil",
+			expected: defaultGitHubHost,
+		},
+		{
+			name:     "github.com",
+			opts:     &options.GitHubOpts{Host: "github.com", OIDCIssuer: certOidcIssuer},
+			expected: defaultGitHubHost,
+		},
+		{
+			name: "github.com with another issuer",
+			opts: &options.GitHubOpts{OIDCIssuer: "https://ghes.example.com/_services/token"},
+			err:  serrors.ErrorInvalidOIDCIssuer,
+		},
+		{
+			name: "ghes default issuer",
+			opts: &options.GitHubOpts{Host: "ghes.example.com"},
+			expected: &githubHost{
+				name:       "ghes.example.com",
+				oidcIssuer: "https://ghes.example.com/_services/token",
+			},
+		},
+		{
+			name: "ghes custom issuer and port",
+			opts: &options.GitHubOpts{Host: "ghes.example.com:8443", OIDCIssuer: "https://issuer.example.com"},
+			expected: &githubHost{
+				name:       "ghes.example.com:8443",
+				oidcIssuer: "https://issuer.example.com",
+			},
+		},
+		{
+			name: "ghes url",
+			opts: &options.GitHubOpts{Host: "https://ghes.example.com"},
+			err:  serrors.ErrorMalformedURI,
+		},
+		{
+			name: "ghes path",
+			opts: &options.GitHubOpts{Host: "ghes.example.com/org"},
+			err:  serrors.ErrorMalformedURI,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			host, err := newGitHubHost(tt.opts)
+			if diff := cmp.Diff(tt.err, err, cmpopts.EquateErrors()); diff != "" {
+				t.Fatalf("unexpected error (-want +got):\n%s", diff)
+			}
+			if err != nil {
+				return
+			}
+			if diff := cmp.Diff(tt.expected, host, cmp.AllowUnexported(githubHost{})); diff != "" {
+				t.Errorf("unexpected host (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_githubHost_builderID(t *testing.T) {
+	t.Parallel()
+
+	ghes := &githubHost{name: "ghes.example.com"}
+	id := ghes.builderID(common.GoBuilderID)
+	if want := "https://ghes.example.com/slsa-framework/slsa-github-generator/.github/workflows/builder_go_slsa3.yml"; id != want {
+		t.Errorf("unexpected builder ID: got %q, want %q", id, want)
+	}
+	if got := ghes.githubComBuilderID(id + refs123); got != common.GoBuilderID+refs123 {
+		t.Errorf("unexpected github.com builder ID: got %q, want %q", got, common.GoBuilderID+refs123)
+	}
+	if got := defaultGitHubHost.builderID(common.GoBuilderID); got != common.GoBuilderID {
+		t.Errorf("unexpected builder ID: got %q, want %q", got, common.GoBuilderID)
+	}
+
+	builders := ghes.trustedBuilders(defaultArtifactTrustedReusableWorkflows)
+	if len(builders) != len(defaultArtifactTrustedReusableWorkflows) {
+		t.Fatalf("unexpected number of builders: got %d, want %d", len(builders), len(defaultArtifactTrustedReusableWorkflows))
+	}
+	if !builders[id] {
+		t.Errorf("builder %q is not trusted", id)
+	}
+	if builders[common.GoBuilderID] {
+		t.Errorf("github.com builder %q is trusted on GHES", common.GoBuilderID)
+	}
+}
+
+// Test_GHESCertificate verifies the identity of a certificate issued
+// to a workflow of a GHES instance, ghes.example.com.
+func Test_GHESCertificate(t *testing.T) {
+	t.Parallel()
+
+	content, err := os.ReadFile(filepath.Join("testdata", "ghes", "fulcio-cert.pem"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	certs, err := cryptoutils.UnmarshalCertificatesFromPEM(content)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert := certs[0]
+	ghes, err := newGitHubHost(&options.GitHubOpts{Host: "ghes.example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		host   *githubHost
+		source string
+		err    error
+	}{
+		{
+			name:   "ghes",
+			host:   ghes,
+			source: "ghes.example.com/org/repo",
+		},
+		{
+			name:   "github.com",
+			host:   defaultGitHubHost,
+			source: "github.com/org/repo",
+			err:    serrors.ErrorInvalidCertificate,
+		},
+		{
+			name:   "ghes with another issuer",
+			host:   &githubHost{name: ghes.name, oidcIssuer: certOidcIssuer},
+			source: "ghes.example.com/org/repo",
+			err:    serrors.ErrorInvalidCertificate,
+		},
+		{
+			name:   "other ghes",
+			host:   &githubHost{name: "other.example.com", oidcIssuer: ghes.oidcIssuer},
+			source: "other.example.com/org/repo",
+			err:    serrors.ErrorInvalidCertificate,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := verifyCertificateIdentity(cert, tt.host)
+			if diff := cmp.Diff(tt.err, err, cmpopts.EquateErrors()); diff != "" {
+				t.Fatalf("unexpected error (-want +got):\n%s", diff)
+			}
+			if err != nil {
+				return
+			}
+
+			workflow, err := GetWorkflowInfoFromCertificate(cert)
+			if err != nil {
+				t.Fatal(err)
+			}
+			builderID, byob, err := VerifyBuilderIdentity(workflow, tt.host, &options.BuilderOpts{},
+				defaultArtifactTrustedReusableWorkflows, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if byob {
+				t.Errorf("unexpected byob builder %q", builderID.String())
+			}
+			if want := tt.host.builderID(common.GenericGeneratorBuilderID) + "@refs/tags/v1.9.0"; builderID.String() != want {
+				t.Errorf("unexpected builder ID: got %q, want %q", builderID.String(), want)
+			}
+			if err := VerifyCertficateSourceRepository(workflow, tt.host, tt.source); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
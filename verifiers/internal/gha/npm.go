@@ -16,6 +16,7 @@ import (
 	sigstoreRoot "github.com/sigstore/sigstore-go/pkg/root"
 	serrors "github.com/slsa-framework/slsa-verifier/v2/errors"
 	"github.com/slsa-framework/slsa-verifier/v2/options"
+	"github.com/slsa-framework/slsa-verifier/v2/report"
 	"github.com/slsa-framework/slsa-verifier/v2/verifiers/internal/gha/slsaprovenance"
 	"github.com/slsa-framework/slsa-verifier/v2/verifiers/internal/gha/slsaprovenance/common"
 	"github.com/slsa-framework/slsa-verifier/v2/verifiers/utils"
@@ -62,7 +63,8 @@ func (b *BundleBytes) UnmarshalJSON(data []byte) error {
 
 type Npm struct {
 	ctx                   context.Context
-	root                  *sigstoreRoot.LiveTrustedRoot
+	root                  sigstoreRoot.TrustedMaterial
+	host                  *githubHost
 	verifiedBuilderID     *utils.TrustedBuilderID
 	verifiedProvenanceAtt *SignedAttestation
 	verifiedPublishAtt    *SignedAttestation
@@ -78,7 +80,7 @@ func (n *Npm) ProvenanceLeafCertificate() *x509.Certificate {
 	return n.verifiedProvenanceAtt.SigningCert
 }
 
-func NpmNew(ctx context.Context, root *sigstoreRoot.LiveTrustedRoot, attestationBytes []byte) (*Npm, error) {
+func NpmNew(ctx context.Context, root sigstoreRoot.TrustedMaterial, attestationBytes []byte) (*Npm, error) {
 	var aSet attestationSet
 	if err := json.Unmarshal(attestationBytes, &aSet); err != nil {
 		return nil, fmt.Errorf("%w: json.Unmarshal: %v", errrorInvalidAttestations, err)
@@ -91,6 +93,7 @@ func NpmNew(ctx context.Context, root *sigstoreRoot.LiveTrustedRoot, attestation
 	return &Npm{
 		ctx:  ctx,
 		root: root,
+		host: defaultGitHubHost,
 
 		provenanceAttestation: prov,
 		publishAttestation:    pub,
@@ -138,11 +141,12 @@ func getAttestationKey(npmRegistryPublicKeyID string) (string, error) {
 
 func (n *Npm) verifyProvenanceAttestationSignature() error {
 	// Re-use the standard bundle verification.
-	signedProvenance, err := VerifyProvenanceBundle(n.ctx, n.provenanceAttestation.BundleBytes, n.root)
+	signedProvenance, err := VerifyProvenanceBundle(n.ctx, n.provenanceAttestation.BundleBytes, n.root, n.host)
 	if err != nil {
 		return err
 	}
 	n.verifiedProvenanceAtt = signedProvenance
+	signedProvenance.record(report.FromContext(n.ctx))
 	return nil
 }
 
@@ -300,7 +304,7 @@ func (n *Npm) verifyBuilderID(
 	defaultBuilders map[string]bool,
 ) (*utils.TrustedBuilderID, error) {
 	// Verify certificate information.
-	builder, err := verifyNpmEnvAndCert(
+	builder, err := verifyNpmEnvAndCert(n.ctx,
 		n.ProvenanceEnvelope(),
 		n.ProvenanceLeafCertificate(),
 		provenanceOpts, builderOpts,
@@ -319,8 +323,8 @@ func verifyPublishPredicateVersion(att *SignedAttestation, expectedVersion strin
 		return err
 	}
 	if version != expectedVersion {
-		return fmt.Errorf("%w: got '%v', expected '%v'", serrors.ErrorMismatchPackageVersion,
-			version, expectedVersion)
+		return serrors.NewVerificationError(fmt.Errorf("%w: got '%v', expected '%v'", serrors.ErrorMismatchPackageVersion,
+			version, expectedVersion), expectedVersion, version)
 	}
 	return nil
 }
@@ -331,8 +335,8 @@ func verifyPublishPredicateName(att *SignedAttestation, expectedName string) err
 		return err
 	}
 	if name != expectedName {
-		return fmt.Errorf("%w: got '%v', expected '%v'", serrors.ErrorMismatchPackageName,
-			name, expectedName)
+		return serrors.NewVerificationError(fmt.Errorf("%w: got '%v', expected '%v'", serrors.ErrorMismatchPackageName,
+			name, expectedName), expectedName, name)
 	}
 	return nil
 }
@@ -389,8 +393,8 @@ func verifyProvenanceSubjectVersion(b *utils.TrustedBuilderID, att *SignedAttest
 	}
 
 	if subVersion != expectedVersion {
-		return fmt.Errorf("%w: got '%v', expected '%v'", serrors.ErrorMismatchPackageVersion,
-			subVersion, expectedVersion)
+		return serrors.NewVerificationError(fmt.Errorf("%w: got '%v', expected '%v'", serrors.ErrorMismatchPackageVersion,
+			subVersion, expectedVersion), expectedVersion, subVersion)
 	}
 
 	return nil
@@ -422,7 +426,8 @@ func (n *Npm) verifyPublishAttestationSubjectDigest(expectedHash string) error {
 
 	// NOTE: We don't need to verify that the digest matches the one in the provenance
 	// because the provenance verification will verify the hash as well.
-	return fmt.Errorf("expected hash '%s' not found: %w", expectedHash, serrors.ErrorMismatchHash)
+	return serrors.NewVerificationError(
+		fmt.Errorf("expected hash '%s' not found: %w", expectedHash, serrors.ErrorMismatchHash), expectedHash, "")
 }
 
 func verifyPublishSubjectVersion(att *SignedAttestation, expectedVersion string) error {
@@ -432,8 +437,8 @@ func verifyPublishSubjectVersion(att *SignedAttestation, expectedVersion string)
 	}
 
 	if version != expectedVersion {
-		return fmt.Errorf("%w: got '%v', expected '%v'", serrors.ErrorMismatchPackageVersion,
-			version, expectedVersion)
+		return serrors.NewVerificationError(fmt.Errorf("%w: got '%v', expected '%v'", serrors.ErrorMismatchPackageVersion,
+			version, expectedVersion), expectedVersion, version)
 	}
 
 	return nil
@@ -484,8 +489,8 @@ func verifyName(actual, expected string) error {
 	}
 
 	if subName != expected {
-		return fmt.Errorf("%w: got '%v', expected '%v'", serrors.ErrorMismatchPackageName,
-			subName, expected)
+		return serrors.NewVerificationError(fmt.Errorf("%w: got '%v', expected '%v'", serrors.ErrorMismatchPackageName,
+			subName, expected), expected, subName)
 	}
 
 	return nil
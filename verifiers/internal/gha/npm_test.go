@@ -2,8 +2,11 @@ package gha
 
 import (
 	"context"
+	"crypto/x509"
+	"crypto/x509/pkix"
 	"encoding/base64"
 	"fmt"
+	"net/url"
 	"os"
 	"path/filepath"
 	"testing"
@@ -13,7 +16,9 @@ import (
 
 	intoto "github.com/in-toto/in-toto-golang/in_toto"
 	dsselib "github.com/secure-systems-lab/go-securesystemslib/dsse"
+	fulcio "github.com/sigstore/fulcio/pkg/certificate"
 	serrors "github.com/slsa-framework/slsa-verifier/v2/errors"
+	"github.com/slsa-framework/slsa-verifier/v2/options"
 	"github.com/slsa-framework/slsa-verifier/v2/verifiers/internal/gha/slsaprovenance/common"
 	"github.com/slsa-framework/slsa-verifier/v2/verifiers/utils"
 )
@@ -1292,3 +1297,68 @@ func Test_verifyProvenanceAttestationSignature(t *testing.T) {
 		})
 	}
 }
+
+func Test_verifyNpmEnvAndCert_host(t *testing.T) {
+	t.Parallel()
+	repo := "slsa-framework/slsa-github-generator"
+	builderID := "https://github.com/" + repo + "/.github/workflows/builder.yml"
+	ghesIssuer := "https://ghes.example.com/_services/token"
+	// newCert returns a certificate of the delegator workflow on host,
+	// issued by issuer.
+	newCert := func(host, issuer string) *x509.Certificate {
+		return &x509.Certificate{
+			URIs: []*url.URL{
+				{
+					Scheme: "https",
+					Host:   host,
+					Path:   "/" + repo + "/.github/workflows/delegator_lowperms-generic_slsa3.yml@refs/tags/v1.9.0",
+				},
+			},
+			Extensions: []pkix.Extension{
+				//nolint: staticcheck // SA1019: Need to support older signatures.
+				{Id: fulcio.OIDIssuer, Value: []byte(issuer)},
+				//nolint: staticcheck // SA1019: Need to support older signatures.
+				{Id: fulcio.OIDGitHubWorkflowTrigger, Value: []byte("push")},
+				//nolint: staticcheck // SA1019: Need to support older signatures.
+				{Id: fulcio.OIDGitHubWorkflowSHA, Value: []byte("0dfcd24824432c4ce587f79c918eef8fc2c44d7b")},
+				//nolint: staticcheck // SA1019: Need to support older signatures.
+				{Id: fulcio.OIDGitHubWorkflowRepository, Value: []byte(repo)},
+			},
+		}
+	}
+
+	tests := []struct {
+		name string
+		cert *x509.Certificate
+		opts *options.GitHubOpts
+		err  error
+	}{
+		{
+			name: "ghes certificate on github.com",
+			cert: newCert("ghes.example.com", certOidcIssuer),
+			err:  serrors.ErrorMismatchCertificate,
+		},
+		{
+			name: "github.com certificate on ghes",
+			cert: newCert("github.com", ghesIssuer),
+			opts: &options.GitHubOpts{Host: "ghes.example.com"},
+			err:  serrors.ErrorMismatchCertificate,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			provenanceOpts := &options.ProvenanceOpts{
+				ExpectedSourceURI: "github.com/" + repo,
+				GitHubOpts:        tt.opts,
+			}
+			builderOpts := &options.BuilderOpts{ExpectedID: &builderID}
+			_, err := verifyNpmEnvAndCert(context.Background(), nil, tt.cert, provenanceOpts, builderOpts,
+				defaultBYOBReusableWorkflows)
+			if diff := cmp.Diff(tt.err, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("unexpected error (-want +got): \n%s", diff)
+			}
+		})
+	}
+}
@@ -5,7 +5,6 @@ import (
 	"crypto/x509"
 	"encoding/json"
 	"fmt"
-	"os"
 	"strings"
 
 	dsselib "github.com/secure-systems-lab/go-securesystemslib/dsse"
@@ -17,6 +16,7 @@ import (
 	"github.com/slsa-framework/slsa-github-generator/signing/envelope"
 	serrors "github.com/slsa-framework/slsa-verifier/v2/errors"
 	"github.com/slsa-framework/slsa-verifier/v2/options"
+	"github.com/slsa-framework/slsa-verifier/v2/report"
 	"github.com/slsa-framework/slsa-verifier/v2/verifiers/internal/gha/slsaprovenance"
 	"github.com/slsa-framework/slsa-verifier/v2/verifiers/internal/gha/slsaprovenance/common"
 	"github.com/slsa-framework/slsa-verifier/v2/verifiers/internal/gha/slsaprovenance/iface"
@@ -36,6 +36,27 @@ type SignedAttestation struct {
 	PublicKey *proto_v1.PublicKeyIdentifier
 }
 
+// record records the signer and the transparency log entry of the verified
+// attestation in rep.
+func (s *SignedAttestation) record(rep *report.Report) {
+	if s.SigningCert != nil {
+		rep.SetSigner(utils.CertificateSigner(s.SigningCert))
+	}
+	e := s.RekorEntry
+	if e == nil || e.LogIndex == nil {
+		return
+	}
+	var integratedTime int64
+	if e.IntegratedTime != nil {
+		integratedTime = *e.IntegratedTime
+	}
+	var logID string
+	if e.LogID != nil {
+		logID = *e.LogID
+	}
+	rep.SetRekorEntry(utils.NewRekorEntry(*e.LogIndex, e.Body, integratedTime, logID))
+}
+
 // EnvelopeFromBytes reads a DSSE envelope from the given payload.
 func EnvelopeFromBytes(payload []byte) (env *dsselib.Envelope, err error) {
 	env = &dsselib.Envelope{}
@@ -103,13 +124,13 @@ func verifyBuilderIDLooseMatch(prov iface.Provenance, expectedBuilderID string)
 }
 
 // Verify source URI in provenance statement.
-func verifySourceURI(prov iface.Provenance, expectedSourceURI string) error {
+func verifySourceURI(prov iface.Provenance, host *githubHost, expectedSourceURI string) error {
 	source := utils.NormalizeGitURI(expectedSourceURI)
 
-	// We expect github.com URIs only.
-	if !strings.HasPrefix(source, "git+https://github.com/") {
-		return fmt.Errorf("%w: expected source github.com repository %q", serrors.ErrorMalformedURI,
-			source)
+	// We expect URIs of the GitHub instance only.
+	if !strings.HasPrefix(source, "git+"+host.url()) {
+		return fmt.Errorf("%w: expected source %s repository %q", serrors.ErrorMalformedURI,
+			host.name, source)
 	}
 
 	// Verify source in the trigger
@@ -123,8 +144,8 @@ func verifySourceURI(prov iface.Provenance, expectedSourceURI string) error {
 		return err
 	}
 	if triggerURI != source {
-		return fmt.Errorf("%w: expected trigger %q to match source-uri %q", serrors.ErrorMismatchSource,
-			source, fullTriggerURI)
+		return serrors.NewVerificationError(fmt.Errorf("%w: expected trigger %q to match source-uri %q", serrors.ErrorMismatchSource,
+			source, fullTriggerURI), source, triggerURI)
 	}
 	// We expect the trigger URI to always have a ref.
 	if triggerRef == "" {
@@ -142,8 +163,8 @@ func verifySourceURI(prov iface.Provenance, expectedSourceURI string) error {
 		return err
 	}
 	if sourceURI != source {
-		return fmt.Errorf("%w: expected source %q to match source-uri %q", serrors.ErrorMismatchSource,
-			fullSourceURI, source)
+		return serrors.NewVerificationError(fmt.Errorf("%w: expected source %q to match source-uri %q", serrors.ErrorMismatchSource,
+			fullSourceURI, source), source, sourceURI)
 	}
 
 	buildType, err := prov.BuildType()
@@ -205,12 +226,13 @@ func verifyDigest(prov iface.Provenance, expectedHash string) error {
 		}
 	}
 
-	return fmt.Errorf("expected hash '%s' not found: %w", expectedHash, serrors.ErrorMismatchHash)
+	return serrors.NewVerificationError(
+		fmt.Errorf("expected hash '%s' not found: %w", expectedHash, serrors.ErrorMismatchHash), expectedHash, "")
 }
 
 // VerifyProvenanceSignature returns the verified DSSE envelope containing the provenance
 // and the signing certificate given the provenance and artifact hash.
-func VerifyProvenanceSignature(ctx context.Context, trustedRoot *sigstoreRoot.LiveTrustedRoot,
+func VerifyProvenanceSignature(ctx context.Context, trustedRoot sigstoreRoot.TrustedMaterial, host *githubHost,
 	rClient *client.Rekor,
 	provenance []byte, artifactHash string) (
 	*SignedAttestation, error,
@@ -219,22 +241,30 @@ func VerifyProvenanceSignature(ctx context.Context, trustedRoot *sigstoreRoot.Li
 	// to use the Redis index for searching by artifact SHA.
 	if hasCertInEnvelope(provenance) {
 		// Get Rekor entries corresponding to provenance
-		return GetValidSignedAttestationWithCert(rClient, provenance, trustedRoot)
+		return GetValidSignedAttestationWithCert(ctx, rClient, provenance, trustedRoot, host)
 	}
 
 	// Fallback on using the redis search index to get matching UUIDs.
-	fmt.Fprintf(os.Stderr, "No certificate provided, trying Redis search index to find entries by subject digest\n")
+	utils.Logf(ctx, "No certificate provided, trying Redis search index to find entries by subject digest\n")
 
 	// Verify the provenance and return the signing certificate.
 	return SearchValidSignedAttestation(ctx, artifactHash,
-		provenance, rClient, trustedRoot)
+		provenance, rClient, trustedRoot, host)
 }
 
 // VerifyNpmPackageProvenance verifies provenance for an npm package.
-func VerifyNpmPackageProvenance(env *dsselib.Envelope, workflow *WorkflowIdentity,
+func VerifyNpmPackageProvenance(ctx context.Context, env *dsselib.Envelope, workflow *WorkflowIdentity,
 	provenanceOpts *options.ProvenanceOpts, trustedBuilderID *utils.TrustedBuilderID, isTrustedBuilder bool,
 ) error {
-	prov, err := slsaprovenance.ProvenanceFromEnvelope(trustedBuilderID.Name(), env)
+	host, err := newGitHubHost(provenanceOpts.GitHubOpts)
+	if err != nil {
+		return err
+	}
+	customBuilders, err := newCustomBuilders(host, provenanceOpts.TrustedBuilders)
+	if err != nil {
+		return err
+	}
+	prov, err := slsaprovenance.ProvenanceFromEnvelope(provenanceBuilderID(host, customBuilders, trustedBuilderID.Name()), env)
 	if err != nil {
 		return err
 	}
@@ -243,6 +273,7 @@ func VerifyNpmPackageProvenance(env *dsselib.Envelope, workflow *WorkflowIdentit
 	// This depends on the builder (delegator or CLI).
 
 	// Verify the builder ID.
+	rep := report.FromContext(ctx)
 	if err := verifyBuilderIDLooseMatch(prov, provenanceOpts.ExpectedBuilderID); err != nil {
 		// Verification failed. Try again by appending or removing the the hosted status.
 		// Older provenance uses the shorted version without status, and recent provenance includes the status.
@@ -254,7 +285,7 @@ func VerifyNpmPackageProvenance(env *dsselib.Envelope, workflow *WorkflowIdentit
 			oerr := verifyBuilderIDLooseMatch(prov, bid)
 			if oerr != nil {
 				// We do return the original error, since that's the caller the user provided.
-				return err
+				return rep.Check(report.CheckBuilderID, err)
 			}
 			// Verification success.
 			err = nil
@@ -265,7 +296,7 @@ func VerifyNpmPackageProvenance(env *dsselib.Envelope, workflow *WorkflowIdentit
 			oerr := verifyBuilderIDLooseMatch(prov, bid)
 			if oerr != nil {
 				// We do return the original error, since that's the caller the user provided.
-				return err
+				return rep.Check(report.CheckBuilderID, err)
 			}
 			// Verification success.
 			err = nil
@@ -275,19 +306,20 @@ func VerifyNpmPackageProvenance(env *dsselib.Envelope, workflow *WorkflowIdentit
 		}
 
 		if err != nil {
-			return err
+			return rep.Check(report.CheckBuilderID, err)
 		}
 	}
+	_ = rep.Check(report.CheckBuilderID, nil)
 
 	// Also, the GitHub context is not recorded for the default builder.
-	if err := VerifyProvenanceCommonOptions(prov, provenanceOpts); err != nil {
+	if err := VerifyProvenanceCommonOptions(ctx, prov, provenanceOpts); err != nil {
 		return err
 	}
 
 	// Verify consistency between the provenance and the certificate.
 	// because for the non trusted builders, the information may be forgeable.
 	if !isTrustedBuilder {
-		return verifyProvenanceMatchesCertificate(prov, workflow)
+		return rep.Check(report.CheckSourceURI, verifyProvenanceMatchesCertificate(prov, workflow))
 	}
 	return nil
 }
@@ -334,9 +366,10 @@ func isValidDelegatorBuilderID(prov iface.Provenance) error {
 }
 
 // builderID returns the trusted builder ID from the provenance.
-// The certTrustedBuilderID input is from the Fulcio certificate.
-func builderID(env *dsselib.Envelope, certTrustedBuilderID *utils.TrustedBuilderID) (*utils.TrustedBuilderID, error) {
-	prov, err := slsaprovenance.ProvenanceFromEnvelope(certTrustedBuilderID.Name(), env)
+// The provenanceID input is derived from the builder in the Fulcio certificate,
+// and identifies the format of the provenance. See provenanceBuilderID.
+func builderID(env *dsselib.Envelope, provenanceID string) (*utils.TrustedBuilderID, error) {
+	prov, err := slsaprovenance.ProvenanceFromEnvelope(provenanceID, env)
 	if err != nil {
 		return nil, err
 	}
@@ -352,17 +385,26 @@ func builderID(env *dsselib.Envelope, certTrustedBuilderID *utils.TrustedBuilder
 }
 
 // VerifyProvenance verifies the provenance for the given DSSE envelope.
-func VerifyProvenance(env *dsselib.Envelope, provenanceOpts *options.ProvenanceOpts, trustedBuilderID *utils.TrustedBuilderID, byob bool,
+func VerifyProvenance(ctx context.Context, env *dsselib.Envelope, provenanceOpts *options.ProvenanceOpts, trustedBuilderID *utils.TrustedBuilderID, byob bool,
 	expectedID *string) error {
-	prov, err := slsaprovenance.ProvenanceFromEnvelope(trustedBuilderID.Name(), env)
+	host, err := newGitHubHost(provenanceOpts.GitHubOpts)
+	if err != nil {
+		return err
+	}
+	customBuilders, err := newCustomBuilders(host, provenanceOpts.TrustedBuilders)
+	if err != nil {
+		return err
+	}
+	prov, err := slsaprovenance.ProvenanceFromEnvelope(provenanceBuilderID(host, customBuilders, trustedBuilderID.Name()), env)
 	if err != nil {
 		return err
 	}
 
 	// Verify Builder ID.
+	rep := report.FromContext(ctx)
 	if byob {
 		if err := isValidDelegatorBuilderID(prov); err != nil {
-			return err
+			return rep.Check(report.CheckBuilderID, err)
 		}
 
 		// If expectedID is not provided, check to see if it is a trusted builder.
@@ -372,66 +414,93 @@ func VerifyProvenance(env *dsselib.Envelope, provenanceOpts *options.ProvenanceO
 		// This can verify the actual BYOB builderIDPath against the trusted builderIDPath provided.
 		// Currently slsa-framework path is the only one supported for ExpectedBuilderPath.
 		if expectedID == nil {
-			var trustedBuilderRepositoryPath = httpsGithubCom + trustedBuilderRepository + "/.github/workflows/"
-			if provenanceOpts.ExpectedBuilderID, err = verifyBuilderIDPathPrefix(prov, trustedBuilderRepositoryPath); err != nil {
-				return err
+			var trustedBuilderRepositoryPath = host.url() + trustedBuilderRepository + "/.github/workflows/"
+			// The builders of custom delegators are in the repository of the delegator.
+			if b := findCustomBuilder(customBuilders, trustedBuilderID.Name()); b != nil {
+				trustedBuilderRepositoryPath = b.workflowsPath()
+			}
+			builderID, err := verifyBuilderIDPathPrefix(prov, trustedBuilderRepositoryPath)
+			if err != nil {
+				return rep.Check(report.CheckBuilderID, err)
 			}
+			provenanceOpts = withExpectedBuilderID(provenanceOpts, builderID)
 		} else {
-			provenanceOpts.ExpectedBuilderID = *expectedID
+			provenanceOpts = withExpectedBuilderID(provenanceOpts, *expectedID)
 		}
 
 		// NOTE: `provenanceOpts.ExpectedBuilderID` is provided by the user
 		// or from return of verifyBuilderIDPath.
 		if err := verifyBuilderIDLooseMatch(prov, provenanceOpts.ExpectedBuilderID); err != nil {
-			return err
+			return rep.Check(report.CheckBuilderID, err)
 		}
 	} else {
 		// Note: `provenanceOpts.ExpectedBuilderID` is not provided by the user,
 		// but taken from the certificate. It always is of the form `name@refs/tags/<name>`.
 		if err := verifyBuilderIDExactMatch(prov, provenanceOpts.ExpectedBuilderID); err != nil {
-			return err
+			return rep.Check(report.CheckBuilderID, err)
 		}
 	}
+	_ = rep.Check(report.CheckBuilderID, nil)
 
-	return VerifyProvenanceCommonOptions(prov, provenanceOpts)
+	return VerifyProvenanceCommonOptions(ctx, prov, provenanceOpts)
 }
 
 // VerifyProvenanceCommonOptions verifies the given provenance.
-func VerifyProvenanceCommonOptions(prov iface.Provenance, provenanceOpts *options.ProvenanceOpts) error {
+func VerifyProvenanceCommonOptions(ctx context.Context, prov iface.Provenance, provenanceOpts *options.ProvenanceOpts) error {
+	rep := report.FromContext(ctx)
+	host, err := newGitHubHost(provenanceOpts.GitHubOpts)
+	if err != nil {
+		return err
+	}
+
 	// Verify source.
-	if err := verifySourceURI(prov, provenanceOpts.ExpectedSourceURI); err != nil {
+	if err := rep.Check(report.CheckSourceURI, verifySourceURI(prov, host, provenanceOpts.ExpectedSourceURI)); err != nil {
 		return err
 	}
 
 	// Verify subject digest.
-	if err := verifyDigest(prov, provenanceOpts.ExpectedDigest); err != nil {
+	if err := rep.Check(report.CheckSubjectDigest, verifyDigest(prov, provenanceOpts.ExpectedDigest)); err != nil {
 		return err
 	}
 
 	// Verify the branch.
 	if provenanceOpts.ExpectedBranch != nil {
-		if err := VerifyBranch(prov, *provenanceOpts.ExpectedBranch); err != nil {
+		if err := rep.Check(report.CheckBranch, VerifyBranch(prov, *provenanceOpts.ExpectedBranch)); err != nil {
+			return err
+		}
+	}
+
+	// Verify the branch patterns.
+	if len(provenanceOpts.ExpectedBranchPatterns) > 0 {
+		if err := rep.Check(report.CheckBranch, VerifyBranchPatterns(prov, provenanceOpts.ExpectedBranchPatterns)); err != nil {
 			return err
 		}
 	}
 
 	// Verify the tag.
 	if provenanceOpts.ExpectedTag != nil {
-		if err := VerifyTag(prov, *provenanceOpts.ExpectedTag); err != nil {
+		if err := rep.Check(report.CheckTag, VerifyTag(prov, *provenanceOpts.ExpectedTag)); err != nil {
+			return err
+		}
+	}
+
+	// Verify the tag patterns.
+	if len(provenanceOpts.ExpectedTagPatterns) > 0 {
+		if err := rep.Check(report.CheckTag, VerifyTagPatterns(prov, provenanceOpts.ExpectedTagPatterns)); err != nil {
 			return err
 		}
 	}
 
 	// Verify the versioned tag.
 	if provenanceOpts.ExpectedVersionedTag != nil {
-		if err := VerifyVersionedTag(prov, *provenanceOpts.ExpectedVersionedTag); err != nil {
+		if err := rep.Check(report.CheckVersionedTag, VerifyVersionedTag(prov, *provenanceOpts.ExpectedVersionedTag)); err != nil {
 			return err
 		}
 	}
 
 	// Verify the workflow inputs.
 	if len(provenanceOpts.ExpectedWorkflowInputs) > 0 {
-		if err := VerifyWorkflowInputs(prov, provenanceOpts.ExpectedWorkflowInputs); err != nil {
+		if err := rep.Check(report.CheckWorkflowInputs, VerifyWorkflowInputs(prov, provenanceOpts.ExpectedWorkflowInputs)); err != nil {
 			return err
 		}
 	}
@@ -455,8 +524,8 @@ func VerifyWorkflowInputs(prov iface.Provenance, inputs map[string]string) error
 		}
 
 		if v != value {
-			return fmt.Errorf("%w: expected '%s=%s', got '%s=%s'",
-				serrors.ErrorMismatchWorkflowInputs, k, v, k, value)
+			return serrors.NewVerificationError(fmt.Errorf("%w: expected '%s=%s', got '%s=%s'",
+				serrors.ErrorMismatchWorkflowInputs, k, v, k, value), k+"="+v, k+"="+value)
 		}
 	}
 
@@ -477,7 +546,32 @@ func VerifyBranch(prov iface.Provenance, expectedBranch string) error {
 	}
 
 	if branch != expectedBranch {
-		return fmt.Errorf("expected branch '%s', got '%s': %w", expectedBranch, branch, serrors.ErrorMismatchBranch)
+		return serrors.NewVerificationError(
+			fmt.Errorf("expected branch '%s', got '%s': %w", expectedBranch, branch, serrors.ErrorMismatchBranch),
+			expectedBranch, branch)
+	}
+
+	return nil
+}
+
+// VerifyBranchPatterns verifies that the branch matches one of the patterns.
+func VerifyBranchPatterns(prov iface.Provenance, patterns []string) error {
+	ref, err := prov.GetBranch()
+	if err != nil {
+		return err
+	}
+
+	branch, err := utils.BranchFromGitRef(ref)
+	if err != nil {
+		return fmt.Errorf("verifying branch: %w", err)
+	}
+
+	ok, err := utils.MatchesAnyPattern(branch, patterns)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("expected branch matching one of %q, got '%s': %w", patterns, branch, serrors.ErrorMismatchBranch)
 	}
 
 	return nil
@@ -497,7 +591,32 @@ func VerifyTag(prov iface.Provenance, expectedTag string) error {
 	}
 
 	if tag != expectedTag {
-		return fmt.Errorf("expected tag '%s', got '%s': %w", expectedTag, tag, serrors.ErrorMismatchTag)
+		return serrors.NewVerificationError(
+			fmt.Errorf("expected tag '%s', got '%s': %w", expectedTag, tag, serrors.ErrorMismatchTag),
+			expectedTag, tag)
+	}
+
+	return nil
+}
+
+// VerifyTagPatterns verifies that the tag matches one of the patterns.
+func VerifyTagPatterns(prov iface.Provenance, patterns []string) error {
+	ref, err := prov.GetTag()
+	if err != nil {
+		return err
+	}
+
+	tag, err := utils.TagFromGitRef(ref)
+	if err != nil {
+		return fmt.Errorf("verifying tag: %w", err)
+	}
+
+	ok, err := utils.MatchesAnyPattern(tag, patterns)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("expected tag matching one of %q, got '%s': %w", patterns, tag, serrors.ErrorMismatchTag)
 	}
 
 	return nil
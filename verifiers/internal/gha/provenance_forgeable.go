@@ -291,16 +291,7 @@ func verifyNpmCLIGithubActionsV1SystemParameters(prov *slsav1.NpmCLIGithubAction
 		"repository_id":       workflow.SourceID,
 		"repository_owner_id": workflow.SourceOwnerID,
 	}
-	for k := range githubParams {
-		certValue, ok := supportedNames[k]
-		if !ok {
-			return fmt.Errorf("%w: unknown '%s' parameter", serrors.ErrorMismatchCertificate, k)
-		}
-		if err := verifySystemParameter(githubParams, k, certValue); err != nil {
-			return err
-		}
-	}
-	return nil
+	return verifyParametersMatchCertificate(githubParams, supportedNames)
 }
 
 func verifySystemParameters(prov iface.Provenance, workflow *WorkflowIdentity) error {
@@ -1,6 +1,7 @@
 package gha
 
 import (
+	"context"
 	"os"
 	"path/filepath"
 	"testing"
@@ -232,6 +233,7 @@ func Test_verifySourceURI(t *testing.T) {
 		provMaterialsURI   string
 		provTriggerURI     string
 		expectedSourceURI  string
+		host               *githubHost
 		allowNoMaterialRef bool
 		err                error
 	}{
@@ -400,6 +402,21 @@ func Test_verifySourceURI(t *testing.T) {
 			expectedSourceURI: "git+https://not-github.com/some/repo",
 			err:               serrors.ErrorMalformedURI,
 		},
+		{
+			name:              "ghes repo",
+			provTriggerURI:    "git+https://ghes.example.com/some/repo@v1.2.3",
+			provMaterialsURI:  "git+https://ghes.example.com/some/repo@v1.2.3",
+			expectedSourceURI: "ghes.example.com/some/repo",
+			host:              &githubHost{name: "ghes.example.com"},
+		},
+		{
+			name:              "github.com repo on ghes",
+			provTriggerURI:    "git+https://github.com/some/repo@v1.2.3",
+			provMaterialsURI:  "git+https://github.com/some/repo@v1.2.3",
+			expectedSourceURI: "github.com/some/repo",
+			host:              &githubHost{name: "ghes.example.com"},
+			err:               serrors.ErrorMalformedURI,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -411,7 +428,11 @@ func Test_verifySourceURI(t *testing.T) {
 				triggerURI: tt.provTriggerURI,
 			}
 
-			err := verifySourceURI(prov02, tt.expectedSourceURI)
+			host := tt.host
+			if host == nil {
+				host = defaultGitHubHost
+			}
+			err := verifySourceURI(prov02, host, tt.expectedSourceURI)
 			if !errCmp(err, tt.err) {
 				t.Error(cmp.Diff(err, tt.err))
 			}
@@ -625,6 +646,72 @@ func Test_VerifyBranch(t *testing.T) {
 	}
 }
 
+func Test_VerifyRefPatterns(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		prov     iface.Provenance
+		branches []string
+		tags     []string
+		expected error
+	}{
+		{
+			name: "branch matches pattern",
+			prov: &testProvenance{
+				branch: "refs/heads/release/v1",
+			},
+			branches: []string{"main", "release/*"},
+		},
+		{
+			name: "branch does not match pattern",
+			prov: &testProvenance{
+				branch: "refs/heads/dev",
+			},
+			branches: []string{"main", "release/*"},
+			expected: serrors.ErrorMismatchBranch,
+		},
+		{
+			name: "branch is a tag",
+			prov: &testProvenance{
+				branch: "refs/tags/main",
+			},
+			branches: []string{"main"},
+			expected: serrors.ErrorInvalidRef,
+		},
+		{
+			name: "tag matches pattern",
+			prov: &testProvenance{
+				tag: "refs/tags/v1.2.3",
+			},
+			tags: []string{"v1.*"},
+		},
+		{
+			name: "tag does not match pattern",
+			prov: &testProvenance{
+				tag: "refs/tags/v2.0.0",
+			},
+			tags:     []string{"v1.*"},
+			expected: serrors.ErrorMismatchTag,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var err error
+			if len(tt.branches) > 0 {
+				err = VerifyBranchPatterns(tt.prov, tt.branches)
+			} else {
+				err = VerifyTagPatterns(tt.prov, tt.tags)
+			}
+			if !errCmp(err, tt.expected) {
+				t.Error(cmp.Diff(err, tt.expected))
+			}
+		})
+	}
+}
+
 func Test_VerifyWorkflowInputs(t *testing.T) {
 	t.Parallel()
 	tests := []struct {
@@ -1308,7 +1395,7 @@ func Test_VerifyProvenance(t *testing.T) {
 				t.Errorf("unexpected error parsing envelope %v", err)
 			}
 
-			if err := VerifyProvenance(env, tt.provenanceOpts, trustedBuilderID, tt.byob, tt.expectedID); !errCmp(err, tt.expected) {
+			if err := VerifyProvenance(context.Background(), env, tt.provenanceOpts, trustedBuilderID, tt.byob, tt.expectedID); !errCmp(err, tt.expected) {
 				t.Error(cmp.Diff(err, tt.expected))
 			}
 		})
@@ -1361,7 +1448,7 @@ func Test_VerifyUntrustedProvenance(t *testing.T) {
 				t.Errorf("unexpected error parsing envelope %v", err)
 			}
 
-			if err := VerifyProvenance(env, tt.provenanceOpts, trustedBuilderID, tt.byob, tt.expectedID); errCmp(err, tt.expected) {
+			if err := VerifyProvenance(context.Background(), env, tt.provenanceOpts, trustedBuilderID, tt.byob, tt.expectedID); errCmp(err, tt.expected) {
 				t.Error(cmp.Diff(err, tt.expected))
 			}
 		})
@@ -0,0 +1,263 @@
+package gha
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+
+	intoto "github.com/in-toto/in-toto-golang/in_toto"
+	"github.com/secure-systems-lab/go-securesystemslib/dsse"
+
+	serrors "github.com/slsa-framework/slsa-verifier/v2/errors"
+	"github.com/slsa-framework/slsa-verifier/v2/options"
+	"github.com/slsa-framework/slsa-verifier/v2/report"
+	"github.com/slsa-framework/slsa-verifier/v2/verifiers/internal/gha/slsaprovenance/common"
+	"github.com/slsa-framework/slsa-verifier/v2/verifiers/utils"
+)
+
+// PyPI serves the attestations of the distributions uploaded with Trusted
+// Publishing, as specified by PEP 740. An attestation is a Sigstore bundle
+// in another encoding: the statement is signed by the workflow that
+// published the distribution, which is verified like the signer of a GitHub
+// artifact attestation.
+// See https://peps.python.org/pep-0740/ and https://docs.pypi.org/attestations/.
+
+// PyPIPublishPredicateType is the predicate type of the attestations generated
+// by PyPI Trusted Publishing.
+const PyPIPublishPredicateType = "https://docs.pypi.org/attestations/publish/v1"
+
+const sigstoreBundleV03MediaType = "application/vnd.dev.sigstore.bundle.v0.3+json"
+
+// pypiAttestation is a PEP 740 attestation object.
+type pypiAttestation struct {
+	Version              int `json:"version"`
+	VerificationMaterial struct {
+		// Certificate is the base64 DER signing certificate.
+		Certificate string `json:"certificate"`
+		// TransparencyEntries are Rekor entries, in the JSON encoding of the
+		// TransparencyLogEntry message of the Sigstore protobuf specs.
+		TransparencyEntries []json.RawMessage `json:"transparency_entries"`
+	} `json:"verification_material"`
+	Envelope struct {
+		Statement string `json:"statement"`
+		Signature string `json:"signature"`
+	} `json:"envelope"`
+}
+
+// pypiProvenance is a PEP 740 provenance object, as served by the PyPI
+// integrity API for a distribution.
+type pypiProvenance struct {
+	Version            int `json:"version"`
+	AttestationBundles []struct {
+		Publisher struct {
+			Kind       string `json:"kind"`
+			Repository string `json:"repository"`
+			Workflow   string `json:"workflow"`
+		} `json:"publisher"`
+		Attestations []pypiAttestation `json:"attestations"`
+	} `json:"attestation_bundles"`
+}
+
+// pypiBundles returns the Sigstore bundles of the attestations, which may be
+// a PEP 740 provenance object, a single PEP 740 attestation object, or
+// Sigstore bundles.
+func pypiBundles(attestations []byte) ([][]byte, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(attestations, &fields); err != nil {
+		// Sigstore bundles downloaded with `gh attestation download`
+		// are JSON lines.
+		return splitSigstoreBundles(attestations), nil
+	}
+
+	var atts []pypiAttestation
+	switch {
+	case fields["attestation_bundles"] != nil:
+		var prov pypiProvenance
+		if err := json.Unmarshal(attestations, &prov); err != nil {
+			return nil, fmt.Errorf("%w: provenance object: %w", serrors.ErrorInvalidFormat, err)
+		}
+		if prov.Version != 1 {
+			return nil, fmt.Errorf("%w: provenance object version %d", serrors.ErrorInvalidFormat, prov.Version)
+		}
+		for _, b := range prov.AttestationBundles {
+			atts = append(atts, b.Attestations...)
+		}
+	case fields["verification_material"] != nil:
+		var att pypiAttestation
+		if err := json.Unmarshal(attestations, &att); err != nil {
+			return nil, fmt.Errorf("%w: attestation object: %w", serrors.ErrorInvalidFormat, err)
+		}
+		atts = append(atts, att)
+	default:
+		return [][]byte{attestations}, nil
+	}
+
+	if len(atts) == 0 {
+		return nil, fmt.Errorf("%w: no attestations", serrors.ErrorNoValidSignature)
+	}
+	bundles := make([][]byte, 0, len(atts))
+	for i := range atts {
+		bundle, err := atts[i].sigstoreBundle()
+		if err != nil {
+			return nil, err
+		}
+		bundles = append(bundles, bundle)
+	}
+	return bundles, nil
+}
+
+// sigstoreBundle converts the attestation to a Sigstore bundle. The fields
+// of the attestation are base64 encoded, like the bytes fields of the
+// bundle, so they are copied as is.
+func (a *pypiAttestation) sigstoreBundle() ([]byte, error) {
+	if a.Version != 1 {
+		return nil, fmt.Errorf("%w: attestation object version %d", serrors.ErrorInvalidFormat, a.Version)
+	}
+	tlogEntries := a.VerificationMaterial.TransparencyEntries
+	if tlogEntries == nil {
+		tlogEntries = []json.RawMessage{}
+	}
+	return json.Marshal(map[string]any{
+		"mediaType": sigstoreBundleV03MediaType,
+		"verificationMaterial": map[string]any{
+			"certificate": map[string]string{"rawBytes": a.VerificationMaterial.Certificate},
+			"tlogEntries": tlogEntries,
+		},
+		"dsseEnvelope": map[string]any{
+			"payload":     a.Envelope.Statement,
+			"payloadType": intoto.PayloadType,
+			"signatures":  []map[string]string{{"sig": a.Envelope.Signature}},
+		},
+	})
+}
+
+// VerifyPyPIPackage verifies the attestations of a Python distribution,
+// a wheel or an sdist, published with Trusted Publishing. The digest of the
+// distribution is provenanceOpts.ExpectedDigest.
+func VerifyPyPIPackage(ctx context.Context,
+	attestations []byte, filename string,
+	provenanceOpts *options.ProvenanceOpts,
+	builderOpts *options.BuilderOpts,
+) ([]byte, *utils.TrustedBuilderID, error) {
+	host, err := newGitHubHost(provenanceOpts.GitHubOpts)
+	if err != nil {
+		return nil, nil, err
+	}
+	trustedRoot, err := utils.GetTrustedMaterial(provenanceOpts.SigstoreOpts)
+	if err != nil {
+		return nil, nil, err
+	}
+	bundles, err := pypiBundles(attestations)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return utils.VerifyEach(ctx, bundles, func(ctx context.Context, bundle []byte) (
+		[]byte, *utils.TrustedBuilderID, error,
+	) {
+		rep := report.FromContext(ctx)
+		signedAtt, err := VerifyProvenanceBundle(ctx, bundle, trustedRoot, host)
+		if err := rep.Check(report.CheckSignature, err); err != nil {
+			return nil, nil, err
+		}
+		signedAtt.record(rep)
+		return verifyPyPIEnvAndCert(ctx, signedAtt.Envelope, signedAtt.SigningCert,
+			filename, provenanceOpts, builderOpts)
+	})
+}
+
+// verifyPyPIEnvAndCert verifies a PyPI attestation whose signature has
+// been verified.
+func verifyPyPIEnvAndCert(ctx context.Context, env *dsse.Envelope,
+	cert *x509.Certificate, filename string,
+	provenanceOpts *options.ProvenanceOpts,
+	builderOpts *options.BuilderOpts,
+) ([]byte, *utils.TrustedBuilderID, error) {
+	rep := report.FromContext(ctx)
+	host, err := newGitHubHost(provenanceOpts.GitHubOpts)
+	if err != nil {
+		return nil, nil, err
+	}
+	workflowInfo, err := GetWorkflowInfoFromCertificate(cert)
+	if err != nil {
+		return nil, nil, rep.Check(report.CheckBuilderID, err)
+	}
+	recordSource(rep, host, workflowInfo)
+
+	// Verify the workflow that published the distribution.
+	signerID, err := verifyAttestationSigner(workflowInfo, host, builderOpts)
+	if err := rep.Check(report.CheckBuilderID, err); err != nil {
+		return nil, nil, err
+	}
+	rep.SetBuilderTrust(report.BuilderTrustWorkflow)
+
+	// Verify the source repository from the certificate.
+	if err := VerifyCertficateSourceRepository(workflowInfo, host, provenanceOpts.ExpectedSourceURI); err != nil {
+		return nil, nil, rep.Check(report.CheckSourceURI, err)
+	}
+
+	pyld, err := utils.PayloadFromEnvelope(env)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := verifyPyPIStatement(rep, pyld, filename, provenanceOpts.ExpectedDigest); err != nil {
+		return nil, nil, err
+	}
+
+	// The ref is the ref that triggered the publishing workflow.
+	var ref string
+	if workflowInfo.SourceRef != nil {
+		ref = *workflowInfo.SourceRef
+	}
+	if err := utils.VerifyRef(rep, ref, provenanceOpts); err != nil {
+		return nil, nil, err
+	}
+
+	utils.Logf(ctx, "Verified distribution %s published by workflow %q at commit %s\n",
+		filename,
+		signerID.String(),
+		workflowInfo.SourceSha1)
+
+	return pyld, signerID, nil
+}
+
+// verifyPyPIStatement verifies that the statement of an attestation has the
+// distribution as its only subject.
+func verifyPyPIStatement(rep *report.Report, pyld []byte, filename, expectedDigest string) error {
+	var statement intoto.Statement
+	if err := json.Unmarshal(pyld, &statement); err != nil {
+		return fmt.Errorf("%w: %w", serrors.ErrorInvalidDssePayload, err)
+	}
+	if statement.PredicateType != PyPIPublishPredicateType &&
+		statement.PredicateType != common.ProvenanceV1Type {
+		return rep.Check(report.CheckAttestationHeaders,
+			fmt.Errorf("%w: predicate type %q", serrors.ErrorInvalidDssePayload, statement.PredicateType))
+	}
+	if len(statement.Subject) != 1 {
+		return rep.Check(report.CheckSubjectDigest,
+			fmt.Errorf("%w: %d subjects", serrors.ErrorInvalidDssePayload, len(statement.Subject)))
+	}
+	subject := statement.Subject[0]
+
+	var nameErr error
+	if subject.Name != filename {
+		nameErr = serrors.NewVerificationError(
+			fmt.Errorf("%w: expected %q, got %q", serrors.ErrorMismatchPackageName, filename, subject.Name),
+			filename, subject.Name)
+	}
+	if err := rep.Check(report.CheckPackageName, nameErr); err != nil {
+		return err
+	}
+
+	var digestErr error
+	if digest := subject.Digest["sha256"]; digest != expectedDigest {
+		digestErr = serrors.NewVerificationError(
+			fmt.Errorf("%w: expected sha256 %s, got %q", serrors.ErrorMismatchHash, expectedDigest, digest),
+			expectedDigest, digest)
+	}
+	if err := rep.Check(report.CheckSubjectDigest, digestErr); err != nil {
+		return err
+	}
+	return nil
+}
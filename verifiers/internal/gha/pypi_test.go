@@ -0,0 +1,188 @@
+package gha
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	bundle_v1 "github.com/sigstore/protobuf-specs/gen/pb-go/bundle/v1"
+	"google.golang.org/protobuf/encoding/protojson"
+
+	serrors "github.com/slsa-framework/slsa-verifier/v2/errors"
+	"github.com/slsa-framework/slsa-verifier/v2/verifiers/internal/gha/slsaprovenance/common"
+)
+
+const (
+	testPyPIFilename = "sampleproject-4.0.0-py3-none-any.whl"
+
+	testPyPIAttestation = `{
+		"version": 1,
+		"verification_material": {
+			"certificate": "MIIB",
+			"transparency_entries": [{
+				"logIndex": "148",
+				"logId": {"keyId": "wNI9atQGlz+VWfO6LRygH4QUfY/8W4RFwiT5i5WRgB0="},
+				"kindVersion": {"kind": "dsse", "version": "0.0.1"},
+				"integratedTime": "1730000000",
+				"canonicalizedBody": "e30="
+			}]
+		},
+		"envelope": {
+			"statement": "eyJfdHlwZSI6ICJodHRwczovL2luLXRvdG8uaW8vU3RhdGVtZW50L3YxIn0=",
+			"signature": "MEUC"
+		}
+	}`
+)
+
+// newTestPyPIStatement returns the statement of a PyPI publish attestation
+// of the distribution.
+func newTestPyPIStatement(name, digest string) []byte {
+	return []byte(`{
+		"_type": "https://in-toto.io/Statement/v1",
+		"subject": [{"name": "` + name + `", "digest": {"sha256": "` + digest + `"}}],
+		"predicateType": "https://docs.pypi.org/attestations/publish/v1",
+		"predicate": null
+	}`)
+}
+
+func Test_pypiBundles(t *testing.T) {
+	t.Parallel()
+
+	bundle := `{"mediaType": "application/vnd.dev.sigstore.bundle.v0.3+json"}`
+	tests := []struct {
+		name         string
+		attestations string
+		bundles      int
+		converted    bool
+		err          error
+	}{
+		{
+			name:         "attestation object",
+			attestations: testPyPIAttestation,
+			bundles:      1,
+			converted:    true,
+		},
+		{
+			name: "provenance object",
+			attestations: `{"version": 1, "attestation_bundles": [
+				{"publisher": {"kind": "GitHub", "repository": "org/repo", "workflow": "release.yml"},
+				 "attestations": [` + testPyPIAttestation + `, ` + testPyPIAttestation + `]}
+			]}`,
+			bundles:   2,
+			converted: true,
+		},
+		{
+			name:         "provenance object without attestations",
+			attestations: `{"version": 1, "attestation_bundles": []}`,
+			err:          serrors.ErrorNoValidSignature,
+		},
+		{
+			name:         "unsupported provenance object version",
+			attestations: `{"version": 2, "attestation_bundles": []}`,
+			err:          serrors.ErrorInvalidFormat,
+		},
+		{
+			name:         "Sigstore bundle",
+			attestations: bundle,
+			bundles:      1,
+		},
+		{
+			name:         "Sigstore bundles",
+			attestations: bundle + "\n" + bundle + "\n",
+			bundles:      2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			bundles, err := pypiBundles([]byte(tt.attestations))
+			if diff := cmp.Diff(tt.err, err, cmpopts.EquateErrors()); diff != "" {
+				t.Fatalf("unexpected error (-want +got):\n%s", diff)
+			}
+			if len(bundles) != tt.bundles {
+				t.Fatalf("unexpected number of bundles: want %d, got %d", tt.bundles, len(bundles))
+			}
+			if !tt.converted {
+				return
+			}
+
+			// The attestation is converted to an equivalent Sigstore bundle.
+			for _, b := range bundles {
+				var pb bundle_v1.Bundle
+				if err := protojson.Unmarshal(b, &pb); err != nil {
+					t.Fatal(err)
+				}
+				if got := base64.StdEncoding.EncodeToString(pb.GetVerificationMaterial().GetCertificate().GetRawBytes()); got != "MIIB" {
+					t.Errorf("unexpected certificate: %q", got)
+				}
+				entries := pb.GetVerificationMaterial().GetTlogEntries()
+				if len(entries) != 1 || entries[0].GetLogIndex() != 148 {
+					t.Errorf("unexpected tlog entries: %v", entries)
+				}
+				env, err := getEnvelopeFromBundle(&pb)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if env.PayloadType != "application/vnd.in-toto+json" || len(env.Signatures) != 1 ||
+					env.Signatures[0].Sig != "MEUC" {
+					t.Errorf("unexpected envelope: %v", env)
+				}
+			}
+		})
+	}
+}
+
+func Test_verifyPyPIStatement(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		statement []byte
+		err       error
+	}{
+		{
+			name:      "matching",
+			statement: newTestPyPIStatement(testPyPIFilename, "0a2b4c"),
+		},
+		{
+			name:      "other distribution",
+			statement: newTestPyPIStatement("sampleproject-4.0.0.tar.gz", "0a2b4c"),
+			err:       serrors.ErrorMismatchPackageName,
+		},
+		{
+			name:      "other digest",
+			statement: newTestPyPIStatement(testPyPIFilename, "fedcba"),
+			err:       serrors.ErrorMismatchHash,
+		},
+		{
+			name: "SLSA provenance",
+			statement: []byte(`{"predicateType": "` + common.ProvenanceV1Type + `",
+				"subject": [{"name": "` + testPyPIFilename + `", "digest": {"sha256": "0a2b4c"}}]}`),
+		},
+		{
+			name: "other predicate type",
+			statement: []byte(`{"predicateType": "https://in-toto.io/attestation/vulns/v0.1",
+				"subject": [{"name": "` + testPyPIFilename + `", "digest": {"sha256": "0a2b4c"}}]}`),
+			err: serrors.ErrorInvalidDssePayload,
+		},
+		{
+			name: "multiple subjects",
+			statement: []byte(`{"predicateType": "https://docs.pypi.org/attestations/publish/v1",
+				"subject": [{"name": "` + testPyPIFilename + `", "digest": {"sha256": "0a2b4c"}},
+					{"name": "other.whl", "digest": {"sha256": "0a2b4c"}}]}`),
+			err: serrors.ErrorInvalidDssePayload,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := verifyPyPIStatement(nil, tt.statement, testPyPIFilename, "0a2b4c")
+			if diff := cmp.Diff(tt.err, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("unexpected error (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -9,10 +9,8 @@ import (
 	"encoding/base64"
 	"errors"
 	"fmt"
-	"os"
 	"runtime"
 	"strings"
-	"sync"
 	"time"
 
 	cjson "github.com/docker/go/canonical/json"
@@ -38,6 +36,9 @@ import (
 	sigstoreRoot "github.com/sigstore/sigstore-go/pkg/root"
 	sigstoreVerify "github.com/sigstore/sigstore-go/pkg/verify"
 	serrors "github.com/slsa-framework/slsa-verifier/v2/errors"
+	"github.com/slsa-framework/slsa-verifier/v2/options"
+	"github.com/slsa-framework/slsa-verifier/v2/report"
+	"github.com/slsa-framework/slsa-verifier/v2/verifiers/utils"
 
 	"sigs.k8s.io/release-utils/version"
 )
@@ -46,36 +47,39 @@ const (
 	defaultRekorAddr = "https://rekor.sigstore.dev"
 )
 
-var (
-	defaultRekorClient     *rekorGenClient.Rekor
-	defaultRekorClientOnce = new(sync.Once)
-)
+// cache the Rekor clients, by Rekor URL.
+var rekorClients utils.KeyedCache[*rekorGenClient.Rekor]
+
+func init() {
+	utils.RegisterCache("rekor-clients", &rekorClients)
+}
 
-// getDefaultRekorClient returns a cached Rekor client.
-func getDefaultRekorClient() (*rekorGenClient.Rekor, error) {
-	var err error
-	defaultRekorClientOnce.Do(func() {
+// getRekorClient returns a cached Rekor client for the Sigstore options.
+// A nil sigstoreOpts selects the public-good Rekor instance.
+func getRekorClient(sigstoreOpts *options.SigstoreOpts) (*rekorGenClient.Rekor, error) {
+	if sigstoreOpts != nil && sigstoreOpts.RekorClient != nil {
+		return sigstoreOpts.RekorClient, nil
+	}
+	rekorAddr := defaultRekorAddr
+	if sigstoreOpts != nil && sigstoreOpts.RekorURL != "" {
+		rekorAddr = sigstoreOpts.RekorURL
+	}
+	return rekorClients.Get(rekorAddr, func() (*rekorGenClient.Rekor, error) {
 		userAgent := fmt.Sprintf("slsa-verifier/%s (%s; %s)", version.GetVersionInfo().GitVersion, runtime.GOOS, runtime.GOARCH)
-		defaultRekorClient, err = rekorClient.GetRekorClient(defaultRekorAddr, rekorClient.WithUserAgent(userAgent))
-		if err != nil {
-			defaultRekorClientOnce = new(sync.Once)
-			return
-		}
+		return rekorClient.GetRekorClient(rekorAddr, rekorClient.WithUserAgent(userAgent))
 	})
-	if err != nil {
-		return nil, err
-	}
-	return defaultRekorClient, nil
 }
 
 func verifyTlogEntryByUUID(ctx context.Context, client *rekorGenClient.Rekor,
-	entryUUID string, trustedRoot *sigstoreRoot.LiveTrustedRoot) (
+	entryUUID string, trustedRoot sigstoreRoot.TrustedMaterial) (
 	*models.LogEntryAnon, error,
 ) {
 	params := entries.NewGetLogEntryByUUIDParamsWithContext(ctx)
 	params.EntryUUID = entryUUID
 
+	stop := report.FromContext(ctx).Time(report.StageRekor)
 	lep, err := client.Entries.GetLogEntryByUUID(params)
+	stop()
 	if err != nil {
 		return nil, err
 	}
@@ -109,7 +113,7 @@ func verifyTlogEntryByUUID(ctx context.Context, client *rekorGenClient.Rekor,
 // Verification includes verifying the SignedEntryTimestamp and, if verifyInclusion
 // is true, the inclusion proof along with the signed tree head.
 func verifyTlogEntry(ctx context.Context, e models.LogEntryAnon,
-	verifyInclusion bool, trustedRoot *sigstoreRoot.LiveTrustedRoot) (
+	verifyInclusion bool, trustedRoot sigstoreRoot.TrustedMaterial) (
 	*models.LogEntryAnon, error,
 ) {
 	// get the public key from sigstore-go
@@ -230,7 +234,7 @@ func getUUIDsByArtifactDigest(rClient *rekorGenClient.Rekor, artifactHash string
 	params.Query = &models.SearchIndex{Hash: fmt.Sprintf("sha256:%v", artifactHash)}
 	resp, err := rClient.Index.SearchIndex(params)
 	if err != nil {
-		return nil, fmt.Errorf("%w: %s", serrors.ErrorRekorSearch, err.Error())
+		return nil, fmt.Errorf("%w: %w", serrors.ErrorRekorSearch, err)
 	}
 
 	if len(resp.Payload) == 0 {
@@ -243,8 +247,8 @@ func getUUIDsByArtifactDigest(rClient *rekorGenClient.Rekor, artifactHash string
 // GetValidSignedAttestationWithCert finds and validates the matching entry UUIDs with
 // the full intoto attestation.
 // The attestation generated by the slsa-github-generator libraries contain a signing certificate.
-func GetValidSignedAttestationWithCert(rClient *rekorGenClient.Rekor,
-	provenance []byte, trustedRoot *sigstoreRoot.LiveTrustedRoot,
+func GetValidSignedAttestationWithCert(ctx context.Context, rClient *rekorGenClient.Rekor,
+	provenance []byte, trustedRoot sigstoreRoot.TrustedMaterial, host *githubHost,
 ) (*SignedAttestation, error) {
 	// Use intoto attestation to find rekor entry UUIDs.
 	params := entries.NewSearchLogQueryParams()
@@ -265,9 +269,11 @@ func GetValidSignedAttestationWithCert(rClient *rekorGenClient.Rekor,
 	searchLogQuery.SetEntries([]models.ProposedEntry{intotoEntry, dsseEntry})
 
 	params.SetEntry(&searchLogQuery)
+	stop := report.FromContext(ctx).Time(report.StageRekor)
 	resp, err := rClient.Entries.SearchLogQuery(params)
+	stop()
 	if err != nil {
-		return nil, fmt.Errorf("%w: %s", serrors.ErrorRekorSearch, err.Error())
+		return nil, fmt.Errorf("%w: %w", serrors.ErrorRekorSearch, err)
 	}
 
 	if len(resp.GetPayload()) != 1 {
@@ -283,7 +289,7 @@ func GetValidSignedAttestationWithCert(rClient *rekorGenClient.Rekor,
 		}
 		rekorEntry = e
 		url := fmt.Sprintf("%v/%v/%v", defaultRekorAddr, "api/v1/log/entries", uuid)
-		fmt.Fprintf(os.Stderr, "Verified signature against tlog entry index %d at URL: %s\n", *e.LogIndex, url)
+		utils.Logf(ctx, "Verified signature against tlog entry index %d at URL: %s\n", *e.LogIndex, url)
 	}
 
 	certs, err := cryptoutils.UnmarshalCertificatesFromPEM(certPem)
@@ -305,7 +311,7 @@ func GetValidSignedAttestationWithCert(rClient *rekorGenClient.Rekor,
 		RekorEntry:  &rekorEntry,
 	}
 
-	if err := verifySignedAttestation(proposedSignedAtt, trustedRoot); err != nil {
+	if err := verifySignedAttestation(proposedSignedAtt, trustedRoot, host); err != nil {
 		return nil, err
 	}
 
@@ -315,10 +321,12 @@ func GetValidSignedAttestationWithCert(rClient *rekorGenClient.Rekor,
 // SearchValidSignedAttestation searches for a valid signing certificate using the Rekor
 // Redis search index by using the artifact digest.
 func SearchValidSignedAttestation(ctx context.Context, artifactHash string, provenance []byte,
-	rClient *rekorGenClient.Rekor, trustedRoot *sigstoreRoot.LiveTrustedRoot,
+	rClient *rekorGenClient.Rekor, trustedRoot sigstoreRoot.TrustedMaterial, host *githubHost,
 ) (*SignedAttestation, error) {
 	// Get Rekor UUIDs by artifact digest.
+	stop := report.FromContext(ctx).Time(report.StageRekor)
 	uuids, err := getUUIDsByArtifactDigest(rClient, artifactHash)
+	stop()
 	if err != nil {
 		return nil, err
 	}
@@ -356,7 +364,7 @@ func SearchValidSignedAttestation(ctx context.Context, artifactHash string, prov
 			RekorEntry:  entry,
 		}
 
-		err = verifySignedAttestation(proposedSignedAtt, trustedRoot)
+		err = verifySignedAttestation(proposedSignedAtt, trustedRoot, host)
 		if errors.Is(err, serrors.ErrorInternal) {
 			// Return on an internal error
 			return nil, err
@@ -367,7 +375,7 @@ func SearchValidSignedAttestation(ctx context.Context, artifactHash string, prov
 
 		// success!
 		url := fmt.Sprintf("%v/%v/%v", defaultRekorAddr, "api/v1/log/entries", uuid)
-		fmt.Fprintf(os.Stderr, "Verified signature against tlog entry index %d at URL: %s\n", *entry.LogIndex, url)
+		utils.Logf(ctx, "Verified signature against tlog entry index %d at URL: %s\n", *entry.LogIndex, url)
 		return proposedSignedAtt, nil
 	}
 
@@ -379,8 +387,11 @@ func SearchValidSignedAttestation(ctx context.Context, artifactHash string, prov
 // Rekor entry.
 // The certificate is verified up to Fulcio, the signature is validated
 // using the certificate, and the signature generation time is checked
-// to be within the certificate validity period.
-func verifySignedAttestation(signedAtt *SignedAttestation, trustedRoot *sigstoreRoot.LiveTrustedRoot) error {
+// to be within the certificate validity period, and the certificate
+// identity is checked to be a workflow of the GitHub instance.
+func verifySignedAttestation(signedAtt *SignedAttestation, trustedRoot sigstoreRoot.TrustedMaterial,
+	host *githubHost,
+) error {
 	cert := signedAtt.SigningCert
 	attBytes, err := cjson.MarshalCanonical(signedAtt.Envelope)
 	if err != nil {
@@ -399,16 +410,8 @@ func verifySignedAttestation(signedAtt *SignedAttestation, trustedRoot *sigstore
 	}
 
 	// Verify the certificate identity information.
-	summary, err := sigstoreFulcioCertificate.SummarizeCertificate(cert)
-	if err != nil {
-		return fmt.Errorf("%w: %s", serrors.ErrorInvalidCertificate, err)
-	}
-	certID, err := sigstoreVerify.NewShortCertificateIdentity(certOidcIssuer, "", "", certSubjectRegexp)
-	if err != nil {
-		return fmt.Errorf("%w: %s", serrors.ErrorInvalidCertificate, err)
-	}
-	if err := certID.Verify(summary); err != nil {
-		return fmt.Errorf("%w: %s", serrors.ErrorInvalidCertificate, err)
+	if err := verifyCertificateIdentity(cert, host); err != nil {
+		return err
 	}
 
 	// Verify signature using validated certificate.
@@ -422,3 +425,20 @@ func verifySignedAttestation(signedAtt *SignedAttestation, trustedRoot *sigstore
 	}
 	return nil
 }
+
+// verifyCertificateIdentity verifies that the certificate was issued
+// to a workflow of the GitHub instance.
+func verifyCertificateIdentity(cert *x509.Certificate, host *githubHost) error {
+	summary, err := sigstoreFulcioCertificate.SummarizeCertificate(cert)
+	if err != nil {
+		return fmt.Errorf("%w: %s", serrors.ErrorInvalidCertificate, err)
+	}
+	certID, err := sigstoreVerify.NewShortCertificateIdentity(host.oidcIssuer, "", "", host.certSubjectRegexp())
+	if err != nil {
+		return fmt.Errorf("%w: %s", serrors.ErrorInvalidCertificate, err)
+	}
+	if err := certID.Verify(summary); err != nil {
+		return fmt.Errorf("%w: %s", serrors.ErrorInvalidCertificate, err)
+	}
+	return nil
+}
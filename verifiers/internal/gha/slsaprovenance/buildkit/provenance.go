@@ -0,0 +1,304 @@
+package buildkit
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	intoto "github.com/in-toto/in-toto-golang/in_toto"
+	slsa02 "github.com/in-toto/in-toto-golang/in_toto/slsa_provenance/v0.2"
+	slsa1 "github.com/in-toto/in-toto-golang/in_toto/slsa_provenance/v1"
+
+	serrors "github.com/slsa-framework/slsa-verifier/v2/errors"
+	"github.com/slsa-framework/slsa-verifier/v2/verifiers/internal/gha/slsaprovenance/common"
+	"github.com/slsa-framework/slsa-verifier/v2/verifiers/internal/gha/slsaprovenance/iface"
+)
+
+// Docker BuildKit generates SLSA v0.2 provenance by default, and SLSA v1.0
+// provenance with `--attest type=provenance,version=v1`. Both record the
+// config source when building from a Git context, e.g.
+// https://github.com/org/repo.git#refs/heads/main, and the VCS information
+// of local contexts in the BuildKit metadata.
+// See https://docs.docker.com/build/metadata/attestations/slsa-definitions/.
+
+// configSource is the source of the build definition.
+type configSource struct {
+	URI    string            `json:"uri"`
+	Digest map[string]string `json:"digest"`
+	// Path is the path of the Dockerfile, recorded as entryPoint in
+	// SLSA v0.2 provenance.
+	Path string `json:"path"`
+}
+
+// vcsMetadata is the VCS information of local contexts.
+type vcsMetadata struct {
+	Source   string `json:"source"`
+	Revision string `json:"revision"`
+}
+
+type buildKitMetadata struct {
+	VCS vcsMetadata `json:"vcs"`
+}
+
+// Provenance is the provenance generated by BuildKit.
+type Provenance struct {
+	header               intoto.StatementHeader
+	builderID            string
+	buildType            string
+	configSource         configSource
+	vcs                  vcsMetadata
+	systemParameters     map[string]any
+	invocationID         string
+	startedOn            *time.Time
+	finishedOn           *time.Time
+	resolvedDependencies int
+}
+
+// New returns a new Provenance for the SLSA v0.2 or v1.0 payload.
+// Unlike the provenance of the GitHub builders, the payload is not decoded
+// strictly: BuildKit records its metadata as extension fields.
+func New(_ string, payload []byte) (iface.Provenance, error) {
+	var header intoto.StatementHeader
+	if err := json.Unmarshal(payload, &header); err != nil {
+		return nil, err
+	}
+	switch header.PredicateType {
+	case common.ProvenanceV02Type:
+		return newV02(header, payload)
+	case common.ProvenanceV1Type:
+		return newV1(header, payload)
+	default:
+		return nil, fmt.Errorf("%w: unexpected predicate type %q", serrors.ErrorInvalidDssePayload, header.PredicateType)
+	}
+}
+
+func newV02(header intoto.StatementHeader, payload []byte) (*Provenance, error) {
+	var statement struct {
+		Predicate slsa02.ProvenancePredicate `json:"predicate"`
+	}
+	if err := json.Unmarshal(payload, &statement); err != nil {
+		return nil, err
+	}
+	var extension struct {
+		Predicate struct {
+			Metadata struct {
+				BuildKit buildKitMetadata `json:"https://mobyproject.org/buildkit@v1#metadata"`
+			} `json:"metadata"`
+		} `json:"predicate"`
+	}
+	if err := json.Unmarshal(payload, &extension); err != nil {
+		return nil, err
+	}
+
+	predicate := statement.Predicate
+	if predicate.BuildType != common.BuildKitBuildTypeV02 {
+		return nil, fmt.Errorf("%w: %q", serrors.ErrorInvalidBuildType, predicate.BuildType)
+	}
+	prov := &Provenance{
+		header:    header,
+		builderID: predicate.Builder.ID,
+		buildType: predicate.BuildType,
+		configSource: configSource{
+			URI:    predicate.Invocation.ConfigSource.URI,
+			Digest: predicate.Invocation.ConfigSource.Digest,
+			Path:   predicate.Invocation.ConfigSource.EntryPoint,
+		},
+		vcs:                  extension.Predicate.Metadata.BuildKit.VCS,
+		resolvedDependencies: len(predicate.Materials),
+	}
+	if env, ok := predicate.Invocation.Environment.(map[string]any); ok {
+		prov.systemParameters = env
+	}
+	if predicate.Metadata != nil {
+		prov.invocationID = predicate.Metadata.BuildInvocationID
+		prov.startedOn = predicate.Metadata.BuildStartedOn
+		prov.finishedOn = predicate.Metadata.BuildFinishedOn
+	}
+	return prov, nil
+}
+
+func newV1(header intoto.StatementHeader, payload []byte) (*Provenance, error) {
+	var statement struct {
+		Predicate slsa1.ProvenancePredicate `json:"predicate"`
+	}
+	if err := json.Unmarshal(payload, &statement); err != nil {
+		return nil, err
+	}
+	var extension struct {
+		Predicate struct {
+			BuildDefinition struct {
+				ExternalParameters struct {
+					ConfigSource configSource `json:"configSource"`
+				} `json:"externalParameters"`
+			} `json:"buildDefinition"`
+			RunDetails struct {
+				Metadata struct {
+					BuildKit buildKitMetadata `json:"buildkit_metadata"`
+				} `json:"metadata"`
+			} `json:"runDetails"`
+		} `json:"predicate"`
+	}
+	if err := json.Unmarshal(payload, &extension); err != nil {
+		return nil, err
+	}
+
+	buildDefinition := statement.Predicate.BuildDefinition
+	if buildDefinition.BuildType != common.BuildKitBuildTypeV1 {
+		return nil, fmt.Errorf("%w: %q", serrors.ErrorInvalidBuildType, buildDefinition.BuildType)
+	}
+	metadata := statement.Predicate.RunDetails.BuildMetadata
+	prov := &Provenance{
+		header:               header,
+		builderID:            statement.Predicate.RunDetails.Builder.ID,
+		buildType:            buildDefinition.BuildType,
+		configSource:         extension.Predicate.BuildDefinition.ExternalParameters.ConfigSource,
+		vcs:                  extension.Predicate.RunDetails.Metadata.BuildKit.VCS,
+		invocationID:         metadata.InvocationID,
+		startedOn:            metadata.StartedOn,
+		finishedOn:           metadata.FinishedOn,
+		resolvedDependencies: len(buildDefinition.ResolvedDependencies),
+	}
+	if params, ok := buildDefinition.InternalParameters.(map[string]any); ok {
+		prov.systemParameters = params
+	}
+	return prov, nil
+}
+
+// Source returns the repository, the ref and the commit of the source of
+// the build. The ref is empty if the build used a local context.
+func (p *Provenance) Source() (string, string, string, error) {
+	// Git context.
+	if p.configSource.URI != "" {
+		repository, fragment, _ := strings.Cut(p.configSource.URI, "#")
+		// The fragment is the ref and, optionally, the subdirectory of the context.
+		ref, _, _ := strings.Cut(fragment, ":")
+		commit := p.configSource.Digest["sha1"]
+		if commit == "" {
+			return "", "", "", fmt.Errorf("%w: no commit for config source %q",
+				serrors.ErrorInvalidDssePayload, p.configSource.URI)
+		}
+		return normalizeRepository(repository), ref, commit, nil
+	}
+
+	// Local context.
+	if p.vcs.Source == "" || p.vcs.Revision == "" {
+		return "", "", "", fmt.Errorf("%w: no config source or VCS metadata", serrors.ErrorInvalidDssePayload)
+	}
+	return normalizeRepository(p.vcs.Source), "", p.vcs.Revision, nil
+}
+
+// normalizeRepository returns the repository URI without the .git suffix.
+func normalizeRepository(uri string) string {
+	return strings.TrimSuffix(uri, ".git")
+}
+
+// BuilderID implements Provenance.BuilderID.
+func (p *Provenance) BuilderID() (string, error) {
+	return p.builderID, nil
+}
+
+// BuildType implements Provenance.BuildType.
+func (p *Provenance) BuildType() (string, error) {
+	return p.buildType, nil
+}
+
+// SourceURI implements Provenance.SourceURI.
+func (p *Provenance) SourceURI() (string, error) {
+	repository, ref, commit, err := p.Source()
+	if err != nil {
+		return "", err
+	}
+	// The ref of local contexts is not recorded, so the commit identifies
+	// the source instead.
+	if ref == "" {
+		ref = commit
+	}
+	return fmt.Sprintf("git+%s@%s", repository, ref), nil
+}
+
+// TriggerURI implements Provenance.TriggerURI. BuildKit does not record a
+// trigger, which is the source of the build.
+func (p *Provenance) TriggerURI() (string, error) {
+	return p.SourceURI()
+}
+
+// Subjects implements Provenance.Subjects.
+func (p *Provenance) Subjects() ([]intoto.Subject, error) {
+	if len(p.header.Subject) == 0 {
+		return nil, fmt.Errorf("%w: %s", serrors.ErrorInvalidDssePayload, "no subjects")
+	}
+	return p.header.Subject, nil
+}
+
+// GetBranch implements Provenance.GetBranch.
+func (p *Provenance) GetBranch() (string, error) {
+	ref, err := p.ref()
+	if err != nil {
+		return "", err
+	}
+	if !strings.HasPrefix(ref, "refs/heads/") {
+		return "", nil
+	}
+	return ref, nil
+}
+
+// GetTag implements Provenance.GetTag.
+func (p *Provenance) GetTag() (string, error) {
+	ref, err := p.ref()
+	if err != nil {
+		return "", err
+	}
+	if !strings.HasPrefix(ref, "refs/tags/") {
+		return "", nil
+	}
+	return ref, nil
+}
+
+func (p *Provenance) ref() (string, error) {
+	_, ref, _, err := p.Source()
+	if err != nil {
+		return "", err
+	}
+	if ref == "" {
+		return "", fmt.Errorf("%w: the ref of local contexts is not recorded in %s provenance",
+			serrors.ErrorNotPresent, p.buildType)
+	}
+	return ref, nil
+}
+
+// GetBuildTriggerPath implements Provenance.GetBuildTriggerPath.
+func (p *Provenance) GetBuildTriggerPath() (string, error) {
+	return p.configSource.Path, nil
+}
+
+// GetSystemParameters implements Provenance.GetSystemParameters.
+func (p *Provenance) GetSystemParameters() (map[string]any, error) {
+	return p.systemParameters, nil
+}
+
+// GetBuildInvocationID implements Provenance.GetBuildInvocationID.
+func (p *Provenance) GetBuildInvocationID() (string, error) {
+	return p.invocationID, nil
+}
+
+// GetBuildStartTime implements Provenance.GetBuildStartTime.
+func (p *Provenance) GetBuildStartTime() (*time.Time, error) {
+	return p.startedOn, nil
+}
+
+// GetBuildFinishTime implements Provenance.GetBuildFinishTime.
+func (p *Provenance) GetBuildFinishTime() (*time.Time, error) {
+	return p.finishedOn, nil
+}
+
+// GetNumberResolvedDependencies implements Provenance.GetNumberResolvedDependencies.
+func (p *Provenance) GetNumberResolvedDependencies() (int, error) {
+	return p.resolvedDependencies, nil
+}
+
+// GetWorkflowInputs implements Provenance.GetWorkflowInputs.
+func (p *Provenance) GetWorkflowInputs() (map[string]interface{}, error) {
+	return nil, fmt.Errorf("%w: workflow inputs are not recorded in %s provenance",
+		serrors.ErrorNotPresent, p.buildType)
+}
@@ -0,0 +1,203 @@
+package buildkit
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+
+	serrors "github.com/slsa-framework/slsa-verifier/v2/errors"
+)
+
+const (
+	gitContextV02 = `{
+		"_type": "https://in-toto.io/Statement/v0.1",
+		"predicateType": "https://slsa.dev/provenance/v0.2",
+		"subject": [{"name": "pkg:docker/ghcr.io/org/repo@latest?platform=linux%2Famd64",
+			"digest": {"sha256": "5f8b5a6c9d3e2f1a0b9c8d7e6f5a4b3c2d1e0f9a8b7c6d5e4f3a2b1c0d9e8f7a"}}],
+		"predicate": {
+			"builder": {"id": "https://github.com/org/repo/actions/runs/123/attempts/1"},
+			"buildType": "https://mobyproject.org/buildkit@v1",
+			"materials": [{"uri": "pkg:docker/alpine@3.20?platform=linux%2Famd64",
+				"digest": {"sha256": "0a1b2c3d4e5f60718293a4b5c6d7e8f90a1b2c3d4e5f60718293a4b5c6d7e8f9"}}],
+			"invocation": {
+				"configSource": {"uri": "https://github.com/org/repo.git#refs/heads/main:docker",
+					"digest": {"sha1": "8d5a3b1c2e4f6a7b9c0d1e2f3a4b5c6d7e8f9a0b"}, "entryPoint": "Dockerfile"},
+				"parameters": {"frontend": "dockerfile.v0"},
+				"environment": {"platform": "linux/amd64"}
+			},
+			"metadata": {
+				"buildInvocationID": "sm4j8t1qqnh3ltq2u9x4lvnhu",
+				"https://mobyproject.org/buildkit@v1#metadata": {}
+			}
+		}
+	}`
+
+	localContextV1 = `{
+		"_type": "https://in-toto.io/Statement/v0.1",
+		"predicateType": "https://slsa.dev/provenance/v1",
+		"subject": [{"name": "pkg:docker/ghcr.io/org/repo@latest?platform=linux%2Farm64",
+			"digest": {"sha256": "5f8b5a6c9d3e2f1a0b9c8d7e6f5a4b3c2d1e0f9a8b7c6d5e4f3a2b1c0d9e8f7a"}}],
+		"predicate": {
+			"buildDefinition": {
+				"buildType": "https://github.com/moby/buildkit/blob/master/docs/attestations/slsa-definitions.md",
+				"externalParameters": {
+					"configSource": {"path": "Dockerfile"},
+					"request": {"frontend": "dockerfile.v0"}
+				},
+				"internalParameters": {"builderPlatform": "linux/amd64"}
+			},
+			"runDetails": {
+				"builder": {"id": ""},
+				"metadata": {
+					"invocationID": "sm4j8t1qqnh3ltq2u9x4lvnhu",
+					"buildkit_metadata": {
+						"vcs": {"source": "https://github.com/org/repo.git",
+							"revision": "8d5a3b1c2e4f6a7b9c0d1e2f3a4b5c6d7e8f9a0b"}
+					}
+				}
+			}
+		}
+	}`
+)
+
+func Test_New(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		payload   string
+		sourceURI string
+		branch    string
+		path      string
+		err       error
+		branchErr error
+	}{
+		{
+			name:      "SLSA v0.2 Git context",
+			payload:   gitContextV02,
+			sourceURI: "git+https://github.com/org/repo@refs/heads/main",
+			branch:    "refs/heads/main",
+			path:      "Dockerfile",
+		},
+		{
+			name:      "SLSA v1.0 local context",
+			payload:   localContextV1,
+			sourceURI: "git+https://github.com/org/repo@8d5a3b1c2e4f6a7b9c0d1e2f3a4b5c6d7e8f9a0b",
+			path:      "Dockerfile",
+			branchErr: serrors.ErrorNotPresent,
+		},
+		{
+			name: "other buildType",
+			payload: `{"predicateType": "https://slsa.dev/provenance/v0.2",
+				"predicate": {"buildType": "https://github.com/slsa-framework/slsa-github-generator/container@v1"}}`,
+			err: serrors.ErrorInvalidBuildType,
+		},
+		{
+			name:    "other predicate type",
+			payload: `{"predicateType": "https://in-toto.io/attestation/vulns/v0.1"}`,
+			err:     serrors.ErrorInvalidDssePayload,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			prov, err := New("", []byte(tt.payload))
+			if diff := cmp.Diff(tt.err, err, cmpopts.EquateErrors()); diff != "" {
+				t.Fatalf("unexpected error (-want +got):\n%s", diff)
+			}
+			if err != nil {
+				return
+			}
+
+			sourceURI, err := prov.SourceURI()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if sourceURI != tt.sourceURI {
+				t.Errorf("unexpected source URI: want %q, got %q", tt.sourceURI, sourceURI)
+			}
+			triggerURI, err := prov.TriggerURI()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if triggerURI != sourceURI {
+				t.Errorf("unexpected trigger URI: want %q, got %q", sourceURI, triggerURI)
+			}
+			branch, err := prov.GetBranch()
+			if diff := cmp.Diff(tt.branchErr, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("unexpected branch error (-want +got):\n%s", diff)
+			}
+			if branch != tt.branch {
+				t.Errorf("unexpected branch: want %q, got %q", tt.branch, branch)
+			}
+			path, err := prov.GetBuildTriggerPath()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if path != tt.path {
+				t.Errorf("unexpected path: want %q, got %q", tt.path, path)
+			}
+		})
+	}
+}
+
+func Test_Source(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		prov       *Provenance
+		repository string
+		ref        string
+		commit     string
+		err        error
+	}{
+		{
+			name: "Git context with tag",
+			prov: &Provenance{configSource: configSource{
+				URI:    "https://github.com/org/repo.git#refs/tags/v1.2.3",
+				Digest: map[string]string{"sha1": "8d5a3b1c2e4f6a7b9c0d1e2f3a4b5c6d7e8f9a0b"},
+			}},
+			repository: "https://github.com/org/repo",
+			ref:        "refs/tags/v1.2.3",
+			commit:     "8d5a3b1c2e4f6a7b9c0d1e2f3a4b5c6d7e8f9a0b",
+		},
+		{
+			name: "Git context without commit",
+			prov: &Provenance{configSource: configSource{
+				URI: "https://github.com/org/repo.git#refs/tags/v1.2.3",
+			}},
+			err: serrors.ErrorInvalidDssePayload,
+		},
+		{
+			name: "local context",
+			prov: &Provenance{vcs: vcsMetadata{
+				Source:   "https://github.com/org/repo",
+				Revision: "8d5a3b1c2e4f6a7b9c0d1e2f3a4b5c6d7e8f9a0b",
+			}},
+			repository: "https://github.com/org/repo",
+			commit:     "8d5a3b1c2e4f6a7b9c0d1e2f3a4b5c6d7e8f9a0b",
+		},
+		{
+			name: "local context without VCS metadata",
+			prov: &Provenance{},
+			err:  serrors.ErrorInvalidDssePayload,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			repository, ref, commit, err := tt.prov.Source()
+			if diff := cmp.Diff(tt.err, err, cmpopts.EquateErrors()); diff != "" {
+				t.Fatalf("unexpected error (-want +got):\n%s", diff)
+			}
+			if repository != tt.repository || ref != tt.ref || commit != tt.commit {
+				t.Errorf("unexpected source: want %q %q %q, got %q %q %q",
+					tt.repository, tt.ref, tt.commit, repository, ref, commit)
+			}
+		})
+	}
+}
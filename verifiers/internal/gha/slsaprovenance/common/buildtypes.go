@@ -24,6 +24,18 @@ var (
 
 	// NpmCLIGithubActionsBuildTypeV1 is the buildType for provenance by the npm cli from GitHub Actions.
 	NpmCLIGithubActionsBuildTypeV1 = "https://slsa-framework.github.io/github-actions-buildtypes/workflow/v1"
+
+	// GitHubActionsBuildTypeV1 is the buildType for GitHub artifact attestations,
+	// generated by actions/attest-build-provenance.
+	GitHubActionsBuildTypeV1 = "https://actions.github.io/buildtypes/workflow/v1"
+
+	// BuildKitBuildTypeV02 is the buildType of the SLSA v0.2 provenance
+	// generated by Docker BuildKit.
+	BuildKitBuildTypeV02 = "https://mobyproject.org/buildkit@v1"
+
+	// BuildKitBuildTypeV1 is the buildType of the SLSA v1.0 provenance
+	// generated by Docker BuildKit.
+	BuildKitBuildTypeV1 = "https://github.com/moby/buildkit/blob/master/docs/attestations/slsa-definitions.md"
 )
 
 // Legacy buildTypes.
@@ -9,6 +9,7 @@ import (
 	dsselib "github.com/secure-systems-lab/go-securesystemslib/dsse"
 
 	serrors "github.com/slsa-framework/slsa-verifier/v2/errors"
+	"github.com/slsa-framework/slsa-verifier/v2/verifiers/internal/gha/slsaprovenance/buildkit"
 	"github.com/slsa-framework/slsa-verifier/v2/verifiers/internal/gha/slsaprovenance/common"
 	"github.com/slsa-framework/slsa-verifier/v2/verifiers/internal/gha/slsaprovenance/iface"
 	slsav02 "github.com/slsa-framework/slsa-verifier/v2/verifiers/internal/gha/slsaprovenance/v0.2"
@@ -25,6 +26,24 @@ var predicateTypeMap = map[string]provenanceConstructor{
 	common.ProvenanceV1Type:  slsav1.New,
 }
 
+// buildTypeMap stores the provenance of buildTypes that are generated in
+// several predicate types. It is a map of buildType -> ProvenanceConstructor,
+// and takes precedence over predicateTypeMap.
+var buildTypeMap = map[string]provenanceConstructor{
+	common.BuildKitBuildTypeV02: buildkit.New,
+	common.BuildKitBuildTypeV1:  buildkit.New,
+}
+
+// statementBuildType is the buildType of SLSA v0.2 and v1.0 provenance.
+type statementBuildType struct {
+	Predicate struct {
+		BuildType       string `json:"buildType"`
+		BuildDefinition struct {
+			BuildType string `json:"buildType"`
+		} `json:"buildDefinition"`
+	} `json:"predicate"`
+}
+
 // ProvenanceFromEnvelope returns a Provenance instance for the given builder
 // ID and DSSE Envelope. The builder ID is retrieved from the signing certificate
 // rather than from the payload itself in order to support delegated builders.
@@ -50,6 +69,15 @@ func ProvenanceFromEnvelope(builderID string, env *dsselib.Envelope) (iface.Prov
 	if !ok {
 		return nil, fmt.Errorf("%w: unexpected predicate type %q", serrors.ErrorInvalidDssePayload, pred.PredicateType)
 	}
+	var bt statementBuildType
+	if err := json.Unmarshal(pyld, &bt); err != nil {
+		return nil, fmt.Errorf("%w: decoding json: %w", serrors.ErrorInvalidDssePayload, err)
+	}
+	for _, buildType := range []string{bt.Predicate.BuildType, bt.Predicate.BuildDefinition.BuildType} {
+		if c, ok := buildTypeMap[buildType]; ok {
+			newProv = c
+		}
+	}
 	prov, err := newProv(builderID, pyld)
 	if err != nil {
 		return nil, fmt.Errorf("%w: %w", serrors.ErrorInvalidDssePayload, err)
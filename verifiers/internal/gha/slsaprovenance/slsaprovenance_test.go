@@ -50,6 +50,23 @@ func Test_ProvenanceFromEnvelope(t *testing.T) {
 				}),
 			},
 		},
+		{
+			name:      "valid dsse: BuildKit provenance of any builder",
+			builderID: "https://github.com/org/repo/.github/workflows/docker.yml",
+			envelope: &dsse.Envelope{
+				PayloadType: intoto.PayloadType,
+				Payload: mustJSON(&slsav1.Attestation{
+					StatementHeader: intoto.StatementHeader{
+						PredicateType: intoto_slsav1.PredicateSLSAProvenance,
+					},
+					Predicate: intoto_slsav1.ProvenancePredicate{
+						BuildDefinition: intoto_slsav1.ProvenanceBuildDefinition{
+							BuildType: common.BuildKitBuildTypeV1,
+						},
+					},
+				}),
+			},
+		},
 		{
 			name:      "invalid dsse: not SLSA predicate",
 			builderID: common.GenericDelegatorBuilderID,
@@ -75,7 +75,16 @@ func (p *provenanceV1) builderTriggerInfo() (string, string, string, error) {
 
 	repo := strings.Join(parts[:2], "/")
 	path := strings.Join(parts[2:], "/")
-	return fmt.Sprintf("git+https://github.com/%s", repo), ref, path, nil
+
+	// Builds on GitHub Enterprise Server record the URL of the instance.
+	serverURL := "https://github.com"
+	if _, exists := sysParams["GITHUB_SERVER_URL"]; exists {
+		serverURL, err = common.GetAsString(sysParams, "GITHUB_SERVER_URL")
+		if err != nil {
+			return "", "", "", err
+		}
+	}
+	return fmt.Sprintf("git+%s/%s", strings.TrimSuffix(serverURL, "/"), repo), ref, path, nil
 }
 
 func (p *provenanceV1) triggerInfo() (string, string, string, error) {
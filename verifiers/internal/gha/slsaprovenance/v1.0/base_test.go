@@ -212,3 +212,65 @@ func Test_GetBuildTriggerPath(t *testing.T) {
 		})
 	}
 }
+
+func Test_builderTriggerInfo(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		name          string
+		sysParams     map[string]interface{}
+		expectedURI   string
+		expectedError error
+	}{
+		{
+			name: "github.com",
+			sysParams: map[string]interface{}{
+				"GITHUB_WORKFLOW_REF": "org/repo/.github/workflows/release.yml@refs/tags/v1.2.3",
+			},
+			expectedURI: "git+https://github.com/org/repo",
+		},
+		{
+			name: "github enterprise server",
+			sysParams: map[string]interface{}{
+				"GITHUB_SERVER_URL":   "https://ghes.example.com",
+				"GITHUB_WORKFLOW_REF": "org/repo/.github/workflows/release.yml@refs/tags/v1.2.3",
+			},
+			expectedURI: "git+https://ghes.example.com/org/repo",
+		},
+		{
+			name: "invalid server url",
+			sysParams: map[string]interface{}{
+				"GITHUB_SERVER_URL":   1,
+				"GITHUB_WORKFLOW_REF": "org/repo/.github/workflows/release.yml@refs/tags/v1.2.3",
+			},
+			expectedError: serrors.ErrorInvalidDssePayload,
+		},
+		{
+			name:          "missing workflow ref",
+			sysParams:     map[string]interface{}{},
+			expectedError: serrors.ErrorNotPresent,
+		},
+	}
+	for i := range testCases {
+		tt := testCases[i]
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			prov := &provenanceV1{
+				prov: &Attestation{
+					Predicate: slsa1.ProvenancePredicate{
+						BuildDefinition: slsa1.ProvenanceBuildDefinition{
+							InternalParameters: tt.sysParams,
+						},
+					},
+				},
+			}
+			uri, _, _, err := prov.builderTriggerInfo()
+			if diff := cmp.Diff(tt.expectedError, err, cmpopts.EquateErrors()); diff != "" {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got, want := uri, tt.expectedURI; got != want {
+				t.Fatalf("unexpected trigger URI, got: %q, want: %q", got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,81 @@
+package v1
+
+import (
+	"fmt"
+	"strings"
+
+	serrors "github.com/slsa-framework/slsa-verifier/v2/errors"
+)
+
+// GitHubActionsProvenance is the provenance of GitHub artifact attestations.
+// See https://actions.github.io/buildtypes/workflow/v1.
+type GitHubActionsProvenance struct {
+	*provenanceV1
+}
+
+// TriggerURI implements Provenance.TriggerURI.
+func (p *GitHubActionsProvenance) TriggerURI() (string, error) {
+	return p.workflowTriggerURI()
+}
+
+// GetBranch implements Provenance.GetBranch.
+func (p *GitHubActionsProvenance) GetBranch() (string, error) {
+	_, ref, err := p.workflowRepositoryAndRef()
+	if err != nil {
+		return "", err
+	}
+	if !strings.HasPrefix(ref, "refs/heads/") {
+		return "", nil
+	}
+	return ref, nil
+}
+
+// GetTag implements Provenance.GetTag.
+func (p *GitHubActionsProvenance) GetTag() (string, error) {
+	_, ref, err := p.workflowRepositoryAndRef()
+	if err != nil {
+		return "", err
+	}
+	if !strings.HasPrefix(ref, "refs/tags/") {
+		return "", nil
+	}
+	return ref, nil
+}
+
+// GetWorkflowInputs implements Provenance.GetWorkflowInputs.
+func (p *GitHubActionsProvenance) GetWorkflowInputs() (map[string]interface{}, error) {
+	return nil, fmt.Errorf("%w: workflow inputs are not recorded in %s provenance",
+		serrors.ErrorNotPresent, p.prov.Predicate.BuildDefinition.BuildType)
+}
+
+// workflowTriggerURI returns the trigger URI from the workflow parameters
+// of the GitHub Actions workflow buildTypes.
+func (p *provenanceV1) workflowTriggerURI() (string, error) {
+	repository, ref, err := p.workflowRepositoryAndRef()
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("git+%s@%s", repository, ref), nil
+}
+
+// workflowRepositoryAndRef returns the repository and ref of the workflow
+// parameters of the GitHub Actions workflow buildTypes.
+func (p *provenanceV1) workflowRepositoryAndRef() (string, string, error) {
+	externalParams, err := p.getExternalParameters()
+	if err != nil {
+		return "", "", err
+	}
+	workflow, ok := externalParams["workflow"].(map[string]interface{})
+	if !ok {
+		return "", "", fmt.Errorf("%w: %s", serrors.ErrorInvalidFormat, "workflow parameters")
+	}
+	repository, ok := workflow["repository"].(string)
+	if !ok {
+		return "", "", fmt.Errorf("%w: %s", serrors.ErrorInvalidFormat, "workflow parameters: repository")
+	}
+	ref, ok := workflow["ref"].(string)
+	if !ok {
+		return "", "", fmt.Errorf("%w: %s", serrors.ErrorInvalidFormat, "workflow parameters: ref")
+	}
+	return repository, ref, nil
+}
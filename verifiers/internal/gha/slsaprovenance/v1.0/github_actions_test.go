@@ -0,0 +1,102 @@
+package v1
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	slsa1 "github.com/in-toto/in-toto-golang/in_toto/slsa_provenance/v1"
+
+	serrors "github.com/slsa-framework/slsa-verifier/v2/errors"
+)
+
+func newTestGitHubActionsProvenance(workflow map[string]interface{}) *GitHubActionsProvenance {
+	return &GitHubActionsProvenance{
+		provenanceV1: &provenanceV1{
+			prov: &Attestation{
+				Predicate: slsa1.ProvenancePredicate{
+					BuildDefinition: slsa1.ProvenanceBuildDefinition{
+						ExternalParameters: map[string]interface{}{
+							"workflow": workflow,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func Test_GitHubActionsProvenance(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name       string
+		workflow   map[string]interface{}
+		triggerURI string
+		branch     string
+		tag        string
+		err        error
+	}{
+		{
+			name: "branch",
+			workflow: map[string]interface{}{
+				"repository": testProvRepository,
+				"ref":        testProvRef,
+				"path":       ".github/workflows/release.yml",
+			},
+			triggerURI: testProvTriggerURI,
+			branch:     testProvRef,
+		},
+		{
+			name: "tag",
+			workflow: map[string]interface{}{
+				"repository": testProvRepository,
+				"ref":        "refs/tags/v1.2.3",
+				"path":       ".github/workflows/release.yml",
+			},
+			triggerURI: "git+https://github.com/sigstore/sigstore-js@refs/tags/v1.2.3",
+			tag:        "refs/tags/v1.2.3",
+		},
+		{
+			name: "missing ref",
+			workflow: map[string]interface{}{
+				"repository": testProvRepository,
+			},
+			err: serrors.ErrorInvalidFormat,
+		},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			prov := newTestGitHubActionsProvenance(tt.workflow)
+			triggerURI, err := prov.TriggerURI()
+			if diff := cmp.Diff(tt.err, err, cmpopts.EquateErrors()); diff != "" {
+				t.Fatalf("unexpected error (-want +got):\n%s", diff)
+			}
+			if err != nil {
+				return
+			}
+			if triggerURI != tt.triggerURI {
+				t.Errorf("unexpected trigger URI: got %q, want %q", triggerURI, tt.triggerURI)
+			}
+			branch, err := prov.GetBranch()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if branch != tt.branch {
+				t.Errorf("unexpected branch: got %q, want %q", branch, tt.branch)
+			}
+			tag, err := prov.GetTag()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tag != tt.tag {
+				t.Errorf("unexpected tag: got %q, want %q", tag, tt.tag)
+			}
+			if _, err := prov.GetWorkflowInputs(); !cmp.Equal(err, serrors.ErrorNotPresent, cmpopts.EquateErrors()) {
+				t.Errorf("unexpected workflow inputs error: %v", err)
+			}
+		})
+	}
+}
@@ -50,6 +50,14 @@ func newNpmCLIGithubActions(a *Attestation) iface.Provenance {
 	}
 }
 
+func newGitHubActions(a *Attestation) iface.Provenance {
+	return &GitHubActionsProvenance{
+		provenanceV1: &provenanceV1{
+			prov: a,
+		},
+	}
+}
+
 // buildTypeMap is a map of builder IDs to supported buildTypes.
 var buildTypeMap = map[string]map[string]provFunc{
 	common.GenericDelegatorBuilderID:         {common.BYOBBuildTypeV0: newBYOB},
@@ -58,6 +66,12 @@ var buildTypeMap = map[string]map[string]provFunc{
 	common.NpmCLIHostedBuilderID:             {common.NpmCLIGithubActionsBuildTypeV1: newNpmCLIGithubActions},
 }
 
+// workflowBuildTypeMap is a map of buildTypes whose builder ID is the
+// workflow that generated the provenance, rather than a known builder.
+var workflowBuildTypeMap = map[string]provFunc{
+	common.GitHubActionsBuildTypeV1: newGitHubActions,
+}
+
 // New returns a new Provenance object based on the payload.
 func New(builderID string, payload []byte) (iface.Provenance, error) {
 	// Strict unmarshal.
@@ -73,6 +87,9 @@ func New(builderID string, payload []byte) (iface.Provenance, error) {
 
 	btMap, ok := buildTypeMap[builderID]
 	if !ok {
+		if provFunc, ok := workflowBuildTypeMap[a.Predicate.BuildDefinition.BuildType]; ok {
+			return provFunc(a), nil
+		}
 		return nil, fmt.Errorf("%w: %q", serrors.ErrorInvalidBuilderID, builderID)
 	}
 
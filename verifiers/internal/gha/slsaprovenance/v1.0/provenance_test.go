@@ -65,6 +65,40 @@ func Test_New(t *testing.T) {
 				},
 			},
 		},
+		{
+			name:      "GitHub Actions build type",
+			builderID: "https://github.com/org/repo/.github/workflows/release.yml",
+			payload: fmt.Sprintf(`{
+				"predicate": {
+					"buildDefinition": {
+						"buildType": %q
+					}
+				}
+			}`, common.GitHubActionsBuildTypeV1),
+			prov: &GitHubActionsProvenance{
+				provenanceV1: &provenanceV1{
+					prov: &Attestation{
+						Predicate: slsa1.ProvenancePredicate{
+							BuildDefinition: slsa1.ProvenanceBuildDefinition{
+								BuildType: common.GitHubActionsBuildTypeV1,
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name:      "GitHub Actions build type for a builder",
+			builderID: common.GenericDelegatorBuilderID,
+			payload: fmt.Sprintf(`{
+				"predicate": {
+					"buildDefinition": {
+						"buildType": %q
+					}
+				}
+			}`, common.GitHubActionsBuildTypeV1),
+			err: serrors.ErrorInvalidBuildType,
+		},
 		{
 			name: "Unknown fields",
 			payload: `{
@@ -110,7 +144,7 @@ func Test_New(t *testing.T) {
 			if diff := cmp.Diff(tt.err, err, cmpopts.EquateErrors()); diff != "" {
 				t.Fatalf("unexpected error (-want +got): \n%s", diff)
 			}
-			if diff := cmp.Diff(tt.prov, p, cmp.AllowUnexported(provenanceV1{}, BYOBProvenance{}, ContainerBasedProvenance{})); diff != "" {
+			if diff := cmp.Diff(tt.prov, p, cmp.AllowUnexported(provenanceV1{}, BYOBProvenance{}, ContainerBasedProvenance{}, GitHubActionsProvenance{})); diff != "" {
 				t.Fatalf("unexpected result (-want +got): \n%s", diff)
 			}
 		})
@@ -0,0 +1,111 @@
+package gha
+
+import (
+	"fmt"
+	"strings"
+
+	serrors "github.com/slsa-framework/slsa-verifier/v2/errors"
+	"github.com/slsa-framework/slsa-verifier/v2/options"
+	"github.com/slsa-framework/slsa-verifier/v2/verifiers/utils"
+)
+
+// customBuilder is a reusable workflow trusted by the user,
+// in addition to the built-in builders. See options.TrustedBuilder.
+type customBuilder struct {
+	// id is the ID of the workflow on the GitHub instance.
+	id string
+	// builtinID is the ID on github.com of the built-in builder
+	// whose provenance the workflow generates.
+	builtinID string
+	// refPatterns are the patterns the ref of the workflow must match.
+	refPatterns []string
+}
+
+// newCustomBuilders returns the custom builders configured in opts.
+func newCustomBuilders(host *githubHost, opts []options.TrustedBuilder) ([]customBuilder, error) {
+	builders := make([]customBuilder, 0, len(opts))
+	for _, o := range opts {
+		if o.Workflow == "" || strings.Contains(o.Workflow, "://") || strings.Contains(o.Workflow, "@") ||
+			!strings.Contains(o.Workflow, "/.github/workflows/") {
+			return nil, fmt.Errorf("%w: trusted workflow %q: expected org/repo/.github/workflows/<file>",
+				serrors.ErrorInvalidBuilderID, o.Workflow)
+		}
+		if !isBuiltinBuilder(o.Builder) {
+			return nil, fmt.Errorf("%w: trusted workflow %q: unknown builder %q",
+				serrors.ErrorInvalidBuilderID, o.Workflow, o.Builder)
+		}
+		if err := utils.ValidatePatterns(o.RefPatterns); err != nil {
+			return nil, fmt.Errorf("trusted workflow %q: %w", o.Workflow, err)
+		}
+		builders = append(builders, customBuilder{
+			id:          host.url() + strings.TrimPrefix(o.Workflow, "/"),
+			builtinID:   o.Builder,
+			refPatterns: o.RefPatterns,
+		})
+	}
+	return builders, nil
+}
+
+func isBuiltinBuilder(id string) bool {
+	return defaultArtifactTrustedReusableWorkflows[id] || defaultContainerTrustedReusableWorkflows[id] ||
+		defaultBYOBReusableWorkflows[id]
+}
+
+// trustedFor returns the custom builders that generate the provenance
+// of one of the builders, keyed by ID on github.com.
+func trustedFor(builders []customBuilder, defaultBuilders map[string]bool) []customBuilder {
+	var trusted []customBuilder
+	for _, b := range builders {
+		if defaultBuilders[b.builtinID] {
+			trusted = append(trusted, b)
+		}
+	}
+	return trusted
+}
+
+// findCustomBuilder returns the custom builder with the ID, or nil.
+func findCustomBuilder(builders []customBuilder, id string) *customBuilder {
+	for i := range builders {
+		if builders[i].id == id {
+			return &builders[i]
+		}
+	}
+	return nil
+}
+
+// delegator returns true if the workflow is a BYOB delegator workflow.
+func (b *customBuilder) delegator() bool {
+	return defaultBYOBReusableWorkflows[b.builtinID]
+}
+
+// workflowsPath returns the path of the workflows of the repository of the builder,
+// e.g. https://github.com/org/repo/.github/workflows/.
+func (b *customBuilder) workflowsPath() string {
+	i := strings.Index(b.id, "/.github/workflows/")
+	return b.id[:i+len("/.github/workflows/")]
+}
+
+// verifyRef verifies the ref the builder was referenced at.
+func (b *customBuilder) verifyRef(ref string) error {
+	if len(b.refPatterns) == 0 {
+		return utils.IsValidBuilderTag(ref, false)
+	}
+	ok, err := utils.MatchesAnyPattern(ref, b.refPatterns)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("%w: %q does not match any of %v", serrors.ErrorInvalidRef, ref, b.refPatterns)
+	}
+	return nil
+}
+
+// provenanceBuilderID returns the builder ID that identifies the format of
+// the provenance generated by the builder: the ID on github.com of the builder,
+// or of the built-in builder it is a copy of.
+func provenanceBuilderID(host *githubHost, builders []customBuilder, id string) string {
+	if b := findCustomBuilder(builders, id); b != nil {
+		return b.builtinID
+	}
+	return host.githubComBuilderID(id)
+}
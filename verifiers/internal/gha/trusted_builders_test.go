@@ -0,0 +1,223 @@
+package gha
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+
+	serrors "github.com/slsa-framework/slsa-verifier/v2/errors"
+	"github.com/slsa-framework/slsa-verifier/v2/options"
+	"github.com/slsa-framework/slsa-verifier/v2/verifiers/internal/gha/slsaprovenance/common"
+)
+
+func Test_newCustomBuilders(t *testing.T) {
+	t.Parallel()
+	ghes := &githubHost{name: "ghes.example.com"}
+	tests := []struct {
+		name     string
+		host     *githubHost
+		opts     []options.TrustedBuilder
+		expected []customBuilder
+		err      error
+	}{
+		{
+			name:     "empty",
+			host:     defaultGitHubHost,
+			expected: []customBuilder{},
+		},
+		{
+			name: "github.com",
+			host: defaultGitHubHost,
+			opts: []options.TrustedBuilder{
+				{
+					Workflow:    "org/builders/.github/workflows/generator_generic_slsa3.yml",
+					Builder:     common.GenericGeneratorBuilderID,
+					RefPatterns: []string{"refs/tags/v*"},
+				},
+			},
+			expected: []customBuilder{
+				{
+					id:          "https://github.com/org/builders/.github/workflows/generator_generic_slsa3.yml",
+					builtinID:   common.GenericGeneratorBuilderID,
+					refPatterns: []string{"refs/tags/v*"},
+				},
+			},
+		},
+		{
+			name: "ghes",
+			host: ghes,
+			opts: []options.TrustedBuilder{
+				{
+					Workflow: "org/builders/.github/workflows/delegator_generic_slsa3.yml",
+					Builder:  common.GenericDelegatorBuilderID,
+				},
+			},
+			expected: []customBuilder{
+				{
+					id:        "https://ghes.example.com/org/builders/.github/workflows/delegator_generic_slsa3.yml",
+					builtinID: common.GenericDelegatorBuilderID,
+				},
+			},
+		},
+		{
+			name: "url",
+			host: defaultGitHubHost,
+			opts: []options.TrustedBuilder{
+				{
+					Workflow: "https://github.com/org/builders/.github/workflows/generator_generic_slsa3.yml",
+					Builder:  common.GenericGeneratorBuilderID,
+				},
+			},
+			err: serrors.ErrorInvalidBuilderID,
+		},
+		{
+			name: "ref",
+			host: defaultGitHubHost,
+			opts: []options.TrustedBuilder{
+				{
+					Workflow: "org/builders/.github/workflows/generator_generic_slsa3.yml@refs/tags/v1.0.0",
+					Builder:  common.GenericGeneratorBuilderID,
+				},
+			},
+			err: serrors.ErrorInvalidBuilderID,
+		},
+		{
+			name: "not a workflow",
+			host: defaultGitHubHost,
+			opts: []options.TrustedBuilder{
+				{
+					Workflow: "org/builders",
+					Builder:  common.GenericGeneratorBuilderID,
+				},
+			},
+			err: serrors.ErrorInvalidBuilderID,
+		},
+		{
+			name: "unknown builder",
+			host: defaultGitHubHost,
+			opts: []options.TrustedBuilder{
+				{
+					Workflow: "org/builders/.github/workflows/generator_generic_slsa3.yml",
+					Builder:  "https://github.com/org/builders/.github/workflows/generator_generic_slsa3.yml",
+				},
+			},
+			err: serrors.ErrorInvalidBuilderID,
+		},
+		{
+			name: "invalid ref pattern",
+			host: defaultGitHubHost,
+			opts: []options.TrustedBuilder{
+				{
+					Workflow:    "org/builders/.github/workflows/generator_generic_slsa3.yml",
+					Builder:     common.GenericGeneratorBuilderID,
+					RefPatterns: []string{"refs/tags/[v"},
+				},
+			},
+			err: serrors.ErrorInvalidFormat,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			builders, err := newCustomBuilders(tt.host, tt.opts)
+			if diff := cmp.Diff(tt.err, err, cmpopts.EquateErrors()); diff != "" {
+				t.Fatalf("unexpected error (-want +got):\n%s", diff)
+			}
+			if err != nil {
+				return
+			}
+			if diff := cmp.Diff(tt.expected, builders, cmp.AllowUnexported(customBuilder{})); diff != "" {
+				t.Errorf("unexpected builders (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_customBuilder_verifyRef(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name        string
+		refPatterns []string
+		ref         string
+		err         error
+	}{
+		{
+			name: "semver tag",
+			ref:  "refs/tags/v1.2.3",
+		},
+		{
+			name: "not a semver tag",
+			ref:  "refs/tags/v1.2",
+			err:  serrors.ErrorInvalidRef,
+		},
+		{
+			name: "branch",
+			ref:  "refs/heads/main",
+			err:  serrors.ErrorInvalidRef,
+		},
+		{
+			name:        "matching pattern",
+			refPatterns: []string{"refs/heads/release/*"},
+			ref:         "refs/heads/release/v1",
+		},
+		{
+			name:        "no matching pattern",
+			refPatterns: []string{"refs/heads/release/*"},
+			ref:         "refs/heads/main",
+			err:         serrors.ErrorInvalidRef,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			b := &customBuilder{refPatterns: tt.refPatterns}
+			err := b.verifyRef(tt.ref)
+			if diff := cmp.Diff(tt.err, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("unexpected error (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_provenanceBuilderID(t *testing.T) {
+	t.Parallel()
+
+	ghes := &githubHost{name: "ghes.example.com"}
+	builders := []customBuilder{
+		{
+			id:        "https://ghes.example.com/org/builders/.github/workflows/delegator_generic_slsa3.yml",
+			builtinID: common.GenericDelegatorBuilderID,
+		},
+	}
+	tests := []struct {
+		name     string
+		id       string
+		expected string
+	}{
+		{
+			name:     "custom builder",
+			id:       "https://ghes.example.com/org/builders/.github/workflows/delegator_generic_slsa3.yml",
+			expected: common.GenericDelegatorBuilderID,
+		},
+		{
+			name:     "built-in builder",
+			id:       ghes.builderID(common.GoBuilderID),
+			expected: common.GoBuilderID,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := provenanceBuilderID(ghes, builders, tt.id); got != tt.expected {
+				t.Errorf("unexpected builder ID: got %q, want %q", got, tt.expected)
+			}
+		})
+	}
+	if got, want := builders[0].workflowsPath(), "https://ghes.example.com/org/builders/.github/workflows/"; got != want {
+		t.Errorf("unexpected workflows path: got %q, want %q", got, want)
+	}
+}
@@ -6,15 +6,16 @@ import (
 	"encoding/base64"
 	"errors"
 	"fmt"
-	"os"
 	"strings"
 
 	"github.com/google/go-containerregistry/pkg/name"
 	"github.com/secure-systems-lab/go-securesystemslib/dsse"
+	"github.com/sigstore/cosign/v2/pkg/cosign"
 
 	serrors "github.com/slsa-framework/slsa-verifier/v2/errors"
 	"github.com/slsa-framework/slsa-verifier/v2/options"
 	"github.com/slsa-framework/slsa-verifier/v2/register"
+	"github.com/slsa-framework/slsa-verifier/v2/report"
 	"github.com/slsa-framework/slsa-verifier/v2/verifiers/internal/gha/slsaprovenance/common"
 	"github.com/slsa-framework/slsa-verifier/v2/verifiers/utils"
 	"github.com/slsa-framework/slsa-verifier/v2/verifiers/utils/container"
@@ -42,7 +43,7 @@ func (v *GHAVerifier) IsAuthoritativeFor(builderID string) bool {
 	return strings.HasPrefix(builderID, httpsGithubCom)
 }
 
-func verifyEnvAndCert(env *dsse.Envelope,
+func verifyEnvAndCert(ctx context.Context, env *dsse.Envelope,
 	cert *x509.Certificate,
 	provenanceOpts *options.ProvenanceOpts,
 	builderOpts *options.BuilderOpts,
@@ -50,41 +51,53 @@ func verifyEnvAndCert(env *dsse.Envelope,
 ) ([]byte, *utils.TrustedBuilderID, error) {
 	/* Verify properties of the signing identity. */
 	// Get the workflow info given the certificate information.
-	workflowInfo, err := GetWorkflowInfoFromCertificate(cert)
+	rep := report.FromContext(ctx)
+	defer rep.Time(report.StageProvenance)()
+	host, err := newGitHubHost(provenanceOpts.GitHubOpts)
 	if err != nil {
 		return nil, nil, err
 	}
+	customBuilders, err := newCustomBuilders(host, provenanceOpts.TrustedBuilders)
+	if err != nil {
+		return nil, nil, err
+	}
+	workflowInfo, err := GetWorkflowInfoFromCertificate(cert)
+	if err != nil {
+		return nil, nil, rep.Check(report.CheckBuilderID, err)
+	}
+	recordSource(rep, host, workflowInfo)
 
 	// Verify the builder identity.
-	verifiedBuilderID, byob, err := VerifyBuilderIdentity(workflowInfo, builderOpts, defaultBuilders)
+	verifiedBuilderID, byob, err := VerifyBuilderIdentity(workflowInfo, host, builderOpts, defaultBuilders, customBuilders)
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, rep.Check(report.CheckBuilderID, err)
 	}
+	recordBuilderTrust(rep, customBuilders, verifiedBuilderID)
 
 	// Verify the source repository from the certificate.
-	if err := VerifyCertficateSourceRepository(workflowInfo, provenanceOpts.ExpectedSourceURI); err != nil {
-		return nil, nil, err
+	if err := VerifyCertficateSourceRepository(workflowInfo, host, provenanceOpts.ExpectedSourceURI); err != nil {
+		return nil, nil, rep.Check(report.CheckSourceURI, err)
 	}
 
 	// Verify properties of the SLSA provenance.
 	// Unpack and verify info in the provenance, including the subject Digest.
-	provenanceOpts.ExpectedBuilderID = verifiedBuilderID.String()
+	provenanceOpts = withExpectedBuilderID(provenanceOpts, verifiedBuilderID.String())
 	// There is a corner-case to handle: if the verified builder ID from the cert
 	// is a delegator builder, the user MUST provide an expected builder ID
 	// and we MUST match it against the content of the provenance.
-	if err := VerifyProvenance(env, provenanceOpts, verifiedBuilderID, byob, builderOpts.ExpectedID); err != nil {
+	if err := VerifyProvenance(ctx, env, provenanceOpts, verifiedBuilderID, byob, builderOpts.ExpectedID); err != nil {
 		return nil, nil, err
 	}
 
 	if byob {
 		// Overwrite the builderID to match the one in the provenance.
-		verifiedBuilderID, err = builderID(env, verifiedBuilderID)
+		verifiedBuilderID, err = builderID(env, provenanceBuilderID(host, customBuilders, verifiedBuilderID.Name()))
 		if err != nil {
 			return nil, nil, err
 		}
 	}
 
-	fmt.Fprintf(os.Stderr, "Verified build using builder %q at commit %s\n",
+	utils.Logf(ctx, "Verified build using builder %q at commit %s\n",
 		verifiedBuilderID.String(),
 		workflowInfo.SourceSha1)
 
@@ -97,7 +110,34 @@ func verifyEnvAndCert(env *dsse.Envelope,
 	return r, verifiedBuilderID, nil
 }
 
-func verifyNpmEnvAndCert(env *dsse.Envelope,
+// withExpectedBuilderID returns a copy of provenanceOpts that expects
+// builderID, so that the options of the caller are left unchanged.
+func withExpectedBuilderID(provenanceOpts *options.ProvenanceOpts, builderID string) *options.ProvenanceOpts {
+	opts := *provenanceOpts
+	opts.ExpectedBuilderID = builderID
+	return &opts
+}
+
+// recordBuilderTrust records in the report whether the builder in the
+// certificate is a built-in or a custom builder.
+func recordBuilderTrust(rep *report.Report, customBuilders []customBuilder, builderID *utils.TrustedBuilderID) {
+	if findCustomBuilder(customBuilders, builderID.Name()) != nil {
+		rep.SetBuilderTrust(report.BuilderTrustCustom)
+		return
+	}
+	rep.SetBuilderTrust(report.BuilderTrustBuiltIn)
+}
+
+// recordSource records the source information from the certificate in the report.
+func recordSource(rep *report.Report, host *githubHost, workflowInfo *WorkflowIdentity) {
+	var ref string
+	if workflowInfo.SourceRef != nil {
+		ref = *workflowInfo.SourceRef
+	}
+	rep.SetSource(host.url()+workflowInfo.SourceRepository, workflowInfo.SourceSha1, ref)
+}
+
+func verifyNpmEnvAndCert(ctx context.Context, env *dsse.Envelope,
 	cert *x509.Certificate,
 	provenanceOpts *options.ProvenanceOpts,
 	builderOpts *options.BuilderOpts,
@@ -105,29 +145,46 @@ func verifyNpmEnvAndCert(env *dsse.Envelope,
 ) (*utils.TrustedBuilderID, error) {
 	/* Verify properties of the signing identity. */
 	// Get the workflow info given the certificate information.
-	workflowInfo, err := GetWorkflowInfoFromCertificate(cert)
+	rep := report.FromContext(ctx)
+	host, err := newGitHubHost(provenanceOpts.GitHubOpts)
 	if err != nil {
 		return nil, err
 	}
+	customBuilders, err := newCustomBuilders(host, provenanceOpts.TrustedBuilders)
+	if err != nil {
+		return nil, err
+	}
+	workflowInfo, err := GetWorkflowInfoFromCertificate(cert)
+	if err != nil {
+		return nil, rep.Check(report.CheckBuilderID, err)
+	}
+	recordSource(rep, host, workflowInfo)
+
+	// The provenance must then match the source repository of the certificate.
+	if provenanceOpts.AnySourceURI {
+		opts := *provenanceOpts
+		opts.ExpectedSourceURI = host.name + "/" + workflowInfo.SourceRepository
+		provenanceOpts = &opts
+	}
 
 	// Verify the workflow identity.
 	// We verify against the delegator re-usable workflow, not the user-provided
 	// builder. This is because the signing identity for delegator-based builders
 	// is *always* the delegator workflow.
-	expectedDelegatorWorkflow := httpsGithubCom + common.GenericLowPermsDelegatorBuilderID
+	expectedDelegatorWorkflow := host.url() + common.GenericLowPermsDelegatorBuilderID
 	delegatorBuilderOpts := options.BuilderOpts{
 		ExpectedID: &expectedDelegatorWorkflow,
 	}
-	trustedBuilderID, byob, err := VerifyBuilderIdentity(workflowInfo, &delegatorBuilderOpts, defaultBuilders)
+	trustedBuilderID, byob, err := VerifyBuilderIdentity(workflowInfo, host, &delegatorBuilderOpts, defaultBuilders, customBuilders)
 	// We accept a non-trusted builder for the default npm builder
 	// that uses npm CLI.
 	if err != nil && !errors.Is(err, serrors.ErrorUntrustedReusableWorkflow) {
-		return nil, err
+		return nil, rep.Check(report.CheckBuilderID, err)
 	}
 
 	// Verify the source repository from the certificate.
-	if err := VerifyCertficateSourceRepository(workflowInfo, provenanceOpts.ExpectedSourceURI); err != nil {
-		return nil, err
+	if err := VerifyCertficateSourceRepository(workflowInfo, host, provenanceOpts.ExpectedSourceURI); err != nil {
+		return nil, rep.Check(report.CheckSourceURI, err)
 	}
 
 	// Users must always provide the builder ID.
@@ -146,7 +203,7 @@ func verifyNpmEnvAndCert(env *dsse.Envelope,
 		if !byob {
 			return nil, fmt.Errorf("%w: byob is false", serrors.ErrorInternal)
 		}
-		provenanceOpts.ExpectedBuilderID = *builderOpts.ExpectedID
+		provenanceOpts = withExpectedBuilderID(provenanceOpts, *builderOpts.ExpectedID)
 
 		if workflowInfo.SubjectHosted != nil && *workflowInfo.SubjectHosted != HostedGitHub {
 			return nil, fmt.Errorf("%w: self hosted re-usable workflow", serrors.ErrorMismatchBuilderID)
@@ -190,16 +247,16 @@ func verifyNpmEnvAndCert(env *dsse.Envelope,
 		}
 
 		// On GitHub we only support the default GitHub runner builder.
-		provenanceOpts.ExpectedBuilderID = *builderOpts.ExpectedID
+		provenanceOpts = withExpectedBuilderID(provenanceOpts, *builderOpts.ExpectedID)
 	}
 
 	// Verify properties of the SLSA provenance.
 	// Unpack and verify info in the provenance, including the Subject Digest.
-	if err := VerifyNpmPackageProvenance(env, workflowInfo, provenanceOpts, trustedBuilderID, isTrustedBuilder); err != nil {
+	if err := VerifyNpmPackageProvenance(ctx, env, workflowInfo, provenanceOpts, trustedBuilderID, isTrustedBuilder); err != nil {
 		return nil, err
 	}
 
-	fmt.Fprintf(os.Stderr, "Verified build using builder %s at commit %s\n",
+	utils.Logf(ctx, "Verified build using builder %s at commit %s\n",
 		trustedBuilderID.String(),
 		workflowInfo.SourceSha1)
 
@@ -212,32 +269,58 @@ func (v *GHAVerifier) VerifyArtifact(ctx context.Context,
 	provenanceOpts *options.ProvenanceOpts,
 	builderOpts *options.BuilderOpts,
 ) ([]byte, *utils.TrustedBuilderID, error) {
+	// Files downloaded with `gh attestation download` contain a bundle per line.
+	if bundles := splitSigstoreBundles(provenance); len(bundles) > 1 {
+		return utils.VerifyEach(ctx, bundles, func(ctx context.Context, bundle []byte) ([]byte, *utils.TrustedBuilderID, error) {
+			return v.VerifyArtifact(ctx, bundle, artifactHash, provenanceOpts, builderOpts)
+		})
+	}
+
 	isSigstoreBundle := IsSigstoreBundle(provenance)
+	sigstoreOpts := provenanceOpts.SigstoreOpts
+	rep := report.FromContext(ctx)
 
-	// This includes a default retry count of 3.
-	rClient, err := getDefaultRekorClient()
+	// Without a bundle, the transparency log entry must be searched on Rekor.
+	if !isSigstoreBundle && sigstoreOpts != nil && sigstoreOpts.Offline {
+		return nil, nil, rep.Check(report.CheckSignature,
+			fmt.Errorf("%w: provenance is not a Sigstore bundle: its transparency log entry must be fetched from Rekor",
+				serrors.ErrorRequiresNetwork))
+	}
+
+	host, err := newGitHubHost(provenanceOpts.GitHubOpts)
 	if err != nil {
 		return nil, nil, err
 	}
-
-	trustedRoot, err := utils.GetSigstoreTrustedRoot()
+	trustedRoot, err := utils.GetTrustedMaterial(sigstoreOpts)
 	if err != nil {
 		return nil, nil, err
 	}
 
 	var signedAtt *SignedAttestation
 	/* Verify signature on the intoto attestation. */
+	stop := rep.Time(report.StageSignature)
 	if isSigstoreBundle {
-		signedAtt, err = VerifyProvenanceBundle(ctx, provenance, trustedRoot)
+		signedAtt, err = VerifyProvenanceBundle(ctx, provenance, trustedRoot, host)
 	} else {
-		signedAtt, err = VerifyProvenanceSignature(ctx, trustedRoot, rClient,
+		// This includes a default retry count of 3.
+		rClient, rErr := getRekorClient(sigstoreOpts)
+		if rErr != nil {
+			return nil, nil, rErr
+		}
+		signedAtt, err = VerifyProvenanceSignature(ctx, trustedRoot, host, rClient,
 			provenance, artifactHash)
 	}
-	if err != nil {
+	stop()
+	if err := rep.Check(report.CheckSignature, err); err != nil {
 		return nil, nil, err
 	}
+	signedAtt.record(rep)
 
-	return verifyEnvAndCert(signedAtt.Envelope, signedAtt.SigningCert,
+	if isArtifactAttestation(signedAtt.Envelope) {
+		return verifyAttestationEnvAndCert(ctx, signedAtt.Envelope, signedAtt.SigningCert,
+			provenanceOpts, builderOpts)
+	}
+	return verifyEnvAndCert(ctx, signedAtt.Envelope, signedAtt.SigningCert,
 		provenanceOpts, builderOpts,
 		utils.MergeMaps(defaultArtifactTrustedReusableWorkflows, defaultBYOBReusableWorkflows))
 }
@@ -247,6 +330,14 @@ func (v *GHAVerifier) VerifyImage(ctx context.Context,
 	provenance []byte, artifactImage string, provenanceOpts *options.ProvenanceOpts,
 	builderOpts *options.BuilderOpts,
 ) ([]byte, *utils.TrustedBuilderID, error) {
+	// Provenance downloaded from the registry, e.g. GitHub artifact attestations.
+	if provenance != nil {
+		return utils.VerifyEach(ctx, splitSigstoreBundles(provenance),
+			func(ctx context.Context, bundle []byte) ([]byte, *utils.TrustedBuilderID, error) {
+				return verifyImageBundle(ctx, bundle, provenanceOpts, builderOpts)
+			})
+	}
+
 	var provenanceTargetRepository name.Repository
 	var err error
 	// Consume input for --provenance-repository when set
@@ -266,56 +357,110 @@ func (v *GHAVerifier) VerifyImage(ctx context.Context,
 	}
 
 	/* Retrieve any valid signed attestations that chain up to Fulcio root CA. */
-	opts, err := getDefaultCosignCheckOpts(ctx)
+	opts, err := GetCosignCheckOpts(ctx, provenanceOpts.SigstoreOpts)
 	if err != nil {
 		return nil, nil, err
 	}
 	opts.RegistryClientOpts = registryClientOpts
 
+	rep := report.FromContext(ctx)
+	stop := rep.Time(report.StageSignature)
 	atts, _, err := container.RunCosignImageVerification(ctx,
 		artifactImage, opts)
-	if err != nil {
+	stop()
+	var noAtts *cosign.ErrNoMatchingAttestations
+	if errors.As(err, &noAtts) {
+		// Registries may store the Sigstore bundles of the image as OCI referrers.
+		bundles, bErr := container.FetchSigstoreBundles(ctx, artifactImage, provenanceTargetRepository.Name())
+		if bErr == nil && len(bundles) > 0 {
+			return utils.VerifyEach(ctx, bundles,
+				func(ctx context.Context, bundle []byte) ([]byte, *utils.TrustedBuilderID, error) {
+					return verifyImageBundle(ctx, bundle, provenanceOpts, builderOpts)
+				})
+		}
+		err = withFetchError(err, bErr)
+		// Images built by BuildKit carry their provenance in the image index.
+		buildKitAtts, bErr := container.FetchBuildKitAttestations(ctx, artifactImage, buildKitPredicateTypes)
+		if bErr == nil && len(buildKitAtts) > 0 {
+			return verifyBuildKitAttestations(ctx, buildKitAtts, provenanceOpts, builderOpts)
+		}
+		err = withFetchError(err, bErr)
+	}
+	if err := rep.Check(report.CheckSignature, err); err != nil {
 		return nil, nil, err
 	}
 
 	/* Now verify properties of the attestations */
-	var errs []error
-	var builderID *utils.TrustedBuilderID
-	var verifiedProvenance []byte
-	for _, att := range atts {
-		pyld, err := att.Payload()
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "unexpected error getting payload from OCI registry %s", err)
-			continue
-		}
-		env, err := EnvelopeFromBytes(pyld)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "unexpected error parsing envelope from OCI registry %s", err)
-			continue
-		}
-		cert, err := att.Cert()
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "unexpected error getting certificate from OCI registry %s", err)
-			continue
-		}
-		verifiedProvenance, builderID, err = verifyEnvAndCert(env,
-			cert, provenanceOpts, builderOpts,
-			defaultContainerTrustedReusableWorkflows)
-		if err == nil {
+	return utils.VerifyEach(ctx, container.CosignAttestations(ctx, atts),
+		func(ctx context.Context, att container.CosignAttestation) ([]byte, *utils.TrustedBuilderID, error) {
+			verifiedProvenance, builderID, err := verifyImageEnvAndCert(ctx, att.Envelope, att.Cert,
+				provenanceOpts, builderOpts)
+			if err != nil {
+				return nil, nil, err
+			}
+			rep := report.FromContext(ctx)
+			rep.SetSigner(utils.CertificateSigner(att.Cert))
+			if att.RekorEntry != nil {
+				rep.SetRekorEntry(*att.RekorEntry)
+			}
 			return verifiedProvenance, builderID, nil
-		}
-		errs = append(errs, err)
+		})
+}
+
+// withFetchError returns err, wrapping fetchErr too if the attestations
+// could not be fetched for another reason than their absence.
+func withFetchError(err, fetchErr error) error {
+	if fetchErr == nil || container.IsNotFound(fetchErr) {
+		return err
 	}
+	return fmt.Errorf("%w: %w", err, fetchErr)
+}
 
-	// Return the first error.
-	if len(errs) > 0 {
-		var s string
-		if len(errs) > 1 {
-			s = fmt.Sprintf(": %v", errs[1:])
-		}
-		return nil, nil, fmt.Errorf("%w%s", errs[0], s)
+// verifyImageBundle verifies the provenance of an image in a Sigstore bundle.
+func verifyImageBundle(ctx context.Context, bundle []byte,
+	provenanceOpts *options.ProvenanceOpts,
+	builderOpts *options.BuilderOpts,
+) ([]byte, *utils.TrustedBuilderID, error) {
+	rep := report.FromContext(ctx)
+	if !IsSigstoreBundle(bundle) {
+		return nil, nil, rep.Check(report.CheckSignature,
+			fmt.Errorf("%w: image provenance must be a Sigstore bundle", serrors.ErrorInvalidFormat))
 	}
-	return nil, nil, fmt.Errorf("%w", serrors.ErrorNoValidSignature)
+	host, err := newGitHubHost(provenanceOpts.GitHubOpts)
+	if err != nil {
+		return nil, nil, err
+	}
+	trustedRoot, err := utils.GetTrustedMaterial(provenanceOpts.SigstoreOpts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	stop := rep.Time(report.StageSignature)
+	signedAtt, err := VerifyProvenanceBundle(ctx, bundle, trustedRoot, host)
+	stop()
+	if err := rep.Check(report.CheckSignature, err); err != nil {
+		return nil, nil, err
+	}
+	signedAtt.record(rep)
+	return verifyImageEnvAndCert(ctx, signedAtt.Envelope, signedAtt.SigningCert,
+		provenanceOpts, builderOpts)
+}
+
+// verifyImageEnvAndCert verifies the provenance of an image whose signature
+// has been verified, by the kind of builder that generated it.
+func verifyImageEnvAndCert(ctx context.Context, env *dsse.Envelope,
+	cert *x509.Certificate,
+	provenanceOpts *options.ProvenanceOpts,
+	builderOpts *options.BuilderOpts,
+) ([]byte, *utils.TrustedBuilderID, error) {
+	if isArtifactAttestation(env) {
+		return verifyAttestationEnvAndCert(ctx, env, cert, provenanceOpts, builderOpts)
+	}
+	if isBuildKitProvenance(env) {
+		return verifyBuildKitEnvAndCert(ctx, env, cert, provenanceOpts, builderOpts)
+	}
+	return verifyEnvAndCert(ctx, env, cert, provenanceOpts, builderOpts,
+		defaultContainerTrustedReusableWorkflows)
 }
 
 // VerifyNpmPackage verifies an npm package tarball.
@@ -324,7 +469,11 @@ func (v *GHAVerifier) VerifyNpmPackage(ctx context.Context,
 	provenanceOpts *options.ProvenanceOpts,
 	builderOpts *options.BuilderOpts,
 ) ([]byte, *utils.TrustedBuilderID, error) {
-	trustedRoot, err := utils.GetSigstoreTrustedRoot()
+	host, err := newGitHubHost(provenanceOpts.GitHubOpts)
+	if err != nil {
+		return nil, nil, err
+	}
+	trustedRoot, err := utils.GetTrustedMaterial(provenanceOpts.SigstoreOpts)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -333,11 +482,17 @@ func (v *GHAVerifier) VerifyNpmPackage(ctx context.Context,
 	if err != nil {
 		return nil, nil, err
 	}
+	npm.host = host
 
 	// Verify provenance signature.
-	if err := npm.verifyProvenanceAttestationSignature(); err != nil {
+	rep := report.FromContext(ctx)
+	stop := rep.Time(report.StageSignature)
+	err = npm.verifyProvenanceAttestationSignature()
+	stop()
+	if err := rep.Check(report.CheckSignature, err); err != nil {
 		return nil, nil, err
 	}
+	defer rep.Time(report.StageProvenance)()
 
 	// Verify provenance builder information.
 	builder, err := npm.verifyBuilderID(
@@ -348,27 +503,29 @@ func (v *GHAVerifier) VerifyNpmPackage(ctx context.Context,
 	}
 
 	// Verify publish attesttation signature.
-	if err := npm.verifyPublishAttestationSignature(); err != nil {
+	if err := rep.Check(report.CheckSignature, npm.verifyPublishAttestationSignature()); err != nil {
 		return nil, nil, err
 	}
 
 	// Verify publish subject digest.
-	if err := npm.verifyPublishAttestationSubjectDigest(provenanceOpts.ExpectedDigest); err != nil {
+	if err := rep.Check(report.CheckSubjectDigest,
+		npm.verifyPublishAttestationSubjectDigest(provenanceOpts.ExpectedDigest)); err != nil {
 		return nil, nil, err
 	}
 
 	// Verify attestation headers.
-	if err := npm.verifyIntotoHeaders(); err != nil {
+	if err := rep.Check(report.CheckAttestationHeaders, npm.verifyIntotoHeaders()); err != nil {
 		return nil, nil, err
 	}
 
 	// Verify package names match.
 	if provenanceOpts != nil {
-		if err := npm.verifyPackageName(provenanceOpts.ExpectedPackageName); err != nil {
+		if err := rep.Check(report.CheckPackageName, npm.verifyPackageName(provenanceOpts.ExpectedPackageName)); err != nil {
 			return nil, nil, err
 		}
 
-		if err := npm.verifyPackageVersion(provenanceOpts.ExpectedPackageVersion); err != nil {
+		if err := rep.Check(report.CheckPackageVersion,
+			npm.verifyPackageVersion(provenanceOpts.ExpectedPackageVersion)); err != nil {
 			return nil, nil, err
 		}
 	}
@@ -0,0 +1,80 @@
+package gitlab
+
+import (
+	"crypto/x509"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+
+	dsselib "github.com/secure-systems-lab/go-securesystemslib/dsse"
+	sigstoreBundle "github.com/sigstore/sigstore-go/pkg/bundle"
+	sigstoreRoot "github.com/sigstore/sigstore-go/pkg/root"
+	sigstoreVerify "github.com/sigstore/sigstore-go/pkg/verify"
+
+	serrors "github.com/slsa-framework/slsa-verifier/v2/errors"
+	"github.com/slsa-framework/slsa-verifier/v2/report"
+	"github.com/slsa-framework/slsa-verifier/v2/verifiers/utils"
+)
+
+// signedProvenance is the provenance in a verified Sigstore bundle.
+type signedProvenance struct {
+	Envelope    *dsselib.Envelope
+	SigningCert *x509.Certificate
+	RekorEntry  *report.RekorEntry
+}
+
+// verifySignedBundle verifies the Sigstore bundle of a provenance signed by
+// a job of the GitLab instance at serverURL: the certificate chains up to
+// Fulcio, the signature is in the transparency log, and the certificate was
+// issued to a job.
+func verifySignedBundle(bundleBytes []byte, trustedMaterial sigstoreRoot.TrustedMaterial, serverURL string,
+) (*signedProvenance, error) {
+	var bundle sigstoreBundle.Bundle
+	if err := bundle.UnmarshalJSON(bundleBytes); err != nil {
+		return nil, fmt.Errorf("%w: %w", serrors.ErrorInvalidFormat, err)
+	}
+
+	verifier, err := sigstoreVerify.NewSignedEntityVerifier(trustedMaterial,
+		sigstoreVerify.WithSignedCertificateTimestamps(1),
+		sigstoreVerify.WithTransparencyLog(1),
+		sigstoreVerify.WithIntegratedTimestamps(1))
+	if err != nil {
+		return nil, err
+	}
+	certID, err := sigstoreVerify.NewShortCertificateIdentity(serverURL, "", "",
+		"^"+regexp.QuoteMeta(serverURL+"/"))
+	if err != nil {
+		return nil, err
+	}
+	// The subjects are verified against the expected digest with the
+	// other fields of the provenance.
+	if _, err := verifier.Verify(&bundle, sigstoreVerify.NewPolicy(sigstoreVerify.WithoutArtifactUnsafe(),
+		sigstoreVerify.WithCertificateIdentity(certID))); err != nil {
+		return nil, fmt.Errorf("%w: %w", serrors.ErrorNoValidSignature, err)
+	}
+
+	env, err := bundle.Envelope()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", serrors.ErrorInvalidFormat, err)
+	}
+	verificationContent, err := bundle.VerificationContent()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", serrors.ErrorInvalidFormat, err)
+	}
+	cert := verificationContent.GetCertificate()
+	if cert == nil {
+		return nil, fmt.Errorf("%w: bundle has no certificate", serrors.ErrorInvalidFormat)
+	}
+	signed := &signedProvenance{
+		Envelope:    env.Envelope,
+		SigningCert: cert,
+	}
+	if entries, err := bundle.TlogEntries(); err == nil && len(entries) > 0 {
+		e := entries[0]
+		// The log ID of the entries of a bundle is the raw key ID.
+		entry := utils.NewRekorEntry(e.LogIndex(), e.Body(), e.IntegratedTime().Unix(),
+			hex.EncodeToString([]byte(e.LogKeyID())))
+		signed.RekorEntry = &entry
+	}
+	return signed, nil
+}
@@ -0,0 +1,135 @@
+package gitlab
+
+import (
+	"crypto/x509"
+	"fmt"
+	"strconv"
+	"strings"
+
+	fulcio "github.com/sigstore/fulcio/pkg/certificate"
+
+	serrors "github.com/slsa-framework/slsa-verifier/v2/errors"
+	"github.com/slsa-framework/slsa-verifier/v2/options"
+)
+
+const (
+	httpsGitLabCom = "https://gitlab.com/"
+	// gitlabComURL is the URL of gitlab.com, which is also the OIDC issuer
+	// of its jobs.
+	gitlabComURL = "https://gitlab.com"
+
+	runnerGitLabHosted = "gitlab-hosted"
+	runnerSelfHosted   = "self-hosted"
+)
+
+// PipelineIdentity is the identity of the GitLab CI job that signed
+// the provenance, captured from the extensions of its Fulcio certificate.
+// Fulcio identifies the pipeline by the job rather than by the pipeline
+// ID, which the certificate does not contain.
+// See https://github.com/sigstore/fulcio/blob/main/docs/oid-info.md.
+type PipelineIdentity struct {
+	// ServerURL is the URL of the GitLab instance, e.g. https://gitlab.com.
+	ServerURL string
+	// Issuer is the OIDC issuer of the job.
+	Issuer string
+	// ProjectPath is the path of the project, e.g. group/project.
+	ProjectPath string
+	// ProjectID is the ID of the project.
+	ProjectID string
+	// NamespaceID is the ID of the group or user that owns the project.
+	NamespaceID string
+	// SourceSha1 is the commit the pipeline ran for.
+	SourceSha1 string
+	// SourceRef is the ref the pipeline ran for, e.g. refs/heads/main.
+	SourceRef string
+	// ConfigURI is the pipeline configuration at its ref,
+	// e.g. https://gitlab.com/group/project//.gitlab-ci.yml@refs/heads/main.
+	ConfigURI string
+	// ConfigSha1 is the commit of the pipeline configuration.
+	ConfigSha1 string
+	// PipelineSource is the event that triggered the pipeline, e.g. push.
+	PipelineSource string
+	// JobID is the ID of the job of the pipeline, from the URL of the job.
+	JobID string
+	// RunnerEnvironment is gitlab-hosted or self-hosted.
+	RunnerEnvironment string
+}
+
+// ProjectURI returns the URI of the project.
+func (id *PipelineIdentity) ProjectURI() string {
+	return id.ServerURL + "/" + id.ProjectPath
+}
+
+// serverURL returns the URL of the GitLab instance of the options.
+func serverURL(gitlabOpts *options.GitLabOpts) string {
+	if gitlabOpts == nil || gitlabOpts.Host == "" {
+		return gitlabComURL
+	}
+	return "https://" + gitlabOpts.Host
+}
+
+// GetPipelineIdentityFromCertificate returns the identity of the job
+// the certificate was issued to, by gitlab.com or by the self-managed
+// instance of gitlabOpts.
+func GetPipelineIdentityFromCertificate(cert *x509.Certificate, gitlabOpts *options.GitLabOpts,
+) (*PipelineIdentity, error) {
+	if len(cert.URIs) == 0 {
+		return nil, fmt.Errorf("%w: missing URI information from certificate", serrors.ErrorInvalidFormat)
+	}
+	ext, err := fulcio.ParseExtensions(cert.Extensions)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", serrors.ErrorInvalidFormat, err)
+	}
+	// The issuer of the jobs of an instance is its URL.
+	url := serverURL(gitlabOpts)
+	if ext.Issuer != url {
+		return nil, serrors.NewVerificationError(fmt.Errorf("%w: expected issuer %q, got %q",
+			serrors.ErrorInvalidCertificate, url, ext.Issuer), url, ext.Issuer)
+	}
+
+	// 1.3.6.1.4.1.57264.1.12 | Source Repository URI
+	projectPath := strings.TrimPrefix(ext.SourceRepositoryURI, url+"/")
+	if projectPath == ext.SourceRepositoryURI || projectPath == "" {
+		return nil, fmt.Errorf("%w: source repository uri: %q", serrors.ErrorInvalidFormat, ext.SourceRepositoryURI)
+	}
+
+	// 1.3.6.1.4.1.57264.1.21 | Run Invocation URI, the URL of the job.
+	// Job IDs are positive integers.
+	jobsPrefix := url + "/" + projectPath + "/-/jobs/"
+	jobID := strings.TrimPrefix(ext.RunInvocationURI, jobsPrefix)
+	if id, err := strconv.ParseUint(jobID, 10, 64); jobID == ext.RunInvocationURI || err != nil || id == 0 {
+		return nil, fmt.Errorf("%w: run invocation uri: %q", serrors.ErrorInvalidFormat, ext.RunInvocationURI)
+	}
+
+	// 1.3.6.1.4.1.57264.1.18 | Build Config URI, which is also the subject.
+	if ext.BuildConfigURI != cert.URIs[0].String() {
+		return nil, fmt.Errorf("%w: build config uri %q does not match subject %q", serrors.ErrorInvalidFormat,
+			ext.BuildConfigURI, cert.URIs[0].String())
+	}
+
+	// 1.3.6.1.4.1.57264.1.11 | Runner Environment
+	if ext.RunnerEnvironment != runnerGitLabHosted && ext.RunnerEnvironment != runnerSelfHosted {
+		return nil, fmt.Errorf("%w: runner environment: %q", serrors.ErrorInvalidFormat, ext.RunnerEnvironment)
+	}
+
+	// 1.3.6.1.4.1.57264.1.13 | Source Repository Digest
+	// 1.3.6.1.4.1.57264.1.14 | Source Repository Ref
+	if ext.SourceRepositoryDigest == "" || ext.SourceRepositoryRef == "" {
+		return nil, fmt.Errorf("%w: missing source repository digest or ref", serrors.ErrorInvalidFormat)
+	}
+
+	return &PipelineIdentity{
+		ServerURL:         url,
+		Issuer:            ext.Issuer,
+		ProjectPath:       projectPath,
+		ProjectID:         ext.SourceRepositoryIdentifier,
+		NamespaceID:       ext.SourceRepositoryOwnerIdentifier,
+		SourceSha1:        ext.SourceRepositoryDigest,
+		SourceRef:         ext.SourceRepositoryRef,
+		ConfigURI:         ext.BuildConfigURI,
+		ConfigSha1:        ext.BuildConfigDigest,
+		PipelineSource:    ext.BuildTrigger,
+		JobID:             jobID,
+		RunnerEnvironment: ext.RunnerEnvironment,
+	}, nil
+}
@@ -0,0 +1,212 @@
+package gitlab
+
+import (
+	"crypto/x509"
+	"net/url"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	fulcio "github.com/sigstore/fulcio/pkg/certificate"
+
+	serrors "github.com/slsa-framework/slsa-verifier/v2/errors"
+	"github.com/slsa-framework/slsa-verifier/v2/options"
+)
+
+const testConfigURI = "https://gitlab.com/group/project//.gitlab-ci.yml@refs/tags/v1.2.3"
+
+// newTestExtensions returns the extensions of the certificate that Fulcio
+// issues to a job of gitlab.com.
+func newTestExtensions() fulcio.Extensions {
+	return fulcio.Extensions{
+		Issuer:                          "https://gitlab.com",
+		BuildConfigURI:                  testConfigURI,
+		BuildConfigDigest:               "4a9b2c7d1e8f3a6b5c0d9e2f7a1b4c8d3e6f0a5b",
+		BuildSignerURI:                  testConfigURI,
+		BuildSignerDigest:               "4a9b2c7d1e8f3a6b5c0d9e2f7a1b4c8d3e6f0a5b",
+		RunnerEnvironment:               "gitlab-hosted",
+		SourceRepositoryURI:             "https://gitlab.com/group/project",
+		SourceRepositoryDigest:          "4a9b2c7d1e8f3a6b5c0d9e2f7a1b4c8d3e6f0a5b",
+		SourceRepositoryRef:             "refs/tags/v1.2.3",
+		SourceRepositoryIdentifier:      "123",
+		SourceRepositoryOwnerURI:        "https://gitlab.com/group",
+		SourceRepositoryOwnerIdentifier: "456",
+		BuildTrigger:                    "push",
+		RunInvocationURI:                "https://gitlab.com/group/project/-/jobs/8123456789",
+	}
+}
+
+func newTestCertificate(t *testing.T, ext fulcio.Extensions) *x509.Certificate {
+	t.Helper()
+	extensions, err := ext.Render()
+	if err != nil {
+		t.Fatal(err)
+	}
+	subject, err := url.Parse(testConfigURI)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &x509.Certificate{
+		URIs:       []*url.URL{subject},
+		Extensions: extensions,
+	}
+}
+
+func Test_GetPipelineIdentityFromCertificate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		mutate     func(*fulcio.Extensions)
+		gitlabOpts *options.GitLabOpts
+		expected   *PipelineIdentity
+		err        error
+	}{
+		{
+			name: "job of gitlab.com",
+			expected: &PipelineIdentity{
+				ServerURL:         "https://gitlab.com",
+				Issuer:            "https://gitlab.com",
+				ProjectPath:       "group/project",
+				ProjectID:         "123",
+				NamespaceID:       "456",
+				SourceSha1:        "4a9b2c7d1e8f3a6b5c0d9e2f7a1b4c8d3e6f0a5b",
+				SourceRef:         "refs/tags/v1.2.3",
+				ConfigURI:         testConfigURI,
+				ConfigSha1:        "4a9b2c7d1e8f3a6b5c0d9e2f7a1b4c8d3e6f0a5b",
+				PipelineSource:    "push",
+				JobID:             "8123456789",
+				RunnerEnvironment: "gitlab-hosted",
+			},
+		},
+		{
+			name: "self-hosted runner",
+			mutate: func(e *fulcio.Extensions) {
+				e.RunnerEnvironment = "self-hosted"
+			},
+			expected: &PipelineIdentity{
+				ServerURL:         "https://gitlab.com",
+				Issuer:            "https://gitlab.com",
+				ProjectPath:       "group/project",
+				ProjectID:         "123",
+				NamespaceID:       "456",
+				SourceSha1:        "4a9b2c7d1e8f3a6b5c0d9e2f7a1b4c8d3e6f0a5b",
+				SourceRef:         "refs/tags/v1.2.3",
+				ConfigURI:         testConfigURI,
+				ConfigSha1:        "4a9b2c7d1e8f3a6b5c0d9e2f7a1b4c8d3e6f0a5b",
+				PipelineSource:    "push",
+				JobID:             "8123456789",
+				RunnerEnvironment: "self-hosted",
+			},
+		},
+		{
+			name: "job of a self-managed instance",
+			mutate: func(e *fulcio.Extensions) {
+				e.Issuer = "https://gitlab.example.com"
+				e.SourceRepositoryURI = "https://gitlab.example.com/group/project"
+				e.RunInvocationURI = "https://gitlab.example.com/group/project/-/jobs/8123456789"
+				e.RunnerEnvironment = "self-hosted"
+			},
+			gitlabOpts: &options.GitLabOpts{Host: "gitlab.example.com"},
+			expected: &PipelineIdentity{
+				ServerURL:         "https://gitlab.example.com",
+				Issuer:            "https://gitlab.example.com",
+				ProjectPath:       "group/project",
+				ProjectID:         "123",
+				NamespaceID:       "456",
+				SourceSha1:        "4a9b2c7d1e8f3a6b5c0d9e2f7a1b4c8d3e6f0a5b",
+				SourceRef:         "refs/tags/v1.2.3",
+				ConfigURI:         testConfigURI,
+				ConfigSha1:        "4a9b2c7d1e8f3a6b5c0d9e2f7a1b4c8d3e6f0a5b",
+				PipelineSource:    "push",
+				JobID:             "8123456789",
+				RunnerEnvironment: "self-hosted",
+			},
+		},
+		{
+			name:       "job of gitlab.com for a self-managed instance",
+			gitlabOpts: &options.GitLabOpts{Host: "gitlab.example.com"},
+			err:        serrors.ErrorInvalidCertificate,
+		},
+		{
+			name: "GitHub Actions issuer",
+			mutate: func(e *fulcio.Extensions) {
+				e.Issuer = "https://token.actions.githubusercontent.com"
+			},
+			err: serrors.ErrorInvalidCertificate,
+		},
+		{
+			name: "project of another instance",
+			mutate: func(e *fulcio.Extensions) {
+				e.SourceRepositoryURI = "https://gitlab.example.com/group/project"
+			},
+			err: serrors.ErrorInvalidFormat,
+		},
+		{
+			name: "job of another project",
+			mutate: func(e *fulcio.Extensions) {
+				e.RunInvocationURI = "https://gitlab.com/group/other/-/jobs/8123456789"
+			},
+			err: serrors.ErrorInvalidFormat,
+		},
+		{
+			name: "pipeline instead of job",
+			mutate: func(e *fulcio.Extensions) {
+				e.RunInvocationURI = "https://gitlab.com/group/project/-/pipelines/1234"
+			},
+			err: serrors.ErrorInvalidFormat,
+		},
+		{
+			name: "job ID is not a number",
+			mutate: func(e *fulcio.Extensions) {
+				e.RunInvocationURI = "https://gitlab.com/group/project/-/jobs/latest"
+			},
+			err: serrors.ErrorInvalidFormat,
+		},
+		{
+			name: "job ID zero",
+			mutate: func(e *fulcio.Extensions) {
+				e.RunInvocationURI = "https://gitlab.com/group/project/-/jobs/0"
+			},
+			err: serrors.ErrorInvalidFormat,
+		},
+		{
+			name: "build config does not match subject",
+			mutate: func(e *fulcio.Extensions) {
+				e.BuildConfigURI = "https://gitlab.com/group/project//other.yml@refs/tags/v1.2.3"
+			},
+			err: serrors.ErrorInvalidFormat,
+		},
+		{
+			name: "unknown runner environment",
+			mutate: func(e *fulcio.Extensions) {
+				e.RunnerEnvironment = "github-hosted"
+			},
+			err: serrors.ErrorInvalidFormat,
+		},
+		{
+			name: "no ref",
+			mutate: func(e *fulcio.Extensions) {
+				e.SourceRepositoryRef = ""
+			},
+			err: serrors.ErrorInvalidFormat,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ext := newTestExtensions()
+			if tt.mutate != nil {
+				tt.mutate(&ext)
+			}
+			identity, err := GetPipelineIdentityFromCertificate(newTestCertificate(t, ext), tt.gitlabOpts)
+			if diff := cmp.Diff(tt.err, err, cmpopts.EquateErrors()); diff != "" {
+				t.Fatalf("unexpected error (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tt.expected, identity); diff != "" {
+				t.Errorf("unexpected identity (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,187 @@
+package gitlab
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	intoto "github.com/in-toto/in-toto-golang/in_toto"
+	intotov1 "github.com/in-toto/in-toto-golang/in_toto/slsa_provenance/v1"
+	dsselib "github.com/secure-systems-lab/go-securesystemslib/dsse"
+
+	serrors "github.com/slsa-framework/slsa-verifier/v2/errors"
+	"github.com/slsa-framework/slsa-verifier/v2/options"
+	"github.com/slsa-framework/slsa-verifier/v2/verifiers/utils"
+)
+
+const (
+	statementTypeV1 = "https://in-toto.io/Statement/v1"
+	// The build type of the provenance generated by GitLab Runner is the
+	// documentation of the version of the runner, e.g.
+	// https://gitlab.com/gitlab-org/gitlab-runner/-/blob/v17.0.0/PROVENANCE.md.
+	runnerBuildTypePrefix = httpsGitLabCom + "gitlab-org/gitlab-runner/-/blob/"
+	runnerBuildTypeSuffix = "/PROVENANCE.md"
+)
+
+// Provenance is the SLSA v1.0 provenance generated by GitLab Runner.
+type Provenance struct {
+	intoto.StatementHeader
+	Predicate intotov1.ProvenancePredicate `json:"predicate"`
+}
+
+// ProvenanceFromEnvelope returns the provenance in the envelope.
+func ProvenanceFromEnvelope(env *dsselib.Envelope) (*Provenance, error) {
+	if env.PayloadType != intoto.PayloadType {
+		return nil, fmt.Errorf("%w: expected payload type %q, got %q",
+			serrors.ErrorInvalidDssePayload, intoto.PayloadType, env.PayloadType)
+	}
+	pyld, err := base64.StdEncoding.DecodeString(env.Payload)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", serrors.ErrorInvalidDssePayload, err)
+	}
+	return ProvenanceFromBytes(pyld)
+}
+
+// ProvenanceFromBytes returns the provenance in the in-toto statement.
+func ProvenanceFromBytes(payload []byte) (*Provenance, error) {
+	var prov Provenance
+	if err := json.Unmarshal(payload, &prov); err != nil {
+		return nil, fmt.Errorf("%w: decoding json: %w", serrors.ErrorInvalidDssePayload, err)
+	}
+	if prov.Type != statementTypeV1 {
+		return nil, fmt.Errorf("%w: unexpected statement type %q", serrors.ErrorInvalidDssePayload, prov.Type)
+	}
+	if prov.PredicateType != intotov1.PredicateSLSAProvenance {
+		return nil, fmt.Errorf("%w: unexpected predicate type %q", serrors.ErrorInvalidDssePayload, prov.PredicateType)
+	}
+	buildType := prov.Predicate.BuildDefinition.BuildType
+	if !strings.HasPrefix(buildType, runnerBuildTypePrefix) || !strings.HasSuffix(buildType, runnerBuildTypeSuffix) {
+		return nil, fmt.Errorf("%w: %q", serrors.ErrorInvalidBuildType, buildType)
+	}
+	return &prov, nil
+}
+
+// verifyBuilderID verifies the runner in the provenance against the
+// builder ID provided by the caller, which is required. The builder ID of
+// a runner is scoped to the project, e.g.
+// https://gitlab.com/group/project/-/runners/12345.
+func verifyBuilderID(prov *Provenance, identity *PipelineIdentity,
+	expectedID *string,
+) (*utils.TrustedBuilderID, error) {
+	if expectedID == nil || *expectedID == "" {
+		return nil, fmt.Errorf("%w: no expected builder ID: the runner must be provided",
+			serrors.ErrorInvalidBuilderID)
+	}
+	id := prov.Predicate.RunDetails.Builder.ID
+	runnersPrefix := identity.ProjectURI() + "/-/runners/"
+	if !strings.HasPrefix(id, runnersPrefix) || id == runnersPrefix {
+		return nil, serrors.NewVerificationError(fmt.Errorf("%w: builder ID %q is not a runner of the project %q",
+			serrors.ErrorMismatchCertificate, id, identity.ProjectURI()), runnersPrefix+"*", id)
+	}
+	builderID, err := utils.TrustedBuilderIDNew(id, false)
+	if err != nil {
+		return nil, err
+	}
+	if err := builderID.MatchesLoose(*expectedID, false); err != nil {
+		return nil, err
+	}
+	return builderID, nil
+}
+
+// verifyRunnerEnvironment verifies the environment of the runner of the
+// job against the expected environment of the GitLab options, if any.
+func verifyRunnerEnvironment(identity *PipelineIdentity, gitlabOpts *options.GitLabOpts) error {
+	if gitlabOpts == nil || gitlabOpts.RunnerEnvironment == "" {
+		return nil
+	}
+	expected := gitlabOpts.RunnerEnvironment
+	if expected != runnerGitLabHosted && expected != runnerSelfHosted {
+		return fmt.Errorf("%w: expected runner environment %q: must be %q or %q", serrors.ErrorInvalidFormat,
+			expected, runnerGitLabHosted, runnerSelfHosted)
+	}
+	if identity.RunnerEnvironment != expected {
+		return serrors.NewVerificationError(fmt.Errorf("%w: expected runner environment %q, got %q",
+			serrors.ErrorMismatchCertificate, expected, identity.RunnerEnvironment),
+			expected, identity.RunnerEnvironment)
+	}
+	return nil
+}
+
+// verifySourceURI verifies the project of the job against the expected
+// source URI.
+func verifySourceURI(identity *PipelineIdentity, expectedSourceURI string) error {
+	source := utils.NormalizeGitURI(expectedSourceURI)
+	project := "git+" + identity.ProjectURI()
+	if source != project {
+		return serrors.NewVerificationError(fmt.Errorf("%w: expected source %q, got %q",
+			serrors.ErrorMismatchSource, source, project), source, project)
+	}
+	return nil
+}
+
+// verifyProvenanceMatchesIdentity verifies that the provenance was
+// generated by the job that signed it: the project, the commit and the
+// job must match the certificate, as must the predefined variables of the
+// pipeline that GitLab Runner records in the external parameters.
+func verifyProvenanceMatchesIdentity(prov *Provenance, identity *PipelineIdentity) error {
+	buildDefinition := prov.Predicate.BuildDefinition
+
+	// Verify the project.
+	externalParams, ok := buildDefinition.ExternalParameters.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("%w: %s", serrors.ErrorInvalidDssePayload, "external parameters type")
+	}
+	source, _ := externalParams["source"].(string)
+	if source != identity.ProjectURI() {
+		return serrors.NewVerificationError(fmt.Errorf("%w: source: '%s' != '%s'",
+			serrors.ErrorMismatchCertificate, source, identity.ProjectURI()), identity.ProjectURI(), source)
+	}
+
+	// Verify the commit of the project.
+	if !slices.ContainsFunc(buildDefinition.ResolvedDependencies, func(d intotov1.ResourceDescriptor) bool {
+		uri, _, _ := strings.Cut(strings.TrimPrefix(d.URI, "git+"), "@")
+		if uri != identity.ProjectURI() {
+			return false
+		}
+		for _, digest := range d.Digest {
+			if digest == identity.SourceSha1 {
+				return true
+			}
+		}
+		return false
+	}) {
+		return serrors.NewVerificationError(fmt.Errorf("%w: no resolved dependency for %s at commit %s",
+			serrors.ErrorMismatchCertificate, identity.ProjectURI(), identity.SourceSha1), identity.SourceSha1, "")
+	}
+
+	// Verify the job.
+	invocationID := prov.Predicate.RunDetails.BuildMetadata.InvocationID
+	if invocationID != identity.JobID {
+		return serrors.NewVerificationError(fmt.Errorf("%w: invocationId: '%s' != '%s'",
+			serrors.ErrorMismatchCertificate, invocationID, identity.JobID), identity.JobID, invocationID)
+	}
+	if internalParams, ok := buildDefinition.InternalParameters.(map[string]interface{}); ok {
+		if job, ok := internalParams["job"]; ok && job != identity.JobID {
+			return serrors.NewVerificationError(fmt.Errorf("%w: job: '%v' != '%s'",
+				serrors.ErrorMismatchCertificate, job, identity.JobID), identity.JobID, fmt.Sprint(job))
+		}
+	}
+
+	// Verify the variables of the pipeline, which are empty or absent if
+	// GitLab Runner does not record them.
+	for _, v := range []struct{ name, expected string }{
+		{"CI_JOB_ID", identity.JobID},
+		{"CI_PROJECT_ID", identity.ProjectID},
+		{"CI_COMMIT_SHA", identity.SourceSha1},
+		{"CI_PIPELINE_SOURCE", identity.PipelineSource},
+	} {
+		value, _ := externalParams[v.name].(string)
+		if value != "" && value != v.expected {
+			return serrors.NewVerificationError(fmt.Errorf("%w: %s: '%s' != '%s'",
+				serrors.ErrorMismatchCertificate, v.name, value, v.expected), v.expected, value)
+		}
+	}
+	return nil
+}
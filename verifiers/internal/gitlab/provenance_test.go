@@ -0,0 +1,290 @@
+package gitlab
+
+import (
+	"os"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	intotov1 "github.com/in-toto/in-toto-golang/in_toto/slsa_provenance/v1"
+
+	serrors "github.com/slsa-framework/slsa-verifier/v2/errors"
+	"github.com/slsa-framework/slsa-verifier/v2/options"
+)
+
+func newTestProvenance(t *testing.T) *Provenance {
+	t.Helper()
+	content, err := os.ReadFile("testdata/provenance.intoto.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	prov, err := ProvenanceFromBytes(content)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return prov
+}
+
+func newTestIdentity(t *testing.T) *PipelineIdentity {
+	t.Helper()
+	identity, err := GetPipelineIdentityFromCertificate(newTestCertificate(t, newTestExtensions()), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return identity
+}
+
+func Test_ProvenanceFromBytes(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		payload string
+		err     error
+	}{
+		{
+			name: "GitLab Runner provenance",
+			payload: `{"_type": "https://in-toto.io/Statement/v1", "predicateType": "https://slsa.dev/provenance/v1",
+				"predicate": {"buildDefinition": {"buildType": "https://gitlab.com/gitlab-org/gitlab-runner/-/blob/v17.5.0/PROVENANCE.md"}}}`,
+		},
+		{
+			name: "GitHub Actions provenance",
+			payload: `{"_type": "https://in-toto.io/Statement/v1", "predicateType": "https://slsa.dev/provenance/v1",
+				"predicate": {"buildDefinition": {"buildType": "https://actions.github.io/buildtypes/workflow/v1"}}}`,
+			err: serrors.ErrorInvalidBuildType,
+		},
+		{
+			name: "SLSA v0.2 provenance",
+			payload: `{"_type": "https://in-toto.io/Statement/v0.1", "predicateType": "https://slsa.dev/provenance/v0.2",
+				"predicate": {"buildType": "https://gitlab.com/gitlab-org/gitlab-runner/-/blob/v15.1.0/PROVENANCE.md"}}`,
+			err: serrors.ErrorInvalidDssePayload,
+		},
+		{
+			name:    "not json",
+			payload: "provenance",
+			err:     serrors.ErrorInvalidDssePayload,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := ProvenanceFromBytes([]byte(tt.payload))
+			if diff := cmp.Diff(tt.err, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("unexpected error (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_verifyBuilderID(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		builderID  string
+		expectedID *string
+		err        error
+	}{
+		{
+			name:       "runner of the project",
+			builderID:  "https://gitlab.com/group/project/-/runners/32585",
+			expectedID: asStringPointer("https://gitlab.com/group/project/-/runners/32585"),
+		},
+		{
+			name:      "no expected builder ID",
+			builderID: "https://gitlab.com/group/project/-/runners/32585",
+			err:       serrors.ErrorInvalidBuilderID,
+		},
+		{
+			name:       "other runner",
+			builderID:  "https://gitlab.com/group/project/-/runners/32585",
+			expectedID: asStringPointer("https://gitlab.com/group/project/-/runners/12270807"),
+			err:        serrors.ErrorMismatchBuilderID,
+		},
+		{
+			name:       "runner of another project",
+			builderID:  "https://gitlab.com/group/other/-/runners/32585",
+			expectedID: asStringPointer("https://gitlab.com/group/other/-/runners/32585"),
+			err:        serrors.ErrorMismatchCertificate,
+		},
+		{
+			name:       "no runner",
+			builderID:  "https://gitlab.com/group/project/-/runners/",
+			expectedID: asStringPointer("https://gitlab.com/group/project/-/runners/"),
+			err:        serrors.ErrorMismatchCertificate,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			prov := newTestProvenance(t)
+			prov.Predicate.RunDetails.Builder.ID = tt.builderID
+			_, err := verifyBuilderID(prov, newTestIdentity(t), tt.expectedID)
+			if diff := cmp.Diff(tt.err, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("unexpected error (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_verifyProvenanceMatchesIdentity(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		mutate func(*Provenance)
+		err    error
+	}{
+		{
+			name: "provenance of the job",
+		},
+		{
+			name: "git source dependency",
+			mutate: func(p *Provenance) {
+				p.Predicate.BuildDefinition.ResolvedDependencies[0].URI = "git+https://gitlab.com/group/project@refs/tags/v1.2.3"
+			},
+		},
+		{
+			name: "source of another project",
+			mutate: func(p *Provenance) {
+				p.Predicate.BuildDefinition.ExternalParameters = map[string]interface{}{
+					"source": "https://gitlab.com/group/other",
+				}
+			},
+			err: serrors.ErrorMismatchCertificate,
+		},
+		{
+			name: "no source",
+			mutate: func(p *Provenance) {
+				p.Predicate.BuildDefinition.ExternalParameters = map[string]interface{}{}
+			},
+			err: serrors.ErrorMismatchCertificate,
+		},
+		{
+			name: "other commit",
+			mutate: func(p *Provenance) {
+				p.Predicate.BuildDefinition.ResolvedDependencies[0].Digest = map[string]string{
+					"sha256": "0000000000000000000000000000000000000000",
+				}
+			},
+			err: serrors.ErrorMismatchCertificate,
+		},
+		{
+			name: "no resolved dependencies",
+			mutate: func(p *Provenance) {
+				p.Predicate.BuildDefinition.ResolvedDependencies = []intotov1.ResourceDescriptor{}
+			},
+			err: serrors.ErrorMismatchCertificate,
+		},
+		{
+			name: "other invocation",
+			mutate: func(p *Provenance) {
+				p.Predicate.RunDetails.BuildMetadata.InvocationID = "8123456790"
+			},
+			err: serrors.ErrorMismatchCertificate,
+		},
+		{
+			name: "other job",
+			mutate: func(p *Provenance) {
+				p.Predicate.BuildDefinition.InternalParameters = map[string]interface{}{
+					"job": "8123456790",
+				}
+			},
+			err: serrors.ErrorMismatchCertificate,
+		},
+		{
+			name: "variables of the pipeline",
+			mutate: func(p *Provenance) {
+				p.Predicate.BuildDefinition.ExternalParameters = map[string]interface{}{
+					"source":             "https://gitlab.com/group/project",
+					"CI_JOB_ID":          "8123456789",
+					"CI_PROJECT_ID":      "123",
+					"CI_COMMIT_SHA":      "4a9b2c7d1e8f3a6b5c0d9e2f7a1b4c8d3e6f0a5b",
+					"CI_PIPELINE_SOURCE": "push",
+				}
+			},
+		},
+		{
+			name: "job variable of another job",
+			mutate: func(p *Provenance) {
+				p.Predicate.BuildDefinition.ExternalParameters = map[string]interface{}{
+					"source":    "https://gitlab.com/group/project",
+					"CI_JOB_ID": "8123456790",
+				}
+			},
+			err: serrors.ErrorMismatchCertificate,
+		},
+		{
+			name: "pipeline of another event",
+			mutate: func(p *Provenance) {
+				p.Predicate.BuildDefinition.ExternalParameters = map[string]interface{}{
+					"source":             "https://gitlab.com/group/project",
+					"CI_PIPELINE_SOURCE": "merge_request_event",
+				}
+			},
+			err: serrors.ErrorMismatchCertificate,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			prov := newTestProvenance(t)
+			if tt.mutate != nil {
+				tt.mutate(prov)
+			}
+			err := verifyProvenanceMatchesIdentity(prov, newTestIdentity(t))
+			if diff := cmp.Diff(tt.err, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("unexpected error (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_verifyRunnerEnvironment(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		gitlabOpts *options.GitLabOpts
+		err        error
+	}{
+		{
+			name: "no options",
+		},
+		{
+			name:       "any environment",
+			gitlabOpts: &options.GitLabOpts{Host: "gitlab.com"},
+		},
+		{
+			name:       "expected environment",
+			gitlabOpts: &options.GitLabOpts{RunnerEnvironment: "gitlab-hosted"},
+		},
+		{
+			name:       "other environment",
+			gitlabOpts: &options.GitLabOpts{RunnerEnvironment: "self-hosted"},
+			err:        serrors.ErrorMismatchCertificate,
+		},
+		{
+			name:       "unknown environment",
+			gitlabOpts: &options.GitLabOpts{RunnerEnvironment: "github-hosted"},
+			err:        serrors.ErrorInvalidFormat,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := verifyRunnerEnvironment(newTestIdentity(t), tt.gitlabOpts)
+			if diff := cmp.Diff(tt.err, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("unexpected error (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func asStringPointer(s string) *string {
+	return &s
+}
@@ -0,0 +1,211 @@
+package gitlab
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	dsselib "github.com/secure-systems-lab/go-securesystemslib/dsse"
+	ociremote "github.com/sigstore/cosign/v2/pkg/oci/remote"
+
+	serrors "github.com/slsa-framework/slsa-verifier/v2/errors"
+	"github.com/slsa-framework/slsa-verifier/v2/options"
+	"github.com/slsa-framework/slsa-verifier/v2/register"
+	"github.com/slsa-framework/slsa-verifier/v2/report"
+	"github.com/slsa-framework/slsa-verifier/v2/verifiers/internal/gha"
+	"github.com/slsa-framework/slsa-verifier/v2/verifiers/utils"
+	"github.com/slsa-framework/slsa-verifier/v2/verifiers/utils/container"
+)
+
+// GitLab CI jobs sign the provenance generated by GitLab Runner with
+// Sigstore keyless signing, using the OIDC token of the job. As for GitHub
+// artifact attestations, the signer is the job and not a trusted builder:
+// the fields of the provenance are verified against the certificate.
+// See https://docs.gitlab.com/ee/ci/runners/configure_runners.html#artifact-provenance-metadata.
+
+const VerifierName = "GitLab"
+
+//nolint:gochecknoinits
+func init() {
+	register.RegisterVerifier(VerifierName, GitLabVerifierNew())
+}
+
+type GitLabVerifier struct{}
+
+func GitLabVerifierNew() *GitLabVerifier {
+	return &GitLabVerifier{}
+}
+
+// IsAuthoritativeFor returns true of the verifier can verify provenance
+// generated by the builderID.
+func (v *GitLabVerifier) IsAuthoritativeFor(builderID string) bool {
+	// The runners of self-managed instances are selected by the host of
+	// the GitLab options, which the verifier is not given here.
+	return strings.HasPrefix(builderID, httpsGitLabCom)
+}
+
+// VerifyArtifact verifies provenance for an artifact.
+func (v *GitLabVerifier) VerifyArtifact(ctx context.Context,
+	provenance []byte, artifactHash string,
+	provenanceOpts *options.ProvenanceOpts,
+	builderOpts *options.BuilderOpts,
+) ([]byte, *utils.TrustedBuilderID, error) {
+	return verifyBundle(ctx, provenance, provenanceOpts, builderOpts)
+}
+
+// VerifyImage verifies provenance for an OCI image.
+func (v *GitLabVerifier) VerifyImage(ctx context.Context,
+	provenance []byte, artifactImage string,
+	provenanceOpts *options.ProvenanceOpts,
+	builderOpts *options.BuilderOpts,
+) ([]byte, *utils.TrustedBuilderID, error) {
+	// Provenance provided by the caller.
+	if provenance != nil {
+		return verifyBundle(ctx, provenance, provenanceOpts, builderOpts)
+	}
+
+	// Provenance attached to the image with cosign attest.
+	registryClientOpts := []ociremote.Option{}
+	if provenanceOpts.ExpectedProvenanceRepository != nil {
+		repository, err := name.NewRepository(*provenanceOpts.ExpectedProvenanceRepository)
+		if err != nil {
+			return nil, nil, err
+		}
+		registryClientOpts = append(registryClientOpts, ociremote.WithTargetRepository(repository))
+	}
+	opts, err := gha.GetCosignCheckOpts(ctx, provenanceOpts.SigstoreOpts)
+	if err != nil {
+		return nil, nil, err
+	}
+	opts.RegistryClientOpts = registryClientOpts
+
+	rep := report.FromContext(ctx)
+	atts, _, err := container.RunCosignImageVerification(ctx, artifactImage, opts)
+	if err := rep.Check(report.CheckSignature, err); err != nil {
+		return nil, nil, err
+	}
+
+	return utils.VerifyEach(ctx, container.CosignAttestations(ctx, atts),
+		func(ctx context.Context, att container.CosignAttestation) ([]byte, *utils.TrustedBuilderID, error) {
+			verifiedProvenance, builderID, err := verifyEnvAndCert(ctx, att.Envelope, att.Cert,
+				provenanceOpts, builderOpts)
+			if err != nil {
+				return nil, nil, err
+			}
+			rep := report.FromContext(ctx)
+			rep.SetSigner(utils.CertificateSigner(att.Cert))
+			if att.RekorEntry != nil {
+				rep.SetRekorEntry(*att.RekorEntry)
+			}
+			return verifiedProvenance, builderID, nil
+		})
+}
+
+// VerifyNpmPackage verifies an npm package tarball.
+func (v *GitLabVerifier) VerifyNpmPackage(ctx context.Context,
+	attestations []byte, tarballHash string,
+	provenanceOpts *options.ProvenanceOpts,
+	builderOpts *options.BuilderOpts,
+) ([]byte, *utils.TrustedBuilderID, error) {
+	return nil, nil, serrors.ErrorNotSupported
+}
+
+// verifyBundle verifies the provenance in a Sigstore bundle.
+func verifyBundle(ctx context.Context, bundle []byte,
+	provenanceOpts *options.ProvenanceOpts,
+	builderOpts *options.BuilderOpts,
+) ([]byte, *utils.TrustedBuilderID, error) {
+	rep := report.FromContext(ctx)
+	if !gha.IsSigstoreBundle(bundle) {
+		return nil, nil, rep.Check(report.CheckSignature,
+			fmt.Errorf("%w: GitLab provenance must be a Sigstore bundle", serrors.ErrorInvalidFormat))
+	}
+	trustedMaterial, err := utils.GetTrustedMaterial(provenanceOpts.SigstoreOpts)
+	if err != nil {
+		return nil, nil, err
+	}
+	signed, err := verifySignedBundle(bundle, trustedMaterial, serverURL(provenanceOpts.GitLabOpts))
+	if err := rep.Check(report.CheckSignature, err); err != nil {
+		return nil, nil, err
+	}
+	rep.SetSigner(utils.CertificateSigner(signed.SigningCert))
+	if signed.RekorEntry != nil {
+		rep.SetRekorEntry(*signed.RekorEntry)
+	}
+	return verifyEnvAndCert(ctx, signed.Envelope, signed.SigningCert, provenanceOpts, builderOpts)
+}
+
+// verifyEnvAndCert verifies the provenance in the envelope against the
+// certificate of the job that signed it, and against the options.
+func verifyEnvAndCert(ctx context.Context, env *dsselib.Envelope,
+	cert *x509.Certificate,
+	provenanceOpts *options.ProvenanceOpts,
+	builderOpts *options.BuilderOpts,
+) ([]byte, *utils.TrustedBuilderID, error) {
+	rep := report.FromContext(ctx)
+	identity, err := GetPipelineIdentityFromCertificate(cert, provenanceOpts.GitLabOpts)
+	if err != nil {
+		return nil, nil, rep.Check(report.CheckBuilderID, err)
+	}
+	rep.SetSource(identity.ProjectURI(), identity.SourceSha1, identity.SourceRef)
+
+	prov, err := ProvenanceFromEnvelope(env)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Verify the runner.
+	builderID, err := verifyBuilderID(prov, identity, builderOpts.ExpectedID)
+	if err := rep.Check(report.CheckBuilderID, err); err != nil {
+		return nil, nil, err
+	}
+	// The provenance is signed by the job, which the runner does not
+	// isolate from the signing key.
+	rep.SetBuilderTrust(report.BuilderTrustWorkflow)
+
+	// Verify the environment of the runner from the certificate.
+	if err := rep.Check(report.CheckBuilderID,
+		verifyRunnerEnvironment(identity, provenanceOpts.GitLabOpts)); err != nil {
+		return nil, nil, err
+	}
+
+	// Verify the project from the certificate.
+	if err := rep.Check(report.CheckSourceURI,
+		verifySourceURI(identity, provenanceOpts.ExpectedSourceURI)); err != nil {
+		return nil, nil, err
+	}
+
+	// The provenance is generated by the job, so it must match the certificate.
+	if err := rep.Check(report.CheckMetadata, verifyProvenanceMatchesIdentity(prov, identity)); err != nil {
+		return nil, nil, err
+	}
+
+	// Verify subject digest.
+	if err := rep.Check(report.CheckSubjectDigest, utils.VerifySubjectDigest(prov.Subject, provenanceOpts.ExpectedDigest)); err != nil {
+		return nil, nil, err
+	}
+
+	// Verify the ref from the certificate.
+	if err := utils.VerifyRef(rep, identity.SourceRef, provenanceOpts); err != nil {
+		return nil, nil, err
+	}
+
+	if len(provenanceOpts.ExpectedWorkflowInputs) > 0 {
+		return nil, nil, rep.Check(report.CheckWorkflowInputs,
+			fmt.Errorf("%w: workflow inputs of GitLab CI pipelines", serrors.ErrorNotSupported))
+	}
+
+	utils.Logf(ctx, "Verified build using runner %q (%s) at commit %s\n",
+		builderID.String(),
+		identity.RunnerEnvironment,
+		identity.SourceSha1)
+
+	r, err := base64.StdEncoding.DecodeString(env.Payload)
+	if err != nil {
+		return nil, nil, err
+	}
+	return r, builderID, nil
+}
@@ -0,0 +1,263 @@
+package gitlab
+
+import (
+	"context"
+	"encoding/base64"
+	"os"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	intoto "github.com/in-toto/in-toto-golang/in_toto"
+	dsselib "github.com/secure-systems-lab/go-securesystemslib/dsse"
+	fulcio "github.com/sigstore/fulcio/pkg/certificate"
+
+	serrors "github.com/slsa-framework/slsa-verifier/v2/errors"
+	"github.com/slsa-framework/slsa-verifier/v2/options"
+	"github.com/slsa-framework/slsa-verifier/v2/report"
+)
+
+const (
+	testDigest    = "6f5c6a4b2d1e9c7f8a3b0e2d4c6a8f1e3b5d7c9a0e2f4b6d8c1a3e5f7b9d0c2e"
+	testBuilderID = "https://gitlab.com/group/project/-/runners/32585"
+)
+
+func newTestEnvelope(t *testing.T) *dsselib.Envelope {
+	t.Helper()
+	content, err := os.ReadFile("testdata/provenance.intoto.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &dsselib.Envelope{
+		PayloadType: intoto.PayloadType,
+		Payload:     base64.StdEncoding.EncodeToString(content),
+	}
+}
+
+func Test_IsAuthoritativeFor(t *testing.T) {
+	t.Parallel()
+
+	v := GitLabVerifierNew()
+	if !v.IsAuthoritativeFor(testBuilderID) {
+		t.Errorf("expected authoritative for %q", testBuilderID)
+	}
+	if v.IsAuthoritativeFor("https://github.com/slsa-framework/slsa-github-generator/.github/workflows/generator_generic_slsa3.yml") {
+		t.Errorf("expected not authoritative for GitHub builders")
+	}
+}
+
+func Test_verifyEnvAndCert(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name           string
+		mutate         func(*fulcio.Extensions)
+		provenanceOpts *options.ProvenanceOpts
+		builderID      *string
+		builderTrust   string
+		err            error
+	}{
+		{
+			name: "all options",
+			provenanceOpts: &options.ProvenanceOpts{
+				ExpectedDigest:       testDigest,
+				ExpectedSourceURI:    "gitlab.com/group/project",
+				ExpectedTag:          asStringPointer("v1.2.3"),
+				ExpectedTagPatterns:  []string{"v1.*"},
+				ExpectedVersionedTag: asStringPointer("v1.2"),
+			},
+			builderID:    asStringPointer(testBuilderID),
+			builderTrust: report.BuilderTrustWorkflow,
+		},
+		{
+			name: "mismatched digest",
+			provenanceOpts: &options.ProvenanceOpts{
+				ExpectedDigest:    "0a2b4c6d8e0f1a3b5c7d9e1f2a4b6c8d0e2f4a6b8c0d2e4f6a8b0c2d4e6f8a0b",
+				ExpectedSourceURI: "gitlab.com/group/project",
+			},
+			builderID:    asStringPointer(testBuilderID),
+			builderTrust: report.BuilderTrustWorkflow,
+			err:          serrors.ErrorMismatchHash,
+		},
+		{
+			name: "mismatched source",
+			provenanceOpts: &options.ProvenanceOpts{
+				ExpectedDigest:    testDigest,
+				ExpectedSourceURI: "gitlab.com/group/other",
+			},
+			builderID:    asStringPointer(testBuilderID),
+			builderTrust: report.BuilderTrustWorkflow,
+			err:          serrors.ErrorMismatchSource,
+		},
+		{
+			name: "GitHub source",
+			provenanceOpts: &options.ProvenanceOpts{
+				ExpectedDigest:    testDigest,
+				ExpectedSourceURI: "github.com/group/project",
+			},
+			builderID:    asStringPointer(testBuilderID),
+			builderTrust: report.BuilderTrustWorkflow,
+			err:          serrors.ErrorMismatchSource,
+		},
+		{
+			name: "tag instead of branch",
+			provenanceOpts: &options.ProvenanceOpts{
+				ExpectedDigest:    testDigest,
+				ExpectedSourceURI: "gitlab.com/group/project",
+				ExpectedBranch:    asStringPointer("main"),
+			},
+			builderID:    asStringPointer(testBuilderID),
+			builderTrust: report.BuilderTrustWorkflow,
+			err:          serrors.ErrorInvalidRef,
+		},
+		{
+			name: "mismatched branch",
+			mutate: func(e *fulcio.Extensions) {
+				e.SourceRepositoryRef = "refs/heads/feature"
+			},
+			provenanceOpts: &options.ProvenanceOpts{
+				ExpectedDigest:    testDigest,
+				ExpectedSourceURI: "gitlab.com/group/project",
+				ExpectedBranch:    asStringPointer("main"),
+			},
+			builderID:    asStringPointer(testBuilderID),
+			builderTrust: report.BuilderTrustWorkflow,
+			err:          serrors.ErrorMismatchBranch,
+		},
+		{
+			name: "mismatched tag pattern",
+			provenanceOpts: &options.ProvenanceOpts{
+				ExpectedDigest:      testDigest,
+				ExpectedSourceURI:   "gitlab.com/group/project",
+				ExpectedTagPatterns: []string{"v2.*"},
+			},
+			builderID:    asStringPointer(testBuilderID),
+			builderTrust: report.BuilderTrustWorkflow,
+			err:          serrors.ErrorMismatchTag,
+		},
+		{
+			name: "mismatched versioned tag",
+			provenanceOpts: &options.ProvenanceOpts{
+				ExpectedDigest:       testDigest,
+				ExpectedSourceURI:    "gitlab.com/group/project",
+				ExpectedVersionedTag: asStringPointer("v2"),
+			},
+			builderID:    asStringPointer(testBuilderID),
+			builderTrust: report.BuilderTrustWorkflow,
+			err:          serrors.ErrorMismatchVersionedTag,
+		},
+		{
+			name: "workflow inputs",
+			provenanceOpts: &options.ProvenanceOpts{
+				ExpectedDigest:         testDigest,
+				ExpectedSourceURI:      "gitlab.com/group/project",
+				ExpectedWorkflowInputs: map[string]string{"release": "true"},
+			},
+			builderID:    asStringPointer(testBuilderID),
+			builderTrust: report.BuilderTrustWorkflow,
+			err:          serrors.ErrorNotSupported,
+		},
+		{
+			name: "expected runner environment",
+			provenanceOpts: &options.ProvenanceOpts{
+				ExpectedDigest:    testDigest,
+				ExpectedSourceURI: "gitlab.com/group/project",
+				GitLabOpts:        &options.GitLabOpts{RunnerEnvironment: "gitlab-hosted"},
+			},
+			builderID:    asStringPointer(testBuilderID),
+			builderTrust: report.BuilderTrustWorkflow,
+		},
+		{
+			name: "self-hosted runner",
+			mutate: func(e *fulcio.Extensions) {
+				e.RunnerEnvironment = "self-hosted"
+			},
+			provenanceOpts: &options.ProvenanceOpts{
+				ExpectedDigest:    testDigest,
+				ExpectedSourceURI: "gitlab.com/group/project",
+				GitLabOpts:        &options.GitLabOpts{RunnerEnvironment: "gitlab-hosted"},
+			},
+			builderID:    asStringPointer(testBuilderID),
+			builderTrust: report.BuilderTrustWorkflow,
+			err:          serrors.ErrorMismatchCertificate,
+		},
+		{
+			name: "no builder ID",
+			provenanceOpts: &options.ProvenanceOpts{
+				ExpectedDigest:    testDigest,
+				ExpectedSourceURI: "gitlab.com/group/project",
+			},
+			err: serrors.ErrorInvalidBuilderID,
+		},
+		{
+			name: "signed by another job",
+			mutate: func(e *fulcio.Extensions) {
+				e.RunInvocationURI = "https://gitlab.com/group/project/-/jobs/8123456790"
+			},
+			provenanceOpts: &options.ProvenanceOpts{
+				ExpectedDigest:    testDigest,
+				ExpectedSourceURI: "gitlab.com/group/project",
+			},
+			builderID:    asStringPointer(testBuilderID),
+			builderTrust: report.BuilderTrustWorkflow,
+			err:          serrors.ErrorMismatchCertificate,
+		},
+		{
+			name: "signed by another project",
+			mutate: func(e *fulcio.Extensions) {
+				e.SourceRepositoryURI = "https://gitlab.com/group/other"
+				e.RunInvocationURI = "https://gitlab.com/group/other/-/jobs/8123456789"
+			},
+			provenanceOpts: &options.ProvenanceOpts{
+				ExpectedDigest:    testDigest,
+				ExpectedSourceURI: "gitlab.com/group/other",
+			},
+			builderID: asStringPointer(testBuilderID),
+			err:       serrors.ErrorMismatchCertificate,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ext := newTestExtensions()
+			if tt.mutate != nil {
+				tt.mutate(&ext)
+			}
+			rep := report.New("artifact-linux-amd64", testDigest)
+			ctx := report.NewContext(context.Background(), rep)
+			_, builderID, err := verifyEnvAndCert(ctx, newTestEnvelope(t), newTestCertificate(t, ext),
+				tt.provenanceOpts, &options.BuilderOpts{ExpectedID: tt.builderID})
+			if diff := cmp.Diff(tt.err, err, cmpopts.EquateErrors()); diff != "" {
+				t.Fatalf("unexpected error (-want +got):\n%s", diff)
+			}
+			if rep.BuilderTrust != tt.builderTrust {
+				t.Errorf("unexpected builder trust: want %q, got %q", tt.builderTrust, rep.BuilderTrust)
+			}
+			if err == nil && builderID.String() != testBuilderID {
+				t.Errorf("unexpected builder ID: %q", builderID.String())
+			}
+		})
+	}
+}
+
+func Test_VerifyArtifact(t *testing.T) {
+	t.Parallel()
+
+	// Provenance must be in a Sigstore bundle, with the certificate
+	// and the transparency log entry.
+	content, err := os.ReadFile("testdata/provenance.intoto.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _, err = GitLabVerifierNew().VerifyArtifact(context.Background(), content, testDigest,
+		&options.ProvenanceOpts{
+			ExpectedDigest:    testDigest,
+			ExpectedSourceURI: "gitlab.com/group/project",
+			SigstoreOpts:      &options.SigstoreOpts{Offline: true},
+		},
+		&options.BuilderOpts{ExpectedID: asStringPointer(testBuilderID)})
+	if diff := cmp.Diff(serrors.ErrorInvalidFormat, err, cmpopts.EquateErrors()); diff != "" {
+		t.Errorf("unexpected error (-want +got):\n%s", diff)
+	}
+}
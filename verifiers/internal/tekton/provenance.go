@@ -0,0 +1,315 @@
+package tekton
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	intoto "github.com/in-toto/in-toto-golang/in_toto"
+	slsa02 "github.com/in-toto/in-toto-golang/in_toto/slsa_provenance/v0.2"
+	intotov1 "github.com/in-toto/in-toto-golang/in_toto/slsa_provenance/v1"
+	dsselib "github.com/secure-systems-lab/go-securesystemslib/dsse"
+
+	serrors "github.com/slsa-framework/slsa-verifier/v2/errors"
+	"github.com/slsa-framework/slsa-verifier/v2/verifiers/utils"
+)
+
+const (
+	statementTypeV01 = "https://in-toto.io/Statement/v0.1"
+	statementTypeV1  = "https://in-toto.io/Statement/v1"
+)
+
+// Build types of the SLSA v0.2 provenance, generated by the slsa/v1 and
+// slsa/v2alpha2 formats of Tekton Chains.
+var buildTypesV02 = []string{
+	"tekton.dev/v1beta1/TaskRun",
+	"tekton.dev/v1beta1/PipelineRun",
+	"tekton.dev/v1/TaskRun",
+	"tekton.dev/v1/PipelineRun",
+	"https://chains.tekton.dev/format/slsa/v2alpha2/type/tekton.dev/v1beta1/TaskRun",
+	"https://chains.tekton.dev/format/slsa/v2alpha2/type/tekton.dev/v1beta1/PipelineRun",
+}
+
+// Build types of the SLSA v1.0 provenance, generated by the slsa/v2alpha3
+// and slsa/v2alpha4 formats of Tekton Chains.
+var buildTypesV1 = []string{
+	"https://tekton.dev/chains/v2/slsa",
+	"https://tekton.dev/chains/v2/slsa-tekton",
+}
+
+// TaskRef is the reference of a run to the pipeline or task it runs.
+// Inline specs have no reference.
+type TaskRef struct {
+	Name     string `json:"name,omitempty"`
+	Kind     string `json:"kind,omitempty"`
+	Bundle   string `json:"bundle,omitempty"`
+	Resolver string `json:"resolver,omitempty"`
+}
+
+// Material is a source or an image the run depended on.
+type Material struct {
+	URI    string
+	Digest map[string]string
+}
+
+// Provenance is the provenance generated by Tekton Chains, in the SLSA
+// v0.2 or v1.0 format.
+type Provenance struct {
+	intoto.StatementHeader
+	BuilderID string
+	BuildType string
+	// Refs are the references of the run to the pipeline or the tasks it
+	// runs, from the buildConfig of the SLSA v0.2 provenance of a
+	// PipelineRun or the runSpec of the SLSA v1.0 provenance. A nil
+	// reference is an inline spec.
+	Refs []*TaskRef
+	// RefsRecorded is false for the SLSA v0.2 provenance of a TaskRun,
+	// which does not record the reference to its task.
+	RefsRecorded bool
+	Materials    []Material
+}
+
+// buildConfigV02 is the buildConfig of the SLSA v0.2 provenance of a
+// PipelineRun.
+type buildConfigV02 struct {
+	Tasks []struct {
+		Name string   `json:"name"`
+		Ref  *TaskRef `json:"ref"`
+	} `json:"tasks"`
+}
+
+// externalParametersV1 are the external parameters of the SLSA v1.0
+// provenance.
+type externalParametersV1 struct {
+	RunSpec struct {
+		PipelineRef  *TaskRef `json:"pipelineRef"`
+		TaskRef      *TaskRef `json:"taskRef"`
+		PipelineSpec *struct {
+			Tasks []struct {
+				Name    string   `json:"name"`
+				TaskRef *TaskRef `json:"taskRef"`
+			} `json:"tasks"`
+		} `json:"pipelineSpec"`
+	} `json:"runSpec"`
+}
+
+// ProvenanceFromEnvelope returns the provenance in the envelope.
+func ProvenanceFromEnvelope(env *dsselib.Envelope) (*Provenance, error) {
+	if env.PayloadType != intoto.PayloadType {
+		return nil, fmt.Errorf("%w: expected payload type %q, got %q",
+			serrors.ErrorInvalidDssePayload, intoto.PayloadType, env.PayloadType)
+	}
+	pyld, err := base64.StdEncoding.DecodeString(env.Payload)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", serrors.ErrorInvalidDssePayload, err)
+	}
+	return ProvenanceFromBytes(pyld)
+}
+
+// ProvenanceFromBytes returns the provenance in the in-toto statement.
+func ProvenanceFromBytes(payload []byte) (*Provenance, error) {
+	var header intoto.StatementHeader
+	if err := json.Unmarshal(payload, &header); err != nil {
+		return nil, fmt.Errorf("%w: decoding json: %w", serrors.ErrorInvalidDssePayload, err)
+	}
+	switch {
+	case header.Type == statementTypeV01 && header.PredicateType == slsa02.PredicateSLSAProvenance:
+		return provenanceFromV02(header, payload)
+	case header.Type == statementTypeV1 && header.PredicateType == intotov1.PredicateSLSAProvenance:
+		return provenanceFromV1(header, payload)
+	default:
+		return nil, fmt.Errorf("%w: unexpected statement type %q and predicate type %q",
+			serrors.ErrorInvalidDssePayload, header.Type, header.PredicateType)
+	}
+}
+
+func provenanceFromV02(header intoto.StatementHeader, payload []byte) (*Provenance, error) {
+	var statement struct {
+		Predicate slsa02.ProvenancePredicate `json:"predicate"`
+	}
+	if err := json.Unmarshal(payload, &statement); err != nil {
+		return nil, fmt.Errorf("%w: decoding json: %w", serrors.ErrorInvalidDssePayload, err)
+	}
+	predicate := statement.Predicate
+	if !slices.Contains(buildTypesV02, predicate.BuildType) {
+		return nil, fmt.Errorf("%w: %q", serrors.ErrorInvalidBuildType, predicate.BuildType)
+	}
+	prov := &Provenance{
+		StatementHeader: header,
+		BuilderID:       predicate.Builder.ID,
+		BuildType:       predicate.BuildType,
+	}
+
+	// Only the buildConfig of a PipelineRun has the refs of its tasks.
+	if strings.HasSuffix(predicate.BuildType, "/PipelineRun") {
+		prov.RefsRecorded = true
+		var buildConfig buildConfigV02
+		if err := remarshal(predicate.BuildConfig, &buildConfig); err != nil {
+			return nil, fmt.Errorf("%w: buildConfig: %w", serrors.ErrorInvalidDssePayload, err)
+		}
+		for _, task := range buildConfig.Tasks {
+			prov.Refs = append(prov.Refs, task.Ref)
+		}
+	}
+
+	for _, m := range predicate.Materials {
+		prov.Materials = append(prov.Materials, Material{URI: m.URI, Digest: m.Digest})
+	}
+	if source := predicate.Invocation.ConfigSource; source.URI != "" {
+		prov.Materials = append(prov.Materials, Material{URI: source.URI, Digest: source.Digest})
+	}
+	return prov, nil
+}
+
+func provenanceFromV1(header intoto.StatementHeader, payload []byte) (*Provenance, error) {
+	var statement struct {
+		Predicate intotov1.ProvenancePredicate `json:"predicate"`
+	}
+	if err := json.Unmarshal(payload, &statement); err != nil {
+		return nil, fmt.Errorf("%w: decoding json: %w", serrors.ErrorInvalidDssePayload, err)
+	}
+	buildDefinition := statement.Predicate.BuildDefinition
+	if !slices.Contains(buildTypesV1, buildDefinition.BuildType) {
+		return nil, fmt.Errorf("%w: %q", serrors.ErrorInvalidBuildType, buildDefinition.BuildType)
+	}
+	prov := &Provenance{
+		StatementHeader: header,
+		BuilderID:       statement.Predicate.RunDetails.Builder.ID,
+		BuildType:       buildDefinition.BuildType,
+		RefsRecorded:    true,
+	}
+
+	var externalParams externalParametersV1
+	if err := remarshal(buildDefinition.ExternalParameters, &externalParams); err != nil {
+		return nil, fmt.Errorf("%w: externalParameters: %w", serrors.ErrorInvalidDssePayload, err)
+	}
+	runSpec := externalParams.RunSpec
+	switch {
+	case runSpec.PipelineRef != nil:
+		prov.Refs = append(prov.Refs, runSpec.PipelineRef)
+	case runSpec.TaskRef != nil:
+		prov.Refs = append(prov.Refs, runSpec.TaskRef)
+	case runSpec.PipelineSpec != nil:
+		for _, task := range runSpec.PipelineSpec.Tasks {
+			prov.Refs = append(prov.Refs, task.TaskRef)
+		}
+	default:
+		// A TaskRun with an inline task spec.
+		prov.Refs = append(prov.Refs, nil)
+	}
+
+	for _, d := range buildDefinition.ResolvedDependencies {
+		prov.Materials = append(prov.Materials, Material{URI: d.URI, Digest: d.Digest})
+	}
+	return prov, nil
+}
+
+// remarshal decodes a field of the predicate that was decoded as an
+// interface into a struct.
+func remarshal(in, out interface{}) error {
+	if in == nil {
+		return nil
+	}
+	b, err := json.Marshal(in)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, out)
+}
+
+// verifyBuilderID verifies the builder ID in the provenance against the
+// builder ID provided by the caller, if any. The ID of the builder is
+// configured in Tekton Chains, and is https://tekton.dev/chains/v2 by
+// default.
+func verifyBuilderID(prov *Provenance, expectedID *string) (*utils.TrustedBuilderID, error) {
+	builderID, err := utils.TrustedBuilderIDNew(prov.BuilderID, false)
+	if err != nil {
+		return nil, err
+	}
+	if expectedID != nil && *expectedID != "" {
+		if err := builderID.MatchesLoose(*expectedID, false); err != nil {
+			return nil, err
+		}
+	}
+	return builderID, nil
+}
+
+// verifyTaskRefs verifies that each ref of the run is to a pipeline or a
+// task whose name matches one of the expected patterns. Inline specs do
+// not match any pattern.
+func verifyTaskRefs(prov *Provenance, patterns []string) error {
+	if len(patterns) == 0 {
+		return nil
+	}
+	if !prov.RefsRecorded {
+		return fmt.Errorf("%w: task refs of the SLSA v0.2 provenance of TaskRuns, which does not record them",
+			serrors.ErrorNotSupported)
+	}
+	if len(prov.Refs) == 0 {
+		return fmt.Errorf("%w: no task refs in the provenance", serrors.ErrorMismatchTaskRef)
+	}
+	for _, ref := range prov.Refs {
+		if ref == nil || ref.Name == "" {
+			return fmt.Errorf("%w: a task has no ref, expected one matching %q",
+				serrors.ErrorMismatchTaskRef, patterns)
+		}
+		ok, err := utils.MatchesAnyPattern(ref.Name, patterns)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return fmt.Errorf("expected task ref matching one of %q, got '%s': %w",
+				patterns, ref.Name, serrors.ErrorMismatchTaskRef)
+		}
+	}
+	return nil
+}
+
+// verifySource verifies that the run depended on the expected source
+// repository, at the expected commit if any, and returns the material of
+// the source.
+func verifySource(prov *Provenance, expectedSourceURI string, expectedCommit *string) (*Material, error) {
+	source := normalizeSourceURI(expectedSourceURI)
+	var found *Material
+	for i := range prov.Materials {
+		m := &prov.Materials[i]
+		if normalizeSourceURI(m.URI) != source {
+			continue
+		}
+		if expectedCommit == nil || m.commit() == *expectedCommit {
+			return m, nil
+		}
+		if found == nil {
+			found = m
+		}
+	}
+	if found != nil {
+		return nil, serrors.NewVerificationError(fmt.Errorf("%w: expected commit %q of source %q, got %q",
+			serrors.ErrorMismatchSource, *expectedCommit, source, found.commit()), *expectedCommit, found.commit())
+	}
+	return nil, serrors.NewVerificationError(fmt.Errorf("%w: expected source %q not found in the materials",
+		serrors.ErrorMismatchSource, source), source, "")
+}
+
+// normalizeSourceURI returns the URI of the repository, without the ref
+// and the .git suffix, e.g. https://github.com/org/repo for
+// git+https://github.com/org/repo.git@refs/heads/main.
+func normalizeSourceURI(uri string) string {
+	uri = strings.TrimPrefix(utils.NormalizeGitURI(uri), "git+")
+	if i := strings.LastIndex(uri, "@"); i > strings.Index(uri, "://")+len("://") {
+		uri = uri[:i]
+	}
+	return strings.TrimSuffix(uri, ".git")
+}
+
+// commit returns the git commit of a source material.
+func (m *Material) commit() string {
+	for _, algo := range []string{"sha1", "gitCommit"} {
+		if c, ok := m.Digest[algo]; ok {
+			return c
+		}
+	}
+	return ""
+}
@@ -0,0 +1,250 @@
+package tekton
+
+import (
+	"os"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+
+	serrors "github.com/slsa-framework/slsa-verifier/v2/errors"
+)
+
+func newTestProvenance(t *testing.T, path string) *Provenance {
+	t.Helper()
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	prov, err := ProvenanceFromBytes(content)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return prov
+}
+
+func Test_ProvenanceFromBytes(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		payload string
+		refs    []*TaskRef
+		err     error
+	}{
+		{
+			name: "SLSA v0.2 PipelineRun",
+			payload: `{"_type": "https://in-toto.io/Statement/v0.1", "predicateType": "https://slsa.dev/provenance/v0.2",
+				"predicate": {"buildType": "tekton.dev/v1/PipelineRun", "buildConfig": {"tasks": [
+					{"name": "clone", "ref": {"name": "git-clone", "kind": "Task"}}, {"name": "inline"}]}}}`,
+			refs: []*TaskRef{{Name: "git-clone", Kind: "Task"}, nil},
+		},
+		{
+			name: "SLSA v0.2 TaskRun",
+			payload: `{"_type": "https://in-toto.io/Statement/v0.1", "predicateType": "https://slsa.dev/provenance/v0.2",
+				"predicate": {"buildType": "https://chains.tekton.dev/format/slsa/v2alpha2/type/tekton.dev/v1beta1/TaskRun",
+					"buildConfig": {"steps": [{"entryPoint": "make"}]}}}`,
+		},
+		{
+			name: "SLSA v1.0 PipelineRun",
+			payload: `{"_type": "https://in-toto.io/Statement/v1", "predicateType": "https://slsa.dev/provenance/v1",
+				"predicate": {"buildDefinition": {"buildType": "https://tekton.dev/chains/v2/slsa-tekton",
+					"externalParameters": {"runSpec": {"pipelineRef": {"resolver": "git"}}}}}}`,
+			refs: []*TaskRef{{Resolver: "git"}},
+		},
+		{
+			name: "SLSA v1.0 inline PipelineRun",
+			payload: `{"_type": "https://in-toto.io/Statement/v1", "predicateType": "https://slsa.dev/provenance/v1",
+				"predicate": {"buildDefinition": {"buildType": "https://tekton.dev/chains/v2/slsa",
+					"externalParameters": {"runSpec": {"pipelineSpec": {"tasks": [
+						{"name": "build", "taskRef": {"name": "kaniko"}}]}}}}}}`,
+			refs: []*TaskRef{{Name: "kaniko"}},
+		},
+		{
+			name: "SLSA v1.0 inline TaskRun",
+			payload: `{"_type": "https://in-toto.io/Statement/v1", "predicateType": "https://slsa.dev/provenance/v1",
+				"predicate": {"buildDefinition": {"buildType": "https://tekton.dev/chains/v2/slsa",
+					"externalParameters": {"runSpec": {"taskSpec": {"steps": []}}}}}}`,
+			refs: []*TaskRef{nil},
+		},
+		{
+			name: "GitLab Runner provenance",
+			payload: `{"_type": "https://in-toto.io/Statement/v1", "predicateType": "https://slsa.dev/provenance/v1",
+				"predicate": {"buildDefinition": {"buildType": "https://gitlab.com/gitlab-org/gitlab-runner/-/blob/v17.5.0/PROVENANCE.md"}}}`,
+			err: serrors.ErrorInvalidBuildType,
+		},
+		{
+			name: "SLSA v0.2 predicate in a v1 statement",
+			payload: `{"_type": "https://in-toto.io/Statement/v1", "predicateType": "https://slsa.dev/provenance/v0.2",
+				"predicate": {"buildType": "tekton.dev/v1/TaskRun"}}`,
+			err: serrors.ErrorInvalidDssePayload,
+		},
+		{
+			name: "invalid buildConfig",
+			payload: `{"_type": "https://in-toto.io/Statement/v0.1", "predicateType": "https://slsa.dev/provenance/v0.2",
+				"predicate": {"buildType": "tekton.dev/v1/PipelineRun", "buildConfig": {"tasks": "clone"}}}`,
+			err: serrors.ErrorInvalidDssePayload,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			prov, err := ProvenanceFromBytes([]byte(tt.payload))
+			if diff := cmp.Diff(tt.err, err, cmpopts.EquateErrors()); diff != "" {
+				t.Fatalf("unexpected error (-want +got):\n%s", diff)
+			}
+			if err != nil {
+				return
+			}
+			if diff := cmp.Diff(tt.refs, prov.Refs); diff != "" {
+				t.Errorf("unexpected refs (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_verifyTaskRefs(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		refs        []*TaskRef
+		notRecorded bool
+		patterns    []string
+		err         error
+	}{
+		{
+			name: "no expected refs",
+			refs: []*TaskRef{nil},
+		},
+		{
+			name:     "matching refs",
+			refs:     []*TaskRef{{Name: "git-clone"}, {Name: "kaniko"}},
+			patterns: []string{"git-clone", "kaniko"},
+		},
+		{
+			name:     "matching pattern",
+			refs:     []*TaskRef{{Name: "build-go"}},
+			patterns: []string{"build-*"},
+		},
+		{
+			name:     "mismatched ref",
+			refs:     []*TaskRef{{Name: "git-clone"}, {Name: "curl"}},
+			patterns: []string{"git-clone", "kaniko"},
+			err:      serrors.ErrorMismatchTaskRef,
+		},
+		{
+			name:     "inline spec",
+			refs:     []*TaskRef{{Name: "git-clone"}, nil},
+			patterns: []string{"git-clone"},
+			err:      serrors.ErrorMismatchTaskRef,
+		},
+		{
+			name:     "resolver without name",
+			refs:     []*TaskRef{{Resolver: "git"}},
+			patterns: []string{"*"},
+			err:      serrors.ErrorMismatchTaskRef,
+		},
+		{
+			name:     "no refs",
+			patterns: []string{"*"},
+			err:      serrors.ErrorMismatchTaskRef,
+		},
+		{
+			name:        "refs not recorded",
+			notRecorded: true,
+			patterns:    []string{"*"},
+			err:         serrors.ErrorNotSupported,
+		},
+		{
+			name:        "refs not recorded and not expected",
+			notRecorded: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := verifyTaskRefs(&Provenance{Refs: tt.refs, RefsRecorded: !tt.notRecorded}, tt.patterns)
+			if diff := cmp.Diff(tt.err, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("unexpected error (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_verifySource(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name           string
+		path           string
+		sourceURI      string
+		expectedCommit *string
+		commit         string
+		err            error
+	}{
+		{
+			name:      "SLSA v0.2 materials",
+			path:      "testdata/pipelinerun.slsa02.json",
+			sourceURI: "github.com/example/app",
+			commit:    "9f2b3c4d5e6f708192a3b4c5d6e7f8091a2b3c4d",
+		},
+		{
+			name:      "SLSA v1.0 resolved dependencies",
+			path:      "testdata/taskrun.slsa1.json",
+			sourceURI: "https://github.com/example/app",
+			commit:    "9f2b3c4d5e6f708192a3b4c5d6e7f8091a2b3c4d",
+		},
+		{
+			name:      "git URI",
+			path:      "testdata/taskrun.slsa1.json",
+			sourceURI: "git+https://github.com/example/app.git",
+			commit:    "9f2b3c4d5e6f708192a3b4c5d6e7f8091a2b3c4d",
+		},
+		{
+			name:           "expected commit",
+			path:           "testdata/taskrun.slsa1.json",
+			sourceURI:      "github.com/example/app",
+			expectedCommit: asStringPointer("9f2b3c4d5e6f708192a3b4c5d6e7f8091a2b3c4d"),
+			commit:         "9f2b3c4d5e6f708192a3b4c5d6e7f8091a2b3c4d",
+		},
+		{
+			name:           "other commit",
+			path:           "testdata/pipelinerun.slsa02.json",
+			sourceURI:      "github.com/example/app",
+			expectedCommit: asStringPointer("0000000000000000000000000000000000000000"),
+			err:            serrors.ErrorMismatchSource,
+		},
+		{
+			name:      "other repository",
+			path:      "testdata/pipelinerun.slsa02.json",
+			sourceURI: "github.com/example/other",
+			err:       serrors.ErrorMismatchSource,
+		},
+		{
+			name:      "repository with the same prefix",
+			path:      "testdata/pipelinerun.slsa02.json",
+			sourceURI: "github.com/example/ap",
+			err:       serrors.ErrorMismatchSource,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			source, err := verifySource(newTestProvenance(t, tt.path), tt.sourceURI, tt.expectedCommit)
+			if diff := cmp.Diff(tt.err, err, cmpopts.EquateErrors()); diff != "" {
+				t.Fatalf("unexpected error (-want +got):\n%s", diff)
+			}
+			if err == nil && source.commit() != tt.commit {
+				t.Errorf("unexpected commit: want %q, got %q", tt.commit, source.commit())
+			}
+		})
+	}
+}
+
+func asStringPointer(s string) *string {
+	return &s
+}
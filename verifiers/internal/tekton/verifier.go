@@ -0,0 +1,244 @@
+package tekton
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	dsselib "github.com/secure-systems-lab/go-securesystemslib/dsse"
+	"github.com/sigstore/cosign/v2/pkg/cosign"
+	ociremote "github.com/sigstore/cosign/v2/pkg/oci/remote"
+	"github.com/sigstore/sigstore/pkg/signature"
+	sigstoreDSSE "github.com/sigstore/sigstore/pkg/signature/dsse"
+
+	serrors "github.com/slsa-framework/slsa-verifier/v2/errors"
+	"github.com/slsa-framework/slsa-verifier/v2/options"
+	"github.com/slsa-framework/slsa-verifier/v2/register"
+	"github.com/slsa-framework/slsa-verifier/v2/report"
+	"github.com/slsa-framework/slsa-verifier/v2/verifiers/utils"
+	"github.com/slsa-framework/slsa-verifier/v2/verifiers/utils/container"
+)
+
+// Tekton Chains signs the provenance of TaskRuns and PipelineRuns with a
+// key configured in the cluster, e.g. a cosign or a KMS key, instead of
+// with Sigstore keyless signing. The public key provided by the caller is
+// the root of trust in the builder.
+// See https://tekton.dev/docs/chains/.
+
+const VerifierName = "Tekton"
+
+// The default builder ID of Tekton Chains is https://tekton.dev/chains/v2.
+const builderIDPrefix = "https://tekton.dev/chains/"
+
+//nolint:gochecknoinits
+func init() {
+	register.RegisterVerifier(VerifierName, TektonVerifierNew())
+}
+
+type TektonVerifier struct{}
+
+func TektonVerifierNew() *TektonVerifier {
+	return &TektonVerifier{}
+}
+
+// IsAuthoritativeFor returns true of the verifier can verify provenance
+// generated by the builderID.
+func (v *TektonVerifier) IsAuthoritativeFor(builderID string) bool {
+	return strings.HasPrefix(builderID, builderIDPrefix)
+}
+
+// VerifyArtifact verifies provenance for an artifact.
+func (v *TektonVerifier) VerifyArtifact(ctx context.Context,
+	provenance []byte, artifactHash string,
+	provenanceOpts *options.ProvenanceOpts,
+	builderOpts *options.BuilderOpts,
+) ([]byte, *utils.TrustedBuilderID, error) {
+	return verifySignedEnvelope(ctx, provenance, provenanceOpts, builderOpts)
+}
+
+// VerifyImage verifies provenance for an OCI image.
+func (v *TektonVerifier) VerifyImage(ctx context.Context,
+	provenance []byte, artifactImage string,
+	provenanceOpts *options.ProvenanceOpts,
+	builderOpts *options.BuilderOpts,
+) ([]byte, *utils.TrustedBuilderID, error) {
+	// Provenance provided by the caller.
+	if provenance != nil {
+		return verifySignedEnvelope(ctx, provenance, provenanceOpts, builderOpts)
+	}
+
+	// Provenance attached to the image by Tekton Chains, with the OCI storage.
+	rep := report.FromContext(ctx)
+	verificationOpts := provenanceOpts.VerificationOpts
+	if verificationOpts == nil || verificationOpts.PublicKey == nil {
+		return nil, nil, rep.Check(report.CheckSignature,
+			fmt.Errorf("%w: Tekton provenance requires the public key of Tekton Chains", serrors.ErrorInvalidPublicKey))
+	}
+	sigVerifier, err := loadVerifier(verificationOpts)
+	if err != nil {
+		return nil, nil, err
+	}
+	registryClientOpts := []ociremote.Option{}
+	if provenanceOpts.ExpectedProvenanceRepository != nil {
+		repository, err := name.NewRepository(*provenanceOpts.ExpectedProvenanceRepository)
+		if err != nil {
+			return nil, nil, err
+		}
+		registryClientOpts = append(registryClientOpts, ociremote.WithTargetRepository(repository))
+	}
+	// Tekton Chains only uploads the signatures to a transparency log
+	// when configured to.
+	opts := &cosign.CheckOpts{
+		SigVerifier:        sigVerifier,
+		IgnoreTlog:         true,
+		IgnoreSCT:          true,
+		RegistryClientOpts: registryClientOpts,
+	}
+
+	atts, _, err := container.RunCosignImageVerification(ctx, artifactImage, opts)
+	if err := rep.Check(report.CheckSignature, err); err != nil {
+		return nil, nil, err
+	}
+
+	return utils.VerifyEach(ctx, container.CosignAttestations(ctx, atts),
+		func(ctx context.Context, att container.CosignAttestation) ([]byte, *utils.TrustedBuilderID, error) {
+			return verifyEnv(ctx, att.Envelope, provenanceOpts, builderOpts)
+		})
+}
+
+// VerifyNpmPackage verifies an npm package tarball.
+func (v *TektonVerifier) VerifyNpmPackage(ctx context.Context,
+	attestations []byte, tarballHash string,
+	provenanceOpts *options.ProvenanceOpts,
+	builderOpts *options.BuilderOpts,
+) ([]byte, *utils.TrustedBuilderID, error) {
+	return nil, nil, serrors.ErrorNotSupported
+}
+
+// verifySignedEnvelope verifies the signature of the DSSE envelope with
+// the public key, then the provenance in the envelope.
+func verifySignedEnvelope(ctx context.Context, provenance []byte,
+	provenanceOpts *options.ProvenanceOpts,
+	builderOpts *options.BuilderOpts,
+) ([]byte, *utils.TrustedBuilderID, error) {
+	rep := report.FromContext(ctx)
+	env, err := utils.EnvelopeFromBytes(provenance)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := rep.Check(report.CheckSignature, verifySignature(ctx, env, provenanceOpts.VerificationOpts)); err != nil {
+		return nil, nil, err
+	}
+	return verifyEnv(ctx, env, provenanceOpts, builderOpts)
+}
+
+// loadVerifier returns the verifier of the signatures of the public key
+// of Tekton Chains, with the hash algorithm of the options, or else the
+// default algorithm of the key.
+func loadVerifier(verificationOpts *options.VerificationOpts) (signature.Verifier, error) {
+	hashAlgo := verificationOpts.PublicKeyHashAlgo
+	if hashAlgo == 0 {
+		hashAlgo = utils.SignatureHashAlgo(verificationOpts.PublicKey)
+	}
+	verifier, err := signature.LoadVerifier(verificationOpts.PublicKey, hashAlgo)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", serrors.ErrorInvalidPublicKey, err)
+	}
+	return verifier, nil
+}
+
+// verifySignature verifies the signature of the envelope with the public
+// key of Tekton Chains.
+func verifySignature(ctx context.Context, env *dsselib.Envelope, verificationOpts *options.VerificationOpts) error {
+	if verificationOpts == nil || verificationOpts.PublicKey == nil {
+		return fmt.Errorf("%w: Tekton provenance requires the public key of Tekton Chains", serrors.ErrorInvalidPublicKey)
+	}
+	sigVerifier, err := loadVerifier(verificationOpts)
+	if err != nil {
+		return err
+	}
+	var keyID string
+	if verificationOpts.PublicKeyID != nil {
+		keyID = *verificationOpts.PublicKeyID
+	}
+	verifier, err := dsselib.NewEnvelopeVerifier(&sigstoreDSSE.VerifierAdapter{
+		SignatureVerifier: sigVerifier,
+		Pub:               verificationOpts.PublicKey,
+		PubKeyID:          keyID,
+	})
+	if err != nil {
+		return fmt.Errorf("%w: %w", serrors.ErrorInvalidPublicKey, err)
+	}
+	if _, err := verifier.Verify(ctx, env); err != nil {
+		return fmt.Errorf("%w: %w", serrors.ErrorNoValidSignature, err)
+	}
+	return nil
+}
+
+// verifyEnv verifies the provenance in the envelope, whose signature
+// is verified, against the options.
+func verifyEnv(ctx context.Context, env *dsselib.Envelope,
+	provenanceOpts *options.ProvenanceOpts,
+	builderOpts *options.BuilderOpts,
+) ([]byte, *utils.TrustedBuilderID, error) {
+	rep := report.FromContext(ctx)
+	prov, err := ProvenanceFromEnvelope(env)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Verify the builder.
+	builderID, err := verifyBuilderID(prov, builderOpts.ExpectedID)
+	if err := rep.Check(report.CheckBuilderID, err); err != nil {
+		return nil, nil, err
+	}
+	// The builder is trusted because the user trusts the key.
+	rep.SetBuilderTrust(report.BuilderTrustCustom)
+
+	// Verify the pipeline and task refs.
+	if err := rep.Check(report.CheckTaskRefs, verifyTaskRefs(prov, provenanceOpts.ExpectedTaskRefs)); err != nil {
+		return nil, nil, err
+	}
+
+	// Verify the source and its commit.
+	source, err := verifySource(prov, provenanceOpts.ExpectedSourceURI, provenanceOpts.ExpectedSourceCommit)
+	if err := rep.Check(report.CheckSourceURI, err); err != nil {
+		return nil, nil, err
+	}
+	rep.SetSource(normalizeSourceURI(source.URI), source.commit(), "")
+
+	// Verify subject digest.
+	if err := rep.Check(report.CheckSubjectDigest, utils.VerifySubjectDigest(prov.Subject, provenanceOpts.ExpectedDigest)); err != nil {
+		return nil, nil, err
+	}
+
+	// Tekton Chains does not record the ref of the source.
+	if provenanceOpts.ExpectedBranch != nil || len(provenanceOpts.ExpectedBranchPatterns) > 0 {
+		return nil, nil, rep.Check(report.CheckBranch,
+			fmt.Errorf("%w: branch of Tekton runs", serrors.ErrorNotSupported))
+	}
+	if provenanceOpts.ExpectedTag != nil || len(provenanceOpts.ExpectedTagPatterns) > 0 {
+		return nil, nil, rep.Check(report.CheckTag,
+			fmt.Errorf("%w: tag of Tekton runs", serrors.ErrorNotSupported))
+	}
+	if provenanceOpts.ExpectedVersionedTag != nil {
+		return nil, nil, rep.Check(report.CheckVersionedTag,
+			fmt.Errorf("%w: tag of Tekton runs", serrors.ErrorNotSupported))
+	}
+	if len(provenanceOpts.ExpectedWorkflowInputs) > 0 {
+		return nil, nil, rep.Check(report.CheckWorkflowInputs,
+			fmt.Errorf("%w: workflow inputs of Tekton runs", serrors.ErrorNotSupported))
+	}
+
+	utils.Logf(ctx, "Verified build using builder %q at commit %s\n",
+		builderID.String(),
+		source.commit())
+
+	r, err := base64.StdEncoding.DecodeString(env.Payload)
+	if err != nil {
+		return nil, nil, err
+	}
+	return r, builderID, nil
+}
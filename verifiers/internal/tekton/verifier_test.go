@@ -0,0 +1,291 @@
+package tekton
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	intoto "github.com/in-toto/in-toto-golang/in_toto"
+	dsselib "github.com/secure-systems-lab/go-securesystemslib/dsse"
+	"github.com/sigstore/sigstore/pkg/signature"
+
+	serrors "github.com/slsa-framework/slsa-verifier/v2/errors"
+	"github.com/slsa-framework/slsa-verifier/v2/options"
+	"github.com/slsa-framework/slsa-verifier/v2/report"
+)
+
+const (
+	testDigest    = "2c26b46b68ffc68ff99b453c1d30413413422d706483bfa0f98a5e886266e7ae"
+	testBuilderID = "https://tekton.dev/chains/v2"
+	testSourceURI = "github.com/example/app"
+)
+
+// newTestEnvelope returns the provenance at the path in an envelope
+// signed by the key, as Tekton Chains signs it.
+func newTestEnvelope(t *testing.T, path string, key *ecdsa.PrivateKey) []byte {
+	t.Helper()
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	digest := sha256.Sum256(dsselib.PAE(intoto.PayloadType, content))
+	sig, err := ecdsa.SignASN1(rand.Reader, key, digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	env, err := json.Marshal(&dsselib.Envelope{
+		PayloadType: intoto.PayloadType,
+		Payload:     base64.StdEncoding.EncodeToString(content),
+		Signatures:  []dsselib.Signature{{Sig: base64.StdEncoding.EncodeToString(sig)}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return env
+}
+
+func newTestKey(t *testing.T) *ecdsa.PrivateKey {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func Test_IsAuthoritativeFor(t *testing.T) {
+	t.Parallel()
+
+	v := TektonVerifierNew()
+	if !v.IsAuthoritativeFor(testBuilderID) {
+		t.Errorf("expected authoritative for %q", testBuilderID)
+	}
+	if v.IsAuthoritativeFor("https://cloudbuild.googleapis.com/GoogleHostedWorker") {
+		t.Errorf("expected not authoritative for GCB builders")
+	}
+}
+
+func Test_VerifyArtifact(t *testing.T) {
+	t.Parallel()
+
+	key := newTestKey(t)
+	otherKey := newTestKey(t)
+
+	tests := []struct {
+		name           string
+		path           string
+		signingKey     *ecdsa.PrivateKey
+		noPublicKey    bool
+		provenanceOpts *options.ProvenanceOpts
+		builderID      *string
+		err            error
+	}{
+		{
+			name: "SLSA v0.2 PipelineRun",
+			path: "testdata/pipelinerun.slsa02.json",
+			provenanceOpts: &options.ProvenanceOpts{
+				ExpectedDigest:    testDigest,
+				ExpectedSourceURI: testSourceURI,
+				ExpectedTaskRefs:  []string{"git-clone", "kaniko"},
+			},
+			builderID: asStringPointer(testBuilderID),
+		},
+		{
+			name: "SLSA v1.0 TaskRun",
+			path: "testdata/taskrun.slsa1.json",
+			provenanceOpts: &options.ProvenanceOpts{
+				ExpectedDigest:    testDigest,
+				ExpectedSourceURI: testSourceURI,
+				ExpectedTaskRefs:  []string{"buildah"},
+			},
+		},
+		{
+			name:       "signed by another key",
+			path:       "testdata/pipelinerun.slsa02.json",
+			signingKey: otherKey,
+			provenanceOpts: &options.ProvenanceOpts{
+				ExpectedDigest:    testDigest,
+				ExpectedSourceURI: testSourceURI,
+			},
+			err: serrors.ErrorNoValidSignature,
+		},
+		{
+			name:        "no public key",
+			path:        "testdata/pipelinerun.slsa02.json",
+			noPublicKey: true,
+			provenanceOpts: &options.ProvenanceOpts{
+				ExpectedDigest:    testDigest,
+				ExpectedSourceURI: testSourceURI,
+			},
+			err: serrors.ErrorInvalidPublicKey,
+		},
+		{
+			name: "mismatched builder ID",
+			path: "testdata/pipelinerun.slsa02.json",
+			provenanceOpts: &options.ProvenanceOpts{
+				ExpectedDigest:    testDigest,
+				ExpectedSourceURI: testSourceURI,
+			},
+			builderID: asStringPointer("https://tekton.dev/chains/v1"),
+			err:       serrors.ErrorMismatchBuilderID,
+		},
+		{
+			name: "mismatched task ref",
+			path: "testdata/pipelinerun.slsa02.json",
+			provenanceOpts: &options.ProvenanceOpts{
+				ExpectedDigest:    testDigest,
+				ExpectedSourceURI: testSourceURI,
+				ExpectedTaskRefs:  []string{"git-clone"},
+			},
+			err: serrors.ErrorMismatchTaskRef,
+		},
+		{
+			name: "mismatched source",
+			path: "testdata/taskrun.slsa1.json",
+			provenanceOpts: &options.ProvenanceOpts{
+				ExpectedDigest:    testDigest,
+				ExpectedSourceURI: "github.com/example/other",
+			},
+			err: serrors.ErrorMismatchSource,
+		},
+		{
+			name: "mismatched digest",
+			path: "testdata/taskrun.slsa1.json",
+			provenanceOpts: &options.ProvenanceOpts{
+				ExpectedDigest:    "0a2b4c6d8e0f1a3b5c7d9e1f2a4b6c8d0e2f4a6b8c0d2e4f6a8b0c2d4e6f8a0b",
+				ExpectedSourceURI: testSourceURI,
+			},
+			err: serrors.ErrorMismatchHash,
+		},
+		{
+			name: "branch",
+			path: "testdata/taskrun.slsa1.json",
+			provenanceOpts: &options.ProvenanceOpts{
+				ExpectedDigest:    testDigest,
+				ExpectedSourceURI: testSourceURI,
+				ExpectedBranch:    asStringPointer("main"),
+			},
+			err: serrors.ErrorNotSupported,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			signingKey := key
+			if tt.signingKey != nil {
+				signingKey = tt.signingKey
+			}
+			if !tt.noPublicKey {
+				tt.provenanceOpts.VerificationOpts = &options.VerificationOpts{PublicKey: &key.PublicKey}
+			}
+			rep := report.New("app", testDigest)
+			ctx := report.NewContext(context.Background(), rep)
+			_, builderID, err := TektonVerifierNew().VerifyArtifact(ctx, newTestEnvelope(t, tt.path, signingKey),
+				testDigest, tt.provenanceOpts, &options.BuilderOpts{ExpectedID: tt.builderID})
+			if diff := cmp.Diff(tt.err, err, cmpopts.EquateErrors()); diff != "" {
+				t.Fatalf("unexpected error (-want +got):\n%s", diff)
+			}
+			if err != nil {
+				return
+			}
+			if builderID.String() != testBuilderID {
+				t.Errorf("unexpected builder ID: %q", builderID.String())
+			}
+			if rep.BuilderTrust != report.BuilderTrustCustom {
+				t.Errorf("unexpected builder trust: %q", rep.BuilderTrust)
+			}
+			if rep.SourceURI != "https://github.com/example/app" {
+				t.Errorf("unexpected source URI: %q", rep.SourceURI)
+			}
+		})
+	}
+}
+
+func Test_verifySignature(t *testing.T) {
+	t.Parallel()
+
+	ecdsaKey, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, ed25519Key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		key        crypto.Signer
+		signHash   crypto.Hash
+		verifyHash crypto.Hash
+		err        error
+	}{
+		{
+			name:     "ECDSA P-384 with the default hash",
+			key:      ecdsaKey,
+			signHash: crypto.SHA384,
+		},
+		{
+			name:       "RSA with SHA-512",
+			key:        rsaKey,
+			signHash:   crypto.SHA512,
+			verifyHash: crypto.SHA512,
+		},
+		{
+			name:     "Ed25519",
+			key:      ed25519Key,
+			signHash: crypto.Hash(0),
+		},
+		{
+			name:       "other hash",
+			key:        rsaKey,
+			signHash:   crypto.SHA256,
+			verifyHash: crypto.SHA512,
+			err:        serrors.ErrorNoValidSignature,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			signer, err := signature.LoadSigner(tt.key, tt.signHash)
+			if err != nil {
+				t.Fatal(err)
+			}
+			payload := []byte(`{"_type": "https://in-toto.io/Statement/v1"}`)
+			sig, err := signer.SignMessage(bytes.NewReader(dsselib.PAE(intoto.PayloadType, payload)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			env := &dsselib.Envelope{
+				PayloadType: intoto.PayloadType,
+				Payload:     base64.StdEncoding.EncodeToString(payload),
+				Signatures:  []dsselib.Signature{{Sig: base64.StdEncoding.EncodeToString(sig)}},
+			}
+			err = verifySignature(context.Background(), env, &options.VerificationOpts{
+				PublicKey:         tt.key.Public(),
+				PublicKeyHashAlgo: tt.verifyHash,
+			})
+			if diff := cmp.Diff(tt.err, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("unexpected error (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
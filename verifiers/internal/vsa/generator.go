@@ -0,0 +1,122 @@
+package vsa
+
+import (
+	"context"
+	"crypto"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	intotoGolang "github.com/in-toto/in-toto-golang/in_toto"
+	intotoCommon "github.com/in-toto/in-toto-golang/in_toto/slsa_provenance/common"
+	"github.com/secure-systems-lab/go-securesystemslib/dsse"
+	sigstoreSignature "github.com/sigstore/sigstore/pkg/signature"
+	sigstoreDSSE "github.com/sigstore/sigstore/pkg/signature/dsse"
+	serrors "github.com/slsa-framework/slsa-verifier/v2/errors"
+	"github.com/slsa-framework/slsa-verifier/v2/options"
+	vsa10 "github.com/slsa-framework/slsa-verifier/v2/verifiers/internal/vsa/v1.0"
+)
+
+// GenerateVSA returns a DSSE envelope containing a VSA with a PASSED
+// verification result, signed with the private key of signingOpts.
+// See https://slsa.dev/spec/v1.0/verification_summary.
+func GenerateVSA(ctx context.Context,
+	vsaOpts *options.VSAGenerationOpts,
+	signingOpts *options.SigningOpts,
+) ([]byte, error) {
+	vsa, err := newVSA(vsaOpts, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	payload, err := json.Marshal(vsa)
+	if err != nil {
+		return nil, err
+	}
+	envelope, err := signEnvelope(ctx, payload, signingOpts)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(envelope)
+}
+
+// newVSA returns the VSA of a successful verification at timeVerified.
+func newVSA(vsaOpts *options.VSAGenerationOpts, timeVerified time.Time) (*vsa10.VSA, error) {
+	if len(vsaOpts.Subjects) == 0 {
+		return nil, fmt.Errorf("%w: no subjects", serrors.ErrorEmptyRequiredField)
+	}
+	if vsaOpts.VerifierID == "" {
+		return nil, fmt.Errorf("%w: no verifier ID", serrors.ErrorEmptyRequiredField)
+	}
+	if vsaOpts.ResourceURI == "" {
+		return nil, fmt.Errorf("%w: no resource URI", serrors.ErrorEmptyRequiredField)
+	}
+	if vsaOpts.Policy.URI == "" {
+		return nil, fmt.Errorf("%w: no policy", serrors.ErrorEmptyRequiredField)
+	}
+
+	subjects := make([]intotoGolang.Subject, len(vsaOpts.Subjects))
+	for i, subject := range vsaOpts.Subjects {
+		subjects[i] = intotoGolang.Subject{
+			Name:   subject.Name,
+			Digest: subject.Digest,
+		}
+	}
+	var inputAttestations []intotoCommon.ProvenanceMaterial
+	for _, attestation := range vsaOpts.InputAttestations {
+		inputAttestations = append(inputAttestations, toMaterial(attestation))
+	}
+
+	verifier := vsa10.Verifier{ID: vsaOpts.VerifierID}
+	if vsaOpts.VerifierVersion != "" {
+		verifier.Version = map[string]string{"slsa-verifier": vsaOpts.VerifierVersion}
+	}
+	return &vsa10.VSA{
+		StatementHeader: intotoGolang.StatementHeader{
+			Type:          vsa10.StatementType,
+			PredicateType: vsa10.PredicateType,
+			Subject:       subjects,
+		},
+		Predicate: vsa10.Predicate{
+			Verifier:           verifier,
+			TimeVerified:       timeVerified.UTC(),
+			ResourceURI:        vsaOpts.ResourceURI,
+			Policy:             toMaterial(vsaOpts.Policy),
+			InputAttestations:  inputAttestations,
+			VerificationResult: vsa10.VerificationResultPassed,
+			VerifiedLevels:     vsaOpts.VerifiedLevels,
+			SlsaVersion:        "1.0",
+		},
+	}, nil
+}
+
+func toMaterial(descriptor options.VSADescriptor) intotoCommon.ProvenanceMaterial {
+	return intotoCommon.ProvenanceMaterial{
+		URI:    descriptor.URI,
+		Digest: descriptor.Digest,
+	}
+}
+
+// signEnvelope signs the in-toto payload into a DSSE envelope.
+func signEnvelope(ctx context.Context, payload []byte, signingOpts *options.SigningOpts) (*dsse.Envelope, error) {
+	signer, err := sigstoreSignature.LoadSigner(signingOpts.PrivateKey, signingOpts.PrivateKeyHashAlgo)
+	if err != nil {
+		return nil, fmt.Errorf("%w: loading signer: %w", serrors.ErrorInvalidPrivateKey, err)
+	}
+	privateKey, ok := signingOpts.PrivateKey.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("%w: unsupported key type %T", serrors.ErrorInvalidPrivateKey, signingOpts.PrivateKey)
+	}
+	envelopeSigner, err := dsse.NewEnvelopeSigner(&sigstoreDSSE.SignerAdapter{
+		SignatureSigner: signer,
+		Pub:             privateKey.Public(),
+		PubKeyID:        signingOpts.PublicKeyID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: creating DSSE envelope signer: %w", serrors.ErrorInvalidPrivateKey, err)
+	}
+	envelope, err := envelopeSigner.SignPayload(ctx, intotoGolang.PayloadType, payload)
+	if err != nil {
+		return nil, fmt.Errorf("signing envelope: %w", err)
+	}
+	return envelope, nil
+}
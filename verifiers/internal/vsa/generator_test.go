@@ -0,0 +1,162 @@
+package vsa
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/secure-systems-lab/go-securesystemslib/dsse"
+
+	serrors "github.com/slsa-framework/slsa-verifier/v2/errors"
+	"github.com/slsa-framework/slsa-verifier/v2/options"
+)
+
+func newTestVSAGenerationOpts() *options.VSAGenerationOpts {
+	return &options.VSAGenerationOpts{
+		Subjects: []options.VSADescriptor{
+			{Name: "artifact", Digest: map[string]string{"sha256": "0a2b4c"}},
+		},
+		VerifierID:      "https://example.com/verifier",
+		VerifierVersion: "v2.7.0",
+		ResourceURI:     "https://example.com/deploy",
+		Policy: options.VSADescriptor{
+			URI:    "policy.yml",
+			Digest: map[string]string{"sha256": "1b3d5f"},
+		},
+		InputAttestations: []options.VSADescriptor{
+			{URI: "artifact.intoto.jsonl", Digest: map[string]string{"sha256": "2c4e6a"}},
+		},
+		VerifiedLevels: []string{"SLSA_BUILD_LEVEL_3"},
+	}
+}
+
+func Test_GenerateVSA(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyID, err := dsse.SHA256KeyID(privateKey.Public())
+	if err != nil {
+		t.Fatal(err)
+	}
+	signingOpts := &options.SigningOpts{
+		PrivateKey:         privateKey,
+		PublicKeyID:        keyID,
+		PrivateKeyHashAlgo: crypto.SHA256,
+	}
+
+	attestation, err := GenerateVSA(ctx, newTestVSAGenerationOpts(), signingOpts)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// The VSA must verify with the public key.
+	expectedDigests := []string{"sha256:0a2b4c"}
+	expectedVerifierID := "https://example.com/verifier"
+	expectedResourceURI := "https://example.com/deploy"
+	expectedLevels := []string{"SLSA_BUILD_LEVEL_2"}
+	_, err = VerifyVSA(ctx, attestation, &options.VSAOpts{
+		ExpectedDigests:        &expectedDigests,
+		ExpectedVerifierID:     &expectedVerifierID,
+		ExpectedResourceURI:    &expectedResourceURI,
+		ExpectedVerifiedLevels: &expectedLevels,
+	}, &options.VerificationOpts{
+		PublicKey:         privateKey.Public(),
+		PublicKeyID:       &keyID,
+		PublicKeyHashAlgo: crypto.SHA256,
+	})
+	if err != nil {
+		t.Fatalf("unexpected verification error: %v", err)
+	}
+
+	// But not with another key.
+	otherKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	emptyKeyID := ""
+	_, err = VerifyVSA(ctx, attestation, &options.VSAOpts{
+		ExpectedDigests:        &expectedDigests,
+		ExpectedVerifierID:     &expectedVerifierID,
+		ExpectedResourceURI:    &expectedResourceURI,
+		ExpectedVerifiedLevels: &expectedLevels,
+	}, &options.VerificationOpts{
+		PublicKey:         otherKey.Public(),
+		PublicKeyID:       &emptyKeyID,
+		PublicKeyHashAlgo: crypto.SHA256,
+	})
+	if diff := cmp.Diff(serrors.ErrorNoValidSignature, err, cmpopts.EquateErrors()); diff != "" {
+		t.Errorf("unexpected error (-want +got):\n%s", diff)
+	}
+}
+
+func Test_newVSA(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		mutate func(*options.VSAGenerationOpts)
+		err    error
+	}{
+		{
+			name: "all fields",
+		},
+		{
+			name: "no input attestations",
+			mutate: func(o *options.VSAGenerationOpts) {
+				o.InputAttestations = nil
+			},
+		},
+		{
+			name: "no subjects",
+			mutate: func(o *options.VSAGenerationOpts) {
+				o.Subjects = nil
+			},
+			err: serrors.ErrorEmptyRequiredField,
+		},
+		{
+			name: "no verifier ID",
+			mutate: func(o *options.VSAGenerationOpts) {
+				o.VerifierID = ""
+			},
+			err: serrors.ErrorEmptyRequiredField,
+		},
+		{
+			name: "no resource URI",
+			mutate: func(o *options.VSAGenerationOpts) {
+				o.ResourceURI = ""
+			},
+			err: serrors.ErrorEmptyRequiredField,
+		},
+		{
+			name: "no policy",
+			mutate: func(o *options.VSAGenerationOpts) {
+				o.Policy = options.VSADescriptor{}
+			},
+			err: serrors.ErrorEmptyRequiredField,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			vsaOpts := newTestVSAGenerationOpts()
+			if tt.mutate != nil {
+				tt.mutate(vsaOpts)
+			}
+			_, err := newVSA(vsaOpts, time.Now())
+			if diff := cmp.Diff(tt.err, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("unexpected error (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
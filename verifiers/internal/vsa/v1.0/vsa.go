@@ -10,7 +10,15 @@ import (
 	serrors "github.com/slsa-framework/slsa-verifier/v2/errors"
 )
 
-const PredicateType = "https://slsa.dev/verification_summary/v1"
+const (
+	PredicateType = "https://slsa.dev/verification_summary/v1"
+
+	// StatementType is the type of the in-toto statements of VSAs.
+	StatementType = "https://in-toto.io/Statement/v1"
+
+	// VerificationResultPassed is the result of a successful verification.
+	VerificationResultPassed = "PASSED"
+)
 
 // VSA is a struct that represents a VSA statement.
 // spec: https://slsa.dev/spec/v1.0/verification_summary.
@@ -28,10 +36,10 @@ type Predicate struct {
 	TimeVerified       time.Time                         `json:"timeVerified"`
 	ResourceURI        string                            `json:"resourceUri"`
 	Policy             intotoCommon.ProvenanceMaterial   `json:"policy"`
-	InputAttestations  []intotoCommon.ProvenanceMaterial `json:"inputAttestations"`
+	InputAttestations  []intotoCommon.ProvenanceMaterial `json:"inputAttestations,omitempty"`
 	VerificationResult string                            `json:"verificationResult"`
 	VerifiedLevels     []string                          `json:"verifiedLevels"`
-	DependecyLevels    map[string]int                    `json:"dependencyLevels"`
+	DependecyLevels    map[string]int                    `json:"dependencyLevels,omitempty"`
 	SlsaVersion        string                            `json:"slsaVersion"`
 }
 
@@ -11,6 +11,7 @@ import (
 	sigstoreDSSE "github.com/sigstore/sigstore/pkg/signature/dsse"
 	serrors "github.com/slsa-framework/slsa-verifier/v2/errors"
 	"github.com/slsa-framework/slsa-verifier/v2/options"
+	"github.com/slsa-framework/slsa-verifier/v2/report"
 	vsa10 "github.com/slsa-framework/slsa-verifier/v2/verifiers/internal/vsa/v1.0"
 	"github.com/slsa-framework/slsa-verifier/v2/verifiers/utils"
 )
@@ -32,10 +33,14 @@ func VerifyVSA(ctx context.Context,
 	// 1. verify the envelope signature,
 	// 4. match the verfier with the public key: implicit because we accept a user-provided public key.
 	// 3. parse the VSA, verifying the predicateType.
+	rep := report.FromContext(ctx)
+	stop := rep.Time(report.StageSignature)
 	vsa, err := extractSignedVSA(ctx, envelope, verificationOpts)
-	if err != nil {
+	stop()
+	if err := rep.Check(report.CheckSignature, err); err != nil {
 		return nil, err
 	}
+	defer rep.Time(report.StageProvenance)()
 
 	// 2. match the subject digests,
 	// 4. match the verifier ID,
@@ -43,7 +48,7 @@ func VerifyVSA(ctx context.Context,
 	// 6. confirm the slsaResult is PASSED,
 	// 7. match the verifiedLevels,
 	// no other fields are checked.
-	err = matchExpectedValues(vsa, vsaOpts)
+	err = matchExpectedValues(ctx, vsa, vsaOpts)
 	if err != nil {
 		return nil, err
 	}
@@ -96,25 +101,26 @@ func verifyEnvelopeSignature(ctx context.Context, envelope *dsse.Envelope, verif
 }
 
 // matchExpectedValues checks if the expected values are present in the VSA.
-func matchExpectedValues(vsa *vsa10.VSA, vsaOpts *options.VSAOpts) error {
+func matchExpectedValues(ctx context.Context, vsa *vsa10.VSA, vsaOpts *options.VSAOpts) error {
+	rep := report.FromContext(ctx)
 	// 2. match the expected subject digests
-	if err := matchExepectedSubjectDigests(vsa, vsaOpts); err != nil {
+	if err := rep.Check(report.CheckSubjectDigest, matchExepectedSubjectDigests(vsa, vsaOpts)); err != nil {
 		return err
 	}
 	// 4. match the verifier ID
-	if err := matchVerifierID(vsa, vsaOpts); err != nil {
+	if err := rep.Check(report.CheckVerifierID, matchVerifierID(vsa, vsaOpts)); err != nil {
 		return err
 	}
 	// 5. match the expected resourceURI
-	if err := matchResourceURI(vsa, vsaOpts); err != nil {
+	if err := rep.Check(report.CheckResourceURI, matchResourceURI(vsa, vsaOpts)); err != nil {
 		return err
 	}
 	// 6. confirm the verificationResult is Passed
-	if err := confirmVerificationResult(vsa); err != nil {
+	if err := rep.Check(report.CheckVerificationResult, confirmVerificationResult(vsa)); err != nil {
 		return err
 	}
 	// 7. match the verifiedLevels
-	if err := matchVerifiedLevels(vsa, vsaOpts); err != nil {
+	if err := rep.Check(report.CheckVerifiedLevels, matchVerifiedLevels(vsa, vsaOpts)); err != nil {
 		return err
 	}
 	return nil
@@ -190,7 +196,7 @@ func matchResourceURI(vsa *vsa10.VSA, vsaOpts *options.VSAOpts) error {
 
 // confirmVerificationResult checks that the policy verification result is "PASSED".
 func confirmVerificationResult(vsa *vsa10.VSA) error {
-	if vsa.Predicate.VerificationResult != "PASSED" {
+	if vsa.Predicate.VerificationResult != vsa10.VerificationResultPassed {
 		return fmt.Errorf("%w: verification result is not Passed: %s", serrors.ErrorInvalidVerificationResult, vsa.Predicate.VerificationResult)
 	}
 	return nil
@@ -471,7 +471,7 @@ func Test_matchExpectedValues(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			t.Parallel()
 
-			err := matchExpectedValues(tc.vsa, tc.opts)
+			err := matchExpectedValues(context.Background(), tc.vsa, tc.opts)
 			if diff := cmp.Diff(tc.err, err, cmpopts.EquateErrors()); diff != "" {
 				t.Errorf("unexpected error (-want +got): \n%s", diff)
 			}
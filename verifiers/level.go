@@ -0,0 +1,66 @@
+package verifiers
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/slsa-framework/slsa-verifier/v2/report"
+	ghacommon "github.com/slsa-framework/slsa-verifier/v2/verifiers/internal/gha/slsaprovenance/common"
+	"github.com/slsa-framework/slsa-verifier/v2/verifiers/utils"
+)
+
+const (
+	// BuildLevel2 is the SLSA build level of signed provenance.
+	BuildLevel2 = "SLSA_BUILD_LEVEL_2"
+	// BuildLevel3 is the SLSA build level of signed provenance generated
+	// by a builder isolated from the build.
+	BuildLevel3 = "SLSA_BUILD_LEVEL_3"
+)
+
+// buildLevel3Builders are the builders that reach SLSA build level 3 when
+// they are trusted by the GHA verifier, the only verifier that records
+// report.BuilderTrustBuiltIn. They are identified by their path, so that
+// they also match on GitHub Enterprise Server instances.
+var buildLevel3Builders = builderPaths(
+	ghacommon.GenericGeneratorBuilderID,
+	ghacommon.ContainerGeneratorBuilderID,
+	ghacommon.GoBuilderID,
+	ghacommon.ContainerBasedBuilderID,
+)
+
+// BuildLevel returns the SLSA build level reached by the artifact of a
+// passed report. Only the builders of an explicit allowlist reach level 3.
+// All other builders, e.g. custom builders, key-signed Tekton provenance,
+// Google Cloud Build or workflows that sign their own provenance, reach
+// level 2.
+func BuildLevel(rep *report.Report) string {
+	if rep.BuilderTrust != report.BuilderTrustBuiltIn {
+		return BuildLevel2
+	}
+	name, _, err := utils.ParseBuilderID(rep.BuilderID, false)
+	if err != nil {
+		return BuildLevel2
+	}
+	if buildLevel3Builders[builderPath(name)] {
+		return BuildLevel3
+	}
+	return BuildLevel2
+}
+
+func builderPaths(ids ...string) map[string]bool {
+	paths := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		paths[builderPath(id)] = true
+	}
+	return paths
+}
+
+// builderPath returns the path of a builder ID without its host, e.g.
+// slsa-framework/slsa-github-generator/.github/workflows/builder_go_slsa3.yml.
+func builderPath(id string) string {
+	u, err := url.Parse(id)
+	if err != nil || u.Host == "" {
+		return ""
+	}
+	return strings.TrimPrefix(u.Path, "/")
+}
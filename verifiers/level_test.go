@@ -0,0 +1,78 @@
+package verifiers
+
+import (
+	"testing"
+
+	"github.com/slsa-framework/slsa-verifier/v2/report"
+)
+
+func Test_BuildLevel(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		builderID string
+		trust     string
+		level     string
+	}{
+		{
+			name:      "generic generator",
+			builderID: "https://github.com/slsa-framework/slsa-github-generator/.github/workflows/generator_generic_slsa3.yml@refs/tags/v2.0.0",
+			trust:     report.BuilderTrustBuiltIn,
+			level:     BuildLevel3,
+		},
+		{
+			name:      "go builder on GitHub Enterprise Server",
+			builderID: "https://ghes.example.com/slsa-framework/slsa-github-generator/.github/workflows/builder_go_slsa3.yml@refs/tags/v2.0.0",
+			trust:     report.BuilderTrustBuiltIn,
+			level:     BuildLevel3,
+		},
+		{
+			name:      "delegated builder",
+			builderID: "https://github.com/org/builder/.github/workflows/builder.yml@refs/tags/v1.0.0",
+			trust:     report.BuilderTrustBuiltIn,
+			level:     BuildLevel2,
+		},
+		{
+			name:      "custom builder",
+			builderID: "https://github.com/org/generator/.github/workflows/generator_generic_slsa3.yml@refs/tags/v1.0.0",
+			trust:     report.BuilderTrustCustom,
+			level:     BuildLevel2,
+		},
+		{
+			name:      "custom copy of a generator",
+			builderID: "https://github.com/slsa-framework/slsa-github-generator/.github/workflows/generator_generic_slsa3.yml@refs/tags/v2.0.0",
+			trust:     report.BuilderTrustCustom,
+			level:     BuildLevel2,
+		},
+		{
+			name:      "tekton",
+			builderID: "https://tekton.dev/chains/v2",
+			trust:     report.BuilderTrustCustom,
+			level:     BuildLevel2,
+		},
+		{
+			name:      "google cloud build",
+			builderID: "https://cloudbuild.googleapis.com/GoogleHostedWorker@v0.3",
+			level:     BuildLevel2,
+		},
+		{
+			name:      "artifact attestation",
+			builderID: "https://github.com/org/repo/.github/workflows/release.yml@refs/tags/v1.0.0",
+			trust:     report.BuilderTrustWorkflow,
+			level:     BuildLevel2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			rep := report.New("artifact", "")
+			rep.SetBuilderTrust(tt.trust)
+			rep.Finish(tt.builderID, nil)
+			if got := BuildLevel(rep); got != tt.level {
+				t.Errorf("unexpected level: got %q, want %q", got, tt.level)
+			}
+		})
+	}
+}
@@ -0,0 +1,48 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+
+	serrors "github.com/slsa-framework/slsa-verifier/v2/errors"
+	"github.com/slsa-framework/slsa-verifier/v2/report"
+)
+
+// VerifyAttestationFn verifies a single attestation, and returns the
+// verified provenance and builder ID.
+type VerifyAttestationFn[T any] func(ctx context.Context, att T) ([]byte, *TrustedBuilderID, error)
+
+// VerifyEach verifies the attestations in turn until one verifies. It
+// returns the first error if none verifies, or ErrorNoValidSignature if
+// there are none. The checks of each attestation are recorded separately,
+// and only those of the attestation that verified, or else of the first
+// failure, are kept in the report of ctx.
+func VerifyEach[T any](ctx context.Context, atts []T, verify VerifyAttestationFn[T]) (
+	[]byte, *TrustedBuilderID, error,
+) {
+	rep := report.FromContext(ctx)
+	var errs []error
+	var failed *report.Report
+	for _, att := range atts {
+		child := rep.Child()
+		verifiedProvenance, builderID, err := verify(report.NewContext(ctx, child), att)
+		if err == nil {
+			rep.Merge(child)
+			return verifiedProvenance, builderID, nil
+		}
+		if failed == nil {
+			failed = child
+		}
+		errs = append(errs, err)
+	}
+	rep.Merge(failed)
+
+	if len(errs) > 0 {
+		var s string
+		if len(errs) > 1 {
+			s = fmt.Sprintf(": %v", errs[1:])
+		}
+		return nil, nil, fmt.Errorf("%w%s", errs[0], s)
+	}
+	return nil, nil, fmt.Errorf("%w", serrors.ErrorNoValidSignature)
+}
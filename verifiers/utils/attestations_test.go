@@ -0,0 +1,65 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	serrors "github.com/slsa-framework/slsa-verifier/v2/errors"
+	"github.com/slsa-framework/slsa-verifier/v2/report"
+)
+
+func Test_VerifyEach(t *testing.T) {
+	t.Parallel()
+
+	// An attestation verifies if it has no error.
+	verify := func(ctx context.Context, err error) ([]byte, *TrustedBuilderID, error) {
+		rep := report.FromContext(ctx)
+		if err := rep.Check(report.CheckSignature, err); err != nil {
+			return nil, nil, err
+		}
+		return []byte("provenance"), nil, nil
+	}
+	tests := []struct {
+		name   string
+		atts   []error
+		err    error
+		checks []report.Check
+	}{
+		{
+			name:   "second verifies",
+			atts:   []error{serrors.ErrorMismatchBuilderID, nil},
+			checks: []report.Check{{Name: report.CheckSignature, Status: report.StatusPassed}},
+		},
+		{
+			name:   "none verifies",
+			atts:   []error{serrors.ErrorMismatchBuilderID, serrors.ErrorMismatchSource},
+			err:    serrors.ErrorMismatchBuilderID,
+			checks: []report.Check{{Name: report.CheckSignature, Status: report.StatusFailed}},
+		},
+		{
+			name:   "no attestations",
+			err:    serrors.ErrorNoValidSignature,
+			checks: []report.Check{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			rep := report.New("artifact", "")
+			provenance, _, err := VerifyEach(report.NewContext(context.Background(), rep), tt.atts, verify)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("unexpected error: got %v, want %v", err, tt.err)
+			}
+			if err == nil && string(provenance) != "provenance" {
+				t.Errorf("unexpected provenance: %q", provenance)
+			}
+			if diff := cmp.Diff(tt.checks, rep.Checks); diff != "" {
+				t.Errorf("unexpected checks (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
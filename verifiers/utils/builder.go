@@ -41,8 +41,8 @@ func (b *TrustedBuilderID) MatchesLoose(builderID string, allowRef bool) error {
 	}
 
 	if name != b.name {
-		return fmt.Errorf("%w: expected name '%s', got '%s'", serrors.ErrorMismatchBuilderID,
-			b.name, name)
+		return serrors.NewVerificationError(fmt.Errorf("%w: expected name '%s', got '%s'", serrors.ErrorMismatchBuilderID,
+			b.name, name), name, b.name)
 	}
 
 	if version != "" && version != b.version {
@@ -51,8 +51,8 @@ func (b *TrustedBuilderID) MatchesLoose(builderID string, allowRef bool) error {
 			"refs/tags/"+version == b.version {
 			return nil
 		}
-		return fmt.Errorf("%w: expected version '%s', got '%s'", serrors.ErrorMismatchBuilderID,
-			version, b.version)
+		return serrors.NewVerificationError(fmt.Errorf("%w: expected version '%s', got '%s'", serrors.ErrorMismatchBuilderID,
+			version, b.version), version, b.version)
 	}
 
 	return nil
@@ -67,8 +67,8 @@ func (b *TrustedBuilderID) MatchesFull(builderID string, allowRef bool) error {
 	}
 
 	if name != b.name {
-		return fmt.Errorf("%w: expected name '%s', got '%s'", serrors.ErrorMismatchBuilderID,
-			b.name, name)
+		return serrors.NewVerificationError(fmt.Errorf("%w: expected name '%s', got '%s'", serrors.ErrorMismatchBuilderID,
+			b.name, name), name, b.name)
 	}
 
 	if version != b.version {
@@ -77,8 +77,8 @@ func (b *TrustedBuilderID) MatchesFull(builderID string, allowRef bool) error {
 			"refs/tags/"+version == b.version {
 			return nil
 		}
-		return fmt.Errorf("%w: expected version '%s', got '%s'", serrors.ErrorMismatchBuilderID,
-			version, b.version)
+		return serrors.NewVerificationError(fmt.Errorf("%w: expected version '%s', got '%s'", serrors.ErrorMismatchBuilderID,
+			version, b.version), version, b.version)
 	}
 
 	return nil
@@ -0,0 +1,102 @@
+package utils
+
+import (
+	"sort"
+	"sync"
+	"sync/atomic"
+)
+
+// KeyedCache caches one value per key, e.g. one client per Sigstore
+// deployment. The value of a key is initialized at most once at a time.
+// Initialization errors are not cached: the next Get retries.
+// The zero value is ready to use.
+type KeyedCache[T any] struct {
+	mu      sync.Mutex
+	entries map[string]*keyedCacheEntry[T]
+
+	hits   atomic.Uint64
+	misses atomic.Uint64
+}
+
+type keyedCacheEntry[T any] struct {
+	once  sync.Once
+	value T
+	err   error
+}
+
+// Get returns the value cached for key, calling init to create it if needed.
+func (c *KeyedCache[T]) Get(key string, init func() (T, error)) (T, error) {
+	c.mu.Lock()
+	if c.entries == nil {
+		c.entries = make(map[string]*keyedCacheEntry[T])
+	}
+	e, ok := c.entries[key]
+	if !ok {
+		e = &keyedCacheEntry[T]{}
+		c.entries[key] = e
+	}
+	c.mu.Unlock()
+
+	initialized := false
+	e.once.Do(func() {
+		initialized = true
+		e.value, e.err = init()
+	})
+	if initialized {
+		c.misses.Add(1)
+	} else {
+		c.hits.Add(1)
+	}
+	if e.err != nil {
+		// Reinitialize upon error.
+		c.mu.Lock()
+		if c.entries[key] == e {
+			delete(c.entries, key)
+		}
+		c.mu.Unlock()
+		var zero T
+		return zero, e.err
+	}
+	return e.value, nil
+}
+
+// CacheStats counts the lookups of a cache. A lookup that initializes
+// the value of its key is a miss.
+type CacheStats struct {
+	Name   string
+	Hits   uint64
+	Misses uint64
+}
+
+// Stats returns the numbers of hits and misses of the cache.
+func (c *KeyedCache[T]) Stats() CacheStats {
+	return CacheStats{Hits: c.hits.Load(), Misses: c.misses.Load()}
+}
+
+var (
+	cachesMu sync.Mutex
+	caches   = map[string]interface{ Stats() CacheStats }{}
+)
+
+// RegisterCache makes the statistics of the cache available, under the
+// given name, to AllCacheStats.
+func RegisterCache(name string, c interface{ Stats() CacheStats }) {
+	cachesMu.Lock()
+	defer cachesMu.Unlock()
+	caches[name] = c
+}
+
+// AllCacheStats returns the statistics of the registered caches, sorted
+// by name. Services export them as metrics.
+func AllCacheStats() []CacheStats {
+	cachesMu.Lock()
+	defer cachesMu.Unlock()
+	stats := make([]CacheStats, 0, len(caches))
+	for name, c := range caches {
+		s := c.Stats()
+		s.Name = name
+		stats = append(stats, s)
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Name < stats[j].Name })
+	return stats
+}
@@ -0,0 +1,60 @@
+package utils
+
+import (
+	"errors"
+	"sync"
+	"testing"
+)
+
+func Test_KeyedCache(t *testing.T) {
+	t.Parallel()
+
+	var c KeyedCache[int]
+	var mu sync.Mutex
+	calls := map[string]int{}
+	get := func(key string, err error) (int, error) {
+		return c.Get(key, func() (int, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			calls[key]++
+			return calls[key], err
+		})
+	}
+
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if v, err := get("a", nil); err != nil || v != 1 {
+				t.Errorf("expected 1, got %d, %v", v, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if v, err := get("b", nil); err != nil || v != 1 {
+		t.Errorf("expected a separate value for another key, got %d, %v", v, err)
+	}
+
+	errInit := errors.New("init")
+	if _, err := get("c", errInit); !errors.Is(err, errInit) {
+		t.Errorf("expected %v, got %v", errInit, err)
+	}
+	if v, err := get("c", nil); err != nil || v != 2 {
+		t.Errorf("expected errors not to be cached, got %d, %v", v, err)
+	}
+
+	// The lookups of "a" but the first are hits.
+	if s := c.Stats(); s.Hits != 9 || s.Misses != 4 {
+		t.Errorf("unexpected stats: %+v", s)
+	}
+	RegisterCache("test", &c)
+	found := false
+	for _, s := range AllCacheStats() {
+		found = found || (s.Name == "test" && s.Hits == 9)
+	}
+	if !found {
+		t.Errorf("cache not registered: %+v", AllCacheStats())
+	}
+}
@@ -0,0 +1,145 @@
+package utils
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	intoto "github.com/in-toto/in-toto-golang/in_toto"
+	serrors "github.com/slsa-framework/slsa-verifier/v2/errors"
+)
+
+// Checksum is an entry of a checksums manifest.
+type Checksum struct {
+	// Name is the name of the file.
+	Name string
+
+	// Algorithm is the digest algorithm, sha256 or sha512.
+	Algorithm string
+
+	// Digest is the hex-encoded digest.
+	Digest string
+}
+
+var (
+	// gnuChecksumLine is a line of the default output of sha256sum and
+	// sha512sum. A '*' before the name marks binary mode.
+	gnuChecksumLine = regexp.MustCompile(`^([0-9a-fA-F]+) [ *](.+)$`)
+	// bsdChecksumLine is a line of the output of sha256sum --tag.
+	bsdChecksumLine = regexp.MustCompile(`^(SHA256|SHA512) \((.+)\) = ([0-9a-fA-F]+)$`)
+)
+
+// ParseChecksums parses a checksums manifest in the formats of sha256sum
+// and sha512sum, with or without --tag. The algorithm of untagged lines is
+// derived from the length of the digest.
+func ParseChecksums(r io.Reader) ([]Checksum, error) {
+	var checksums []Checksum
+	names := make(map[string]bool)
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSuffix(scanner.Text(), "\r")
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		var c Checksum
+		if m := bsdChecksumLine.FindStringSubmatch(line); m != nil {
+			c = Checksum{Name: m[2], Algorithm: strings.ToLower(m[1]), Digest: strings.ToLower(m[3])}
+		} else if m := gnuChecksumLine.FindStringSubmatch(line); m != nil {
+			c = Checksum{Name: m[2], Digest: strings.ToLower(m[1])}
+			c.Algorithm = checksumAlgorithm(c.Digest)
+		} else {
+			return nil, fmt.Errorf("%w: line %d: unknown format", serrors.ErrorInvalidChecksums, n)
+		}
+		if c.Algorithm == "" || checksumAlgorithm(c.Digest) != c.Algorithm {
+			return nil, fmt.Errorf("%w: line %d: invalid digest length %d", serrors.ErrorInvalidChecksums,
+				n, len(c.Digest))
+		}
+		// The names are resolved against the directory of the files to
+		// verify, so they must not escape it.
+		if !filepath.IsLocal(c.Name) {
+			return nil, fmt.Errorf("%w: line %d: name %q is not a local path", serrors.ErrorInvalidChecksums,
+				n, c.Name)
+		}
+		c.Name = path.Clean(c.Name)
+		if names[c.Name] {
+			return nil, fmt.Errorf("%w: line %d: duplicate name %q", serrors.ErrorInvalidChecksums, n, c.Name)
+		}
+		names[c.Name] = true
+		checksums = append(checksums, c)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %w", serrors.ErrorInvalidChecksums, err)
+	}
+	if len(checksums) == 0 {
+		return nil, fmt.Errorf("%w: no entries", serrors.ErrorInvalidChecksums)
+	}
+	return checksums, nil
+}
+
+// checksumAlgorithm returns the algorithm of a hex-encoded digest, or an
+// empty string if the length is not supported.
+func checksumAlgorithm(digest string) string {
+	switch len(digest) {
+	case 64:
+		return "sha256"
+	case 128:
+		return "sha512"
+	default:
+		return ""
+	}
+}
+
+// ChecksumsComparison is the comparison of the entries of a checksums
+// manifest with the subjects of a provenance, by name.
+type ChecksumsComparison struct {
+	// Missing are the entries that are not subjects.
+	Missing []string
+
+	// Mismatched are the entries whose digest differs from the subject's.
+	Mismatched []string
+
+	// Extra are the subjects that are not entries.
+	Extra []string
+}
+
+// VerifyChecksums verifies that every entry of a checksums manifest is a
+// subject with the same name and digest. Subjects that are not entries are
+// reported as extra, but are not an error.
+func VerifyChecksums(checksums []Checksum, subjects []intoto.Subject) (*ChecksumsComparison, error) {
+	comparison := &ChecksumsComparison{}
+	subjectsByName := make(map[string]intoto.Subject, len(subjects))
+	for _, s := range subjects {
+		subjectsByName[path.Clean(s.Name)] = s
+	}
+	entries := make(map[string]bool, len(checksums))
+	for _, c := range checksums {
+		entries[c.Name] = true
+		subject, ok := subjectsByName[c.Name]
+		if !ok {
+			comparison.Missing = append(comparison.Missing, c.Name)
+			continue
+		}
+		if !strings.EqualFold(subject.Digest[c.Algorithm], c.Digest) {
+			comparison.Mismatched = append(comparison.Mismatched, c.Name)
+		}
+	}
+	for _, s := range subjects {
+		if !entries[path.Clean(s.Name)] {
+			comparison.Extra = append(comparison.Extra, s.Name)
+		}
+	}
+
+	if len(comparison.Missing) > 0 {
+		return comparison, fmt.Errorf("%w: entries not in provenance: %q",
+			serrors.ErrorMissingSubjectDigest, comparison.Missing)
+	}
+	if len(comparison.Mismatched) > 0 {
+		return comparison, fmt.Errorf("%w: entries with a different digest in provenance: %q",
+			serrors.ErrorMismatchHash, comparison.Mismatched)
+	}
+	return comparison, nil
+}
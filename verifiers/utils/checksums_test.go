@@ -0,0 +1,170 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	intoto "github.com/in-toto/in-toto-golang/in_toto"
+	serrors "github.com/slsa-framework/slsa-verifier/v2/errors"
+)
+
+var (
+	testSHA256 = strings.Repeat("0a", 32)
+	testSHA512 = strings.Repeat("1b", 64)
+)
+
+func Test_ParseChecksums(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		manifest string
+		expected []Checksum
+		err      error
+	}{
+		{
+			name:     "sha256sum",
+			manifest: testSHA256 + "  artifact-linux-amd64\n" + testSHA256 + " *./artifact-darwin-amd64\n",
+			expected: []Checksum{
+				{Name: "artifact-linux-amd64", Algorithm: "sha256", Digest: testSHA256},
+				{Name: "artifact-darwin-amd64", Algorithm: "sha256", Digest: testSHA256},
+			},
+		},
+		{
+			name:     "sha512sum with empty lines",
+			manifest: "\n" + strings.ToUpper(testSHA512) + "  artifact\r\n\n",
+			expected: []Checksum{
+				{Name: "artifact", Algorithm: "sha512", Digest: testSHA512},
+			},
+		},
+		{
+			name:     "tagged",
+			manifest: "SHA512 (dist/artifact) = " + testSHA512 + "\n",
+			expected: []Checksum{
+				{Name: "dist/artifact", Algorithm: "sha512", Digest: testSHA512},
+			},
+		},
+		{
+			name:     "tag does not match digest length",
+			manifest: "SHA256 (artifact) = " + testSHA512 + "\n",
+			err:      serrors.ErrorInvalidChecksums,
+		},
+		{
+			name:     "unsupported digest length",
+			manifest: "0a2b4c  artifact\n",
+			err:      serrors.ErrorInvalidChecksums,
+		},
+		{
+			name:     "unknown format",
+			manifest: "artifact: " + testSHA256 + "\n",
+			err:      serrors.ErrorInvalidChecksums,
+		},
+		{
+			name:     "duplicate name",
+			manifest: testSHA256 + "  artifact\n" + testSHA256 + "  ./artifact\n",
+			err:      serrors.ErrorInvalidChecksums,
+		},
+		{
+			name:     "absolute name",
+			manifest: testSHA256 + "  /etc/passwd\n",
+			err:      serrors.ErrorInvalidChecksums,
+		},
+		{
+			name:     "name outside the directory",
+			manifest: "SHA256 (dist/../../artifact) = " + testSHA256 + "\n",
+			err:      serrors.ErrorInvalidChecksums,
+		},
+		{
+			name:     "empty",
+			manifest: "\n",
+			err:      serrors.ErrorInvalidChecksums,
+		},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			checksums, err := ParseChecksums(strings.NewReader(tt.manifest))
+			if diff := cmp.Diff(tt.err, err, cmpopts.EquateErrors()); diff != "" {
+				t.Fatalf("unexpected error (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tt.expected, checksums); diff != "" {
+				t.Errorf("unexpected checksums (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_VerifyChecksums(t *testing.T) {
+	t.Parallel()
+
+	subjects := []intoto.Subject{
+		{Name: "artifact-linux-amd64", Digest: map[string]string{"sha256": testSHA256}},
+		{Name: "./artifact-darwin-amd64", Digest: map[string]string{"sha256": testSHA256, "sha512": testSHA512}},
+		{Name: "checksums.txt", Digest: map[string]string{"sha256": testSHA256}},
+	}
+	testCases := []struct {
+		name      string
+		checksums []Checksum
+		expected  *ChecksumsComparison
+		err       error
+	}{
+		{
+			name: "all entries match",
+			checksums: []Checksum{
+				{Name: "artifact-linux-amd64", Algorithm: "sha256", Digest: testSHA256},
+				{Name: "artifact-darwin-amd64", Algorithm: "sha512", Digest: testSHA512},
+			},
+			expected: &ChecksumsComparison{Extra: []string{"checksums.txt"}},
+		},
+		{
+			name: "missing entry",
+			checksums: []Checksum{
+				{Name: "artifact-linux-amd64", Algorithm: "sha256", Digest: testSHA256},
+				{Name: "artifact-windows-amd64", Algorithm: "sha256", Digest: testSHA256},
+			},
+			expected: &ChecksumsComparison{
+				Missing: []string{"artifact-windows-amd64"},
+				Extra:   []string{"./artifact-darwin-amd64", "checksums.txt"},
+			},
+			err: serrors.ErrorMissingSubjectDigest,
+		},
+		{
+			name: "mismatched digest",
+			checksums: []Checksum{
+				{Name: "artifact-linux-amd64", Algorithm: "sha256", Digest: strings.Repeat("ff", 32)},
+			},
+			expected: &ChecksumsComparison{
+				Mismatched: []string{"artifact-linux-amd64"},
+				Extra:      []string{"./artifact-darwin-amd64", "checksums.txt"},
+			},
+			err: serrors.ErrorMismatchHash,
+		},
+		{
+			name: "no digest for algorithm",
+			checksums: []Checksum{
+				{Name: "artifact-linux-amd64", Algorithm: "sha512", Digest: testSHA512},
+			},
+			expected: &ChecksumsComparison{
+				Mismatched: []string{"artifact-linux-amd64"},
+				Extra:      []string{"./artifact-darwin-amd64", "checksums.txt"},
+			},
+			err: serrors.ErrorMismatchHash,
+		},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			comparison, err := VerifyChecksums(tt.checksums, subjects)
+			if diff := cmp.Diff(tt.err, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("unexpected error (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tt.expected, comparison); diff != "" {
+				t.Errorf("unexpected comparison (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
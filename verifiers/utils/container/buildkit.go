@@ -0,0 +1,173 @@
+package container
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"slices"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	crname "github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+
+	serrors "github.com/slsa-framework/slsa-verifier/v2/errors"
+)
+
+// Docker BuildKit stores the attestations of the images of an image index
+// in attestation manifests of the index, one for each platform image,
+// rather than in cosign tags. Attestation manifests may be signed with
+// Sigstore bundles attached to them as OCI referrers, see referrers.go.
+// See https://docs.docker.com/build/metadata/attestations/attestation-storage/.
+
+const (
+	referenceTypeAnnotation   = "vnd.docker.reference.type"
+	referenceDigestAnnotation = "vnd.docker.reference.digest"
+	attestationManifestType   = "attestation-manifest"
+	predicateTypeAnnotation   = "in-toto.io/predicate-type"
+)
+
+// BuildKitAttestation is an in-toto statement of a BuildKit attestation manifest.
+type BuildKitAttestation struct {
+	// ImageDigest is the digest of the platform image the statement is about.
+	ImageDigest string
+	// Platform is the platform of the image, e.g. linux/amd64.
+	Platform string
+	// Statement is the in-toto statement.
+	Statement []byte
+	// Bundles are the Sigstore bundles attached to the attestation manifest.
+	Bundles [][]byte
+}
+
+// FetchBuildKitAttestations returns the in-toto statements with one of the
+// predicate types in the attestation manifests of the image index. It returns
+// no attestations if the image is not an index. The attestations of a local
+// image are read from its layout.
+var FetchBuildKitAttestations = func(ctx context.Context, image string, predicateTypes []string) (
+	[]*BuildKitAttestation, error,
+) {
+	if IsLocalImage(image) {
+		img, err := openLocalImage(image)
+		if err != nil {
+			return nil, err
+		}
+		index, err := img.imageIndex()
+		if err != nil || index == nil {
+			return nil, err
+		}
+		return buildKitAttestations(index, predicateTypes, img.sigstoreBundles)
+	}
+
+	ref, err := crname.ParseReference(image)
+	if err != nil {
+		return nil, fmt.Errorf("crane.ParseReference(): %w", err)
+	}
+	opts := []remote.Option{
+		remote.WithContext(ctx),
+		remote.WithAuthFromKeychain(authn.DefaultKeychain),
+	}
+	desc, err := remote.Get(ref, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", serrors.ErrorImageHash, err)
+	}
+	if !desc.MediaType.IsIndex() {
+		return nil, nil
+	}
+	index, err := desc.ImageIndex()
+	if err != nil {
+		return nil, err
+	}
+	return buildKitAttestations(index, predicateTypes, func(digest v1.Hash) ([][]byte, error) {
+		return fetchSigstoreBundles(ref.Context().Digest(digest.String()), opts)
+	})
+}
+
+// buildKitAttestations returns the in-toto statements with one of the
+// predicate types in the attestation manifests of the image index.
+// fetchBundles returns the Sigstore bundles attached to a manifest.
+func buildKitAttestations(index v1.ImageIndex, predicateTypes []string,
+	fetchBundles func(v1.Hash) ([][]byte, error),
+) ([]*BuildKitAttestation, error) {
+	manifest, err := index.IndexManifest()
+	if err != nil {
+		return nil, err
+	}
+
+	// The platform images of the index.
+	platforms := make(map[string]string)
+	for _, image := range platformImages(manifest) {
+		platforms[image.Digest] = image.Platform
+	}
+
+	var atts []*BuildKitAttestation
+	for _, m := range manifest.Manifests {
+		if m.Annotations[referenceTypeAnnotation] != attestationManifestType {
+			continue
+		}
+		// Only keep the attestations of the images of the index.
+		imageDigest := m.Annotations[referenceDigestAnnotation]
+		platform, ok := platforms[imageDigest]
+		if !ok {
+			continue
+		}
+		statements, err := fetchStatements(index, m.Digest, predicateTypes)
+		if err != nil {
+			return nil, err
+		}
+		if len(statements) == 0 {
+			continue
+		}
+		bundles, err := fetchBundles(m.Digest)
+		if err != nil {
+			return nil, err
+		}
+		for _, statement := range statements {
+			atts = append(atts, &BuildKitAttestation{
+				ImageDigest: imageDigest,
+				Platform:    platform,
+				Statement:   statement,
+				Bundles:     bundles,
+			})
+		}
+	}
+	return atts, nil
+}
+
+// fetchStatements returns the in-toto statements with one of the predicate
+// types in the layers of the attestation manifest.
+func fetchStatements(index v1.ImageIndex, digest v1.Hash, predicateTypes []string) ([][]byte, error) {
+	img, err := index.Image(digest)
+	if err != nil {
+		return nil, err
+	}
+	manifest, err := img.Manifest()
+	if err != nil {
+		return nil, err
+	}
+	var statements [][]byte
+	for _, l := range manifest.Layers {
+		if !slices.Contains(predicateTypes, l.Annotations[predicateTypeAnnotation]) {
+			continue
+		}
+		layer, err := img.LayerByDigest(l.Digest)
+		if err != nil {
+			return nil, err
+		}
+		statement, err := readLayer(layer)
+		if err != nil {
+			return nil, err
+		}
+		statements = append(statements, statement)
+	}
+	return statements, nil
+}
+
+// readLayer returns the content of the layer, which is not compressed.
+func readLayer(layer v1.Layer) ([]byte, error) {
+	rc, err := layer.Compressed()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	return io.ReadAll(rc)
+}
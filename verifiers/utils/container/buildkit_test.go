@@ -0,0 +1,180 @@
+package container
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	crname "github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/static"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+)
+
+const slsaProvenanceV02 = "https://slsa.dev/provenance/v0.2"
+
+// newTestRegistry starts an in-process registry and returns its host.
+func newTestRegistry(t *testing.T, opts ...registry.Option) string {
+	t.Helper()
+	s := httptest.NewServer(registry.New(opts...))
+	t.Cleanup(s.Close)
+	return strings.TrimPrefix(s.URL, "http://")
+}
+
+func newTestImage(t *testing.T) v1.Image {
+	t.Helper()
+	img, err := random.Image(64, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return img
+}
+
+// newTestAttestationManifest returns a BuildKit attestation manifest with
+// the statements, keyed by predicate type.
+func newTestAttestationManifest(t *testing.T, statements map[string]string) v1.Image {
+	t.Helper()
+	var adds []mutate.Addendum
+	for predicateType, statement := range statements {
+		adds = append(adds, mutate.Addendum{
+			Layer:       static.NewLayer([]byte(statement), "application/vnd.in-toto+json"),
+			Annotations: map[string]string{predicateTypeAnnotation: predicateType},
+		})
+	}
+	img, err := mutate.Append(mutate.MediaType(empty.Image, types.OCIManifestSchema1), adds...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return img
+}
+
+func descriptor(t *testing.T, img v1.Image) v1.Descriptor {
+	t.Helper()
+	digest, err := img.Digest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	mediaType, err := img.MediaType()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return v1.Descriptor{Digest: digest, MediaType: mediaType}
+}
+
+// pushTestBundle attaches a Sigstore bundle to the manifest as a referrer.
+func pushTestBundle(t *testing.T, repo crname.Repository, subject v1.Descriptor, bundle string) {
+	t.Helper()
+	img, err := mutate.Append(mutate.MediaType(empty.Image, types.OCIManifestSchema1), mutate.Addendum{
+		Layer: static.NewLayer([]byte(bundle), SigstoreBundleArtifactType),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	img = mutate.ConfigMediaType(img, SigstoreBundleArtifactType)
+	img = mutate.Subject(img, subject).(v1.Image)
+	if err := remote.Write(repo.Digest(descriptor(t, img).Digest.String()), img); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func Test_FetchBuildKitAttestations(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		referrers bool
+	}{
+		{
+			name:      "referrers API",
+			referrers: true,
+		},
+		{
+			name: "referrers tag schema",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			repo, err := crname.NewRepository(newTestRegistry(t, registry.WithReferrersSupport(tt.referrers)) + "/app")
+			if err != nil {
+				t.Fatal(err)
+			}
+			amd64, arm64 := newTestImage(t), newTestImage(t)
+			amd64Digest, arm64Digest := descriptor(t, amd64).Digest.String(), descriptor(t, arm64).Digest.String()
+			amd64Att := newTestAttestationManifest(t, map[string]string{
+				slsaProvenanceV02:                     `{"predicateType": "https://slsa.dev/provenance/v0.2"}`,
+				"https://spdx.dev/Document":           `{"predicateType": "https://spdx.dev/Document"}`,
+				"https://in-toto.io/attestation/v0.1": `{}`,
+			})
+			arm64Att := newTestAttestationManifest(t, map[string]string{
+				slsaProvenanceV02: `{"predicateType": "https://slsa.dev/provenance/v0.2", "arch": "arm64"}`,
+			})
+			attestation := func(digest string) v1.Descriptor {
+				return v1.Descriptor{
+					Platform: &v1.Platform{OS: "unknown", Architecture: "unknown"},
+					Annotations: map[string]string{
+						referenceTypeAnnotation:   attestationManifestType,
+						referenceDigestAnnotation: digest,
+					},
+				}
+			}
+			index := mutate.AppendManifests(mutate.IndexMediaType(empty.Index, types.OCIImageIndex),
+				mutate.IndexAddendum{Add: amd64, Descriptor: v1.Descriptor{Platform: &v1.Platform{OS: "linux", Architecture: "amd64"}}},
+				mutate.IndexAddendum{Add: arm64, Descriptor: v1.Descriptor{Platform: &v1.Platform{OS: "linux", Architecture: "arm64"}}},
+				mutate.IndexAddendum{Add: amd64Att, Descriptor: attestation(amd64Digest)},
+				mutate.IndexAddendum{Add: arm64Att, Descriptor: attestation(arm64Digest)},
+				// The attestation of an image that is not in the index is ignored.
+				mutate.IndexAddendum{Add: newTestAttestationManifest(t, map[string]string{slsaProvenanceV02: `{}`}),
+					Descriptor: attestation("sha256:0000000000000000000000000000000000000000000000000000000000000000")},
+			)
+			indexDigest, err := index.Digest()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := remote.WriteIndex(repo.Tag("latest"), index); err != nil {
+				t.Fatal(err)
+			}
+			pushTestBundle(t, repo, descriptor(t, amd64Att), `{"bundle": "amd64"}`)
+
+			atts, err := FetchBuildKitAttestations(context.Background(),
+				repo.Digest(indexDigest.String()).String(), []string{slsaProvenanceV02})
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := []*BuildKitAttestation{
+				{
+					ImageDigest: amd64Digest,
+					Platform:    "linux/amd64",
+					Statement:   []byte(`{"predicateType": "https://slsa.dev/provenance/v0.2"}`),
+					Bundles:     [][]byte{[]byte(`{"bundle": "amd64"}`)},
+				},
+				{
+					ImageDigest: arm64Digest,
+					Platform:    "linux/arm64",
+					Statement:   []byte(`{"predicateType": "https://slsa.dev/provenance/v0.2", "arch": "arm64"}`),
+				},
+			}
+			if diff := cmp.Diff(want, atts); diff != "" {
+				t.Errorf("unexpected attestations (-want +got):\n%s", diff)
+			}
+
+			// An image that is not an index has no BuildKit attestations.
+			atts, err = FetchBuildKitAttestations(context.Background(),
+				repo.Digest(amd64Digest).String(), []string{slsaProvenanceV02})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(atts) != 0 {
+				t.Errorf("unexpected attestations for a platform image: %v", atts)
+			}
+		})
+	}
+}
@@ -1,25 +1,34 @@
 package container
 
 import (
+	"context"
 	"fmt"
 	"strings"
 
+	"github.com/google/go-containerregistry/pkg/authn"
 	"github.com/google/go-containerregistry/pkg/crane"
 	crname "github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
 	serrors "github.com/slsa-framework/slsa-verifier/v2/errors"
 )
 
 func GetImageDigest(image string) (string, error) {
 	digest, err := crane.Digest(image)
 	if err != nil {
-		return "", fmt.Errorf("%w: crane.Digest(): %v", serrors.ErrorImageHash, err)
+		return "", fmt.Errorf("%w: crane.Digest(): %w", serrors.ErrorImageHash, err)
 	}
 	return strings.TrimPrefix(digest, "sha256:"), nil
 }
 
 // GetDigestFromImmutableReference verifies that the reference is immutable
-// and returns the `digest`.
+// and returns the `digest`. The digest of a local image is computed from
+// its layout.
 func GetDigestFromImmutableReference(image string) (string, error) {
+	if IsLocalImage(image) {
+		return getLocalImageDigest(image)
+	}
+
 	// Only allow immutable images.
 	ref, err := crname.ParseReference(image)
 	if err != nil {
@@ -33,3 +42,115 @@ func GetDigestFromImmutableReference(image string) (string, error) {
 
 	return strings.TrimPrefix(ref.Identifier(), "sha256:"), nil
 }
+
+// PlatformImage is a platform image of an image index.
+type PlatformImage struct {
+	// Digest is the digest of the image, e.g. sha256:abc.
+	Digest string
+	// Platform is the platform of the image, e.g. linux/amd64.
+	Platform string
+}
+
+// GetImagePlatforms returns the platform images of the image index. It
+// returns no images if the image is not an index. The attestation manifests
+// of an index built by BuildKit are not platform images.
+var GetImagePlatforms = func(ctx context.Context, image string) ([]PlatformImage, error) {
+	if IsLocalImage(image) {
+		img, err := openLocalImage(image)
+		if err != nil {
+			return nil, err
+		}
+		index, err := img.imageIndex()
+		if err != nil || index == nil {
+			return nil, err
+		}
+		manifest, err := index.IndexManifest()
+		if err != nil {
+			return nil, err
+		}
+		return platformImages(manifest), nil
+	}
+
+	ref, err := crname.ParseReference(image)
+	if err != nil {
+		return nil, fmt.Errorf("crane.ParseReference(): %w", err)
+	}
+	desc, err := remote.Get(ref, remote.WithContext(ctx), remote.WithAuthFromKeychain(authn.DefaultKeychain))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", serrors.ErrorImageHash, err)
+	}
+	if !desc.MediaType.IsIndex() {
+		return nil, nil
+	}
+	index, err := desc.ImageIndex()
+	if err != nil {
+		return nil, err
+	}
+	manifest, err := index.IndexManifest()
+	if err != nil {
+		return nil, err
+	}
+	return platformImages(manifest), nil
+}
+
+// platformImages returns the platform images listed in the index manifest.
+func platformImages(manifest *v1.IndexManifest) []PlatformImage {
+	var images []PlatformImage
+	for _, m := range manifest.Manifests {
+		if m.Annotations[referenceTypeAnnotation] == attestationManifestType {
+			continue
+		}
+		image := PlatformImage{Digest: m.Digest.String()}
+		if m.Platform != nil {
+			image.Platform = m.Platform.String()
+		}
+		images = append(images, image)
+	}
+	return images
+}
+
+// getLocalImageDigest returns the digest of the manifest of a local image.
+// A docker-archive tarball is extracted to read it: callers that verify the
+// image too open it with OpenDockerArchive first, to extract it once.
+func getLocalImageDigest(image string) (string, error) {
+	image, cleanup, err := OpenDockerArchive(image)
+	if err != nil {
+		return "", err
+	}
+	defer cleanup()
+	img, err := openLocalImage(image)
+	if err != nil {
+		return "", err
+	}
+	return img.desc.Digest.Hex, nil
+}
+
+// WithDigest returns the reference to the image with the digest in the same
+// repository, or in the same layout for a local image.
+func WithDigest(image, digest string) (string, error) {
+	if IsLocalImage(image) {
+		name, err := ImageName(image)
+		return name + "@" + digest, err
+	}
+	ref, err := crname.ParseReference(image)
+	if err != nil {
+		return "", fmt.Errorf("crane.ParseReference(): %w", err)
+	}
+	return ref.Context().Digest(digest).String(), nil
+}
+
+// ImageName returns the repository of the image, e.g. ghcr.io/org/image,
+// or the reference to a local image without its digest.
+func ImageName(image string) (string, error) {
+	if IsLocalImage(image) {
+		if i := strings.LastIndex(image, "@sha256:"); i >= 0 {
+			return image[:i], nil
+		}
+		return image, nil
+	}
+	ref, err := crname.ParseReference(image)
+	if err != nil {
+		return "", fmt.Errorf("crane.ParseReference(): %w", err)
+	}
+	return ref.Context().Name(), nil
+}
@@ -0,0 +1,68 @@
+package container
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	crname "github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+)
+
+func Test_GetImagePlatforms(t *testing.T) {
+	t.Parallel()
+
+	repo, err := crname.NewRepository(newTestRegistry(t) + "/app")
+	if err != nil {
+		t.Fatal(err)
+	}
+	amd64, arm64 := newTestImage(t), newTestImage(t)
+	amd64Digest, arm64Digest := descriptor(t, amd64).Digest.String(), descriptor(t, arm64).Digest.String()
+	index := mutate.AppendManifests(mutate.IndexMediaType(empty.Index, types.OCIImageIndex),
+		mutate.IndexAddendum{Add: amd64, Descriptor: v1.Descriptor{Platform: &v1.Platform{OS: "linux", Architecture: "amd64"}}},
+		mutate.IndexAddendum{Add: arm64, Descriptor: v1.Descriptor{Platform: &v1.Platform{OS: "linux", Architecture: "arm64", Variant: "v8"}}},
+		// Attestation manifests are not platform images.
+		mutate.IndexAddendum{
+			Add: newTestAttestationManifest(t, map[string]string{slsaProvenanceV02: `{}`}),
+			Descriptor: v1.Descriptor{
+				Platform: &v1.Platform{OS: "unknown", Architecture: "unknown"},
+				Annotations: map[string]string{
+					referenceTypeAnnotation:   attestationManifestType,
+					referenceDigestAnnotation: amd64Digest,
+				},
+			},
+		},
+	)
+	indexDigest, err := index.Digest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := remote.WriteIndex(repo.Tag("latest"), index); err != nil {
+		t.Fatal(err)
+	}
+
+	platforms, err := GetImagePlatforms(context.Background(), repo.Digest(indexDigest.String()).String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []PlatformImage{
+		{Digest: amd64Digest, Platform: "linux/amd64"},
+		{Digest: arm64Digest, Platform: "linux/arm64/v8"},
+	}
+	if diff := cmp.Diff(want, platforms); diff != "" {
+		t.Errorf("unexpected platform images (-want +got):\n%s", diff)
+	}
+
+	// An image that is not an index has no platform images.
+	platforms, err = GetImagePlatforms(context.Background(), repo.Digest(amd64Digest).String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(platforms) != 0 {
+		t.Errorf("unexpected platform images for a platform image: %v", platforms)
+	}
+}
@@ -2,17 +2,77 @@ package container
 
 import (
 	"context"
+	"crypto/x509"
 
 	crname "github.com/google/go-containerregistry/pkg/name"
+	dsselib "github.com/secure-systems-lab/go-securesystemslib/dsse"
 	"github.com/sigstore/cosign/v2/pkg/cosign"
 	"github.com/sigstore/cosign/v2/pkg/oci"
+
+	"github.com/slsa-framework/slsa-verifier/v2/report"
+	"github.com/slsa-framework/slsa-verifier/v2/verifiers/utils"
 )
 
 var RunCosignImageVerification = func(ctx context.Context,
 	image string, co *cosign.CheckOpts) ([]oci.Signature, bool, error) {
+	// The attestations of a local image are saved in its layout by `cosign save`.
+	if IsLocalImage(image) {
+		img, err := openLocalImage(image)
+		if err != nil {
+			return nil, false, err
+		}
+		atts, err := img.cosignAttestations()
+		if err != nil {
+			return nil, false, err
+		}
+		return cosign.VerifyImageAttestation(ctx, atts, img.desc.Digest, co)
+	}
 	signedImgRef, err := crname.ParseReference(image)
 	if err != nil {
 		return nil, false, err
 	}
 	return cosign.VerifyImageAttestations(ctx, signedImgRef, co)
 }
+
+// CosignAttestation is an attestation of an image verified by
+// RunCosignImageVerification.
+type CosignAttestation struct {
+	Envelope *dsselib.Envelope
+	// Cert is the signing certificate, or nil if the attestation is signed
+	// with a key.
+	Cert *x509.Certificate
+	// RekorEntry is the transparency log entry of the signature, or nil.
+	RekorEntry *report.RekorEntry
+}
+
+// CosignAttestations reads the envelopes of the attestations verified by
+// RunCosignImageVerification. The attestations that cannot be read are
+// logged and skipped.
+func CosignAttestations(ctx context.Context, atts []oci.Signature) []CosignAttestation {
+	var res []CosignAttestation
+	for _, att := range atts {
+		pyld, err := att.Payload()
+		if err != nil {
+			utils.Logf(ctx, "unexpected error getting payload from OCI registry %s", err)
+			continue
+		}
+		env, err := utils.EnvelopeFromBytes(pyld)
+		if err != nil {
+			utils.Logf(ctx, "unexpected error parsing envelope from OCI registry %s", err)
+			continue
+		}
+		cert, err := att.Cert()
+		if err != nil {
+			utils.Logf(ctx, "unexpected error getting certificate from OCI registry %s", err)
+			continue
+		}
+		a := CosignAttestation{Envelope: env, Cert: cert}
+		if bundle, err := att.Bundle(); err == nil && bundle != nil {
+			entry := utils.NewRekorEntry(bundle.Payload.LogIndex, bundle.Payload.Body,
+				bundle.Payload.IntegratedTime, bundle.Payload.LogID)
+			a.RekorEntry = &entry
+		}
+		res = append(res, a)
+	}
+	return res
+}
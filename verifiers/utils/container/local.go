@@ -0,0 +1,274 @@
+package container
+
+import (
+	"archive/tar"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/layout"
+	"github.com/sigstore/cosign/v2/pkg/oci"
+	"github.com/sigstore/cosign/v2/pkg/oci/empty"
+	cosignlayout "github.com/sigstore/cosign/v2/pkg/oci/layout"
+
+	serrors "github.com/slsa-framework/slsa-verifier/v2/errors"
+)
+
+// Images can be verified before they are pushed to a registry, from a local
+// OCI image layout, e.g. written by `docker buildx build --output type=oci`
+// or `cosign save`, or from a tarball written by `docker save`. Their digest
+// is computed locally, and their attestations are read from the layout: the
+// cosign attestations saved by `cosign save`, the Sigstore bundles stored as
+// referrers, and the BuildKit attestation manifests.
+
+const (
+	// OCILayoutPrefix is the prefix of the references to the images of a
+	// local OCI image layout, e.g. oci-layout:/path/to/layout@sha256:abc.
+	// The digest may be omitted if the layout has a single image.
+	OCILayoutPrefix = "oci-layout:"
+	// DockerArchivePrefix is the prefix of the references to the images of
+	// a tarball written by `docker save`, e.g. docker-archive:/path/to/image.tar.
+	DockerArchivePrefix = "docker-archive:"
+)
+
+const (
+	cosignKindAnnotation = "kind"
+	cosignImageKind      = "dev.cosignproject.cosign/image"
+	cosignImageIndexKind = "dev.cosignproject.cosign/imageIndex"
+
+	// maxArchiveBlobSize is the size of the largest file extracted from a
+	// docker-archive tarball. Image layers are not needed to verify the
+	// provenance, and are much larger than manifests and attestations.
+	maxArchiveBlobSize = 16 << 20
+)
+
+// IsLocalImage returns true if the reference is to a local image.
+func IsLocalImage(image string) bool {
+	return strings.HasPrefix(image, OCILayoutPrefix) || strings.HasPrefix(image, DockerArchivePrefix)
+}
+
+// parseLocalImage returns the path and the digest, if any, of a reference
+// to a local image.
+func parseLocalImage(image string) (path, digest string) {
+	ref := strings.TrimPrefix(strings.TrimPrefix(image, OCILayoutPrefix), DockerArchivePrefix)
+	if i := strings.LastIndex(ref, "@sha256:"); i >= 0 {
+		return ref[:i], ref[i+1:]
+	}
+	return ref, ""
+}
+
+// OpenDockerArchive extracts the OCI image layout of a docker-archive
+// tarball to a temporary directory, and returns the reference to the image
+// in the layout. cleanup removes the directory. Other references are
+// returned as is.
+func OpenDockerArchive(image string) (ref string, cleanup func(), err error) {
+	cleanup = func() {}
+	if !strings.HasPrefix(image, DockerArchivePrefix) {
+		return image, cleanup, nil
+	}
+	path, digest := parseLocalImage(image)
+	dir, err := os.MkdirTemp("", "slsa-verifier-")
+	if err != nil {
+		return "", cleanup, err
+	}
+	if err := extractLayout(path, dir); err != nil {
+		os.RemoveAll(dir)
+		return "", cleanup, err
+	}
+	ref = OCILayoutPrefix + dir
+	if digest != "" {
+		ref += "@" + digest
+	}
+	return ref, func() { os.RemoveAll(dir) }, nil
+}
+
+// extractLayout extracts the OCI image layout of the tarball to dir, except
+// for the image layers.
+func extractLayout(path, dir string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var hasIndex bool
+	tr := tar.NewReader(f)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("%w: %w", serrors.ErrorInvalidFormat, err)
+		}
+		name := filepath.Clean(hdr.Name)
+		if hdr.Typeflag != tar.TypeReg || hdr.Size > maxArchiveBlobSize ||
+			(name != "index.json" && name != "oci-layout" && !strings.HasPrefix(name, "blobs"+string(filepath.Separator))) {
+			continue
+		}
+		if !filepath.IsLocal(name) {
+			return fmt.Errorf("%w: invalid path %q in tarball", serrors.ErrorInvalidFormat, hdr.Name)
+		}
+		target := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(target), 0o700); err != nil {
+			return err
+		}
+		content, err := io.ReadAll(tr)
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(target, content, 0o600); err != nil {
+			return err
+		}
+		hasIndex = hasIndex || name == "index.json"
+	}
+	if !hasIndex {
+		return fmt.Errorf("%w: %s has no OCI image layout, save the image with the containerd image store",
+			serrors.ErrorInvalidFormat, path)
+	}
+	return nil
+}
+
+// localImage is an image of a local OCI image layout.
+type localImage struct {
+	path layout.Path
+	// index is the index of the layout, i.e. index.json.
+	index v1.ImageIndex
+	desc  v1.Descriptor
+}
+
+// openLocalImage opens the image of an OCI image layout, and verifies
+// the digest of its manifest.
+func openLocalImage(image string) (*localImage, error) {
+	if !strings.HasPrefix(image, OCILayoutPrefix) {
+		return nil, fmt.Errorf("%w: '%s' is not an OCI image layout", serrors.ErrorInvalidFormat, image)
+	}
+	p, digest := parseLocalImage(image)
+	path, err := layout.FromPath(p)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", serrors.ErrorImageHash, err)
+	}
+	index, err := path.ImageIndex()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", serrors.ErrorImageHash, err)
+	}
+	manifest, err := index.IndexManifest()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", serrors.ErrorImageHash, err)
+	}
+	desc, err := selectLocalImage(manifest, digest)
+	if err != nil {
+		return nil, err
+	}
+
+	// The digest listed in the layout must be the digest of the manifest.
+	raw, err := path.Bytes(desc.Digest)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", serrors.ErrorImageHash, err)
+	}
+	h, _, err := v1.SHA256(bytes.NewReader(raw))
+	if err != nil {
+		return nil, err
+	}
+	if h != desc.Digest {
+		return nil, fmt.Errorf("%w: manifest %s has digest %s", serrors.ErrorImageHash, desc.Digest, h)
+	}
+	return &localImage{path: path, index: index, desc: desc}, nil
+}
+
+// selectLocalImage returns the image with the digest, or else the only image
+// of the layout. The image saved by `cosign save` is selected over its
+// signatures and attestations.
+func selectLocalImage(manifest *v1.IndexManifest, digest string) (v1.Descriptor, error) {
+	var images []v1.Descriptor
+	for _, m := range manifest.Manifests {
+		if digest != "" {
+			if m.Digest.String() == digest {
+				return m, nil
+			}
+			continue
+		}
+		switch m.Annotations[cosignKindAnnotation] {
+		case cosignImageKind, cosignImageIndexKind:
+			return m, nil
+		case "":
+		default:
+			// Signatures and attestations saved by `cosign save`.
+			continue
+		}
+		// Referrers, e.g. Sigstore bundles, are not images.
+		if m.ArtifactType != "" || !(m.MediaType.IsImage() || m.MediaType.IsIndex()) {
+			continue
+		}
+		images = append(images, m)
+	}
+	if digest != "" {
+		return v1.Descriptor{}, fmt.Errorf("%w: no image %s in the layout", serrors.ErrorImageHash, digest)
+	}
+	if len(images) != 1 {
+		return v1.Descriptor{}, fmt.Errorf("%w: the layout has %d images, select one with @sha256:<digest>",
+			serrors.ErrorImageHash, len(images))
+	}
+	return images[0], nil
+}
+
+// imageIndex returns the image index, or nil if the image is not an index.
+func (i *localImage) imageIndex() (v1.ImageIndex, error) {
+	if !i.desc.MediaType.IsIndex() {
+		return nil, nil
+	}
+	return i.index.ImageIndex(i.desc.Digest)
+}
+
+// cosignAttestations returns the attestations saved by `cosign save`.
+func (i *localImage) cosignAttestations() (oci.Signatures, error) {
+	se, err := cosignlayout.SignedImageIndex(string(i.path))
+	if err != nil {
+		return nil, err
+	}
+	atts, err := se.Attestations()
+	if err != nil {
+		return nil, err
+	}
+	if atts == nil {
+		return empty.Signatures(), nil
+	}
+	return atts, nil
+}
+
+// sigstoreBundles returns the Sigstore bundles of the layout whose subject
+// is the manifest with the digest.
+func (i *localImage) sigstoreBundles(digest v1.Hash) ([][]byte, error) {
+	manifest, err := i.index.IndexManifest()
+	if err != nil {
+		return nil, err
+	}
+	var bundles [][]byte
+	for _, m := range manifest.Manifests {
+		if m.ArtifactType != SigstoreBundleArtifactType {
+			continue
+		}
+		img, err := i.index.Image(m.Digest)
+		if err != nil {
+			return nil, err
+		}
+		referrer, err := img.Manifest()
+		if err != nil {
+			return nil, err
+		}
+		if referrer.Subject == nil || referrer.Subject.Digest != digest {
+			continue
+		}
+		b, err := sigstoreBundlesOf(img)
+		if err != nil {
+			return nil, err
+		}
+		bundles = append(bundles, b...)
+	}
+	return bundles, nil
+}
@@ -0,0 +1,246 @@
+package container
+
+import (
+	"archive/tar"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/layout"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/static"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+	"github.com/sigstore/cosign/v2/pkg/cosign"
+
+	serrors "github.com/slsa-framework/slsa-verifier/v2/errors"
+)
+
+// newTestLayout writes the images to a new OCI image layout.
+func newTestLayout(t *testing.T, images ...v1.Image) layout.Path {
+	t.Helper()
+	path, err := layout.Write(t.TempDir(), empty.Index)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, img := range images {
+		if err := path.AppendImage(img); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return path
+}
+
+// newTestArchive writes the files of the directory to a tarball, like
+// `docker save`.
+func newTestArchive(t *testing.T, dir string) string {
+	t.Helper()
+	archive := filepath.Join(t.TempDir(), "image.tar")
+	f, err := os.Create(archive)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	tw := tar.NewWriter(f)
+	if err := tw.AddFS(os.DirFS(dir)); err != nil {
+		t.Fatal(err)
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return archive
+}
+
+// appendTestReferrer stores a Sigstore bundle of the manifest in the layout.
+func appendTestReferrer(t *testing.T, path layout.Path, subject v1.Descriptor, bundle string) {
+	t.Helper()
+	img, err := mutate.Append(mutate.MediaType(empty.Image, types.OCIManifestSchema1), mutate.Addendum{
+		Layer: static.NewLayer([]byte(bundle), SigstoreBundleArtifactType),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	img = mutate.ConfigMediaType(img, SigstoreBundleArtifactType)
+	img = mutate.Subject(img, subject).(v1.Image)
+	if err := path.WriteImage(img); err != nil {
+		t.Fatal(err)
+	}
+	desc := descriptor(t, img)
+	desc.ArtifactType = SigstoreBundleArtifactType
+	if desc.Size, err = img.Size(); err != nil {
+		t.Fatal(err)
+	}
+	if err := path.AppendDescriptor(desc); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func Test_GetDigestFromImmutableReferenceLocal(t *testing.T) {
+	t.Parallel()
+
+	img, other := newTestImage(t), newTestImage(t)
+	digest := descriptor(t, img).Digest
+	single := newTestLayout(t, img)
+	multiple := newTestLayout(t, img, other)
+
+	// A layout whose index lists a manifest with another digest.
+	tampered := newTestLayout(t)
+	if err := tampered.WriteBlob(digest, io.NopCloser(strings.NewReader(`{"schemaVersion": 2}`))); err != nil {
+		t.Fatal(err)
+	}
+	if err := tampered.AppendDescriptor(v1.Descriptor{
+		MediaType: types.OCIManifestSchema1,
+		Digest:    digest,
+		Size:      20,
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	// docker save without the containerd image store writes no OCI image layout.
+	legacy := t.TempDir()
+	if err := os.WriteFile(filepath.Join(legacy, "manifest.json"), []byte(`[]`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		image string
+		err   error
+	}{
+		{
+			name:  "layout with a single image",
+			image: OCILayoutPrefix + string(single),
+		},
+		{
+			name:  "layout with the digest",
+			image: OCILayoutPrefix + string(multiple) + "@" + digest.String(),
+		},
+		{
+			name:  "layout with several images",
+			image: OCILayoutPrefix + string(multiple),
+			err:   serrors.ErrorImageHash,
+		},
+		{
+			name:  "layout without the digest",
+			image: OCILayoutPrefix + string(single) + "@sha256:0000000000000000000000000000000000000000000000000000000000000000",
+			err:   serrors.ErrorImageHash,
+		},
+		{
+			name:  "layout with another manifest",
+			image: OCILayoutPrefix + string(tampered),
+			err:   serrors.ErrorImageHash,
+		},
+		{
+			name:  "docker archive",
+			image: DockerArchivePrefix + newTestArchive(t, string(single)),
+		},
+		{
+			name:  "docker archive without OCI image layout",
+			image: DockerArchivePrefix + newTestArchive(t, legacy),
+			err:   serrors.ErrorInvalidFormat,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := GetDigestFromImmutableReference(tt.image)
+			if diff := cmp.Diff(tt.err, err, cmpopts.EquateErrors()); diff != "" {
+				t.Fatalf("unexpected error (-want +got):\n%s", diff)
+			}
+			if err == nil && got != digest.Hex {
+				t.Errorf("unexpected digest: want %q, got %q", digest.Hex, got)
+			}
+		})
+	}
+}
+
+func Test_localImageAttestations(t *testing.T) {
+	t.Parallel()
+
+	amd64, arm64 := newTestImage(t), newTestImage(t)
+	amd64Digest, arm64Digest := descriptor(t, amd64).Digest.String(), descriptor(t, arm64).Digest.String()
+	amd64Att := newTestAttestationManifest(t, map[string]string{slsaProvenanceV02: `{"arch": "amd64"}`})
+	index := mutate.AppendManifests(mutate.IndexMediaType(empty.Index, types.OCIImageIndex),
+		mutate.IndexAddendum{Add: amd64, Descriptor: v1.Descriptor{Platform: &v1.Platform{OS: "linux", Architecture: "amd64"}}},
+		mutate.IndexAddendum{Add: arm64, Descriptor: v1.Descriptor{Platform: &v1.Platform{OS: "linux", Architecture: "arm64"}}},
+		mutate.IndexAddendum{Add: amd64Att, Descriptor: v1.Descriptor{
+			Platform: &v1.Platform{OS: "unknown", Architecture: "unknown"},
+			Annotations: map[string]string{
+				referenceTypeAnnotation:   attestationManifestType,
+				referenceDigestAnnotation: amd64Digest,
+			},
+		}},
+	)
+	indexDigest, err := index.Digest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := newTestLayout(t)
+	if err := path.AppendIndex(index); err != nil {
+		t.Fatal(err)
+	}
+	// Sigstore bundles are stored as referrers in the layout.
+	appendTestReferrer(t, path, v1.Descriptor{MediaType: types.OCIImageIndex, Digest: indexDigest}, `{"bundle": "index"}`)
+	appendTestReferrer(t, path, descriptor(t, amd64Att), `{"bundle": "amd64"}`)
+	image := OCILayoutPrefix + string(path)
+
+	// The referrers are not images of the layout.
+	digest, err := GetDigestFromImmutableReference(image)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if digest != indexDigest.Hex {
+		t.Errorf("unexpected digest: want %q, got %q", indexDigest.Hex, digest)
+	}
+
+	platforms, err := GetImagePlatforms(context.Background(), image)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantPlatforms := []PlatformImage{
+		{Digest: amd64Digest, Platform: "linux/amd64"},
+		{Digest: arm64Digest, Platform: "linux/arm64"},
+	}
+	if diff := cmp.Diff(wantPlatforms, platforms); diff != "" {
+		t.Errorf("unexpected platform images (-want +got):\n%s", diff)
+	}
+
+	bundles, err := FetchSigstoreBundles(context.Background(), image, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([][]byte{[]byte(`{"bundle": "index"}`)}, bundles); diff != "" {
+		t.Errorf("unexpected bundles (-want +got):\n%s", diff)
+	}
+
+	atts, err := FetchBuildKitAttestations(context.Background(), image, []string{slsaProvenanceV02})
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantAtts := []*BuildKitAttestation{
+		{
+			ImageDigest: amd64Digest,
+			Platform:    "linux/amd64",
+			Statement:   []byte(`{"arch": "amd64"}`),
+			Bundles:     [][]byte{[]byte(`{"bundle": "amd64"}`)},
+		},
+	}
+	if diff := cmp.Diff(wantAtts, atts); diff != "" {
+		t.Errorf("unexpected attestations (-want +got):\n%s", diff)
+	}
+
+	// The layout has no cosign attestations.
+	_, _, err = RunCosignImageVerification(context.Background(), image, &cosign.CheckOpts{})
+	var noAtts *cosign.ErrNoMatchingAttestations
+	if !errors.As(err, &noAtts) {
+		t.Errorf("unexpected error: want no matching attestations, got %v", err)
+	}
+}
@@ -0,0 +1,121 @@
+package container
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	crname "github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
+
+	serrors "github.com/slsa-framework/slsa-verifier/v2/errors"
+)
+
+// Registries that implement OCI 1.1 store the Sigstore bundles of an image
+// as referrers of its manifest, rather than in cosign tags. Registries
+// without the referrers API list them in the referrers tag schema.
+// See https://github.com/opencontainers/distribution-spec/blob/v1.1.0/spec.md#listing-referrers.
+
+// SigstoreBundleArtifactType is the artifact type of the Sigstore bundles
+// attached to a manifest.
+const SigstoreBundleArtifactType = "application/vnd.dev.sigstore.bundle.v0.3+json"
+
+// FetchSigstoreBundles returns the Sigstore bundles attached to the image as
+// OCI referrers. The referrers are looked up in repository if it is not
+// empty, and otherwise in the repository of the image. The referrers of a
+// local image are looked up in its layout.
+var FetchSigstoreBundles = func(ctx context.Context, image, repository string) ([][]byte, error) {
+	if IsLocalImage(image) {
+		img, err := openLocalImage(image)
+		if err != nil {
+			return nil, err
+		}
+		return img.sigstoreBundles(img.desc.Digest)
+	}
+	ref, err := crname.ParseReference(image)
+	if err != nil {
+		return nil, fmt.Errorf("crane.ParseReference(): %w", err)
+	}
+	// Referrers are listed by the digest of their subject.
+	if _, ok := ref.(crname.Digest); !ok {
+		return nil, fmt.Errorf("%w: '%s'", serrors.ErrorMutableImage, image)
+	}
+	repo := ref.Context()
+	if repository != "" {
+		repo, err = crname.NewRepository(repository)
+		if err != nil {
+			return nil, err
+		}
+	}
+	opts := []remote.Option{
+		remote.WithContext(ctx),
+		remote.WithAuthFromKeychain(authn.DefaultKeychain),
+	}
+	return fetchSigstoreBundles(repo.Digest(ref.Identifier()), opts)
+}
+
+// IsNotFound returns true if err is caused by a manifest or a repository
+// missing from the registry, rather than by a failing registry.
+func IsNotFound(err error) bool {
+	var transportErr *transport.Error
+	return errors.As(err, &transportErr) && transportErr.StatusCode == http.StatusNotFound
+}
+
+// fetchSigstoreBundles returns the Sigstore bundles attached to the manifest.
+// Registries without the OCI referrers API are queried with the referrers
+// tag schema.
+func fetchSigstoreBundles(digest crname.Digest, opts []remote.Option) ([][]byte, error) {
+	referrers, err := remote.Referrers(digest,
+		append(opts, remote.WithFilter("artifactType", SigstoreBundleArtifactType))...)
+	if err != nil {
+		return nil, err
+	}
+	manifest, err := referrers.IndexManifest()
+	if err != nil {
+		return nil, err
+	}
+	var bundles [][]byte
+	for _, m := range manifest.Manifests {
+		if m.ArtifactType != SigstoreBundleArtifactType {
+			continue
+		}
+		img, err := remote.Image(digest.Context().Digest(m.Digest.String()), opts...)
+		if err != nil {
+			return nil, err
+		}
+		b, err := sigstoreBundlesOf(img)
+		if err != nil {
+			return nil, err
+		}
+		bundles = append(bundles, b...)
+	}
+	return bundles, nil
+}
+
+// sigstoreBundlesOf returns the Sigstore bundles in the layers of the referrer.
+func sigstoreBundlesOf(img v1.Image) ([][]byte, error) {
+	layers, err := img.Layers()
+	if err != nil {
+		return nil, err
+	}
+	var bundles [][]byte
+	for _, layer := range layers {
+		mediaType, err := layer.MediaType()
+		if err != nil {
+			return nil, err
+		}
+		if mediaType != SigstoreBundleArtifactType {
+			continue
+		}
+		bundle, err := readLayer(layer)
+		if err != nil {
+			return nil, err
+		}
+		bundles = append(bundles, bundle)
+	}
+	return bundles, nil
+}
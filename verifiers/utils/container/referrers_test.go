@@ -0,0 +1,144 @@
+package container
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	crname "github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
+	"github.com/google/go-containerregistry/pkg/v1/static"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+
+	serrors "github.com/slsa-framework/slsa-verifier/v2/errors"
+)
+
+func Test_FetchSigstoreBundles(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		referrers bool
+		// repository is the repository of the bundles, relative to the registry.
+		repository string
+	}{
+		{
+			name:      "referrers API",
+			referrers: true,
+		},
+		{
+			name: "referrers tag schema",
+		},
+		{
+			name:       "provenance repository",
+			referrers:  true,
+			repository: "attestations",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			host := newTestRegistry(t, registry.WithReferrersSupport(tt.referrers))
+			repo, err := crname.NewRepository(host + "/app")
+			if err != nil {
+				t.Fatal(err)
+			}
+			bundleRepo, repository := repo, ""
+			if tt.repository != "" {
+				repository = host + "/" + tt.repository
+				if bundleRepo, err = crname.NewRepository(repository); err != nil {
+					t.Fatal(err)
+				}
+			}
+			img := newTestImage(t)
+			if err := remote.Write(repo.Tag("latest"), img); err != nil {
+				t.Fatal(err)
+			}
+			subject := descriptor(t, img)
+			pushTestBundle(t, bundleRepo, subject, `{"bundle": 1}`)
+			pushTestBundle(t, bundleRepo, subject, `{"bundle": 2}`)
+
+			// Referrers of other artifact types are ignored.
+			sbom, err := mutate.Append(mutate.MediaType(empty.Image, types.OCIManifestSchema1), mutate.Addendum{
+				Layer: static.NewLayer([]byte(`{"spdxVersion": "SPDX-2.3"}`), "application/spdx+json"),
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			sbom = mutate.ConfigMediaType(sbom, "application/spdx+json")
+			sbom = mutate.Subject(sbom, subject).(v1.Image)
+			if err := remote.Write(bundleRepo.Digest(descriptor(t, sbom).Digest.String()), sbom); err != nil {
+				t.Fatal(err)
+			}
+
+			bundles, err := FetchSigstoreBundles(context.Background(),
+				repo.Digest(subject.Digest.String()).String(), repository)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := []string{`{"bundle": 1}`, `{"bundle": 2}`}
+			var got []string
+			for _, b := range bundles {
+				got = append(got, string(b))
+			}
+			if diff := cmp.Diff(want, got, cmpopts.SortSlices(func(a, b string) bool { return a < b })); diff != "" {
+				t.Errorf("unexpected bundles (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_FetchSigstoreBundlesMutableImage(t *testing.T) {
+	t.Parallel()
+
+	_, err := FetchSigstoreBundles(context.Background(), "ghcr.io/org/app:latest", "")
+	if diff := cmp.Diff(serrors.ErrorMutableImage, err, cmpopts.EquateErrors()); diff != "" {
+		t.Errorf("unexpected error (-want +got):\n%s", diff)
+	}
+}
+
+func Test_IsNotFound(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{
+			name:     "manifest unknown",
+			err:      fmt.Errorf("%w: %w", serrors.ErrorImageHash, &transport.Error{StatusCode: http.StatusNotFound}),
+			expected: true,
+		},
+		{
+			name: "unauthorized",
+			err:  &transport.Error{StatusCode: http.StatusUnauthorized},
+		},
+		{
+			name: "bad gateway",
+			err:  &transport.Error{StatusCode: http.StatusBadGateway},
+		},
+		{
+			name: "mutable image",
+			err:  serrors.ErrorMutableImage,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := IsNotFound(tt.err); got != tt.expected {
+				t.Errorf("unexpected result: got %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
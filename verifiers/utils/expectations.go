@@ -0,0 +1,90 @@
+package utils
+
+import (
+	"fmt"
+
+	intoto "github.com/in-toto/in-toto-golang/in_toto"
+
+	serrors "github.com/slsa-framework/slsa-verifier/v2/errors"
+	"github.com/slsa-framework/slsa-verifier/v2/options"
+	"github.com/slsa-framework/slsa-verifier/v2/report"
+)
+
+// VerifyRef verifies the git ref the artifact was built from, e.g.
+// refs/tags/v1.0.0, against the expected branch, tag and versioned tag,
+// and records the checks in rep.
+func VerifyRef(rep *report.Report, ref string, provenanceOpts *options.ProvenanceOpts) error {
+	if provenanceOpts.ExpectedBranch != nil || len(provenanceOpts.ExpectedBranchPatterns) > 0 {
+		if err := rep.Check(report.CheckBranch,
+			verifyRefName(ref, "branch", provenanceOpts.ExpectedBranch, provenanceOpts.ExpectedBranchPatterns,
+				serrors.ErrorMismatchBranch)); err != nil {
+			return err
+		}
+	}
+	if provenanceOpts.ExpectedTag != nil || len(provenanceOpts.ExpectedTagPatterns) > 0 {
+		if err := rep.Check(report.CheckTag,
+			verifyRefName(ref, "tag", provenanceOpts.ExpectedTag, provenanceOpts.ExpectedTagPatterns,
+				serrors.ErrorMismatchTag)); err != nil {
+			return err
+		}
+	}
+	if provenanceOpts.ExpectedVersionedTag != nil {
+		tag, err := TagFromGitRef(ref)
+		if err == nil {
+			err = VerifyVersionedTag(tag, *provenanceOpts.ExpectedVersionedTag)
+		}
+		if err := rep.Check(report.CheckVersionedTag, err); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// verifyRefName verifies the name of a branch or tag ref against the
+// expected name and patterns.
+func verifyRefName(ref, refType string, expected *string, patterns []string, mismatchErr error) error {
+	var refName string
+	var err error
+	if refType == "branch" {
+		refName, err = BranchFromGitRef(ref)
+	} else {
+		refName, err = TagFromGitRef(ref)
+	}
+	if err != nil {
+		return fmt.Errorf("verifying %s: %w", refType, err)
+	}
+	if expected != nil && refName != *expected {
+		return serrors.NewVerificationError(
+			fmt.Errorf("expected %s '%s', got '%s': %w", refType, *expected, refName, mismatchErr),
+			*expected, refName)
+	}
+	if len(patterns) > 0 {
+		ok, err := MatchesAnyPattern(refName, patterns)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return fmt.Errorf("expected %s matching one of %q, got '%s': %w", refType, patterns, refName, mismatchErr)
+		}
+	}
+	return nil
+}
+
+// VerifySubjectDigest verifies that one of the subjects has the expected
+// hex-encoded digest. The algorithm is derived from the length of the
+// digest.
+func VerifySubjectDigest(subjects []intoto.Subject, expectedHash string) error {
+	// 8 bit represented in hex, so 8/2=4.
+	bitLength := len(expectedHash) * 4
+	if bitLength < 256 {
+		return fmt.Errorf("%w: expected minimum 256-bit. Got %d", serrors.ErrorInvalidHash, bitLength)
+	}
+	expectedAlgo := fmt.Sprintf("sha%v", bitLength)
+	for _, subject := range subjects {
+		if subject.Digest[expectedAlgo] == expectedHash {
+			return nil
+		}
+	}
+	return serrors.NewVerificationError(
+		fmt.Errorf("expected hash '%s' not found: %w", expectedHash, serrors.ErrorMismatchHash), expectedHash, "")
+}
@@ -0,0 +1,151 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	intoto "github.com/in-toto/in-toto-golang/in_toto"
+
+	serrors "github.com/slsa-framework/slsa-verifier/v2/errors"
+	"github.com/slsa-framework/slsa-verifier/v2/options"
+)
+
+func Test_VerifyRef(t *testing.T) {
+	t.Parallel()
+
+	str := func(s string) *string { return &s }
+	tests := []struct {
+		name string
+		ref  string
+		opts options.ProvenanceOpts
+		err  error
+	}{
+		{
+			name: "no expectations",
+			ref:  "refs/tags/v1.0.0",
+		},
+		{
+			name: "tag",
+			ref:  "refs/tags/v1.0.0",
+			opts: options.ProvenanceOpts{ExpectedTag: str("v1.0.0")},
+		},
+		{
+			name: "other tag",
+			ref:  "refs/tags/v1.0.1",
+			opts: options.ProvenanceOpts{ExpectedTag: str("v1.0.0")},
+			err:  serrors.ErrorMismatchTag,
+		},
+		{
+			name: "tag pattern",
+			ref:  "refs/tags/v1.0.0",
+			opts: options.ProvenanceOpts{ExpectedTagPatterns: []string{"v1.*"}},
+		},
+		{
+			name: "versioned tag",
+			ref:  "refs/tags/v1.2.3",
+			opts: options.ProvenanceOpts{ExpectedVersionedTag: str("v1.2")},
+		},
+		{
+			name: "other versioned tag",
+			ref:  "refs/tags/v2.0.0",
+			opts: options.ProvenanceOpts{ExpectedVersionedTag: str("v1")},
+			err:  serrors.ErrorMismatchVersionedTag,
+		},
+		{
+			name: "versioned tag range",
+			ref:  "refs/tags/v1.4.0",
+			opts: options.ProvenanceOpts{ExpectedVersionedTag: str(">=v1.2.0 <v2")},
+		},
+		{
+			name: "versioned tag below range",
+			ref:  "refs/tags/v1.1.9",
+			opts: options.ProvenanceOpts{ExpectedVersionedTag: str(">=v1.2.0 <v2")},
+			err:  serrors.ErrorMismatchVersionedTag,
+		},
+		{
+			name: "versioned tag above range",
+			ref:  "refs/tags/v2.0.0",
+			opts: options.ProvenanceOpts{ExpectedVersionedTag: str(">=v1.2.0 <v2")},
+			err:  serrors.ErrorMismatchVersionedTag,
+		},
+		{
+			name: "invalid versioned tag range",
+			ref:  "refs/tags/v1.4.0",
+			opts: options.ProvenanceOpts{ExpectedVersionedTag: str(">=1.2.0")},
+			err:  serrors.ErrorInvalidSemver,
+		},
+		{
+			name: "branch",
+			ref:  "refs/heads/main",
+			opts: options.ProvenanceOpts{ExpectedBranch: str("main")},
+		},
+		{
+			name: "other branch",
+			ref:  "refs/heads/dev",
+			opts: options.ProvenanceOpts{ExpectedBranch: str("main")},
+			err:  serrors.ErrorMismatchBranch,
+		},
+		{
+			name: "branch pattern",
+			ref:  "refs/heads/release-1",
+			opts: options.ProvenanceOpts{ExpectedBranchPatterns: []string{"main", "release-*"}},
+		},
+		{
+			name: "tag instead of branch",
+			ref:  "refs/tags/v1.0.0",
+			opts: options.ProvenanceOpts{ExpectedBranch: str("main")},
+			err:  serrors.ErrorInvalidRef,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := VerifyRef(nil, tt.ref, &tt.opts)
+			if diff := cmp.Diff(tt.err, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("unexpected error (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_VerifySubjectDigest(t *testing.T) {
+	t.Parallel()
+
+	sha256 := "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+	subjects := []intoto.Subject{
+		{Name: "other", Digest: map[string]string{"sha256": "0a2b4c"}},
+		{Name: "artifact", Digest: map[string]string{"sha256": sha256}},
+	}
+	tests := []struct {
+		name     string
+		expected string
+		err      error
+	}{
+		{
+			name:     "subject",
+			expected: sha256,
+		},
+		{
+			name:     "no subject",
+			expected: "a" + sha256[1:],
+			err:      serrors.ErrorMismatchHash,
+		},
+		{
+			name:     "short digest",
+			expected: "0a2b4c",
+			err:      serrors.ErrorInvalidHash,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := VerifySubjectDigest(subjects, tt.expected)
+			if diff := cmp.Diff(tt.err, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("unexpected error (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,28 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+type loggerKey struct{}
+
+// ContextWithLogger returns a copy of ctx that carries logger. The
+// verifiers write their progress messages to it instead of stderr.
+func ContextWithLogger(ctx context.Context, logger *slog.Logger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, logger)
+}
+
+// Logf writes a progress message of the verification to the logger carried
+// by ctx, or to stderr if there is none, as the CLI expects.
+func Logf(ctx context.Context, format string, args ...any) {
+	logger, _ := ctx.Value(loggerKey{}).(*slog.Logger)
+	if logger == nil {
+		fmt.Fprintf(os.Stderr, format, args...)
+		return
+	}
+	logger.InfoContext(ctx, strings.TrimSpace(fmt.Sprintf(format, args...)))
+}
@@ -0,0 +1,26 @@
+package utils
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"testing"
+)
+
+func Test_Logf(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{
+		ReplaceAttr: func(_ []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return a
+		},
+	}))
+	Logf(ContextWithLogger(context.Background(), logger), "Verified build at commit %s\n", "abc")
+	if got, want := buf.String(), "level=INFO msg=\"Verified build at commit abc\"\n"; got != want {
+		t.Errorf("unexpected log: got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,200 @@
+package utils
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	serrors "github.com/slsa-framework/slsa-verifier/v2/errors"
+)
+
+// DefaultNpmRegistry is the base URL of the public npm registry.
+const DefaultNpmRegistry = "https://registry.npmjs.org"
+
+// maxNpmAttestationsSize is the size of the largest attestations document
+// read from a registry. The attestations of a package version are a few
+// Sigstore bundles.
+const maxNpmAttestationsSize = 10 << 20
+
+// NpmAttestationsURL returns the URL of the attestations of the package
+// version on the registry, e.g.
+// https://registry.npmjs.org/-/npm/v1/attestations/@scope%2fname@1.0.0.
+// This is the URL listed as dist.attestations.url in the package metadata.
+func NpmAttestationsURL(registry, name, version string) (string, error) {
+	if name == "" || version == "" {
+		return "", fmt.Errorf("%w: the package name and version are required to fetch the attestations",
+			serrors.ErrorInvalidPackageName)
+	}
+	u, err := url.Parse(registry)
+	if err != nil || u.Host == "" || (u.Scheme != "https" && u.Scheme != "http") {
+		return "", fmt.Errorf("%w: registry %q", serrors.ErrorMalformedURI, registry)
+	}
+	// The slash of a scoped package name is escaped, as by the npm CLI.
+	escapedName := strings.Replace(name, "/", "%2f", 1)
+	return strings.TrimSuffix(registry, "/") + "/-/npm/v1/attestations/" +
+		escapedName + "@" + url.PathEscape(version), nil
+}
+
+// FetchNpmAttestations returns the attestations of a package version served
+// at attestationsURL by an npm registry.
+func FetchNpmAttestations(ctx context.Context, attestationsURL string) ([]byte, error) {
+	u, err := url.Parse(attestationsURL)
+	if err != nil || u.Host == "" || (u.Scheme != "https" && u.Scheme != "http") {
+		return nil, fmt.Errorf("%w: attestations URL %q", serrors.ErrorMalformedURI, attestationsURL)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, attestationsURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", serrors.ErrorFetchAttestations, err)
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", serrors.ErrorFetchAttestations, err)
+	}
+	defer resp.Body.Close()
+	// The registry serves no attestations for packages published without
+	// provenance.
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("%w: %w: %s", serrors.ErrorFetchAttestations, serrors.ErrorNotPresent, attestationsURL)
+	}
+	// The registry may serve the attestations if the request is retried.
+	if isUnavailableStatus(resp.StatusCode) {
+		return nil, fmt.Errorf("%w: %w: %s: %s", serrors.ErrorFetchAttestations, serrors.ErrorUnavailable,
+			attestationsURL, resp.Status)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: %s: %s", serrors.ErrorFetchAttestations, attestationsURL, resp.Status)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxNpmAttestationsSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", serrors.ErrorFetchAttestations, err)
+	}
+	if len(body) > maxNpmAttestationsSize {
+		return nil, fmt.Errorf("%w: %s: larger than %d bytes", serrors.ErrorFetchAttestations,
+			attestationsURL, maxNpmAttestationsSize)
+	}
+	return body, nil
+}
+
+// NpmLockPackage is a package installed from a registry, as listed in
+// a package-lock.json file.
+type NpmLockPackage struct {
+	Name    string
+	Version string
+	// Integrity is the Subresource Integrity of the tarball,
+	// e.g. sha512-<base64>.
+	Integrity string
+}
+
+// SHA512 returns the hex encoded sha512 digest of the tarball from its
+// integrity, or false if the integrity has no sha512 digest, e.g. for
+// packages published before npm used sha512.
+func (p *NpmLockPackage) SHA512() (string, bool) {
+	for _, h := range strings.Fields(p.Integrity) {
+		digest, ok := strings.CutPrefix(h, "sha512-")
+		if !ok {
+			continue
+		}
+		// Options, e.g. sha512-<base64>?foo, are ignored.
+		digest, _, _ = strings.Cut(digest, "?")
+		b, err := base64.StdEncoding.DecodeString(digest)
+		if err != nil || len(b) != 64 {
+			return "", false
+		}
+		return hex.EncodeToString(b), true
+	}
+	return "", false
+}
+
+type npmLockfile struct {
+	LockfileVersion int                       `json:"lockfileVersion"`
+	Packages        map[string]npmLockEntry   `json:"packages"`
+	Dependencies    map[string]npmLockV1Entry `json:"dependencies"`
+}
+
+// npmLockEntry is an entry of the packages of a lockfile version 2 or 3,
+// keyed by its path, e.g. node_modules/@scope/name.
+type npmLockEntry struct {
+	Name      string `json:"name"`
+	Version   string `json:"version"`
+	Integrity string `json:"integrity"`
+	Link      bool   `json:"link"`
+	InBundle  bool   `json:"inBundle"`
+}
+
+// npmLockV1Entry is an entry of the dependencies of a lockfile version 1,
+// keyed by the package name.
+type npmLockV1Entry struct {
+	Version      string                    `json:"version"`
+	Integrity    string                    `json:"integrity"`
+	Bundled      bool                      `json:"bundled"`
+	Dependencies map[string]npmLockV1Entry `json:"dependencies"`
+}
+
+// ParseNpmLockfile returns the packages of a package-lock.json or
+// npm-shrinkwrap.json file, sorted by name and version. Each package is
+// listed once, even if several versions of other packages depend on it.
+// Links to local packages and bundled dependencies, which are part of the
+// tarball of another package, are not listed.
+func ParseNpmLockfile(content []byte) ([]NpmLockPackage, error) {
+	var lockfile npmLockfile
+	if err := json.Unmarshal(content, &lockfile); err != nil {
+		return nil, fmt.Errorf("%w: lockfile: %w", serrors.ErrorInvalidFormat, err)
+	}
+
+	seen := make(map[NpmLockPackage]bool)
+	var packages []NpmLockPackage
+	add := func(p NpmLockPackage) {
+		if !seen[p] {
+			seen[p] = true
+			packages = append(packages, p)
+		}
+	}
+	switch {
+	case lockfile.LockfileVersion >= 2 && lockfile.Packages != nil:
+		for path, entry := range lockfile.Packages {
+			// The root package has an empty path.
+			if path == "" || entry.Link || entry.InBundle {
+				continue
+			}
+			i := strings.LastIndex(path, "node_modules/")
+			if i < 0 {
+				// A workspace package.
+				continue
+			}
+			name := entry.Name
+			if name == "" {
+				name = path[i+len("node_modules/"):]
+			}
+			add(NpmLockPackage{Name: name, Version: entry.Version, Integrity: entry.Integrity})
+		}
+	case lockfile.LockfileVersion == 1:
+		var walk func(deps map[string]npmLockV1Entry)
+		walk = func(deps map[string]npmLockV1Entry) {
+			for name, entry := range deps {
+				if !entry.Bundled && !strings.HasPrefix(entry.Version, "file:") {
+					add(NpmLockPackage{Name: name, Version: entry.Version, Integrity: entry.Integrity})
+				}
+				walk(entry.Dependencies)
+			}
+		}
+		walk(lockfile.Dependencies)
+	default:
+		return nil, fmt.Errorf("%w: unsupported lockfile version %d", serrors.ErrorInvalidFormat, lockfile.LockfileVersion)
+	}
+
+	sort.Slice(packages, func(i, j int) bool {
+		if packages[i].Name != packages[j].Name {
+			return packages[i].Name < packages[j].Name
+		}
+		return packages[i].Version < packages[j].Version
+	})
+	return packages, nil
+}
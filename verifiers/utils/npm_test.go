@@ -0,0 +1,239 @@
+package utils
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	serrors "github.com/slsa-framework/slsa-verifier/v2/errors"
+)
+
+func Test_NpmAttestationsURL(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		registry string
+		pkg      string
+		version  string
+		expected string
+		err      error
+	}{
+		{
+			name:     "scoped package",
+			registry: DefaultNpmRegistry,
+			pkg:      "@ianlewis/actions-test",
+			version:  "0.1.132",
+			expected: "https://registry.npmjs.org/-/npm/v1/attestations/@ianlewis%2factions-test@0.1.132",
+		},
+		{
+			name:     "unscoped package on a registry with a path",
+			registry: "http://127.0.0.1:4873/npm/",
+			pkg:      "gundam-visor",
+			version:  "1.0.1",
+			expected: "http://127.0.0.1:4873/npm/-/npm/v1/attestations/gundam-visor@1.0.1",
+		},
+		{
+			name:     "no version",
+			registry: DefaultNpmRegistry,
+			pkg:      "gundam-visor",
+			err:      serrors.ErrorInvalidPackageName,
+		},
+		{
+			name:     "not a URL",
+			registry: "registry.npmjs.org",
+			pkg:      "gundam-visor",
+			version:  "1.0.1",
+			err:      serrors.ErrorMalformedURI,
+		},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			u, err := NpmAttestationsURL(tt.registry, tt.pkg, tt.version)
+			if diff := cmp.Diff(tt.err, err, cmpopts.EquateErrors()); diff != "" {
+				t.Fatalf("unexpected error (-want +got):\n%s", diff)
+			}
+			if u != tt.expected {
+				t.Errorf("unexpected URL: got %q, want %q", u, tt.expected)
+			}
+		})
+	}
+}
+
+func Test_FetchNpmAttestations(t *testing.T) {
+	t.Parallel()
+
+	attestations := `{"attestations": []}`
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The escaped slash of a scoped package name is preserved.
+		if r.URL.EscapedPath() != "/-/npm/v1/attestations/@scope%2fpkg@1.0.0" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(attestations))
+	}))
+	t.Cleanup(s.Close)
+
+	testCases := []struct {
+		name     string
+		pkg      string
+		expected string
+		err      error
+	}{
+		{
+			name:     "published with attestations",
+			pkg:      "@scope/pkg",
+			expected: attestations,
+		},
+		{
+			name: "not found",
+			pkg:  "@scope/other",
+			err:  serrors.ErrorNotPresent,
+		},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			u, err := NpmAttestationsURL(s.URL, tt.pkg, "1.0.0")
+			if err != nil {
+				t.Fatal(err)
+			}
+			content, err := FetchNpmAttestations(context.Background(), u)
+			if diff := cmp.Diff(tt.err, err, cmpopts.EquateErrors()); diff != "" {
+				t.Fatalf("unexpected error (-want +got):\n%s", diff)
+			}
+			if string(content) != tt.expected {
+				t.Errorf("unexpected attestations: got %q, want %q", content, tt.expected)
+			}
+		})
+	}
+}
+
+func Test_ParseNpmLockfile(t *testing.T) {
+	t.Parallel()
+
+	integrity := "sha512-" + strings.Repeat("A", 86) + "=="
+	testCases := []struct {
+		name     string
+		lockfile string
+		expected []NpmLockPackage
+		err      error
+	}{
+		{
+			name: "lockfile version 3",
+			lockfile: `{
+				"lockfileVersion": 3,
+				"packages": {
+					"": {"name": "app", "version": "1.0.0"},
+					"node_modules/@scope/pkg": {"version": "2.0.0", "integrity": "` + integrity + `"},
+					"node_modules/dep": {"version": "1.0.0", "integrity": "` + integrity + `"},
+					"node_modules/other/node_modules/dep": {"version": "1.0.0", "integrity": "` + integrity + `"},
+					"node_modules/other": {"version": "3.0.0", "integrity": "sha1-AAAA"},
+					"node_modules/alias": {"name": "real", "version": "1.2.3", "integrity": "` + integrity + `"},
+					"node_modules/other/node_modules/bundled": {"version": "1.0.0", "inBundle": true},
+					"node_modules/workspace": {"resolved": "packages/workspace", "link": true},
+					"packages/workspace": {"version": "0.1.0"}
+				}
+			}`,
+			expected: []NpmLockPackage{
+				{Name: "@scope/pkg", Version: "2.0.0", Integrity: integrity},
+				{Name: "dep", Version: "1.0.0", Integrity: integrity},
+				{Name: "other", Version: "3.0.0", Integrity: "sha1-AAAA"},
+				{Name: "real", Version: "1.2.3", Integrity: integrity},
+			},
+		},
+		{
+			name: "lockfile version 1",
+			lockfile: `{
+				"lockfileVersion": 1,
+				"dependencies": {
+					"dep": {"version": "1.0.0", "integrity": "` + integrity + `",
+						"dependencies": {"nested": {"version": "2.0.0", "integrity": "` + integrity + `"}}},
+					"bundled": {"version": "1.0.0", "bundled": true},
+					"local": {"version": "file:../local"}
+				}
+			}`,
+			expected: []NpmLockPackage{
+				{Name: "dep", Version: "1.0.0", Integrity: integrity},
+				{Name: "nested", Version: "2.0.0", Integrity: integrity},
+			},
+		},
+		{
+			name:     "no lockfile version",
+			lockfile: `{"name": "app"}`,
+			err:      serrors.ErrorInvalidFormat,
+		},
+		{
+			name:     "not JSON",
+			lockfile: `lockfileVersion: 3`,
+			err:      serrors.ErrorInvalidFormat,
+		},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			packages, err := ParseNpmLockfile([]byte(tt.lockfile))
+			if diff := cmp.Diff(tt.err, err, cmpopts.EquateErrors()); diff != "" {
+				t.Fatalf("unexpected error (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tt.expected, packages); diff != "" {
+				t.Errorf("unexpected packages (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_NpmLockPackage_SHA512(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name      string
+		integrity string
+		expected  string
+		ok        bool
+	}{
+		{
+			name:      "sha512",
+			integrity: "sha512-" + strings.Repeat("G", 86) + "==",
+			expected:  strings.Repeat("186", 42) + "18",
+			ok:        true,
+		},
+		{
+			name:      "sha1 and sha512",
+			integrity: "sha1-AAAAAAAAAAAAAAAAAAAAAAAAAAA= sha512-" + strings.Repeat("G", 86) + "==",
+			expected:  strings.Repeat("186", 42) + "18",
+			ok:        true,
+		},
+		{
+			name:      "sha1",
+			integrity: "sha1-AAAAAAAAAAAAAAAAAAAAAAAAAAA=",
+		},
+		{
+			name:      "truncated sha512",
+			integrity: "sha512-AAAA",
+		},
+		{
+			name: "no integrity",
+		},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			p := NpmLockPackage{Integrity: tt.integrity}
+			digest, ok := p.SHA512()
+			if ok != tt.ok || digest != tt.expected {
+				t.Errorf("unexpected digest: got %q %v, want %q %v", digest, ok, tt.expected, tt.ok)
+			}
+		})
+	}
+}
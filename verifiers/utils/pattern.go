@@ -0,0 +1,34 @@
+package utils
+
+import (
+	"fmt"
+	"path"
+
+	serrors "github.com/slsa-framework/slsa-verifier/v2/errors"
+)
+
+// ValidatePatterns validates that the patterns use the syntax of path.Match.
+func ValidatePatterns(patterns []string) error {
+	for _, p := range patterns {
+		if _, err := path.Match(p, ""); err != nil {
+			return fmt.Errorf("%w: pattern %q: %w", serrors.ErrorInvalidFormat, p, err)
+		}
+	}
+	return nil
+}
+
+// MatchesAnyPattern returns true if name matches one of the patterns.
+// Patterns use the syntax of path.Match, so "release/*" matches
+// "release/v1" but not "release/v1/rc1".
+func MatchesAnyPattern(name string, patterns []string) (bool, error) {
+	for _, p := range patterns {
+		ok, err := path.Match(p, name)
+		if err != nil {
+			return false, fmt.Errorf("%w: pattern %q: %w", serrors.ErrorInvalidFormat, p, err)
+		}
+		if ok {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,70 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	serrors "github.com/slsa-framework/slsa-verifier/v2/errors"
+)
+
+func Test_MatchesAnyPattern(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		value    string
+		patterns []string
+		expected bool
+		err      error
+	}{
+		{
+			name:     "exact match",
+			value:    "main",
+			patterns: []string{"main"},
+			expected: true,
+		},
+		{
+			name:     "second pattern matches",
+			value:    "release/v1",
+			patterns: []string{"main", "release/*"},
+			expected: true,
+		},
+		{
+			name:     "wildcard does not match separator",
+			value:    "release/v1/rc1",
+			patterns: []string{"release/*"},
+			expected: false,
+		},
+		{
+			name:     "no match",
+			value:    "dev",
+			patterns: []string{"main", "release/*"},
+			expected: false,
+		},
+		{
+			name:     "no patterns",
+			value:    "main",
+			expected: false,
+		},
+		{
+			name:     "invalid pattern",
+			value:    "main",
+			patterns: []string{"[main"},
+			err:      serrors.ErrorInvalidFormat,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			ok, err := MatchesAnyPattern(tc.value, tc.patterns)
+			if !cmp.Equal(err, tc.err, cmpopts.EquateErrors()) {
+				t.Fatalf("unexpected error: %v", cmp.Diff(err, tc.err, cmpopts.EquateErrors()))
+			}
+			if ok != tc.expected {
+				t.Errorf("expected %v, got %v", tc.expected, ok)
+			}
+		})
+	}
+}
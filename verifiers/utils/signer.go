@@ -0,0 +1,57 @@
+package utils
+
+import (
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+
+	fulcio "github.com/sigstore/fulcio/pkg/certificate"
+	"github.com/sigstore/sigstore/pkg/cryptoutils"
+	"github.com/transparency-dev/merkle/rfc6962"
+
+	"github.com/slsa-framework/slsa-verifier/v2/report"
+)
+
+// CertificateSigner returns the identity of a verified Fulcio certificate.
+// The workflow is the build signer URI, or else the URI of the certificate
+// of older Fulcio versions. The fields that cannot be read, or all of them
+// if cert is nil, are empty.
+func CertificateSigner(cert *x509.Certificate) report.Signer {
+	var s report.Signer
+	if cert == nil {
+		return s
+	}
+	if ext, err := fulcio.ParseExtensions(cert.Extensions); err == nil {
+		s.Workflow = ext.BuildSignerURI
+		s.Issuer = ext.Issuer
+	}
+	if sans := cryptoutils.GetSubjectAlternateNames(cert); len(sans) > 0 {
+		s.SAN = sans[0]
+	}
+	if s.Workflow == "" && len(cert.URIs) > 0 {
+		s.Workflow = cert.URIs[0].String()
+	}
+	return s
+}
+
+// NewRekorEntry returns the entry of the transparency log at logIndex with
+// the canonicalized body, either raw or base64-encoded as returned by the
+// Rekor API. The UUID is the hex-encoded RFC 6962 leaf hash of the body.
+func NewRekorEntry(logIndex int64, body any, integratedTime int64, logID string) report.RekorEntry {
+	e := report.RekorEntry{
+		LogIndex:       logIndex,
+		IntegratedTime: integratedTime,
+		LogID:          logID,
+	}
+	var content []byte
+	switch b := body.(type) {
+	case []byte:
+		content = b
+	case string:
+		content, _ = base64.StdEncoding.DecodeString(b)
+	}
+	if len(content) > 0 {
+		e.UUID = hex.EncodeToString(rfc6962.DefaultHasher.HashLeaf(content))
+	}
+	return e
+}
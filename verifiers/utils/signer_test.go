@@ -0,0 +1,132 @@
+package utils
+
+import (
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/base64"
+	"net/url"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	fulcio "github.com/sigstore/fulcio/pkg/certificate"
+
+	"github.com/slsa-framework/slsa-verifier/v2/report"
+)
+
+func Test_CertificateSigner(t *testing.T) {
+	t.Parallel()
+
+	san := "https://github.com/org/repo/.github/workflows/release.yml@refs/tags/v1.0.0"
+	uri, err := url.Parse(san)
+	if err != nil {
+		t.Fatal(err)
+	}
+	utf8 := func(s string) []byte {
+		b, err := asn1.MarshalWithParams(s, "utf8")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return b
+	}
+	signer := "https://github.com/org/builder/.github/workflows/builder.yml@refs/tags/v2.0.0"
+
+	tests := []struct {
+		name     string
+		cert     *x509.Certificate
+		expected report.Signer
+	}{
+		{
+			name: "build signer URI",
+			cert: &x509.Certificate{
+				URIs: []*url.URL{uri},
+				Extensions: []pkix.Extension{
+					{Id: fulcio.OIDIssuerV2, Value: utf8("https://token.actions.githubusercontent.com")},
+					{Id: fulcio.OIDBuildSignerURI, Value: utf8(signer)},
+				},
+			},
+			expected: report.Signer{
+				Workflow: signer,
+				Issuer:   "https://token.actions.githubusercontent.com",
+				SAN:      san,
+			},
+		},
+		{
+			name: "deprecated issuer",
+			cert: &x509.Certificate{
+				URIs: []*url.URL{uri},
+				Extensions: []pkix.Extension{
+					{Id: fulcio.OIDIssuer, Value: []byte("https://token.actions.githubusercontent.com")},
+				},
+			},
+			expected: report.Signer{
+				Workflow: san,
+				Issuer:   "https://token.actions.githubusercontent.com",
+				SAN:      san,
+			},
+		},
+		{
+			name: "email",
+			cert: &x509.Certificate{
+				EmailAddresses: []string{"user@example.com"},
+			},
+			expected: report.Signer{SAN: "user@example.com"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if diff := cmp.Diff(tt.expected, CertificateSigner(tt.cert)); diff != "" {
+				t.Errorf("unexpected signer (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_NewRekorEntry(t *testing.T) {
+	t.Parallel()
+
+	body := []byte(`{"apiVersion":"0.0.1","kind":"intoto"}`)
+	expected := report.RekorEntry{
+		LogIndex:       42,
+		UUID:           "8e0fb99bb66df3a6ae220c0e8c01379a69acef00ae5d513ff97e8598afed050e",
+		IntegratedTime: 1675272243,
+		LogID:          "c0d23d6a",
+	}
+
+	tests := []struct {
+		name     string
+		body     any
+		expected report.RekorEntry
+	}{
+		{
+			name:     "raw body",
+			body:     body,
+			expected: expected,
+		},
+		{
+			name:     "base64 body",
+			body:     base64.StdEncoding.EncodeToString(body),
+			expected: expected,
+		},
+		{
+			name: "no body",
+			expected: report.RekorEntry{
+				LogIndex:       42,
+				IntegratedTime: 1675272243,
+				LogID:          "c0d23d6a",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			entry := NewRekorEntry(42, tt.body, 1675272243, "c0d23d6a")
+			if diff := cmp.Diff(tt.expected, entry); diff != "" {
+				t.Errorf("unexpected entry (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -1,24 +1,39 @@
 package utils
 
 import (
-	"sync"
+	"crypto"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
 
 	sigstoreRoot "github.com/sigstore/sigstore-go/pkg/root"
 	sigstoreTUF "github.com/sigstore/sigstore-go/pkg/tuf"
+	"github.com/sigstore/sigstore/pkg/cryptoutils"
+
+	serrors "github.com/slsa-framework/slsa-verifier/v2/errors"
+	"github.com/slsa-framework/slsa-verifier/v2/options"
 )
 
 var (
-	// cache the default Sigstore TUF client.
-	defaultSigstoreTUFClient *sigstoreTUF.Client
-	// defaultSigstoreTUFClientOnce is used for initializing the defaultSigstoreTUFClient.
-	defaultSigstoreTUFClientOnce = new(sync.Once)
-
-	// cache the trusted root.
-	trustedRoot *sigstoreRoot.LiveTrustedRoot
-	// trustedRootOnce is used for initializing the trustedRoot.
-	trustedRootOnce = new(sync.Once)
+	// cache the Sigstore TUF clients, by TUF repository and initial root.
+	sigstoreTUFClients KeyedCache[*sigstoreTUF.Client]
+
+	// cache the live trusted roots, by TUF repository and initial root.
+	liveTrustedRoots KeyedCache[*sigstoreRoot.LiveTrustedRoot]
+
+	// cache the trusted material, by Sigstore options.
+	trustedMaterials KeyedCache[sigstoreRoot.TrustedMaterial]
 )
 
+func init() {
+	RegisterCache("sigstore-tuf-clients", &sigstoreTUFClients)
+	RegisterCache("trusted-roots", &liveTrustedRoots)
+	RegisterCache("trusted-materials", &trustedMaterials)
+}
+
 // SigstoreTUFClient is the interface for the Sigstore TUF client.
 type SigstoreTUFClient interface {
 	// GetTarget retrieves the target file from the TUF repository.
@@ -28,33 +43,258 @@ type SigstoreTUFClient interface {
 // GetDefaultSigstoreTUFClient returns the default Sigstore TUF client.
 // The client will be cached in memory.
 func GetDefaultSigstoreTUFClient() (*sigstoreTUF.Client, error) {
-	var err error
-	defaultSigstoreTUFClientOnce.Do(func() {
-		defaultSigstoreTUFClient, err = sigstoreTUF.DefaultClient()
-		if err != nil {
-			defaultSigstoreTUFClientOnce = new(sync.Once)
-			return
-		}
-	})
+	return GetSigstoreTUFClient(nil)
+}
+
+// GetSigstoreTUFClient returns the Sigstore TUF client for the Sigstore options.
+// The client will be cached in memory, by TUF repository and initial root.
+func GetSigstoreTUFClient(opts *options.SigstoreOpts) (*sigstoreTUF.Client, error) {
+	tufOpts, err := sigstoreTUFOptions(opts)
 	if err != nil {
 		return nil, err
 	}
-	return defaultSigstoreTUFClient, nil
+	return sigstoreTUFClients.Get(tufOptionsKey(tufOpts), func() (*sigstoreTUF.Client, error) {
+		return sigstoreTUF.New(tufOpts)
+	})
 }
 
 // GetSigstoreTrustedRoot returns the trusted root for the Sigstore TUF client.
 func GetSigstoreTrustedRoot() (*sigstoreRoot.LiveTrustedRoot, error) {
-	var err error
-	trustedRootOnce.Do(func() {
-		opts := sigstoreTUF.DefaultOptions()
-		trustedRoot, err = sigstoreRoot.NewLiveTrustedRoot(opts)
+	return getLiveTrustedRoot(nil)
+}
+
+func getLiveTrustedRoot(opts *options.SigstoreOpts) (*sigstoreRoot.LiveTrustedRoot, error) {
+	tufOpts, err := sigstoreTUFOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+	return liveTrustedRoots.Get(tufOptionsKey(tufOpts), func() (*sigstoreRoot.LiveTrustedRoot, error) {
+		trustedRoot, err := sigstoreRoot.NewLiveTrustedRoot(tufOpts)
 		if err != nil {
-			trustedRootOnce = new(sync.Once)
-			return
+			return nil, fmt.Errorf("%w: TUF repository %q: %w", serrors.ErrorUnavailable, tufOpts.RepositoryBaseURL, err)
 		}
+		return trustedRoot, nil
 	})
+}
+
+// sigstoreTUFOptions returns the options of the TUF repository to fetch
+// the trusted root from: the public-good one, or TUFMirrorURL.
+func sigstoreTUFOptions(opts *options.SigstoreOpts) (*sigstoreTUF.Options, error) {
+	tufOpts := sigstoreTUF.DefaultOptions()
+	if opts == nil || opts.TUFMirrorURL == "" {
+		return tufOpts, nil
+	}
+	if opts.TUFRootPath == "" {
+		return nil, fmt.Errorf("%w: TUF mirror %q: no initial root.json", serrors.ErrorInvalidFormat, opts.TUFMirrorURL)
+	}
+	root, err := os.ReadFile(opts.TUFRootPath)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("%w: TUF root %q: %w", serrors.ErrorInvalidFormat, opts.TUFRootPath, err)
+	}
+	tufOpts.RepositoryBaseURL = opts.TUFMirrorURL
+	tufOpts.Root = root
+	return tufOpts, nil
+}
+
+// tufOptionsKey returns a key that identifies the TUF repository and its
+// initial root: a mirror may be configured with another root.
+func tufOptionsKey(tufOpts *sigstoreTUF.Options) string {
+	return tufOpts.RepositoryBaseURL + "#" + digestKey(tufOpts.Root)
+}
+
+// digestKey returns the hex-encoded sha256 digest of content.
+func digestKey(content []byte) string {
+	digest := sha256.Sum256(content)
+	return hex.EncodeToString(digest[:])
+}
+
+// SigstoreOptsKey returns a key that identifies the Sigstore deployment
+// configured by the options, to cache clients and keys by.
+func SigstoreOptsKey(opts *options.SigstoreOpts) string {
+	if opts == nil {
+		return ""
+	}
+	// Marshaling a struct of strings, bools and slices cannot fail.
+	b, _ := json.Marshal(opts)
+	key := string(b)
+	// The initial TUF root is identified by its content, which may change
+	// at the same path. A root that cannot be read fails the verification.
+	if opts.TUFRootPath != "" {
+		if root, err := os.ReadFile(opts.TUFRootPath); err == nil {
+			key += "#" + digestKey(root)
+		}
+	}
+	// The trusted material and the Rekor client are identified by address.
+	if opts.TrustedMaterial != nil || opts.RekorClient != nil {
+		key += fmt.Sprintf("%p%p", opts.TrustedMaterial, opts.RekorClient)
+	}
+	return key
+}
+
+// GetTrustedMaterial returns the trusted material to verify Sigstore signatures with.
+// A nil opts selects the trusted root of the public-good Sigstore instance.
+func GetTrustedMaterial(opts *options.SigstoreOpts) (sigstoreRoot.TrustedMaterial, error) {
+	if opts == nil || (opts.TrustedMaterial == nil && opts.TrustedRootPath == "" && !opts.Offline &&
+		!hasTrustedMaterialOverrides(opts)) {
+		// The live trusted root refreshes itself, and is cached by TUF repository.
+		trustedRoot, err := getLiveTrustedRoot(opts)
+		if err != nil {
+			return nil, err
+		}
+		return trustedRoot, nil
+	}
+	return trustedMaterials.Get(SigstoreOptsKey(opts), func() (sigstoreRoot.TrustedMaterial, error) {
+		return newTrustedMaterial(opts)
+	})
+}
+
+func newTrustedMaterial(opts *options.SigstoreOpts) (sigstoreRoot.TrustedMaterial, error) {
+	var trustedMaterial sigstoreRoot.TrustedMaterial
+	switch {
+	case opts.TrustedMaterial != nil:
+		trustedMaterial = opts.TrustedMaterial
+	case opts.TrustedRootPath != "":
+		trustedRoot, err := sigstoreRoot.NewTrustedRootFromPath(opts.TrustedRootPath)
+		if err != nil {
+			return nil, fmt.Errorf("%w: trusted root %q: %w", serrors.ErrorInvalidFormat, opts.TrustedRootPath, err)
+		}
+		trustedMaterial = trustedRoot
+	case opts.Offline:
+		return nil, fmt.Errorf("%w: offline verification requires a trusted root file", serrors.ErrorRequiresNetwork)
+	default:
+		trustedRoot, err := getLiveTrustedRoot(opts)
+		if err != nil {
+			return nil, err
+		}
+		trustedMaterial = trustedRoot
+	}
+	return withTrustedMaterialOverrides(trustedMaterial, opts)
+}
+
+func hasTrustedMaterialOverrides(opts *options.SigstoreOpts) bool {
+	return len(opts.RekorPublicKeyPaths) > 0 || len(opts.CTLogPublicKeyPaths) > 0 ||
+		len(opts.FulcioCertificatePaths) > 0
+}
+
+// overriddenTrustedMaterial replaces parts of a trusted material
+// with the keys and certificates configured in the Sigstore options.
+type overriddenTrustedMaterial struct {
+	sigstoreRoot.TrustedMaterial
+	fulcioCertificateAuthorities []sigstoreRoot.CertificateAuthority
+	rekorLogs                    map[string]*sigstoreRoot.TransparencyLog
+	ctLogs                       map[string]*sigstoreRoot.TransparencyLog
+}
+
+func (m *overriddenTrustedMaterial) FulcioCertificateAuthorities() []sigstoreRoot.CertificateAuthority {
+	if m.fulcioCertificateAuthorities != nil {
+		return m.fulcioCertificateAuthorities
+	}
+	return m.TrustedMaterial.FulcioCertificateAuthorities()
+}
+
+func (m *overriddenTrustedMaterial) RekorLogs() map[string]*sigstoreRoot.TransparencyLog {
+	if m.rekorLogs != nil {
+		return m.rekorLogs
+	}
+	return m.TrustedMaterial.RekorLogs()
+}
+
+func (m *overriddenTrustedMaterial) CTLogs() map[string]*sigstoreRoot.TransparencyLog {
+	if m.ctLogs != nil {
+		return m.ctLogs
+	}
+	return m.TrustedMaterial.CTLogs()
+}
+
+func withTrustedMaterialOverrides(trustedMaterial sigstoreRoot.TrustedMaterial,
+	opts *options.SigstoreOpts,
+) (sigstoreRoot.TrustedMaterial, error) {
+	if !hasTrustedMaterialOverrides(opts) {
+		return trustedMaterial, nil
+	}
+	m := &overriddenTrustedMaterial{TrustedMaterial: trustedMaterial}
+	var err error
+	if len(opts.FulcioCertificatePaths) > 0 {
+		m.fulcioCertificateAuthorities, err = loadCertificateAuthorities(opts.FulcioCertificatePaths)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if len(opts.RekorPublicKeyPaths) > 0 {
+		m.rekorLogs, err = loadTransparencyLogs(opts.RekorPublicKeyPaths, opts.RekorURL)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", serrors.ErrorRekorPubKey, err)
+		}
+	}
+	if len(opts.CTLogPublicKeyPaths) > 0 {
+		m.ctLogs, err = loadTransparencyLogs(opts.CTLogPublicKeyPaths, "")
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", serrors.ErrorInvalidPublicKey, err)
+		}
+	}
+	return m, nil
+}
+
+// loadCertificateAuthorities returns one certificate authority per root
+// certificate in the files, with all the other certificates as intermediates.
+func loadCertificateAuthorities(paths []string) ([]sigstoreRoot.CertificateAuthority, error) {
+	var roots, intermediates []*x509.Certificate
+	for _, path := range paths {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", serrors.ErrorInvalidPEM, err)
+		}
+		certs, err := cryptoutils.UnmarshalCertificatesFromPEM(content)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %q: %w", serrors.ErrorInvalidPEM, path, err)
+		}
+		for _, cert := range certs {
+			if cert.CheckSignatureFrom(cert) == nil {
+				roots = append(roots, cert)
+			} else {
+				intermediates = append(intermediates, cert)
+			}
+		}
+	}
+	if len(roots) == 0 {
+		return nil, fmt.Errorf("%w: no root certificate in %v", serrors.ErrorInvalidCertificate, paths)
+	}
+	cas := make([]sigstoreRoot.CertificateAuthority, 0, len(roots))
+	for _, root := range roots {
+		cas = append(cas, sigstoreRoot.CertificateAuthority{
+			Root:          root,
+			Intermediates: intermediates,
+		})
+	}
+	return cas, nil
+}
+
+// loadTransparencyLogs returns the transparency logs of the public keys
+// in the files, indexed by hex-encoded log ID.
+func loadTransparencyLogs(paths []string, baseURL string) (map[string]*sigstoreRoot.TransparencyLog, error) {
+	logs := make(map[string]*sigstoreRoot.TransparencyLog, len(paths))
+	for _, path := range paths {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		pubKey, err := cryptoutils.UnmarshalPEMToPublicKey(content)
+		if err != nil {
+			return nil, fmt.Errorf("%q: %w", path, err)
+		}
+		der, err := cryptoutils.MarshalPublicKeyToDER(pubKey)
+		if err != nil {
+			return nil, fmt.Errorf("%q: %w", path, err)
+		}
+		// The log ID is the SHA-256 digest of the DER-encoded public key.
+		id := sha256.Sum256(der)
+		logs[hex.EncodeToString(id[:])] = &sigstoreRoot.TransparencyLog{
+			BaseURL:           baseURL,
+			ID:                id[:],
+			HashFunc:          crypto.SHA256,
+			PublicKey:         pubKey,
+			SignatureHashFunc: crypto.SHA256,
+		}
 	}
-	return trustedRoot, nil
+	return logs, nil
 }
@@ -0,0 +1,241 @@
+package utils
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/sigstore/sigstore/pkg/cryptoutils"
+
+	serrors "github.com/slsa-framework/slsa-verifier/v2/errors"
+	"github.com/slsa-framework/slsa-verifier/v2/options"
+)
+
+const trustedRootPath = "../internal/gha/testdata/trusted_root.json"
+
+func writePublicKey(t *testing.T, dir, name string) string {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pemBytes, err := cryptoutils.MarshalPublicKeyToPEM(key.Public())
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, pemBytes, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func writeCertificates(t *testing.T, dir, name string) string {
+	t.Helper()
+	rootKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rootTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "root"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	rootDER, err := x509.CreateCertificate(rand.Reader, rootTemplate, rootTemplate, rootKey.Public(), rootKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	root, err := x509.ParseCertificate(rootDER)
+	if err != nil {
+		t.Fatal(err)
+	}
+	intermediateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	intermediateTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(2),
+		Subject:               pkix.Name{CommonName: "intermediate"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	intermediateDER, err := x509.CreateCertificate(rand.Reader, intermediateTemplate, root, intermediateKey.Public(), rootKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	intermediate, err := x509.ParseCertificate(intermediateDER)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pemBytes, err := cryptoutils.MarshalCertificatesToPEM([]*x509.Certificate{intermediate, root})
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, pemBytes, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func Test_GetTrustedMaterial(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	rekorKey := writePublicKey(t, dir, "rekor.pub")
+	ctLogKey := writePublicKey(t, dir, "ctlog.pub")
+	fulcioCerts := writeCertificates(t, dir, "fulcio.pem")
+
+	tests := []struct {
+		name          string
+		opts          *options.SigstoreOpts
+		rekorLogs     int
+		ctLogs        int
+		fulcioCAs     int
+		intermediates int
+		err           error
+	}{
+		{
+			name:          "trusted root",
+			opts:          &options.SigstoreOpts{TrustedRootPath: trustedRootPath, Offline: true},
+			rekorLogs:     1,
+			ctLogs:        2,
+			fulcioCAs:     2,
+			intermediates: 0,
+		},
+		{
+			name: "overrides",
+			opts: &options.SigstoreOpts{
+				TrustedRootPath:        trustedRootPath,
+				Offline:                true,
+				RekorPublicKeyPaths:    []string{rekorKey},
+				CTLogPublicKeyPaths:    []string{ctLogKey, rekorKey},
+				FulcioCertificatePaths: []string{fulcioCerts},
+			},
+			rekorLogs:     1,
+			ctLogs:        2,
+			fulcioCAs:     1,
+			intermediates: 1,
+		},
+		{
+			name: "offline without trusted root",
+			opts: &options.SigstoreOpts{Offline: true},
+			err:  serrors.ErrorRequiresNetwork,
+		},
+		{
+			name: "TUF mirror without root",
+			opts: &options.SigstoreOpts{TUFMirrorURL: "https://tuf.example.com"},
+			err:  serrors.ErrorInvalidFormat,
+		},
+		{
+			name: "invalid Rekor key",
+			opts: &options.SigstoreOpts{
+				TrustedRootPath:     trustedRootPath,
+				RekorPublicKeyPaths: []string{fulcioCerts},
+			},
+			err: serrors.ErrorRekorPubKey,
+		},
+		{
+			name: "no Fulcio root",
+			opts: &options.SigstoreOpts{
+				TrustedRootPath:        trustedRootPath,
+				FulcioCertificatePaths: []string{rekorKey},
+			},
+			err: serrors.ErrorInvalidPEM,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			trustedMaterial, err := GetTrustedMaterial(tt.opts)
+			if !cmp.Equal(err, tt.err, cmpopts.EquateErrors()) {
+				t.Fatalf("unexpected error: %v", cmp.Diff(err, tt.err, cmpopts.EquateErrors()))
+			}
+			if err != nil {
+				return
+			}
+			if n := len(trustedMaterial.RekorLogs()); n != tt.rekorLogs {
+				t.Errorf("expected %d Rekor logs, got %d", tt.rekorLogs, n)
+			}
+			if n := len(trustedMaterial.CTLogs()); n != tt.ctLogs {
+				t.Errorf("expected %d CT logs, got %d", tt.ctLogs, n)
+			}
+			cas := trustedMaterial.FulcioCertificateAuthorities()
+			if len(cas) != tt.fulcioCAs {
+				t.Fatalf("expected %d Fulcio CAs, got %d", tt.fulcioCAs, len(cas))
+			}
+			if n := len(cas[0].Intermediates); n != tt.intermediates {
+				t.Errorf("expected %d intermediates, got %d", tt.intermediates, n)
+			}
+
+			// The trusted material is cached by options.
+			cached, err := GetTrustedMaterial(tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if cached != trustedMaterial {
+				t.Errorf("expected the trusted material to be cached")
+			}
+		})
+	}
+}
+
+func Test_SigstoreOptsKey(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	rootPath := filepath.Join(dir, "root.json")
+	if err := os.WriteFile(rootPath, []byte(`{"version": 1}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	opts := &options.SigstoreOpts{TUFMirrorURL: "https://tuf.example.com", TUFRootPath: rootPath}
+	key := SigstoreOptsKey(opts)
+	tufOpts, err := sigstoreTUFOptions(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tufKey := tufOptionsKey(tufOpts)
+
+	// Another root at the same path is another Sigstore deployment.
+	if err := os.WriteFile(rootPath, []byte(`{"version": 2}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if SigstoreOptsKey(opts) == key {
+		t.Errorf("same key %q for another TUF root", key)
+	}
+	tufOpts, err = sigstoreTUFOptions(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tufOptionsKey(tufOpts) == tufKey {
+		t.Errorf("same TUF key %q for another TUF root", tufKey)
+	}
+
+	// The same repository with another initial root has another key.
+	defaultOpts, err := sigstoreTUFOptions(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mirrorOpts := *defaultOpts
+	mirrorOpts.Root = []byte(`{"version": 1}`)
+	if tufOptionsKey(defaultOpts) == tufOptionsKey(&mirrorOpts) {
+		t.Errorf("same TUF key %q for another TUF root", tufOptionsKey(defaultOpts))
+	}
+}
@@ -8,7 +8,14 @@ import (
 	"golang.org/x/mod/semver"
 )
 
+// VerifyVersionedTag verifies the semantic version of the provenance tag
+// against expectedTag. expectedTag is either a version, of which the
+// components given must match, e.g. v1 matches any v1.x.y release and v1.2
+// any v1.2.x release, or a version range, see ValidateVersionedTag.
 func VerifyVersionedTag(provenanceTag, expectedTag string) error {
+	if isVersionRange(expectedTag) {
+		return verifyVersionRange(provenanceTag, expectedTag)
+	}
 	if !semver.IsValid(expectedTag) {
 		return fmt.Errorf("%s: %w", expectedTag, serrors.ErrorInvalidSemver)
 	}
@@ -22,8 +29,8 @@ func VerifyVersionedTag(provenanceTag, expectedTag string) error {
 	expectedMajor := semver.Major(expectedTag)
 	major := semver.Major(semTag)
 	if major != expectedMajor {
-		return fmt.Errorf("%w: major version expected '%s', got '%s'",
-			serrors.ErrorMismatchVersionedTag, expectedMajor, major)
+		return serrors.NewVerificationError(fmt.Errorf("%w: major version expected '%s', got '%s'",
+			serrors.ErrorMismatchVersionedTag, expectedMajor, major), expectedTag, provenanceTag)
 	}
 
 	expectedMinor, err := minorVersion(expectedTag)
@@ -35,8 +42,8 @@ func VerifyVersionedTag(provenanceTag, expectedTag string) error {
 		}
 
 		if minor != expectedMinor {
-			return fmt.Errorf("%w: minor version expected '%s', got '%s'",
-				serrors.ErrorMismatchVersionedTag, expectedMinor, minor)
+			return serrors.NewVerificationError(fmt.Errorf("%w: minor version expected '%s', got '%s'",
+				serrors.ErrorMismatchVersionedTag, expectedMinor, minor), expectedTag, provenanceTag)
 		}
 	}
 
@@ -49,8 +56,8 @@ func VerifyVersionedTag(provenanceTag, expectedTag string) error {
 		}
 
 		if patch != expectedPatch {
-			return fmt.Errorf("%w: patch version expected '%s', got '%s'",
-				serrors.ErrorMismatchVersionedTag, expectedPatch, patch)
+			return serrors.NewVerificationError(fmt.Errorf("%w: patch version expected '%s', got '%s'",
+				serrors.ErrorMismatchVersionedTag, expectedPatch, patch), expectedTag, provenanceTag)
 		}
 	}
 
@@ -77,3 +84,91 @@ func extractFromVersion(v string, i int) (string, error) {
 	}
 	return parts[i], nil
 }
+
+// versionComparison is a comparison of a version range, e.g. >=v1.2.0.
+type versionComparison struct {
+	op      string
+	version string
+}
+
+// versionOperators are the operators of the comparisons of version
+// ranges, longest first.
+var versionOperators = []string{">=", "<=", ">", "<", "="}
+
+func isVersionRange(s string) bool {
+	return strings.ContainsAny(s, "<>=")
+}
+
+// ValidateVersionedTag validates an expected versioned tag: a semantic
+// version, e.g. v1 or v1.2, or a version range. A version range is a list
+// of comparisons separated by spaces, that a version must all satisfy,
+// e.g. ">=v1.2.0 <v2". Each comparison is one of the operators >=, >, <=,
+// < and = followed by a semantic version. Versions are compared by
+// semantic version precedence, so v2 is v2.0.0, and v2.0.0-rc.1 is lower
+// than v2.0.0.
+func ValidateVersionedTag(expectedTag string) error {
+	if isVersionRange(expectedTag) {
+		_, err := parseVersionRange(expectedTag)
+		return err
+	}
+	if !semver.IsValid(expectedTag) {
+		return fmt.Errorf("%s: %w", expectedTag, serrors.ErrorInvalidSemver)
+	}
+	return nil
+}
+
+// parseVersionRange parses a version range. See ValidateVersionedTag.
+func parseVersionRange(r string) ([]versionComparison, error) {
+	fields := strings.Fields(r)
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("%w: empty version range", serrors.ErrorInvalidSemver)
+	}
+	comparisons := make([]versionComparison, 0, len(fields))
+	for _, f := range fields {
+		var c versionComparison
+		for _, op := range versionOperators {
+			if strings.HasPrefix(f, op) {
+				c = versionComparison{op: op, version: strings.TrimPrefix(f, op)}
+				break
+			}
+		}
+		if c.op == "" || !semver.IsValid(c.version) {
+			return nil, fmt.Errorf("%w: comparison %q of version range %q", serrors.ErrorInvalidSemver, f, r)
+		}
+		comparisons = append(comparisons, c)
+	}
+	return comparisons, nil
+}
+
+// verifyVersionRange verifies that the provenance tag is in the version
+// range.
+func verifyVersionRange(provenanceTag, versionRange string) error {
+	comparisons, err := parseVersionRange(versionRange)
+	if err != nil {
+		return err
+	}
+	if !semver.IsValid(provenanceTag) {
+		return fmt.Errorf("%s: %w", provenanceTag, serrors.ErrorInvalidSemver)
+	}
+	for _, c := range comparisons {
+		cmp := semver.Compare(provenanceTag, c.version)
+		var ok bool
+		switch c.op {
+		case ">=":
+			ok = cmp >= 0
+		case "<=":
+			ok = cmp <= 0
+		case ">":
+			ok = cmp > 0
+		case "<":
+			ok = cmp < 0
+		case "=":
+			ok = cmp == 0
+		}
+		if !ok {
+			return serrors.NewVerificationError(fmt.Errorf("%w: expected version in range '%s', got '%s'",
+				serrors.ErrorMismatchVersionedTag, versionRange, provenanceTag), versionRange, provenanceTag)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+
+	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
+
+	serrors "github.com/slsa-framework/slsa-verifier/v2/errors"
+)
+
+// IsUnavailable returns true if err is caused by an unreachable or failing
+// service, e.g. Rekor, a TUF repository or a container registry, or by a
+// canceled context, rather than by a failed verification. The
+// verification may then succeed if it is retried.
+func IsUnavailable(err error) bool {
+	if errors.Is(err, serrors.ErrorUnavailable) ||
+		errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) {
+		return true
+	}
+	// Errors of container registries.
+	var transportErr *transport.Error
+	if errors.As(err, &transportErr) {
+		return isUnavailableStatus(transportErr.StatusCode)
+	}
+	// Errors of the generated clients of Rekor.
+	var serverErr interface {
+		IsServerError() bool
+		IsCode(code int) bool
+	}
+	if errors.As(err, &serverErr) {
+		return serverErr.IsServerError() || serverErr.IsCode(http.StatusTooManyRequests)
+	}
+	// Network errors, including those of HTTP clients.
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+func isUnavailableStatus(code int) bool {
+	return code >= http.StatusInternalServerError || code == http.StatusTooManyRequests
+}
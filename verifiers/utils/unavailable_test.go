@@ -0,0 +1,73 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"syscall"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
+	"github.com/sigstore/rekor/pkg/generated/client/entries"
+
+	serrors "github.com/slsa-framework/slsa-verifier/v2/errors"
+)
+
+func Test_IsUnavailable(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		err         error
+		unavailable bool
+	}{
+		{
+			name:        "unavailable",
+			err:         fmt.Errorf("%w: %w", serrors.ErrorFetchAttestations, serrors.ErrorUnavailable),
+			unavailable: true,
+		},
+		{
+			name:        "timeout",
+			err:         fmt.Errorf("%w: %w", serrors.ErrorRekorSearch, context.DeadlineExceeded),
+			unavailable: true,
+		},
+		{
+			name: "network error",
+			err: fmt.Errorf("%w: %w", serrors.ErrorRekorSearch,
+				&url.Error{Op: "Post", URL: "https://rekor.sigstore.dev", Err: syscall.ECONNREFUSED}),
+			unavailable: true,
+		},
+		{
+			name:        "registry error",
+			err:         fmt.Errorf("%w: %w", serrors.ErrorImageHash, &transport.Error{StatusCode: http.StatusBadGateway}),
+			unavailable: true,
+		},
+		{
+			name: "missing image",
+			err:  fmt.Errorf("%w: %w", serrors.ErrorImageHash, &transport.Error{StatusCode: http.StatusNotFound}),
+		},
+		{
+			name:        "rekor error",
+			err:         fmt.Errorf("%w: %w", serrors.ErrorRekorSearch, entries.NewSearchLogQueryDefault(http.StatusServiceUnavailable)),
+			unavailable: true,
+		},
+		{
+			name: "invalid rekor query",
+			err:  fmt.Errorf("%w: %w", serrors.ErrorRekorSearch, entries.NewSearchLogQueryBadRequest()),
+		},
+		{
+			name: "failed verification",
+			err:  fmt.Errorf("%w: %s", serrors.ErrorMismatchSource, "github.com/org/repo"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := IsUnavailable(tt.err); got != tt.unavailable {
+				t.Errorf("unexpected result: got %v, want %v", got, tt.unavailable)
+			}
+		})
+	}
+}
@@ -1,22 +1,49 @@
+// Package verifiers verifies provenance with the verifier of its builder.
+// Its functions do not modify the options they are given.
+//
+// Package verifier is a higher-level API on top of these functions, with
+// functional options and structured results.
 package verifiers
 
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	serrors "github.com/slsa-framework/slsa-verifier/v2/errors"
 	"github.com/slsa-framework/slsa-verifier/v2/options"
 	"github.com/slsa-framework/slsa-verifier/v2/register"
 	_ "github.com/slsa-framework/slsa-verifier/v2/verifiers/internal/gcb"
 	"github.com/slsa-framework/slsa-verifier/v2/verifiers/internal/gha"
+	"github.com/slsa-framework/slsa-verifier/v2/verifiers/internal/gitlab"
+	"github.com/slsa-framework/slsa-verifier/v2/verifiers/internal/tekton"
 	"github.com/slsa-framework/slsa-verifier/v2/verifiers/internal/vsa"
 	"github.com/slsa-framework/slsa-verifier/v2/verifiers/utils"
+	"github.com/slsa-framework/slsa-verifier/v2/verifiers/utils/container"
 )
 
-func getVerifier(builderOpts *options.BuilderOpts) (register.SLSAVerifier, error) {
+func getVerifier(provenanceOpts *options.ProvenanceOpts, builderOpts *options.BuilderOpts) (register.SLSAVerifier, error) {
 	// By default, use the GHA builders
 	verifier := register.SLSAVerifiers[gha.VerifierName]
 
+	// Builders of a GitHub Enterprise Server instance are verified by the GHA verifier.
+	if provenanceOpts != nil && provenanceOpts.GitHubOpts != nil && provenanceOpts.GitHubOpts.Host != "" {
+		return verifier, nil
+	}
+
+	// Runners of a self-managed GitLab instance are verified by the GitLab verifier.
+	if provenanceOpts != nil && provenanceOpts.GitLabOpts != nil && provenanceOpts.GitLabOpts.Host != "" &&
+		builderOpts.ExpectedID != nil &&
+		strings.HasPrefix(*builderOpts.ExpectedID, "https://"+provenanceOpts.GitLabOpts.Host+"/") {
+		return register.SLSAVerifiers[gitlab.VerifierName], nil
+	}
+
+	// Provenance signed with a public key is verified by the Tekton verifier,
+	// whose builder ID is configurable.
+	if provenanceOpts != nil && provenanceOpts.VerificationOpts != nil {
+		return register.SLSAVerifiers[tekton.VerifierName], nil
+	}
+
 	// If user provids a builderID, find the right verifier based on its ID.
 	if builderOpts.ExpectedID != nil &&
 		*builderOpts.ExpectedID != "" {
@@ -36,24 +63,53 @@ func getVerifier(builderOpts *options.BuilderOpts) (register.SLSAVerifier, error
 	return verifier, nil
 }
 
+// VerifyImage verifies the provenance of a container image, and of the
+// platform images of an image index if provenance is nil and the
+// attestations are fetched from the registry. It returns the verified
+// provenance and the ID of the builder that generated it.
 func VerifyImage(ctx context.Context, artifactImage string,
 	provenance []byte,
 	provenanceOpts *options.ProvenanceOpts,
 	builderOpts *options.BuilderOpts,
 ) ([]byte, *utils.TrustedBuilderID, error) {
-	verifier, err := getVerifier(builderOpts)
+	verifier, err := getVerifier(provenanceOpts, builderOpts)
 	if err != nil {
 		return nil, nil, err
 	}
+
+	// The images of a docker-archive tarball are verified in its OCI image
+	// layout. Callers that also compute the digest of the image pass the
+	// reference returned by container.OpenDockerArchive, to extract it once.
+	artifactImage, cleanup, err := container.OpenDockerArchive(artifactImage)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer cleanup()
+
+	// The platform images of an image index are verified too, when their
+	// attestations are fetched from the registry. Provenance supplied by
+	// the caller is verified for the image only.
+	if provenance == nil {
+		platforms, err := container.GetImagePlatforms(ctx, artifactImage)
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(platforms) > 0 {
+			return verifyImageIndex(ctx, verifier, provenance, artifactImage, platforms, provenanceOpts, builderOpts)
+		}
+	}
 	return verifier.VerifyImage(ctx, provenance, artifactImage, provenanceOpts, builderOpts)
 }
 
+// VerifyArtifact verifies the provenance of the artifact with the given
+// hex-encoded sha256 digest. It returns the verified provenance and the ID
+// of the builder that generated it.
 func VerifyArtifact(ctx context.Context,
 	provenance []byte, artifactHash string,
 	provenanceOpts *options.ProvenanceOpts,
 	builderOpts *options.BuilderOpts,
 ) ([]byte, *utils.TrustedBuilderID, error) {
-	verifier, err := getVerifier(builderOpts)
+	verifier, err := getVerifier(provenanceOpts, builderOpts)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -62,12 +118,15 @@ func VerifyArtifact(ctx context.Context,
 		provenanceOpts, builderOpts)
 }
 
+// VerifyNpmPackage verifies the attestations of the npm package tarball
+// with the given hex-encoded sha512 digest. It returns the verified
+// provenance and the ID of the builder that generated it.
 func VerifyNpmPackage(ctx context.Context,
 	attestations []byte, tarballHash string,
 	provenanceOpts *options.ProvenanceOpts,
 	builderOpts *options.BuilderOpts,
 ) ([]byte, *utils.TrustedBuilderID, error) {
-	verifier, err := getVerifier(builderOpts)
+	verifier, err := getVerifier(provenanceOpts, builderOpts)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -76,6 +135,17 @@ func VerifyNpmPackage(ctx context.Context,
 		provenanceOpts, builderOpts)
 }
 
+// VerifyPyPIPackage verifies the PEP 740 attestations of a Python
+// distribution published to PyPI with Trusted Publishing. The attestations
+// are signed by the publishing workflow, so only the GHA verifier supports them.
+func VerifyPyPIPackage(ctx context.Context,
+	attestations []byte, filename string,
+	provenanceOpts *options.ProvenanceOpts,
+	builderOpts *options.BuilderOpts,
+) ([]byte, *utils.TrustedBuilderID, error) {
+	return gha.VerifyPyPIPackage(ctx, attestations, filename, provenanceOpts, builderOpts)
+}
+
 // VerifyVSA verifies the VSA attestation. It returns the attestation base64-decoded from the envelope.
 // We don't return a TrustedBuilderID. Instead, the user can user can parse the builderID separately, perhaps with
 // https://pkg.go.dev/golang.org/x/mod/semver
@@ -86,3 +156,13 @@ func VerifyVSA(ctx context.Context,
 ) ([]byte, error) {
 	return vsa.VerifyVSA(ctx, attestation, vsaOpts, verificationOpts)
 }
+
+// GenerateVSA returns a DSSE envelope containing a VSA of a successful
+// verification, signed with the private key of signingOpts. The VSA can be
+// verified with VerifyVSA.
+func GenerateVSA(ctx context.Context,
+	vsaOpts *options.VSAGenerationOpts,
+	signingOpts *options.SigningOpts,
+) ([]byte, error) {
+	return vsa.GenerateVSA(ctx, vsaOpts, signingOpts)
+}